@@ -1,17 +1,33 @@
 package main
 
 import (
-	"log"
+	"context"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/jaskrrish/Go-OKD/internal/handlers"
+	"github.com/jaskrrish/Go-OKD/internal/logging"
+	models "github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd"
 	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
 )
 
 func main() {
+	// Log JSON records annotated with the request ID of the request that produced them
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logLevel(os.Getenv("QKD_LOG_LEVEL")),
+	})))
+	slog.SetDefault(logger)
+
 	// Get port from environment variable or use default
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -21,45 +37,224 @@ func main() {
 	// Create a new HTTP multiplexer
 	mux := http.NewServeMux()
 
-	// Initialize quantum backend (simulator for development)
-	quantumBackend := quantum.NewSimulatorBackend(true, 0.05) // 5% noise
-	qkdHandler := handlers.NewQKDHandler(quantumBackend)
+	// Initialize quantum backends. The simulator is always available; Braket, when
+	// configured, also becomes the default for sessions that do not name a backend.
+	simulator := quantum.NewSimulatorBackend(true, 0.05) // 5% noise
+	var quantumBackend quantum.QuantumBackend = simulator
+	if deviceArn := os.Getenv("QKD_BRAKET_DEVICE_ARN"); deviceArn != "" {
+		region := os.Getenv("QKD_BRAKET_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		braket, err := quantum.NewBraketBackendWithConfig(quantum.BraketConfig{
+			Region:              region,
+			DeviceArn:           deviceArn,
+			OutputS3Bucket:      os.Getenv("QKD_BRAKET_S3_BUCKET"),
+			OutputS3KeyPrefix:   os.Getenv("QKD_BRAKET_S3_PREFIX"),
+			FallbackToSimulator: os.Getenv("QKD_BRAKET_FALLBACK") == "true",
+		})
+		if err != nil {
+			fatal(logger, "failed to configure Braket backend", err)
+		}
+		quantumBackend = braket
+	}
+	sessionManager := qkd.NewSessionManager(quantumBackend)
+	sessionManager.RegisterBackend(models.BackendSimulator, simulator)
+	if apiToken := os.Getenv("QKD_QISKIT_API_TOKEN"); apiToken != "" {
+		qiskit, err := quantum.NewQiskitBackendWithConfig(quantum.QiskitBackendConfig{
+			QiskitConfig: quantum.QiskitConfig{
+				APIToken: apiToken,
+				BaseURL:  os.Getenv("QKD_QISKIT_BASE_URL"),
+				CRN:      os.Getenv("QKD_QISKIT_CRN"),
+				Backend:  os.Getenv("QKD_QISKIT_DEVICE"),
+			},
+			FallbackToSimulator: os.Getenv("QKD_QISKIT_FALLBACK") == "true",
+		})
+		if err != nil {
+			fatal(logger, "failed to configure Qiskit backend", err)
+		}
+		sessionManager.RegisterBackend(models.BackendQiskit, qiskit)
+	}
+	sessionManager.SetLogger(logger)
+	sessionManager.SetIDNormalization(qkd.DefaultIDNormalization())
+	sessionManager.SetRequirePostProcessing(true) // Never serve raw sifted keys
+	sessionManager.SetMaxRawQubits(envInt("QKD_MAX_RAW_QUBITS", qkd.DefaultMaxRawQubits))
+	sessionManager.SetOversamplingFactor(envInt("QKD_OVERSAMPLING_FACTOR", 0))
+	sessionManager.SetMaxConcurrentExchanges(envInt("QKD_MAX_CONCURRENT_EXCHANGES", 0))
+	exchangeTimeout := time.Duration(envInt("QKD_EXCHANGE_TIMEOUT_SECONDS", 0)) * time.Second
+	sessionManager.SetExchangeTimeout(exchangeTimeout)
+	if err := sessionManager.SetKeyTTL(time.Duration(envInt("QKD_KEY_TTL_MINUTES", int(qkd.DefaultKeyTTL/time.Minute))) * time.Minute); err != nil {
+		fatal(logger, "invalid QKD_KEY_TTL_MINUTES", err)
+	}
+	sessionManager.SetSubscriberLimits(
+		envInt("QKD_MAX_SUBSCRIBERS_PER_SESSION", qkd.DefaultMaxSubscribersPerSession),
+		envInt("QKD_MAX_SUBSCRIBERS", qkd.DefaultMaxSubscribers),
+	)
+	if path := os.Getenv("QKD_STORE_PATH"); path != "" {
+		storeKey, err := hex.DecodeString(os.Getenv("QKD_STORE_KEY"))
+		if err != nil {
+			fatal(logger, "QKD_STORE_KEY must be hex encoded", err)
+		}
+		store, err := qkd.NewBoltStore(path, storeKey)
+		if err != nil {
+			fatal(logger, "failed to open QKD store", err)
+		}
+		defer store.Close()
+		sessionManager.SetStore(store)
+	}
+	if secret := os.Getenv("QKD_WEBHOOK_SECRET"); secret != "" {
+		notifier := qkd.NewWebhookNotifier([]byte(secret))
+		// Callbacks to loopback and private addresses are refused unless receivers run internally
+		notifier.SetAllowPrivateNetworks(os.Getenv("QKD_WEBHOOK_ALLOW_PRIVATE_NETWORKS") == "true")
+		sessionManager.SetWebhookNotifier(notifier)
+	}
+	if size := envInt("QKD_KEY_POOL_SIZE", 0); size > 0 {
+		if err := sessionManager.StartKeyPool(envInt("QKD_KEY_POOL_KEY_LENGTH", 256), size); err != nil {
+			fatal(logger, "invalid QKD_KEY_POOL_KEY_LENGTH", err)
+		}
+	}
+	qkdHandler := handlers.NewQKDHandlerWithManager(sessionManager)
+
+	// Remove expired sessions and keys in the background
+	sessionManager.StartCleanupLoop(time.Duration(envInt("QKD_CLEANUP_INTERVAL_SECONDS", int(qkd.DefaultCleanupInterval/time.Second))) * time.Second)
+
+	// Session setup and key retrieval identify callers by the subject of an HS256 bearer token
+	jwtSecret := os.Getenv("QKD_JWT_SECRET")
+	if jwtSecret == "" {
+		logger.Warn("QKD_JWT_SECRET is not set; session setup and key retrieval endpoints will reject every request")
+	}
+	auth := handlers.NewJWTAuthenticator([]byte(jwtSecret), os.Getenv("QKD_JWT_ISSUER"))
+
+	// Browser clients may only call the API from the origins listed in QKD_CORS_ALLOWED_ORIGINS
+	corsConfig := handlers.DefaultCORSConfig()
+	for _, origin := range strings.Split(os.Getenv("QKD_CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			corsConfig.AllowedOrigins = append(corsConfig.AllowedOrigins, origin)
+		}
+	}
+	corsConfig.AllowCredentials = os.Getenv("QKD_CORS_ALLOW_CREDENTIALS") == "true"
+	cors := handlers.NewCORS(corsConfig)
+
+	// Compress large responses for clients that accept it; key material is never compressed
+	compressor := handlers.NewCompressor(envInt("QKD_COMPRESSION_MIN_BYTES", handlers.DefaultCompressionMinSize))
+
+	// Throttle per user (or per IP for anonymous callers), most tightly on key exchanges
+	limiter := handlers.NewRateLimiter(handlers.DefaultRateLimitRules())
+
+	// Retried joins and key exchanges carrying X-Idempotency-Key replay the first response
+	idempotency := handlers.NewIdempotencyCache(handlers.DefaultIdempotencyTTL)
 
 	// Register existing routes
 	mux.HandleFunc("/", handlers.HomeHandler)
 	mux.HandleFunc("/health", handlers.HealthHandler)
 	mux.HandleFunc("/api/v1/users", handlers.UsersHandler)
+	mux.Handle("/metrics", sessionManager.Metrics().Registry.Handler())
+	mux.HandleFunc("/openapi.json", handlers.OpenAPIHandler)
 
 	// Register QKD routes
 	mux.HandleFunc("/api/v1/qkd/health", qkdHandler.HealthCheckHandler)
 	mux.HandleFunc("/api/v1/qkd/session/initiate", qkdHandler.InitiateSessionHandler)
-	mux.HandleFunc("/api/v1/qkd/session/join", qkdHandler.JoinSessionHandler)
-	mux.HandleFunc("/api/v1/qkd/session/", handleQKDSession(qkdHandler))
+	mux.Handle("/api/v1/qkd/session/join", idempotency.Middleware(http.HandlerFunc(qkdHandler.JoinSessionHandler)))
+	mux.HandleFunc("/api/v1/qkd/sessions", qkdHandler.ListSessionsHandler)
+	mux.Handle("/api/v1/qkd/session/", idempotency.Middleware(handleQKDSession(qkdHandler)))
 	mux.HandleFunc("/api/v1/qkd/key/", handleQKDKey(qkdHandler))
+	mux.HandleFunc("/api/v1/qkd/bases", qkdHandler.BasesHandler)
+	mux.HandleFunc("/api/v1/qkd/reconcile", qkdHandler.ReconcileHandler)
+	mux.HandleFunc("/api/v1/qkd/compare", qkdHandler.CompareProtocolsHandler)
+	mux.HandleFunc("/api/v1/qkd/analyze", qkdHandler.AnalyzeChannelHandler)
+	mux.HandleFunc("/api/v1/qkd/estimate", qkdHandler.EstimateFeasibilityHandler)
+	mux.HandleFunc("/api/v1/qkd/random", qkdHandler.RandomHandler)
+	mux.HandleFunc("/api/v1/qkd/pool", qkdHandler.KeyPoolHandler)
+	mux.HandleFunc("/api/v1/qkd/channel/bell", qkdHandler.BellTestHandler)
 
-	// Create server with timeouts
+	// Register ETSI GS QKD 014 key delivery routes
+	mux.HandleFunc("/api/v1/keys/", handleETSIKeys(qkdHandler))
+
+	// Create server with timeouts. Responses may take as long as a synchronous
+	// exchange is allowed to run.
+	writeTimeout := max(15*time.Second, exchangeTimeout+5*time.Second)
 	server := &http.Server{
 		Addr:         ":" + port,
-		Handler:      loggingMiddleware(mux),
+		Handler:      logging.Middleware(logger, compressor.Middleware(cors.Middleware(auth.Middleware(limiter.Middleware(mux))))),
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		WriteTimeout: writeTimeout,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	// SIGINT or SIGTERM starts a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		fatal(logger, "server failed to start", err)
 	}
+	shutdownTimeout := time.Duration(envInt("QKD_SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second
+
+	logger.Info("server starting", "port", port)
+	if err := serve(ctx, server, listener, shutdownTimeout); err != nil {
+		logger.Error("server stopped with error", "error", err)
+	}
+
+	// Let background exchanges finish before the store is closed
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := sessionManager.Shutdown(drainCtx); err != nil {
+		logger.Warn("aborted background key exchanges at shutdown", "error", err)
+	}
+	logger.Info("server stopped")
+}
+
+// serve runs server on listener until ctx is done, then stops accepting
+// connections and waits up to shutdownTimeout for in-flight requests to finish.
+// Requests still running after the timeout have their connections closed, which
+// cancels their key exchanges.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// envInt reads an integer from the environment, falling back to def when unset or invalid
+func envInt(name string, def int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return def
+}
+
+// logLevel parses QKD_LOG_LEVEL ("debug", "info", "warn" or "error"), defaulting to info
+func logLevel(value string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
 }
 
-// loggingMiddleware logs all incoming requests
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		log.Printf("%s %s %s", r.Method, r.RequestURI, r.RemoteAddr)
-		next.ServeHTTP(w, r)
-		log.Printf("Request completed in %v", time.Since(start))
-	})
+// fatal logs a startup error and exits
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
 
 // handleQKDSession routes QKD session-related requests
@@ -68,16 +263,60 @@ func handleQKDSession(qkdHandler *handlers.QKDHandler) http.HandlerFunc {
 		path := r.URL.Path
 		if strings.HasSuffix(path, "/execute") {
 			qkdHandler.ExecuteKeyExchangeHandler(w, r)
+		} else if strings.HasSuffix(path, "/execute/batch") {
+			qkdHandler.BatchKeyExchangeHandler(w, r)
+		} else if strings.HasSuffix(path, "/retry") {
+			qkdHandler.RetryKeyExchangeHandler(w, r)
+		} else if strings.HasSuffix(path, "/events") {
+			qkdHandler.SessionEventsHandler(w, r)
+		} else if strings.HasSuffix(path, "/ws") {
+			qkdHandler.SessionWebSocketHandler(w, r)
+		} else if strings.HasSuffix(path, "/disclosures") {
+			qkdHandler.DisclosuresHandler(w, r)
+		} else if strings.HasSuffix(path, "/transcript") {
+			qkdHandler.TranscriptHandler(w, r)
+		} else if strings.HasSuffix(path, "/metrics") {
+			qkdHandler.SessionMetricsHandler(w, r)
+		} else if strings.HasSuffix(path, "/status") {
+			qkdHandler.SessionStatusHandler(w, r)
 		} else {
 			qkdHandler.GetSessionHandler(w, r)
 		}
 	}
 }
 
+// handleETSIKeys routes ETSI GS QKD 014 key delivery requests
+func handleETSIKeys(qkdHandler *handlers.QKDHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if strings.HasSuffix(path, "/status") {
+			qkdHandler.ETSIStatusHandler(w, r)
+		} else if strings.HasSuffix(path, "/enc_keys") {
+			qkdHandler.ETSIEncKeysHandler(w, r)
+		} else if strings.HasSuffix(path, "/dec_keys") {
+			qkdHandler.ETSIDecKeysHandler(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+	}
+}
+
 // handleQKDKey routes QKD key-related requests
 func handleQKDKey(qkdHandler *handlers.QKDHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodDelete {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/qkd/key/by-label/") {
+			qkdHandler.GetKeyByLabelHandler(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/derive") {
+			qkdHandler.DeriveKeyHandler(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/info") {
+			qkdHandler.KeyInfoHandler(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/consume") {
+			qkdHandler.ConsumeKeyHandler(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/download") {
+			qkdHandler.DownloadKeyHandler(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/rotate") {
+			qkdHandler.RotateKeyHandler(w, r)
+		} else if r.Method == http.MethodDelete {
 			qkdHandler.RevokeKeyHandler(w, r)
 		} else {
 			qkdHandler.GetKeyHandler(w, r)
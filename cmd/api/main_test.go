@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServeFinishesInFlightRequestOnShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, ts.Config, ts.Listener, 5*time.Second)
+	}()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ts.Listener.Addr().String())
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	// Shut down while the request is still being handled
+	<-started
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("Expected serve to wait for the in-flight request, returned %v", err)
+	default:
+	}
+	close(release)
+
+	res := <-responses
+	if res.err != nil || res.status != http.StatusOK || res.body != "done" {
+		t.Errorf("Expected the in-flight request to complete, got status %d, body %q, error %v", res.status, res.body, res.err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestServeClosesRequestsAfterTimeout(t *testing.T) {
+	started := make(chan struct{})
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, ts.Config, ts.Listener, 50*time.Millisecond)
+	}()
+
+	requested := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + ts.Listener.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+		requested <- err
+	}()
+
+	<-started
+	cancel()
+
+	if err := <-served; err != context.DeadlineExceeded {
+		t.Errorf("Expected the shutdown to time out, got %v", err)
+	}
+	if err := <-requested; err == nil {
+		t.Error("Expected the stuck request's connection to be closed")
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"log"
@@ -59,7 +60,7 @@ func demoSimpleKeyExchange() {
 	fmt.Println("Alice & Bob: Discarding mismatched bases (key sifting)...")
 	fmt.Println("Alice & Bob: Estimating QBER...")
 
-	result, err := bb84.PerformKeyExchange()
+	result, err := bb84.PerformKeyExchange(context.Background())
 	if err != nil {
 		log.Fatalf("Key exchange failed: %v", err)
 	}
@@ -82,7 +83,7 @@ func demoRealisticKeyExchange() {
 	fmt.Println("Simulating realistic quantum channel with 5% noise...")
 	fmt.Println("(Noise from photon loss, detector errors, etc.)")
 
-	result, err := bb84.PerformKeyExchange()
+	result, err := bb84.PerformKeyExchange(context.Background())
 	if err != nil {
 		log.Fatalf("Key exchange failed: %v", err)
 	}
@@ -105,7 +106,7 @@ func demoEavesdropperDetection() {
 	fmt.Println("Eve is intercepting and measuring qubits...")
 	fmt.Println("This introduces errors due to quantum no-cloning theorem...")
 
-	result, err := bb84.PerformKeyExchange()
+	result, err := bb84.PerformKeyExchange(context.Background())
 	if err != nil {
 		log.Fatalf("Key exchange failed: %v", err)
 	}
@@ -133,13 +134,13 @@ func demoFullProtocol() {
 
 	// Step 1: Quantum transmission
 	fmt.Println("Step 1: Quantum Transmission")
-	alice, err := bb84.AliceGenerateQubits()
+	alice, err := bb84.AliceGenerateQubits(context.Background())
 	if err != nil {
 		log.Fatal(err)
 	}
 	fmt.Printf("  Alice generated %d qubits\n", len(alice.Qubits))
 
-	bob, err := bb84.BobMeasureQubits(alice.Qubits)
+	bob, err := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -153,7 +154,7 @@ func demoFullProtocol() {
 	}
 	fmt.Printf("  Sifted key: %d bits (%.1f%% efficiency)\n",
 		len(sifted.AliceKey),
-		float64(len(sifted.AliceKey))/float64(len(alice.Bits))*100)
+		sifted.Efficiency(sifted.RawLength)*100)
 
 	// Step 3: Error estimation
 	fmt.Println("\nStep 3: Error Detection")
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Errors returned when a bearer token fails validation
+var (
+	ErrMissingToken   = errors.New("missing bearer token")
+	ErrMalformedToken = errors.New("malformed token")
+	ErrUnsupportedAlg = errors.New("unsupported token algorithm")
+	ErrBadSignature   = errors.New("invalid token signature")
+	ErrTokenExpired   = errors.New("token has expired")
+	ErrTokenNotYet    = errors.New("token is not valid yet")
+	ErrBadIssuer      = errors.New("invalid token issuer")
+	ErrMissingSubject = errors.New("token has no subject")
+)
+
+// userIDKey is the context key under which the authenticated user ID is stored
+type userIDKey struct{}
+
+// WithUserID returns a copy of ctx carrying an authenticated user ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the authenticated user ID, if any
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// jwtHeader is the JOSE header of a compact JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// jwtClaims are the registered claims the authenticator checks
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// JWTAuthenticator validates HS256-signed JWTs and exposes their subject as the caller's identity
+type JWTAuthenticator struct {
+	signingKey []byte
+	issuer     string // Required "iss" claim; empty accepts any issuer
+	leeway     time.Duration
+	now        func() time.Time
+}
+
+// NewJWTAuthenticator creates an authenticator for tokens signed with signingKey.
+// If issuer is non-empty, tokens must carry a matching "iss" claim.
+func NewJWTAuthenticator(signingKey []byte, issuer string) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		signingKey: signingKey,
+		issuer:     issuer,
+		leeway:     30 * time.Second,
+		now:        time.Now,
+	}
+}
+
+// IssueToken signs a token for subject that expires after ttl
+func (a *JWTAuthenticator) IssueToken(subject string, ttl time.Duration) (string, error) {
+	now := a.now()
+	return a.sign(jwtClaims{
+		Subject:   subject,
+		Issuer:    a.issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+}
+
+// sign encodes and signs claims as a compact HS256 JWT
+func (a *JWTAuthenticator) sign(claims jwtClaims) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(a.mac(signingInput)), nil
+}
+
+// mac computes the HS256 signature of the signing input
+func (a *JWTAuthenticator) mac(signingInput string) []byte {
+	m := hmac.New(sha256.New, a.signingKey)
+	m.Write([]byte(signingInput))
+	return m.Sum(nil)
+}
+
+// Verify validates a compact JWT and returns its subject
+func (a *JWTAuthenticator) Verify(token string) (string, error) {
+	// An empty HMAC key would let anyone mint valid tokens
+	if len(a.signingKey) == 0 {
+		return "", ErrBadSignature
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrMalformedToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", ErrMalformedToken
+	}
+	if header.Alg != "HS256" {
+		return "", ErrUnsupportedAlg
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformedToken
+	}
+	if !hmac.Equal(signature, a.mac(parts[0]+"."+parts[1])) {
+		return "", ErrBadSignature
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", ErrMalformedToken
+	}
+
+	now := a.now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(a.leeway)) {
+		return "", ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(a.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return "", ErrTokenNotYet
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return "", ErrBadIssuer
+	}
+	if claims.Subject == "" {
+		return "", ErrMissingSubject
+	}
+
+	return claims.Subject, nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Middleware authenticates requests carrying an "Authorization: Bearer" token and
+// stores the token subject in the request context. Requests without a token pass
+// through unauthenticated; requests with an invalid token are rejected with 401.
+func (a *JWTAuthenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			respondWithError(w, http.StatusUnauthorized, ErrMissingToken.Error())
+			return
+		}
+
+		userID, err := a.Verify(strings.TrimSpace(token))
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
+	})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testAuth signs and verifies the tokens used by handler tests
+var testAuth = NewJWTAuthenticator([]byte("test-signing-key"), "go-okd-test")
+
+// setBearerToken authenticates req as userID with a fresh token
+func setBearerToken(t *testing.T, req *http.Request, userID string) {
+	t.Helper()
+
+	token, err := testAuth.IssueToken(userID, time.Minute)
+	if err != nil {
+		t.Fatalf("IssueToken failed: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+}
+
+func TestJWTVerify(t *testing.T) {
+	valid, err := testAuth.IssueToken("alice", time.Minute)
+	if err != nil {
+		t.Fatalf("IssueToken failed: %v", err)
+	}
+
+	expired, _ := testAuth.sign(jwtClaims{Subject: "alice", Issuer: "go-okd-test", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+	noExpiry, _ := testAuth.sign(jwtClaims{Subject: "alice", Issuer: "go-okd-test"})
+	notYet, _ := testAuth.sign(jwtClaims{Subject: "alice", Issuer: "go-okd-test", ExpiresAt: time.Now().Add(2 * time.Hour).Unix(), NotBefore: time.Now().Add(time.Hour).Unix()})
+	noSubject, _ := testAuth.sign(jwtClaims{Issuer: "go-okd-test", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	forged, _ := NewJWTAuthenticator([]byte("attacker-key"), "go-okd-test").IssueToken("alice", time.Minute)
+	wrongIssuer, _ := NewJWTAuthenticator([]byte("test-signing-key"), "someone-else").IssueToken("alice", time.Minute)
+
+	// Re-sign the valid payload with a different subject but keep the original signature
+	parts := strings.Split(valid, ".")
+	tamperedPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory","iss":"go-okd-test","exp":9999999999}`))
+	tampered := parts[0] + "." + tamperedPayload + "." + parts[2]
+
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	algNone := noneHeader + "." + parts[1] + "."
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", valid, nil},
+		{"expired", expired, ErrTokenExpired},
+		{"no expiry", noExpiry, ErrTokenExpired},
+		{"not yet valid", notYet, ErrTokenNotYet},
+		{"no subject", noSubject, ErrMissingSubject},
+		{"forged", forged, ErrBadSignature},
+		{"tampered", tampered, ErrBadSignature},
+		{"alg none", algNone, ErrUnsupportedAlg},
+		{"wrong issuer", wrongIssuer, ErrBadIssuer},
+		{"malformed", "not-a-token", ErrMalformedToken},
+	}
+
+	for _, tt := range tests {
+		subject, err := testAuth.Verify(tt.token)
+		if err != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+		if tt.want == nil && subject != "alice" {
+			t.Errorf("%s: expected subject alice, got %q", tt.name, subject)
+		}
+	}
+}
+
+func TestJWTVerifyRejectsEmptySigningKey(t *testing.T) {
+	unconfigured := NewJWTAuthenticator(nil, "")
+	token, err := unconfigured.IssueToken("alice", time.Minute)
+	if err != nil {
+		t.Fatalf("IssueToken failed: %v", err)
+	}
+
+	if _, err := unconfigured.Verify(token); err != ErrBadSignature {
+		t.Errorf("Expected tokens to be rejected without a signing key, got %v", err)
+	}
+}
+
+func TestGetKeyRequiresValidToken(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+	handler := testAuth.Middleware(http.HandlerFunc(h.GetKeyHandler))
+	path := "/api/v1/qkd/key/" + key.KeyID.String()
+
+	expired, _ := testAuth.sign(jwtClaims{Subject: "alice", Issuer: "go-okd-test", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+	forged, _ := NewJWTAuthenticator([]byte("attacker-key"), "go-okd-test").IssueToken("alice", time.Minute)
+	mallory, _ := testAuth.IssueToken("mallory", time.Minute)
+
+	tests := []struct {
+		name   string
+		header func(req *http.Request)
+		want   int
+	}{
+		{"alice", func(req *http.Request) { setBearerToken(t, req, "alice") }, http.StatusOK},
+		{"bob", func(req *http.Request) { setBearerToken(t, req, "bob") }, http.StatusOK},
+		{"third party", func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+mallory) }, http.StatusForbidden},
+		{"expired", func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+expired) }, http.StatusUnauthorized},
+		{"forged", func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+forged) }, http.StatusUnauthorized},
+		{"not bearer", func(req *http.Request) { req.Header.Set("Authorization", "Basic YWxpY2U6") }, http.StatusUnauthorized},
+		{"no token", func(req *http.Request) {}, http.StatusUnauthorized},
+		{"legacy header", func(req *http.Request) { req.Header.Set("X-User-ID", "alice") }, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		tt.header(req)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+		if tt.want != http.StatusOK && strings.Contains(rec.Body.String(), "key_hex") {
+			t.Errorf("%s: key material leaked in a rejected response", tt.name)
+		}
+	}
+}
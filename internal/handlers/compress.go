@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompressionMinSize is the smallest response body worth compressing
+const DefaultCompressionMinSize = 1024
+
+// Compressor compresses response bodies with gzip or deflate when the client
+// accepts it. Bodies smaller than the threshold, responses that are already
+// encoded or use an already-compressed content type, event streams and
+// responses marked Cache-Control: no-transform are sent as they are. Key
+// material is always sent with no-transform, so it is never compressed.
+type Compressor struct {
+	minSize int
+}
+
+// NewCompressor creates a compression middleware for bodies of at least minSize bytes
+func NewCompressor(minSize int) *Compressor {
+	if minSize < 0 {
+		minSize = 0
+	}
+	return &Compressor{minSize: minSize}
+}
+
+// Middleware compresses responses according to the request's Accept-Encoding
+func (c *Compressor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: c.minSize}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks gzip, then deflate, from an Accept-Encoding header,
+// honoring q-values. It returns "" if neither is acceptable.
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if value, ok := strings.CutPrefix(param, "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		accepted[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{"gzip", "deflate"} {
+		q, ok := accepted[encoding]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// incompressibleTypes are content types whose bodies are already compressed
+var incompressibleTypes = []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/octet-stream", "text/event-stream"}
+
+// compressWriter buffers the start of a response until it knows whether the body
+// reaches the size threshold, then either compresses it or passes it through
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status   int
+	buf      bytes.Buffer
+	decided  bool
+	encoder  io.WriteCloser // nil when passing through
+	hijacked bool
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.decided {
+		return cw.write(p)
+	}
+	if !cw.compressible() {
+		cw.start(false)
+		return cw.write(p)
+	}
+
+	cw.buf.Write(p)
+	if cw.buf.Len() >= cw.minSize {
+		if err := cw.flushBuffer(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (cw *compressWriter) write(p []byte) (int, error) {
+	if cw.encoder != nil {
+		return cw.encoder.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// compressible reports whether the headers set so far allow compression
+func (cw *compressWriter) compressible() bool {
+	if cw.status < http.StatusOK || cw.status == http.StatusNoContent || cw.status == http.StatusNotModified {
+		return false
+	}
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-transform") {
+		return false
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// start writes the status line and headers, choosing whether to compress
+func (cw *compressWriter) start(compress bool) {
+	cw.decided = true
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		if cw.encoding == "gzip" {
+			cw.encoder = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			// flate.NewWriter only fails for an invalid level
+			cw.encoder, _ = flate.NewWriter(cw.ResponseWriter, flate.DefaultCompression)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// flushBuffer decides on compression and writes out the buffered body
+func (cw *compressWriter) flushBuffer(compress bool) error {
+	cw.start(compress && cw.compressible())
+	_, err := cw.write(cw.buf.Bytes())
+	cw.buf.Reset()
+	return err
+}
+
+// Flush sends what has been written so far. A response flushed before it reaches
+// the threshold, such as an event stream, is sent uncompressed.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		if err := cw.flushBuffer(false); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.encoder.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades take over the connection
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, rw, err := hj.Hijack()
+	if err == nil {
+		cw.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Close finishes the response: a body still buffered is below the threshold and
+// is sent uncompressed
+func (cw *compressWriter) Close() error {
+	if cw.hijacked {
+		return nil
+	}
+	if !cw.decided {
+		if cw.status == 0 {
+			// Nothing was written; let net/http send its default response
+			return nil
+		}
+		if err := cw.flushBuffer(false); err != nil {
+			return err
+		}
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+func TestCompressorGzipsLargeSessionList(t *testing.T) {
+	h, sm := newTestHandler()
+	for i := 0; i < 20; i++ {
+		createTestSession(t, sm)
+	}
+
+	handler := NewCompressor(DefaultCompressionMinSize).Middleware(testAuth.Middleware(http.HandlerFunc(h.ListSessionsHandler)))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/sessions", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	setBearerToken(t, req, "alice")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Expected a gzipped session list, got Content-Encoding %q", got)
+	}
+
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader failed: %v", err)
+	}
+	var resp qkd.SessionListResponse
+	if err := json.NewDecoder(zr).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode decompressed response: %v", err)
+	}
+	if len(resp.Sessions) != 20 {
+		t.Errorf("Expected 20 sessions, got %d", len(resp.Sessions))
+	}
+}
+
+func TestCompressorNeverCompressesKeys(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+
+	// A zero threshold would compress any other response
+	handler := NewCompressor(0).Middleware(testAuth.Middleware(http.HandlerFunc(h.GetKeyHandler)))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/"+key.KeyID.String(), nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	setBearerToken(t, req, "alice")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("Expected key material to be sent uncompressed, got Content-Encoding %q", got)
+	}
+	var resp qkd.KeyResponse
+	decodeJSON(t, rec, &resp)
+	if resp.KeyID != key.KeyID.String() {
+		t.Errorf("Expected key %s, got %s", key.KeyID, resp.KeyID)
+	}
+}
+
+func TestCompressorSkipsSmallResponses(t *testing.T) {
+	handler := NewCompressor(DefaultCompressionMinSize).Middleware(http.HandlerFunc(HealthHandler))
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Expected a small response to be sent uncompressed, got Content-Encoding %q", got)
+	}
+	if rec.Body.Len() == 0 {
+		t.Error("Expected the response body to be passed through")
+	}
+}
+
+func TestCompressorDeflateAndNegotiation(t *testing.T) {
+	body := make([]byte, 4096)
+	handler := NewCompressor(1024).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"br", ""},
+		{"deflate", "deflate"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", "gzip"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", tt.accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+			t.Errorf("%q: expected Content-Encoding %q, got %q", tt.accept, tt.want, got)
+			continue
+		}
+		if tt.want != "deflate" {
+			continue
+		}
+		decoded, err := io.ReadAll(flate.NewReader(rec.Body))
+		if err != nil || len(decoded) != len(body) {
+			t.Errorf("%q: expected %d decompressed bytes, got %d (%v)", tt.accept, len(body), len(decoded), err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig controls which browser origins may call the API. An origin of "*"
+// allows any origin. With no allowed origins, cross-origin requests are rejected.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string // Response headers scripts may read
+	AllowCredentials bool
+	MaxAge           time.Duration // How long browsers may cache a preflight result
+}
+
+// DefaultCORSConfig allows the methods and headers the API uses, but no origins
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete},
+		AllowedHeaders: []string{"Authorization", "Content-Type", "If-None-Match", "X-Idempotency-Key", "X-Request-ID"},
+		ExposedHeaders: []string{"ETag", "Idempotent-Replayed", "Location", "Retry-After", "X-Request-ID"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// CORS answers preflight requests and adds CORS headers for allowed origins
+type CORS struct {
+	config    CORSConfig
+	anyOrigin bool
+	origins   map[string]bool
+	methods   map[string]bool
+}
+
+// NewCORS creates a CORS middleware from config
+func NewCORS(config CORSConfig) *CORS {
+	c := &CORS{
+		config:  config,
+		origins: make(map[string]bool),
+		methods: make(map[string]bool),
+	}
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	for _, method := range config.AllowedMethods {
+		c.methods[strings.ToUpper(method)] = true
+	}
+	return c
+}
+
+// Middleware passes requests without an Origin header through unchanged. Requests
+// from an origin that is not allowed are rejected with 403, preflight requests
+// from an allowed origin are answered with 204, and other requests from an
+// allowed origin get CORS headers and are passed on.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !c.anyOrigin && !c.origins[origin] {
+			respondWithError(w, http.StatusForbidden, "Origin not allowed")
+			return
+		}
+
+		// A wildcard cannot be combined with credentials, so echo the origin instead
+		if c.anyOrigin && !c.config.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.config.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		requestedMethod := r.Header.Get("Access-Control-Request-Method")
+		if r.Method != http.MethodOptions || requestedMethod == "" {
+			if len(c.config.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.config.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Preflight
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		if !c.methods[strings.ToUpper(requestedMethod)] {
+			respondWithError(w, http.StatusForbidden, "Method not allowed by CORS policy")
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.config.AllowedMethods, ", "))
+		if len(c.config.AllowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.config.AllowedHeaders, ", "))
+		}
+		if c.config.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.config.MaxAge/time.Second)))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
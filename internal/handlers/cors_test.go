@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestCORS returns the middleware for config in front of an OK handler
+func newTestCORS(config CORSConfig) http.Handler {
+	return NewCORS(config).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+// corsRequest sends a request from origin and returns the response
+func corsRequest(handler http.Handler, method, origin, requestMethod string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/qkd/session/initiate", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSPreflight(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"https://dashboard.example.com"}
+	handler := newTestCORS(config)
+
+	rec := corsRequest(handler, http.MethodOptions, "https://dashboard.example.com", http.MethodPost)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for an allowed preflight, got %d", rec.Code)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://dashboard.example.com",
+		"Access-Control-Allow-Methods": "GET, POST, DELETE",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type, If-None-Match, X-Idempotency-Key, X-Request-ID",
+		"Access-Control-Max-Age":       "600",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s: expected %q, got %q", header, want, got)
+		}
+	}
+	if rec.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Error("Expected no credentials header when credentials are not allowed")
+	}
+
+	if rec := corsRequest(handler, http.MethodOptions, "https://dashboard.example.com", http.MethodPut); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a preflight of a disallowed method, got %d", rec.Code)
+	}
+
+	rec = corsRequest(handler, http.MethodPost, "https://dashboard.example.com", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://dashboard.example.com" {
+		t.Errorf("Expected an allowed request to pass with CORS headers, got %d %v", rec.Code, rec.Header())
+	}
+	if rec.Header().Get("Access-Control-Expose-Headers") == "" {
+		t.Error("Expected response headers to be exposed")
+	}
+}
+
+func TestCORSRejectsDisallowedOrigin(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"https://dashboard.example.com"}
+	handler := newTestCORS(config)
+
+	for _, method := range []string{http.MethodOptions, http.MethodPost} {
+		rec := corsRequest(handler, method, "https://evil.example.com", http.MethodPost)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for a disallowed origin, got %d", method, rec.Code)
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s: expected no Access-Control-Allow-Origin for a disallowed origin", method)
+		}
+	}
+
+	// Requests without an Origin header, such as from other servers, are unaffected
+	if rec := corsRequest(handler, http.MethodPost, "", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 without an Origin header, got %d", rec.Code)
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"*"}
+
+	rec := corsRequest(newTestCORS(config), http.MethodGet, "https://any.example.com", "")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected a wildcard origin, got %q", got)
+	}
+
+	// Credentials cannot be combined with a wildcard, so the origin is echoed
+	config.AllowCredentials = true
+	rec = corsRequest(newTestCORS(config), http.MethodGet, "https://any.example.com", "")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://any.example.com" {
+		t.Errorf("Expected the origin to be echoed with credentials, got %q", got)
+	}
+	if rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Error("Expected Access-Control-Allow-Credentials: true")
+	}
+}
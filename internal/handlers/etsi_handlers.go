@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	qkdcore "github.com/jaskrrish/Go-OKD/internal/qkd"
+)
+
+// ETSI GS QKD 014 endpoints identify the calling SAE by the authenticated user ID
+// and the peer SAE by the path: /api/v1/keys/{SAE_ID}/{operation}
+
+// ETSIStatusHandler handles GET /api/v1/keys/{slave_SAE_ID}/status
+func (h *QKDHandler) ETSIStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	masterSAE, slaveSAE, ok := etsiSAEs(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.sessionManager.ETSIStatus(masterSAE, slaveSAE)
+	if err != nil {
+		respondWithETSIError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+// ETSIEncKeysHandler handles GET /api/v1/keys/{slave_SAE_ID}/enc_keys?number=N&size=bits
+func (h *QKDHandler) ETSIEncKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	masterSAE, slaveSAE, ok := etsiSAEs(w, r)
+	if !ok {
+		return
+	}
+
+	number, err := etsiQueryInt(r, "number", 1)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]string{"message": qkd.ErrInvalidETSIKeyNumber.Error()})
+		return
+	}
+	size, err := etsiQueryInt(r, "size", qkdcore.ETSIDefaultKeySize)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]string{"message": qkd.ErrInvalidETSIKeySize.Error()})
+		return
+	}
+
+	keys, err := h.sessionManager.ETSIEncKeys(masterSAE, slaveSAE, number, size)
+	if err != nil {
+		respondWithETSIError(w, err)
+		return
+	}
+
+	respondWithSecret(w, http.StatusOK, keys)
+}
+
+// ETSIDecKeysHandler handles POST /api/v1/keys/{master_SAE_ID}/dec_keys
+func (h *QKDHandler) ETSIDecKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	slaveSAE, masterSAE, ok := etsiSAEs(w, r)
+	if !ok {
+		return
+	}
+
+	var req qkd.ETSIKeyIDsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
+		return
+	}
+
+	keyIDs := make([]uuid.UUID, len(req.KeyIDs))
+	for i, entry := range req.KeyIDs {
+		keyID, err := uuid.Parse(entry.KeyID)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, map[string]string{"message": "Invalid key_ID: " + entry.KeyID})
+			return
+		}
+		keyIDs[i] = keyID
+	}
+
+	keys, err := h.sessionManager.ETSIDecKeys(slaveSAE, masterSAE, keyIDs)
+	if err != nil {
+		respondWithETSIError(w, err)
+		return
+	}
+
+	respondWithSecret(w, http.StatusOK, keys)
+}
+
+// etsiSAEs returns the calling SAE and the SAE named in the path
+func etsiSAEs(w http.ResponseWriter, r *http.Request) (caller, peer string, ok bool) {
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) != 6 || pathParts[4] == "" {
+		respondWithJSON(w, http.StatusBadRequest, map[string]string{"message": "Invalid URL format"})
+		return "", "", false
+	}
+
+	caller, ok = UserIDFromContext(r.Context())
+	if !ok {
+		respondWithJSON(w, http.StatusUnauthorized, map[string]string{"message": "SAE authentication required"})
+		return "", "", false
+	}
+
+	return caller, pathParts[4], true
+}
+
+// etsiQueryInt parses an optional integer query parameter
+func etsiQueryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// respondWithETSIError sends an error in the ETSI GS QKD 014 format, using the
+// status codes the specification defines
+func respondWithETSIError(w http.ResponseWriter, err error) {
+	status := http.StatusServiceUnavailable
+	switch err {
+	case qkd.ErrUnauthorized:
+		status = http.StatusUnauthorized
+	case qkd.ErrInvalidETSIKeyNumber, qkd.ErrInvalidETSIKeySize, qkd.ErrInsufficientKeys,
+		qkd.ErrKeyNotFound, qkd.ErrKeyExpired, qkd.ErrKeyInactive:
+		status = http.StatusBadRequest
+	}
+
+	respondWithJSON(w, status, map[string]string{"message": err.Error()})
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// etsiRequest calls an ETSI handler through the auth middleware as sae
+func etsiRequest(t *testing.T, handler http.HandlerFunc, method, path, sae string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	setBearerToken(t, req, sae)
+	rec := httptest.NewRecorder()
+	testAuth.Middleware(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+// etsiStatus returns the stored key count alice sees for bob
+func etsiStatus(t *testing.T, h *QKDHandler) int {
+	t.Helper()
+
+	rec := etsiRequest(t, h.ETSIStatusHandler, http.MethodGet, "/api/v1/keys/bob/status", "alice", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var status qkd.ETSIStatus
+	decodeJSON(t, rec, &status)
+	if status.MasterSAEID != "alice" || status.SlaveSAEID != "bob" {
+		t.Errorf("Unexpected SAE IDs in status %+v", status)
+	}
+	return status.StoredKeyCount
+}
+
+func TestETSIKeyDeliveryFlow(t *testing.T) {
+	h, sm := newTestHandler()
+	first := createTestKey(t, sm, "")
+	second := createTestKey(t, sm, "")
+
+	if count := etsiStatus(t, h); count != 2 {
+		t.Fatalf("Expected 2 stored keys, got %d", count)
+	}
+
+	// The master SAE requests two 64-bit keys for the slave
+	rec := etsiRequest(t, h.ETSIEncKeysHandler, http.MethodGet, "/api/v1/keys/bob/enc_keys?number=2&size=64", "alice", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enc_keys: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var enc qkd.ETSIKeyContainer
+	decodeJSON(t, rec, &enc)
+	if len(enc.Keys) != 2 {
+		t.Fatalf("Expected 2 keys, got %d", len(enc.Keys))
+	}
+	for i, source := range []*qkd.QuantumKey{first, second} {
+		material, _ := base64.StdEncoding.DecodeString(enc.Keys[i].Key)
+		if enc.Keys[i].KeyID != source.KeyID.String() || !bytes.Equal(material, source.KeyMaterial[:8]) {
+			t.Errorf("Key %d does not match the oldest stored key truncated to 64 bits", i)
+		}
+	}
+
+	if count := etsiStatus(t, h); count != 0 {
+		t.Errorf("Expected delivered keys to leave the pool, got %d", count)
+	}
+
+	// The slave SAE retrieves the same keys by ID from the master
+	var ids qkd.ETSIKeyIDsRequest
+	for _, key := range enc.Keys {
+		ids.KeyIDs = append(ids.KeyIDs, struct {
+			KeyID string `json:"key_ID"`
+		}{key.KeyID})
+	}
+	rec = etsiRequest(t, h.ETSIDecKeysHandler, http.MethodPost, "/api/v1/keys/alice/dec_keys", "bob", ids)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dec_keys: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var dec qkd.ETSIKeyContainer
+	decodeJSON(t, rec, &dec)
+	for i := range enc.Keys {
+		if dec.Keys[i] != enc.Keys[i] {
+			t.Errorf("Key %d differs between master and slave: %+v vs %+v", i, enc.Keys[i], dec.Keys[i])
+		}
+	}
+
+	// Only the slave the keys were reserved for can retrieve them
+	if rec := etsiRequest(t, h.ETSIDecKeysHandler, http.MethodPost, "/api/v1/keys/alice/dec_keys", "mallory", ids); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for another SAE, got %d", rec.Code)
+	}
+	if rec := etsiRequest(t, h.ETSIDecKeysHandler, http.MethodPost, "/api/v1/keys/bob/dec_keys", "alice", ids); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 when the master retrieves its own keys as slave, got %d", rec.Code)
+	}
+}
+
+func TestETSIEncKeysErrors(t *testing.T) {
+	h, sm := newTestHandler()
+	createTestKey(t, sm, "")
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"more keys than stored", "number=2&size=64"},
+		{"larger than the stored key", "number=1&size=256"},
+		{"size not a multiple of 8", "number=1&size=60"},
+		{"zero keys", "number=0"},
+		{"non-numeric size", "size=big"},
+	}
+
+	for _, tt := range tests {
+		rec := etsiRequest(t, h.ETSIEncKeysHandler, http.MethodGet, "/api/v1/keys/bob/enc_keys?"+tt.query, "alice", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", tt.name, rec.Code)
+		}
+
+		var body map[string]string
+		decodeJSON(t, rec, &body)
+		if body["message"] == "" {
+			t.Errorf("%s: expected an ETSI error message", tt.name)
+		}
+	}
+
+	// Failed requests do not consume keys
+	if count := etsiStatus(t, h); count != 1 {
+		t.Errorf("Expected the key to remain available, got %d", count)
+	}
+}
+
+func TestETSIKeysAreNotSharedWithREST(t *testing.T) {
+	h, sm := newTestHandler()
+	retrieved := createTestKey(t, sm, "")
+	delivered := createTestKey(t, sm, "")
+
+	// A key already retrieved over REST is not offered to the SAEs
+	if _, err := sm.RetrieveKey(retrieved.KeyID, "alice"); err != nil {
+		t.Fatalf("RetrieveKey failed: %v", err)
+	}
+	if count := etsiStatus(t, h); count != 1 {
+		t.Fatalf("Expected only the unretrieved key to be available, got %d", count)
+	}
+
+	rec := etsiRequest(t, h.ETSIEncKeysHandler, http.MethodGet, "/api/v1/keys/bob/enc_keys?number=1&size=64", "alice", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enc_keys: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var enc qkd.ETSIKeyContainer
+	decodeJSON(t, rec, &enc)
+	if len(enc.Keys) != 1 || enc.Keys[0].KeyID != delivered.KeyID.String() {
+		t.Fatalf("Expected the unretrieved key to be delivered, got %+v", enc.Keys)
+	}
+
+	// A key delivered to the SAEs is no longer served over REST
+	get := serveAs(t, h.GetKeyHandler, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/"+delivered.KeyID.String(), nil), "alice")
+	if get.Code != http.StatusConflict {
+		t.Errorf("Expected 409 retrieving an ETSI-delivered key, got %d", get.Code)
+	}
+	if rec := deriveKey(t, h, delivered.KeyID, "alg=aes256"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 deriving from an ETSI-delivered key, got %d", rec.Code)
+	}
+
+	// Nor handed to the slave SAE once rotated away
+	if _, err := sm.RotateKey(context.Background(), delivered.KeyID, "alice"); err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	ids := qkd.ETSIKeyIDsRequest{KeyIDs: []struct {
+		KeyID string `json:"key_ID"`
+	}{{delivered.KeyID.String()}}}
+	if rec := etsiRequest(t, h.ETSIDecKeysHandler, http.MethodPost, "/api/v1/keys/alice/dec_keys", "bob", ids); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a retired key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
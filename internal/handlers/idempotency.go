@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyHeader carries a client-chosen key that makes retrying a POST safe
+const IdempotencyHeader = "X-Idempotency-Key"
+
+// DefaultIdempotencyTTL is how long a response is replayed for its idempotency key
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+const (
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodySize   = 1 << 20
+	// maxIdempotencyEntries bounds the cached responses before expired ones are pruned
+	maxIdempotencyEntries = 10000
+)
+
+// replayedHeaders are the response headers a replay repeats; the rest, such as
+// the request ID, describe the retry itself
+var replayedHeaders = []string{"Content-Type", "Cache-Control", "Location"}
+
+// idempotentResponse is the outcome of the first request made with a key
+type idempotentResponse struct {
+	fingerprint [sha256.Size]byte // Hash of the request body
+	done        bool              // False while the first request is still running
+	status      int
+	header      http.Header
+	body        []byte
+	expires     time.Time
+}
+
+// IdempotencyCache replays the first successful response to a POST for retries
+// carrying the same X-Idempotency-Key, so a retried join or key exchange returns
+// the original result instead of failing or running twice. Keys are scoped to the
+// request path. A key reused with a different request body is rejected with 422,
+// and a retry arriving while the first request is still running gets 409. Error
+// responses are not cached, so a failed request can be retried.
+type IdempotencyCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotentResponse
+	now     func() time.Time
+}
+
+// NewIdempotencyCache creates a cache that replays responses for ttl
+func NewIdempotencyCache(ttl time.Duration) *IdempotencyCache {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &IdempotencyCache{
+		ttl:     ttl,
+		entries: make(map[string]*idempotentResponse),
+		now:     time.Now,
+	}
+}
+
+// Middleware applies idempotency keys to POST requests; other requests, and POSTs
+// without the header, pass straight through
+func (c *IdempotencyCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondWithError(w, http.StatusBadRequest, "Idempotency key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		cacheKey := r.URL.Path + "\x00" + key
+		cached, status := c.begin(cacheKey, sha256.Sum256(body))
+		switch status {
+		case http.StatusUnprocessableEntity:
+			respondWithError(w, status, "Idempotency key was already used for a different request")
+			return
+		case http.StatusConflict:
+			respondWithError(w, status, "A request with this idempotency key is still in progress")
+			return
+		}
+
+		if cached != nil {
+			for name, values := range cached.header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(cached.status)
+			w.Write(cached.body)
+			return
+		}
+
+		rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() { c.finish(cacheKey, rec) }()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// begin claims a key for a new request, or returns the cached response to replay.
+// A non-zero status rejects the request.
+func (c *IdempotencyCache) begin(key string, fingerprint [sha256.Size]byte) (*idempotentResponse, int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	if entry, exists := c.entries[key]; exists && (!entry.done || now.Before(entry.expires)) {
+		switch {
+		case entry.fingerprint != fingerprint:
+			return nil, http.StatusUnprocessableEntity
+		case !entry.done:
+			return nil, http.StatusConflict
+		}
+		return entry, 0
+	}
+
+	if len(c.entries) >= maxIdempotencyEntries {
+		c.prune(now)
+	}
+	c.entries[key] = &idempotentResponse{fingerprint: fingerprint}
+	return nil, 0
+}
+
+// finish caches a successful response and releases the key of any other
+func (c *IdempotencyCache) finish(key string, rec *idempotencyRecorder) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := c.entries[key]
+	if !rec.wroteHeader || rec.status < 200 || rec.status >= 300 {
+		delete(c.entries, key)
+		return
+	}
+
+	entry.done = true
+	entry.status = rec.status
+	entry.header = rec.header
+	entry.body = rec.body.Bytes()
+	entry.expires = c.now().Add(c.ttl)
+}
+
+// prune drops expired responses. The caller must hold the mutex.
+func (c *IdempotencyCache) prune(now time.Time) {
+	for key, entry := range c.entries {
+		if entry.done && !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// idempotencyRecorder passes a response through while keeping a copy of it
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.wroteHeader = true
+		rec.status = status
+		rec.header = make(http.Header)
+		for _, name := range replayedHeaders {
+			if values := rec.ResponseWriter.Header().Values(name); len(values) > 0 {
+				rec.header[name] = values
+			}
+		}
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(p []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(p)
+	return rec.ResponseWriter.Write(p)
+}
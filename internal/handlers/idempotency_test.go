@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+func TestIdempotentJoinReplaysOriginalSession(t *testing.T) {
+	h, sm := newTestHandler()
+	handler := testAuth.Middleware(NewIdempotencyCache(DefaultIdempotencyTTL).Middleware(http.HandlerFunc(h.JoinSessionHandler)))
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+
+	join := func(bobID, key string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(qkd.SessionJoinRequest{SessionID: session.SessionID.String(), BobID: bobID, JoinToken: session.JoinToken})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/qkd/session/join", bytes.NewReader(body))
+		req.Header.Set(IdempotencyHeader, key)
+		setBearerToken(t, req, bobID)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := join("bob", "join-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", first.Code, first.Body.String())
+	}
+
+	retry := join("bob", "join-1")
+	if retry.Code != http.StatusOK || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("Expected a replayed 200, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if !bytes.Equal(retry.Body.Bytes(), first.Body.Bytes()) {
+		t.Errorf("Expected the original response, got %s", retry.Body.String())
+	}
+
+	// Another Bob can neither reuse the key nor join with a fresh one
+	if rec := join("mallory", "join-1"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a reused key with a different body, got %d", rec.Code)
+	}
+	if rec := join("mallory", "join-2"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a different Bob, got %d", rec.Code)
+	}
+
+	stored, _ := sm.GetSession(session.SessionID)
+	if stored.BobID != "bob" {
+		t.Errorf("Expected bob to stay joined, got %q", stored.BobID)
+	}
+}
+
+func TestIdempotentExecuteRunsOnce(t *testing.T) {
+	h, sm := newTestHandler()
+	handler := NewIdempotencyCache(DefaultIdempotencyTTL).Middleware(http.HandlerFunc(h.ExecuteKeyExchangeHandler))
+	session := createTestSession(t, sm)
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	path := "/api/v1/qkd/session/" + session.SessionID.String() + "/execute"
+
+	execute := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if key != "" {
+			req.Header.Set(IdempotencyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := execute("exec-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", first.Code, first.Body.String())
+	}
+	if retry := execute("exec-1"); !bytes.Equal(retry.Body.Bytes(), first.Body.Bytes()) {
+		t.Errorf("Expected the retry to replay the first exchange, got %d: %s", retry.Code, retry.Body.String())
+	}
+
+	// Without a key the request runs again and fails on the completed session
+	if rec := execute(""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a second execute, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/logging"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	qkdcore "github.com/jaskrrish/Go-OKD/internal/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestExecuteLogsShareRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	// Noise keeps the sampled QBER above zero so Cascade uses realistic block sizes
+	sm := qkdcore.NewSessionManager(quantum.NewSimulatorBackend(true, 0.03))
+	sm.SetLogger(logger)
+	h := NewQKDHandlerWithManager(sm)
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	server := logging.Middleware(logger, http.HandlerFunc(h.ExecuteKeyExchangeHandler))
+	url := fmt.Sprintf("/api/v1/qkd/session/%s/execute", session.SessionID)
+	req := httptest.NewRequest(http.MethodPost, url, nil)
+	req.Header.Set(logging.RequestIDHeader, "exchange-1")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	messages := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		if record["request_id"] != "exchange-1" {
+			t.Errorf("Expected request_id exchange-1 on %q, got %v", record["msg"], record["request_id"])
+		}
+		messages[record["msg"].(string)] = true
+	}
+
+	// Handler, session manager and middleware all log under the same request ID
+	for _, msg := range []string{"executing key exchange", "qubits measured", "post-processing stage", "key stored", "request completed"} {
+		if !messages[msg] {
+			t.Errorf("Expected a %q record, got %v", msg, messages)
+		}
+	}
+
+	if session, _ := sm.GetSession(session.SessionID); session.Status != qkd.SessionCompleted {
+		t.Errorf("Expected completed session, got %s", session.Status)
+	}
+}
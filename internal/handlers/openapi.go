@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// apiParam is a query parameter of an API operation
+type apiParam struct {
+	Name string
+	Type string // OpenAPI primitive type
+}
+
+// apiOperation describes one operation of the HTTP API for the OpenAPI document.
+// OperationID is the name of the handler serving it without the "Handler" suffix.
+type apiOperation struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Auth        bool // Requires a bearer token
+	Query       []apiParam
+	Request     any    // Request body model, if any
+	Status      int    // Success status code
+	Response    any    // Response body model; nil for a free-form JSON object
+	ContentType string // Response media type; defaults to application/json
+}
+
+// apiOperations lists every route the server registers. Keep it in step with cmd/api.
+var apiOperations = []apiOperation{
+	{Method: http.MethodGet, Path: "/", OperationID: "Home", Summary: "Service banner", Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/health", OperationID: "Health", Summary: "Server health check", Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/users", OperationID: "ListUsers", Summary: "List demo users", Status: http.StatusOK, Response: []models.User{}},
+	{Method: http.MethodPost, Path: "/api/v1/users", OperationID: "CreateUser", Summary: "Create a demo user", Request: models.User{}, Status: http.StatusCreated, Response: models.User{}},
+	{Method: http.MethodGet, Path: "/metrics", OperationID: "Metrics", Summary: "Prometheus metrics", Status: http.StatusOK, ContentType: "text/plain"},
+	{Method: http.MethodGet, Path: "/openapi.json", OperationID: "OpenAPI", Summary: "This OpenAPI document", Status: http.StatusOK},
+
+	{Method: http.MethodGet, Path: "/api/v1/qkd/health", OperationID: "HealthCheck", Summary: "QKD service and backend health check", Status: http.StatusOK, Response: qkd.HealthResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/session/initiate", OperationID: "InitiateSession", Summary: "Create a session as Alice", Auth: true, Request: qkd.SessionCreateRequest{}, Status: http.StatusCreated, Response: qkd.SessionResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/session/join", OperationID: "JoinSession", Summary: "Join a session as Bob", Auth: true, Request: qkd.SessionJoinRequest{}, Status: http.StatusOK, Response: qkd.SessionResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/sessions", OperationID: "ListSessions", Summary: "List the caller's sessions, newest first", Auth: true,
+		Query: []apiParam{{"status", "string"}, {"user", "string"}, {"limit", "integer"}, {"offset", "integer"}}, Status: http.StatusOK, Response: qkd.SessionListResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/session/{session_id}", OperationID: "GetSession", Summary: "Get a session", Status: http.StatusOK, Response: qkd.SessionResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/session/{session_id}/execute", OperationID: "ExecuteKeyExchange", Summary: "Run the key exchange",
+		Query: []apiParam{{"async", "boolean"}}, Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/session/{session_id}/execute/batch", OperationID: "BatchKeyExchange", Summary: "Generate several keys from one session",
+		Request: qkd.BatchExchangeRequest{}, Status: http.StatusOK, Response: qkd.BatchExchangeResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/session/{session_id}/retry", OperationID: "RetryKeyExchange", Summary: "Retry a failed key exchange", Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/session/{session_id}/status", OperationID: "SessionStatus", Summary: "Poll an asynchronous key exchange", Status: http.StatusOK, Response: qkd.SessionStatusResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/session/{session_id}/events", OperationID: "SessionEvents", Summary: "Stream session progress as server-sent events", Status: http.StatusOK, ContentType: "text/event-stream"},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/session/{session_id}/ws", OperationID: "SessionWebSocket", Summary: "Stream session progress over a WebSocket", Status: http.StatusSwitchingProtocols},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/session/{session_id}/disclosures", OperationID: "Disclosures", Summary: "Public-channel disclosures of a session", Status: http.StatusOK, Response: qkd.DisclosureSummary{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/session/{session_id}/transcript", OperationID: "Transcript", Summary: "Public transcript of a session's exchange", Status: http.StatusOK, Response: qkd.Transcript{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/session/{session_id}/metrics", OperationID: "SessionMetrics", Summary: "Metrics of a session", Status: http.StatusOK, Response: qkd.SessionMetrics{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/qkd/key/{key_id}", OperationID: "GetKey", Summary: "Retrieve key material, once per participant", Auth: true, Status: http.StatusOK, Response: qkd.KeyResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/qkd/key/{key_id}", OperationID: "RevokeKey", Summary: "Revoke a key", Auth: true, Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/key/{key_id}/info", OperationID: "KeyInfo", Summary: "Key metadata without material", Auth: true, Status: http.StatusOK, Response: qkd.KeyMetadataResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/key/{key_id}/derive", OperationID: "DeriveKey", Summary: "Derive a symmetric key with HKDF", Auth: true,
+		Query: []apiParam{{"alg", "string"}, {"info", "string"}}, Status: http.StatusOK, Response: qkd.DerivedKeyResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/key/{key_id}/consume", OperationID: "ConsumeKey", Summary: "Consume key bytes for one-time-pad use", Auth: true, Request: qkd.ConsumeKeyRequest{}, Status: http.StatusOK, Response: qkd.ConsumedKeyResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/key/{key_id}/download", OperationID: "DownloadKey", Summary: "Download a key's unused bytes as a one-time-pad file", Auth: true, Status: http.StatusOK, ContentType: "application/octet-stream"},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/key/{key_id}/rotate", OperationID: "RotateKey", Summary: "Replace a key with one from a new exchange on its session", Auth: true, Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/key/by-label/{label}", OperationID: "GetKeyByLabel", Summary: "Retrieve a labeled key", Auth: true, Status: http.StatusOK, Response: qkd.KeyResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/v1/qkd/bases", OperationID: "Bases", Summary: "Issue bases for external hardware", Auth: true,
+		Query: []apiParam{{"session_id", "string"}, {"length", "integer"}, {"include_bits", "boolean"}}, Status: http.StatusOK, Response: qkd.BasesResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/reconcile", OperationID: "Reconcile", Summary: "Sift external measurements", Auth: true, Request: qkd.ReconcileRequest{}, Status: http.StatusOK, Response: qkd.ReconcileResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/compare", OperationID: "CompareProtocols", Summary: "Compare protocols on one simulated channel", Request: qkd.CompareRequest{}, Status: http.StatusOK, Response: qkd.CompareResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/analyze", OperationID: "AnalyzeChannel", Summary: "Aggregate statistics over repeated exchanges", Request: qkd.AnalyzeRequest{}, Status: http.StatusOK, Response: qkd.ChannelAnalysis{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/estimate", OperationID: "EstimateFeasibility", Summary: "Estimate the secure key length a channel allows",
+		Query: []apiParam{{"noise", "number"}, {"length", "integer"}, {"oversampling", "integer"}}, Status: http.StatusOK, Response: qkd.FeasibilityEstimate{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/random", OperationID: "Random", Summary: "Random bytes from a BB84 exchange",
+		Query: []apiParam{{"bytes", "integer"}}, Status: http.StatusOK, Response: qkd.RandomResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/qkd/pool", OperationID: "KeyPool", Summary: "Pre-generated key pool statistics", Status: http.StatusOK, Response: qkd.KeyPoolStats{}},
+	{Method: http.MethodPost, Path: "/api/v1/qkd/channel/bell", OperationID: "BellTest", Summary: "CHSH Bell test of the backend", Request: qkd.BellTestRequest{}, Status: http.StatusOK, Response: qkd.BellTestResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/keys/{slave_SAE_ID}/status", OperationID: "ETSIStatus", Summary: "ETSI GS QKD 014 key status", Auth: true, Status: http.StatusOK, Response: qkd.ETSIStatus{}},
+	{Method: http.MethodGet, Path: "/api/v1/keys/{slave_SAE_ID}/enc_keys", OperationID: "ETSIEncKeys", Summary: "ETSI GS QKD 014 get keys", Auth: true,
+		Query: []apiParam{{"number", "integer"}, {"size", "integer"}}, Status: http.StatusOK, Response: qkd.ETSIKeyContainer{}},
+	{Method: http.MethodPost, Path: "/api/v1/keys/{master_SAE_ID}/dec_keys", OperationID: "ETSIDecKeys", Summary: "ETSI GS QKD 014 get keys with key IDs", Auth: true, Request: qkd.ETSIKeyIDsRequest{}, Status: http.StatusOK, Response: qkd.ETSIKeyContainer{}},
+}
+
+// pathParamPattern matches the {name} parameters of an OpenAPI path template
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// OpenAPISpec builds the OpenAPI 3 document for the API. Schemas are generated
+// from the request and response models, so they follow their JSON tags.
+func OpenAPISpec() map[string]any {
+	schemas := make(map[string]any)
+	paths := make(map[string]any)
+
+	for _, op := range apiOperations {
+		item, ok := paths[op.Path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = openAPIOperation(op, schemas)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Go-OKD Quantum Key Distribution API",
+			"version":     "1.0.0",
+			"description": "Quantum key distribution sessions, key retrieval and ETSI GS QKD 014 key delivery",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+// openAPIOperation builds the operation object for op, adding its models to schemas
+func openAPIOperation(op apiOperation, schemas map[string]any) map[string]any {
+	var params []any
+	for _, match := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+		params = append(params, map[string]any{
+			"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, param := range op.Query {
+		params = append(params, map[string]any{
+			"name": param.Name, "in": "query", "schema": map[string]any{"type": param.Type},
+		})
+	}
+
+	contentType := op.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	success := map[string]any{"description": http.StatusText(op.Status)}
+	if op.Status != http.StatusSwitchingProtocols {
+		schema := map[string]any{"type": "object"}
+		if op.Response != nil {
+			schema = schemaFor(reflect.TypeOf(op.Response), schemas)
+		} else if contentType != "application/json" {
+			schema = map[string]any{"type": "string"}
+		}
+		success["content"] = map[string]any{contentType: map[string]any{"schema": schema}}
+	}
+
+	operation := map[string]any{
+		"operationId": op.OperationID,
+		"summary":     op.Summary,
+		"responses": map[string]any{
+			strconv.Itoa(op.Status): success,
+			"default": map[string]any{"description": "Error", "content": map[string]any{
+				"application/json": map[string]any{"schema": map[string]any{
+					"type": "object", "properties": map[string]any{"error": map[string]any{"type": "string"}},
+				}},
+			}},
+		},
+	}
+	if len(params) > 0 {
+		operation["parameters"] = params
+	}
+	if op.Request != nil {
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{"application/json": map[string]any{
+				"schema": schemaFor(reflect.TypeOf(op.Request), schemas),
+			}},
+		}
+	}
+	if op.Auth {
+		operation["security"] = []any{map[string]any{"bearerAuth": []any{}}}
+	}
+	return operation
+}
+
+// Types with a fixed JSON string encoding
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// schemaFor returns the schema of t. Named structs are added to schemas and
+// referenced; anonymous structs are inlined.
+func schemaFor(t reflect.Type, schemas map[string]any) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case uuidType:
+		return map[string]any{"type": "string", "format": "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, exists := schemas[t.Name()]; !exists {
+			schemas[t.Name()] = nil // Reserve the name so recursive types terminate
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return ref
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema returns the object schema of a struct's JSON-encoded fields
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaFor(field.Type, schemas)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// OpenAPIHandler handles GET /openapi.json
+// Serves the OpenAPI 3 description of the API
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, OpenAPISpec())
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// schemaRefPattern matches schema references in a JSON document
+var schemaRefPattern = regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]*)"`)
+
+func TestOpenAPIHandlerServesValidSpec(t *testing.T) {
+	rec := httptest.NewRecorder()
+	OpenAPIHandler(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	var spec struct {
+		OpenAPI    string                               `json:"openapi"`
+		Info       struct{ Title, Version string }      `json:"info"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Spec is not valid JSON: %v", err)
+	}
+
+	if !strings.HasPrefix(spec.OpenAPI, "3.") || spec.Info.Title == "" || spec.Info.Version == "" {
+		t.Errorf("Expected an OpenAPI 3 document with title and version, got %q %+v", spec.OpenAPI, spec.Info)
+	}
+	methods := map[string]bool{"get": true, "post": true, "put": true, "delete": true, "patch": true}
+	for path, item := range spec.Paths {
+		if !strings.HasPrefix(path, "/") {
+			t.Errorf("%s: paths must start with /", path)
+		}
+		for method, operation := range item {
+			if !methods[method] {
+				t.Errorf("%s: unexpected method %q", path, method)
+			}
+			if responses, ok := operation["responses"].(map[string]any); !ok || len(responses) == 0 {
+				t.Errorf("%s %s: operation has no responses", method, path)
+			}
+		}
+	}
+
+	// Every schema reference must resolve
+	for _, ref := range schemaRefPattern.FindAllStringSubmatch(rec.Body.String(), -1) {
+		if _, ok := spec.Components.Schemas[ref[1]]; !ok {
+			t.Errorf("Unresolved schema reference %s", ref[1])
+		}
+	}
+	if _, ok := spec.Components.Schemas["SessionCreateRequest"]; !ok {
+		t.Error("Expected the session create request model in the schemas")
+	}
+	if strings.Contains(string(spec.Components.Schemas["QuantumKey"]), "KeyMaterial") {
+		t.Error("Fields excluded from JSON must not appear in schemas")
+	}
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	spec := OpenAPISpec()
+	paths := spec["paths"].(map[string]any)
+	operations := make(map[string]bool)
+	for _, item := range paths {
+		for _, operation := range item.(map[string]any) {
+			operations[operation.(map[string]any)["operationId"].(string)] = true
+		}
+	}
+
+	// Every QKD handler is served at some route
+	handlerType := reflect.TypeOf(&QKDHandler{})
+	for i := 0; i < handlerType.NumMethod(); i++ {
+		name := handlerType.Method(i).Name
+		if id, ok := strings.CutSuffix(name, "Handler"); ok && !operations[id] {
+			t.Errorf("%s is not described in the OpenAPI spec", name)
+		}
+	}
+
+	for _, path := range []string{
+		"/", "/health", "/api/v1/users", "/metrics", "/openapi.json",
+		"/api/v1/qkd/session/initiate", "/api/v1/qkd/session/join", "/api/v1/qkd/session/{session_id}",
+		"/api/v1/qkd/session/{session_id}/execute", "/api/v1/qkd/key/{key_id}", "/api/v1/qkd/health",
+	} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("Expected %s in the OpenAPI spec", path)
+		}
+	}
+	if _, ok := paths["/api/v1/qkd/key/{key_id}"].(map[string]any)["delete"]; !ok {
+		t.Error("Expected key revocation in the OpenAPI spec")
+	}
+}
@@ -1,15 +1,22 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
 	qkdcore "github.com/jaskrrish/Go-OKD/internal/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
 	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
 )
 
@@ -25,25 +32,49 @@ func NewQKDHandler(backend quantum.QuantumBackend) *QKDHandler {
 	}
 }
 
+// NewQKDHandlerWithManager creates a QKD handler around a preconfigured session manager
+func NewQKDHandlerWithManager(sessionManager *qkdcore.SessionManager) *QKDHandler {
+	return &QKDHandler{
+		sessionManager: sessionManager,
+	}
+}
+
 // InitiateSessionHandler handles POST /api/v1/qkd/session/initiate
-// Alice initiates a new QKD session
+// Alice initiates a new QKD session. The caller must be Alice, so no one else can
+// set up a session, callback or label that later serves keys to her.
 func (h *QKDHandler) InitiateSessionHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
 	var req qkd.SessionCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	if !h.sessionManager.SameUser(userID, req.AliceID) {
+		respondWithError(w, http.StatusForbidden, "alice_id must be the authenticated user")
+		return
+	}
+
 	session, err := h.sessionManager.CreateSession(&req)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		statusCode := http.StatusBadRequest
+		if err == qkd.ErrDuplicateLabel {
+			statusCode = http.StatusConflict
+		}
+		respondWithError(w, statusCode, err.Error())
 		return
 	}
+	h.sessionManager.Logger().DebugContext(r.Context(), "session created", "session_id", session.SessionID)
 
 	respondWithJSON(w, http.StatusCreated, qkd.SessionResponse{
 		Session: session,
@@ -51,13 +82,19 @@ func (h *QKDHandler) InitiateSessionHandler(w http.ResponseWriter, r *http.Reque
 }
 
 // JoinSessionHandler handles POST /api/v1/qkd/session/join
-// Bob joins an existing QKD session
+// Bob joins an existing QKD session. The caller must be the joining Bob.
 func (h *QKDHandler) JoinSessionHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
 	var req qkd.SessionJoinRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request body")
@@ -69,17 +106,29 @@ func (h *QKDHandler) JoinSessionHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.sessionManager.SameUser(userID, req.BobID) {
+		respondWithError(w, http.StatusForbidden, "bob_id must be the authenticated user")
+		return
+	}
+
 	sessionID, err := uuid.Parse(req.SessionID)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
-	session, err := h.sessionManager.JoinSession(sessionID, req.BobID)
+	session, err := h.sessionManager.JoinSession(sessionID, req.BobID, req.JoinToken)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		statusCode := http.StatusBadRequest
+		if err == qkd.ErrInvalidJoinToken || err == qkd.ErrJoinTokenExpired {
+			statusCode = http.StatusForbidden
+		} else if err == qkd.ErrAlreadyJoined {
+			statusCode = http.StatusConflict
+		}
+		respondWithError(w, statusCode, err.Error())
 		return
 	}
+	h.sessionManager.Logger().DebugContext(r.Context(), "session joined", "session_id", sessionID)
 
 	respondWithJSON(w, http.StatusOK, qkd.SessionResponse{
 		Session: session,
@@ -87,7 +136,8 @@ func (h *QKDHandler) JoinSessionHandler(w http.ResponseWriter, r *http.Request)
 }
 
 // ExecuteKeyExchangeHandler handles POST /api/v1/qkd/session/{id}/execute
-// Executes the BB84 key exchange for an active session
+// Executes the BB84 key exchange for an active session. With ?async=true the
+// exchange runs in the background and 202 Accepted is returned immediately.
 func (h *QKDHandler) ExecuteKeyExchangeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -107,10 +157,31 @@ func (h *QKDHandler) ExecuteKeyExchangeHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Execute key exchange with full post-processing
-	key, err := h.sessionManager.ExecuteKeyExchangeWithPostProcessing(sessionID)
+	async := r.URL.Query().Get("async") == "true"
+	h.sessionManager.Logger().DebugContext(r.Context(), "executing key exchange", "session_id", sessionID, "async", async)
+
+	if async {
+		job, err := h.sessionManager.ExecuteKeyExchangeAsync(r.Context(), sessionID)
+		if err != nil {
+			respondWithError(w, exchangeErrorStatus(err), fmt.Sprintf("Key exchange failed: %v", err))
+			return
+		}
+
+		statusURL := fmt.Sprintf("/api/v1/qkd/session/%s/status", sessionID)
+		w.Header().Set("Location", statusURL)
+		respondWithJSON(w, http.StatusAccepted, qkd.ExchangeJobResponse{
+			JobID:     job.JobID.String(),
+			SessionID: sessionID.String(),
+			Status:    qkd.SessionInitiating,
+			StatusURL: statusURL,
+		})
+		return
+	}
+
+	// Execute key exchange with full post-processing; a client disconnect cancels it
+	key, err := h.sessionManager.ExecuteKeyExchangeWithPostProcessing(r.Context(), sessionID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Key exchange failed: %v", err))
+		respondWithError(w, exchangeErrorStatus(err), fmt.Sprintf("Key exchange failed: %v", err))
 		return
 	}
 
@@ -130,6 +201,166 @@ func (h *QKDHandler) ExecuteKeyExchangeHandler(w http.ResponseWriter, r *http.Re
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+// RetryKeyExchangeHandler handles POST /api/v1/qkd/session/{id}/retry
+// Re-runs the key exchange of a failed or aborted session that has not expired
+func (h *QKDHandler) RetryKeyExchangeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract session ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	sessionID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	key, err := h.sessionManager.RetryKeyExchange(r.Context(), sessionID)
+	if err != nil {
+		respondWithError(w, exchangeErrorStatus(err), fmt.Sprintf("Key exchange retry failed: %v", err))
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve session")
+		return
+	}
+
+	response := map[string]interface{}{
+		"session": session,
+		"key_id":  key.KeyID.String(),
+		"message": "Quantum key generated successfully!",
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// BatchKeyExchangeHandler handles POST /api/v1/qkd/session/{id}/execute/batch
+// Runs several key exchanges on an active session and returns the generated key IDs
+func (h *QKDHandler) BatchKeyExchangeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	sessionID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	var req qkd.BatchExchangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	keyIDs, err := h.sessionManager.ExecuteBatchKeyExchange(r.Context(), sessionID, req.Count)
+	if err != nil {
+		respondWithError(w, exchangeErrorStatus(err), fmt.Sprintf("Batch key exchange failed after %d of %d keys: %v", len(keyIDs), req.Count, err))
+		return
+	}
+
+	response := qkd.BatchExchangeResponse{
+		SessionID: sessionID.String(),
+		KeyIDs:    make([]string, len(keyIDs)),
+		Count:     len(keyIDs),
+	}
+	for i, keyID := range keyIDs {
+		response.KeyIDs[i] = keyID.String()
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// exchangeErrorStatus maps a key exchange error to an HTTP status code
+func exchangeErrorStatus(err error) int {
+	switch err {
+	case qkd.ErrSessionNotFound:
+		return http.StatusNotFound
+	case qkd.ErrSessionNotActive, qkd.ErrSessionAlreadyCompleted, qkd.ErrSessionTerminated,
+		qkd.ErrSessionNotRetryable, qkd.ErrRetryLimitReached:
+		return http.StatusConflict
+	case qkd.ErrSessionExpired:
+		return http.StatusGone
+	case qkd.ErrShuttingDown, qkd.ErrBackendNotConfigured:
+		return http.StatusServiceUnavailable
+	case qkd.ErrTooManyExchanges:
+		return http.StatusTooManyRequests
+	}
+	if errors.Is(err, qkd.ErrOversamplingTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// SessionStatusHandler handles GET /api/v1/qkd/session/{id}/status
+// Reports the progress of a session's key exchange, including background exchanges
+func (h *QKDHandler) SessionStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	sessionID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response := qkd.SessionStatusResponse{
+		SessionID:   sessionID.String(),
+		Status:      session.Status,
+		Message:     session.Message,
+		CompletedAt: session.CompletedAt,
+	}
+
+	if job, err := h.sessionManager.GetExchangeJob(sessionID); err == nil {
+		response.JobID = job.JobID.String()
+		response.Error = job.Err
+		if job.KeyID != uuid.Nil {
+			response.KeyID = job.KeyID.String()
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	respondWithJSON(w, http.StatusOK, response)
+}
+
 // GetSessionHandler handles GET /api/v1/qkd/session/{id}
 // Retrieves information about a specific session
 func (h *QKDHandler) GetSessionHandler(w http.ResponseWriter, r *http.Request) {
@@ -157,68 +388,190 @@ func (h *QKDHandler) GetSessionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, qkd.SessionResponse{
-		Session: session,
+	// Terminal sessions rarely change, but retries, rotation and batches can still
+	// update them, so they carry an ETag for revalidation rather than a max-age
+	if !session.Status.IsTerminal() {
+		w.Header().Set("Cache-Control", "no-cache")
+		respondWithJSON(w, http.StatusOK, qkd.SessionResponse{
+			Session: session,
+		})
+		return
+	}
+
+	body, err := json.Marshal(qkd.SessionResponse{Session: session})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to encode session")
+		return
+	}
+
+	digest := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(digest[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Page size bounds for ListSessionsHandler
+const (
+	defaultSessionListLimit = 50
+	maxSessionListLimit     = 200
+)
+
+// ListSessionsHandler handles GET /api/v1/qkd/sessions
+// Lists the caller's sessions, optionally filtered by ?status=, paginated by
+// ?limit= and ?offset=. ?user= is accepted only for the caller's own ID.
+func (h *QKDHandler) ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+	if user := query.Get("user"); user != "" && !h.sessionManager.SameUser(userID, user) {
+		respondWithError(w, http.StatusForbidden, "Only your own sessions can be listed")
+		return
+	}
+
+	status := qkd.SessionStatus(query.Get("status"))
+	if status != "" && !status.IsValid() {
+		respondWithError(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	limit := defaultSessionListLimit
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxSessionListLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxSessionListLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if raw := query.Get("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+		offset = parsed
+	}
+
+	sessions, err := h.sessionManager.ListSessions(qkdcore.SessionFilter{
+		Status: status,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	total := len(sessions)
+	start := min(offset, total)
+	page := sessions[start:min(start+limit, total)]
+
+	respondWithJSON(w, http.StatusOK, qkd.SessionListResponse{
+		Sessions: page,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
 	})
 }
 
-// GetKeyHandler handles GET /api/v1/qkd/key/{id}
-// Retrieves a generated quantum key (requires authentication)
-func (h *QKDHandler) GetKeyHandler(w http.ResponseWriter, r *http.Request) {
+// DisclosuresHandler handles GET /api/v1/qkd/session/{id}/disclosures
+// Returns the ledger of public-channel disclosures made while post-processing the session
+func (h *QKDHandler) DisclosuresHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Extract key ID from URL
 	pathParts := strings.Split(r.URL.Path, "/")
 	if len(pathParts) < 6 {
 		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
 		return
 	}
 
-	keyID, err := uuid.Parse(pathParts[5])
+	sessionID, err := uuid.Parse(pathParts[5])
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
-	// Get user ID from header (in production, this would come from JWT token)
-	userID := r.Header.Get("X-User-ID")
-	if userID == "" {
-		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+	summary, err := h.sessionManager.GetDisclosures(sessionID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// SessionMetricsHandler handles GET /api/v1/qkd/session/{id}/metrics
+// Returns the metrics recorded for the session's post-processed key exchange
+func (h *QKDHandler) SessionMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
 		return
 	}
 
-	key, err := h.sessionManager.GetKey(keyID, userID)
+	sessionID, err := uuid.Parse(pathParts[5])
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err == qkd.ErrKeyNotFound {
-			statusCode = http.StatusNotFound
-		} else if err == qkd.ErrUnauthorized {
-			statusCode = http.StatusForbidden
-		} else if err == qkd.ErrKeyExpired {
-			statusCode = http.StatusGone
-		}
-		respondWithError(w, statusCode, err.Error())
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
-	response := qkd.KeyResponse{
-		KeyID:     key.KeyID.String(),
-		SessionID: key.SessionID.String(),
-		KeyHex:    hex.EncodeToString(key.KeyMaterial),
-		KeyLength: key.KeyLength,
-		ExpiresAt: key.ExpiresAt,
+	metrics, err := h.sessionManager.GetSessionMetrics(sessionID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, http.StatusOK, metrics)
 }
 
-// RevokeKeyHandler handles DELETE /api/v1/qkd/key/{id}
-// Revokes a quantum key
-func (h *QKDHandler) RevokeKeyHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
+// TranscriptHandler handles GET /api/v1/qkd/session/{id}/transcript
+// Returns the public transcript of the session's latest post-processed exchange
+func (h *QKDHandler) TranscriptHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -229,46 +582,931 @@ func (h *QKDHandler) RevokeKeyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	keyID, err := uuid.Parse(pathParts[5])
+	sessionID, err := uuid.Parse(pathParts[5])
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
-	if err := h.sessionManager.RevokeKey(keyID); err != nil {
+	transcript, err := h.sessionManager.GetTranscript(sessionID)
+	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "Key revoked successfully",
-	})
+	respondWithJSON(w, http.StatusOK, transcript)
 }
 
-// HealthCheckHandler handles GET /api/v1/qkd/health
-// Returns health status of the QKD service
-func (h *QKDHandler) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
+// SessionEventsHandler handles GET /api/v1/qkd/session/{id}/events
+// Streams session progress as Server-Sent Events until the session reaches a
+// terminal state or the client disconnects
+func (h *QKDHandler) SessionEventsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	health := map[string]interface{}{
-		"status":  "healthy",
-		"service": "Quantum Key Distribution",
-		"version": "1.0.0",
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, health)
-}
-
-// respondWithJSON sends a JSON response
-func respondWithJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	sessionID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	sub, err := h.sessionManager.SubscribeEvents(sessionID)
+	if err != nil {
+		statusCode := http.StatusNotFound
+		if err == qkd.ErrTooManySubscribers {
+			statusCode = http.StatusServiceUnavailable
+			w.Header().Set("Retry-After", "5")
+		}
+		respondWithError(w, statusCode, err.Error())
+		return
+	}
+	defer sub.Close()
+
+	// Progress streams outlive the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// The first event carries the session's current status
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			writeSSE(w, event)
+			flusher.Flush()
+
+			if event.Type == qkdcore.EventStatus && event.Status.IsTerminal() {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes a single Server-Sent Event
+func writeSSE(w http.ResponseWriter, event qkdcore.SessionEvent) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
+
+// wsWriteTimeout bounds how long a single WebSocket message may take to send
+const wsWriteTimeout = 10 * time.Second
+
+// wsUpgrader upgrades progress requests to WebSocket connections. Browser
+// clients must connect from the same origin.
+var wsUpgrader = websocket.Upgrader{}
+
+// SessionWebSocketHandler handles GET /api/v1/qkd/session/{id}/ws
+// Streams session progress as JSON WebSocket messages until the session reaches
+// a terminal state or the client disconnects
+func (h *QKDHandler) SessionWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	sessionID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Subscribe before upgrading so errors are reported as plain HTTP responses
+	sub, err := h.sessionManager.SubscribeEvents(sessionID)
+	if err != nil {
+		statusCode := http.StatusNotFound
+		if err == qkd.ErrTooManySubscribers {
+			statusCode = http.StatusServiceUnavailable
+			w.Header().Set("Retry-After", "5")
+		}
+		respondWithError(w, statusCode, err.Error())
+		return
+	}
+	defer sub.Close()
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader has already replied
+	}
+	defer conn.Close()
+
+	// Progress streams outlive the server's read timeout
+	conn.SetReadDeadline(time.Time{})
+
+	// Clients only send control frames; reading them surfaces a disconnect
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// The first event carries the session's current status
+	for {
+		select {
+		case <-disconnected:
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+
+			if event.Type == qkdcore.EventStatus && event.Status.IsTerminal() {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, string(event.Status)),
+					time.Now().Add(wsWriteTimeout))
+				return
+			}
+		}
+	}
+}
+
+// CompareProtocolsHandler handles POST /api/v1/qkd/compare
+// Runs every implemented protocol over the same simulated channel and returns a result table
+func (h *QKDHandler) CompareProtocolsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req qkd.CompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, err := qkdcore.CompareProtocols(r.Context(), req.NoiseLevel, req.KeyLength)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Comparison failed: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, qkd.CompareResponse{
+		NoiseLevel: req.NoiseLevel,
+		KeyLength:  req.KeyLength,
+		Results:    results,
+	})
+}
+
+// AnalyzeChannelHandler handles POST /api/v1/qkd/analyze
+// Runs repeated BB84 exchanges over a simulated channel and returns aggregate statistics
+func (h *QKDHandler) AnalyzeChannelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req qkd.AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	backend := quantum.NewSimulatorBackend(req.NoiseLevel > 0, req.NoiseLevel)
+	backend.SetLossRate(req.LossRate)
+	backend.SetInterceptProbability(req.InterceptProbability)
+
+	analysis, err := qkdcore.AnalyzeChannel(r.Context(), backend, req.KeyLength, req.Runs)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Analysis failed: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, analysis)
+}
+
+// EstimateFeasibilityHandler handles GET /api/v1/qkd/estimate?noise=0.05&length=256&oversampling=N
+// Predicts whether a secure key of the requested length is achievable without running an exchange
+func (h *QKDHandler) EstimateFeasibilityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var req qkd.EstimateRequest
+	var err error
+	if req.NoiseLevel, err = strconv.ParseFloat(query.Get("noise"), 64); err != nil {
+		respondWithError(w, http.StatusBadRequest, qkd.ErrInvalidNoiseLevel.Error())
+		return
+	}
+	if req.KeyLength, err = strconv.Atoi(query.Get("length")); err != nil {
+		respondWithError(w, http.StatusBadRequest, qkd.ErrInvalidKeyLength.Error())
+		return
+	}
+	if raw := query.Get("oversampling"); raw != "" {
+		if req.Oversampling, err = strconv.Atoi(raw); err != nil || req.Oversampling == 0 {
+			respondWithError(w, http.StatusBadRequest, qkd.ErrInvalidOversampling.Error())
+			return
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, qkdcore.EstimateFeasibility(req.NoiseLevel, req.KeyLength, req.Oversampling))
+}
+
+// RandomHandler handles GET /api/v1/qkd/random?bytes=32
+// Returns fresh random bytes from the sifted key of a BB84 exchange
+func (h *QKDHandler) RandomHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n := qkd.DefaultRandomBytes
+	if raw := r.URL.Query().Get("bytes"); raw != "" {
+		var err error
+		if n, err = strconv.Atoi(raw); err != nil {
+			respondWithError(w, http.StatusBadRequest, qkd.ErrInvalidRandomBytes.Error())
+			return
+		}
+	}
+
+	random, err := h.sessionManager.RandomBytes(r.Context(), n)
+	if err != nil {
+		statusCode := exchangeErrorStatus(err)
+		if err == qkd.ErrInvalidRandomBytes {
+			statusCode = http.StatusBadRequest
+		}
+		respondWithError(w, statusCode, err.Error())
+		return
+	}
+	defer crypto.Zeroize(random)
+
+	respondWithSecret(w, http.StatusOK, qkd.RandomResponse{
+		Bytes:     len(random),
+		RandomHex: hex.EncodeToString(random),
+	})
+}
+
+// KeyPoolHandler handles GET /api/v1/qkd/pool
+// Reports how many pre-generated keys are ready, whether one is being generated
+// and how many have been drawn
+func (h *QKDHandler) KeyPoolHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := h.sessionManager.KeyPoolStats()
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, stats)
+}
+
+// BellTestHandler handles POST /api/v1/qkd/channel/bell
+// Measures entangled pairs on the configured backend and reports the CHSH value S
+func (h *QKDHandler) BellTestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req qkd.BellTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Pairs == 0 {
+		req.Pairs = qkd.DefaultBellTestPairs
+	}
+
+	result, err := h.sessionManager.RunBellTest(r.Context(), req.Pairs)
+	if errors.Is(err, qkd.ErrEntanglementUnsupported) {
+		respondWithError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Bell test failed: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetKeyHandler handles GET /api/v1/qkd/key/{id}
+// Retrieves a generated quantum key (requires authentication)
+func (h *QKDHandler) GetKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract key ID from URL
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	keyID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	key, err := h.sessionManager.RetrieveKey(keyID, userID)
+	if err != nil {
+		respondWithError(w, keyErrorStatus(err), err.Error())
+		return
+	}
+	defer crypto.Zeroize(key.KeyMaterial)
+
+	respondWithSecret(w, http.StatusOK, newKeyResponse(key))
+}
+
+// KeyInfoHandler handles GET /api/v1/qkd/key/{id}/info
+// Returns a quantum key's metadata without its material
+func (h *QKDHandler) KeyInfoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 7 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	keyID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	key, err := h.sessionManager.GetKeyInfo(keyID, userID)
+	if err != nil {
+		respondWithError(w, keyErrorStatus(err), err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, qkd.KeyMetadataResponse{
+		KeyID:             key.KeyID.String(),
+		SessionID:         key.SessionID.String(),
+		Label:             key.Label,
+		KeyLength:         key.KeyLength,
+		GeneratedAt:       key.GeneratedAt,
+		ExpiresAt:         key.ExpiresAt,
+		UsedAt:            key.UsedAt,
+		IsActive:          key.IsActive,
+		ConsumedBytes:     key.ConsumedBytes,
+		MaterialRetrieved: h.sessionManager.HasRetrievedKey(key, userID),
+	})
+}
+
+// GetKeyByLabelHandler handles GET /api/v1/qkd/key/by-label/{label}
+// Retrieves the caller's key carrying an application-supplied label
+func (h *QKDHandler) GetKeyByLabelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	label := strings.TrimPrefix(r.URL.Path, "/api/v1/qkd/key/by-label/")
+	if label == "" || strings.Contains(label, "/") {
+		respondWithError(w, http.StatusBadRequest, qkd.ErrInvalidLabel.Error())
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	key, err := h.sessionManager.RetrieveKeyByLabel(label, userID)
+	if err != nil {
+		respondWithError(w, keyErrorStatus(err), err.Error())
+		return
+	}
+	defer crypto.Zeroize(key.KeyMaterial)
+
+	respondWithSecret(w, http.StatusOK, newKeyResponse(key))
+}
+
+// DeriveKeyHandler handles GET /api/v1/qkd/key/{id}/derive?alg=aes256&info=...
+// Derives a symmetric key of the algorithm's size from a quantum key with HKDF.
+// Deriving counts as the caller's retrieval of the key, so its raw material can no
+// longer be fetched afterwards; further derivations remain allowed.
+func (h *QKDHandler) DeriveKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 7 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	keyID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		return
+	}
+
+	alg := crypto.KeyAlgorithm(strings.ToLower(r.URL.Query().Get("alg")))
+	if _, err := crypto.KeySize(alg); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	info := r.URL.Query().Get("info")
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	key, err := h.sessionManager.RetrieveKeyForDerivation(keyID, userID)
+	if err != nil {
+		respondWithError(w, keyErrorStatus(err), err.Error())
+		return
+	}
+	defer crypto.Zeroize(key.KeyMaterial)
+
+	derived, err := crypto.DeriveKey(key.KeyMaterial, alg, info)
+	if err != nil {
+		respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	defer crypto.Zeroize(derived)
+
+	respondWithSecret(w, http.StatusOK, qkd.DerivedKeyResponse{
+		KeyID:     key.KeyID.String(),
+		Algorithm: string(alg),
+		Info:      info,
+		KeyHex:    hex.EncodeToString(derived),
+		KeyLength: len(derived) * 8,
+		ExpiresAt: key.ExpiresAt,
+	})
+}
+
+// ConsumeKeyHandler handles POST /api/v1/qkd/key/{id}/consume
+// Hands out the next unused bytes of a quantum key for one-time-pad use
+func (h *QKDHandler) ConsumeKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 7 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	keyID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		return
+	}
+
+	var req qkd.ConsumeKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	material, key, err := h.sessionManager.ConsumeKey(keyID, userID, req.Bytes)
+	if err != nil {
+		respondWithError(w, keyErrorStatus(err), err.Error())
+		return
+	}
+	defer crypto.Zeroize(material)
+	defer crypto.Zeroize(key.KeyMaterial)
+
+	respondWithSecret(w, http.StatusOK, qkd.ConsumedKeyResponse{
+		KeyID:          key.KeyID.String(),
+		KeyHex:         hex.EncodeToString(material),
+		Offset:         key.ConsumedBytes - len(material),
+		Bytes:          len(material),
+		RemainingBytes: len(key.KeyMaterial) - key.ConsumedBytes,
+		Exhausted:      !key.IsActive,
+	})
+}
+
+// DownloadKeyHandler handles GET /api/v1/qkd/key/{id}/download
+// Returns a quantum key's unused bytes as a one-time-pad file and marks the key consumed
+func (h *QKDHandler) DownloadKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 7 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	keyID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	material, key, err := h.sessionManager.DownloadKey(keyID, userID)
+	if err != nil {
+		respondWithError(w, keyErrorStatus(err), err.Error())
+		return
+	}
+	defer crypto.Zeroize(material)
+	defer crypto.Zeroize(key.KeyMaterial)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"qkd-key-%s.bin\"", key.KeyID))
+	w.Header().Set("Content-Length", strconv.Itoa(len(material)))
+	w.Header().Set("Cache-Control", "no-store, no-transform")
+	w.WriteHeader(http.StatusOK)
+	w.Write(material)
+}
+
+// RotateKeyHandler handles POST /api/v1/qkd/key/{id}/rotate
+// Runs a new key exchange on the key's session and retires the old key
+func (h *QKDHandler) RotateKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 7 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	keyID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	key, err := h.sessionManager.RotateKey(r.Context(), keyID, userID)
+	if err != nil {
+		status := keyErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = exchangeErrorStatus(err)
+		}
+		respondWithError(w, status, fmt.Sprintf("Key rotation failed: %v", err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"key_id":          key.KeyID.String(),
+		"previous_key_id": keyID.String(),
+		"session_id":      key.SessionID.String(),
+		"expires_at":      key.ExpiresAt,
+		"message":         "Key rotated successfully",
+	})
+}
+
+// newKeyResponse builds the key retrieval response, including the key material
+func newKeyResponse(key *qkd.QuantumKey) qkd.KeyResponse {
+	return qkd.KeyResponse{
+		KeyID:     key.KeyID.String(),
+		SessionID: key.SessionID.String(),
+		Label:     key.Label,
+		KeyHex:    hex.EncodeToString(key.KeyMaterial),
+		KeyLength: key.KeyLength,
+		ExpiresAt: key.ExpiresAt,
+	}
+}
+
+// keyErrorStatus maps key retrieval errors to HTTP status codes
+func keyErrorStatus(err error) int {
+	switch err {
+	case qkd.ErrKeyNotFound:
+		return http.StatusNotFound
+	case qkd.ErrUnauthorized:
+		return http.StatusForbidden
+	case qkd.ErrKeyExpired, qkd.ErrKeyInactive:
+		return http.StatusGone
+	case qkd.ErrInvalidConsumeLength:
+		return http.StatusBadRequest
+	case qkd.ErrKeyExhausted, qkd.ErrKeyAlreadyRetrieved, qkd.ErrKeyConsumed, qkd.ErrKeyDeliveredETSI:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// RevokeKeyHandler handles DELETE /api/v1/qkd/key/{id}
+// Revokes a quantum key
+func (h *QKDHandler) RevokeKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL format")
+		return
+	}
+
+	keyID, err := uuid.Parse(pathParts[5])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID")
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	if err := h.sessionManager.RevokeKey(keyID, userID); err != nil {
+		status := keyErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusNotFound
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Key revoked successfully",
+	})
+}
+
+// BasesHandler handles POST /api/v1/qkd/bases?session_id={id}&length=N[&include_bits=true]
+// Issues server-generated random bases for participants running quantum hardware externally
+func (h *QKDHandler) BasesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	sessionID, err := uuid.Parse(query.Get("session_id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	length, err := strconv.Atoi(query.Get("length"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, qkd.ErrInvalidBasesLength.Error())
+		return
+	}
+
+	includeBits, _ := strconv.ParseBool(query.Get("include_bits"))
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	external, err := h.sessionManager.GenerateBases(sessionID, userID, length, includeBits)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err {
+		case qkd.ErrSessionNotFound:
+			statusCode = http.StatusNotFound
+		case qkd.ErrUnauthorized:
+			statusCode = http.StatusForbidden
+		case qkd.ErrBasesAlreadyIssued:
+			statusCode = http.StatusConflict
+		case qkd.ErrInvalidBasesLength, qkd.ErrSessionExpired:
+			statusCode = http.StatusBadRequest
+		}
+		respondWithError(w, statusCode, err.Error())
+		return
+	}
+
+	response := qkd.BasesResponse{
+		SessionID:  sessionID.String(),
+		Length:     length,
+		AliceBases: quantum.EncodeBases(external.AliceBases),
+		BobBases:   quantum.EncodeBases(external.BobBases),
+	}
+	if external.AliceBits != nil {
+		// Alice's bits become the raw key, so they are sent like key material
+		response.AliceBits = quantum.EncodeBits(external.AliceBits)
+		respondWithSecret(w, http.StatusOK, response)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// ReconcileHandler handles POST /api/v1/qkd/reconcile
+// Sifts externally measured bits against previously issued bases
+func (h *QKDHandler) ReconcileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req qkd.ReconcileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sessionID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "User authentication required")
+		return
+	}
+
+	// Taking the bases first means concurrent requests cannot both reconcile them
+	external, err := h.sessionManager.TakeExternalBases(sessionID, userID)
+	if err != nil {
+		statusCode := http.StatusNotFound
+		if err == qkd.ErrUnauthorized {
+			statusCode = http.StatusForbidden
+		}
+		respondWithError(w, statusCode, err.Error())
+		return
+	}
+	defer crypto.ZeroizeBits(external.AliceBits)
+	length := len(external.AliceBases)
+
+	measurements, err := quantum.DecodeBits(req.BobMeasurements, length)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid bob_measurements: %v", err))
+		return
+	}
+
+	var aliceBits []quantum.Bit
+	if req.AliceBits != "" {
+		aliceBits, err = quantum.DecodeBits(req.AliceBits, length)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid alice_bits: %v", err))
+			return
+		}
+	}
+
+	sifted, disclosed, qber, err := h.sessionManager.ReconcileBases(external, measurements, aliceBits)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mask := make([]quantum.Bit, length)
+	for _, idx := range sifted.Indices {
+		mask[idx] = quantum.One
+	}
+	sampleMask := make([]quantum.Bit, length)
+	for _, idx := range disclosed {
+		sampleMask[idx] = quantum.One
+	}
+
+	respondWithJSON(w, http.StatusOK, qkd.ReconcileResponse{
+		SessionID:         sessionID.String(),
+		RawLength:         length,
+		SiftedLength:      len(sifted.AliceKey),
+		SiftingEfficiency: sifted.Efficiency(sifted.RawLength),
+		SiftMask:          quantum.EncodeBits(mask),
+		SampleMask:        quantum.EncodeBits(sampleMask),
+		SampledLength:     len(disclosed),
+		QBER:              qber,
+	})
+}
+
+// healthProbeTimeout bounds how long a health check waits for the backend
+const healthProbeTimeout = 5 * time.Second
+
+// HealthCheckHandler handles GET /api/v1/qkd/health
+// Returns health status of the QKD service, probing the quantum backend. The
+// service is as healthy as its backend; an unhealthy backend returns 503.
+func (h *QKDHandler) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthProbeTimeout)
+	defer cancel()
+	backend := h.sessionManager.BackendHealth(ctx)
+
+	statusCode := http.StatusOK
+	if backend.Status == string(quantum.HealthUnhealthy) {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	respondWithJSON(w, statusCode, qkd.HealthResponse{
+		Status:  backend.Status,
+		Service: "Quantum Key Distribution",
+		Version: "1.0.0",
+		Backend: backend,
+	})
+}
+
+// respondWithJSON sends a JSON response
+func respondWithJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(data)
 }
 
+// respondWithSecret sends a JSON response carrying key material. It must not be
+// stored by caches or compressed: compressing a secret next to data an attacker
+// can influence leaks it through the response length (CRIME/BREACH).
+func respondWithSecret(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Cache-Control", "no-store, no-transform")
+	respondWithJSON(w, statusCode, data)
+}
+
 // respondWithError sends an error response
 func respondWithError(w http.ResponseWriter, statusCode int, message string) {
 	respondWithJSON(w, statusCode, map[string]string{
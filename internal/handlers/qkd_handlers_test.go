@@ -0,0 +1,1686 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	qkdcore "github.com/jaskrrish/Go-OKD/internal/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// newTestHandler returns a handler backed by a noiseless simulator together with its session manager
+func newTestHandler() (*QKDHandler, *qkdcore.SessionManager) {
+	sm := qkdcore.NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	return NewQKDHandlerWithManager(sm), sm
+}
+
+// createTestSession creates a session for alice and returns it
+func createTestSession(t *testing.T, sm *qkdcore.SessionManager) *qkd.QKDSession {
+	t.Helper()
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{
+		AliceID:   "alice",
+		KeyLength: 128,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	return session
+}
+
+// decodeJSON decodes a recorded response body into v
+func decodeJSON(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+}
+
+// serveAs calls handler through the auth middleware with a token for userID
+func serveAs(t *testing.T, handler http.HandlerFunc, req *http.Request, userID string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	setBearerToken(t, req, userID)
+	rec := httptest.NewRecorder()
+	testAuth.Middleware(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBasesHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	session := createTestSession(t, sm)
+
+	const length = 10000
+	url := fmt.Sprintf("/api/v1/qkd/bases?session_id=%s&length=%d&include_bits=true", session.SessionID, length)
+	rec := serveAs(t, h.BasesHandler, httptest.NewRequest(http.MethodPost, url, nil), "alice")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store, no-transform" {
+		t.Errorf("Expected Alice's bits to be sent uncached and uncompressed, got %q", cc)
+	}
+
+	var resp qkd.BasesResponse
+	decodeJSON(t, rec, &resp)
+
+	for name, encoded := range map[string]string{"alice": resp.AliceBases, "bob": resp.BobBases} {
+		bases, err := quantum.DecodeBases(encoded, length)
+		if err != nil {
+			t.Fatalf("Failed to decode %s bases: %v", name, err)
+		}
+
+		diagonal := 0
+		for _, basis := range bases {
+			if basis == quantum.DiagonalBasis {
+				diagonal++
+			}
+		}
+
+		ratio := float64(diagonal) / float64(length)
+		if ratio < 0.45 || ratio > 0.55 {
+			t.Errorf("Expected ~50%% diagonal %s bases, got %.2f%%", name, ratio*100)
+		}
+	}
+
+	if _, err := quantum.DecodeBits(resp.AliceBits, length); err != nil {
+		t.Errorf("Expected Alice bits of length %d: %v", length, err)
+	}
+}
+
+func TestBasesHandlerRejectsInvalidLength(t *testing.T) {
+	h, sm := newTestHandler()
+	session := createTestSession(t, sm)
+
+	for _, length := range []string{"0", "-5", "65537", "abc"} {
+		url := fmt.Sprintf("/api/v1/qkd/bases?session_id=%s&length=%s", session.SessionID, length)
+		rec := serveAs(t, h.BasesHandler, httptest.NewRequest(http.MethodPost, url, nil), "alice")
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("length=%s: expected 400, got %d", length, rec.Code)
+		}
+	}
+}
+
+func TestBasesRoundTripThroughReconcile(t *testing.T) {
+	h, sm := newTestHandler()
+	session := createTestSession(t, sm)
+
+	const length = 512
+	url := fmt.Sprintf("/api/v1/qkd/bases?session_id=%s&length=%d&include_bits=true", session.SessionID, length)
+	rec := serveAs(t, h.BasesHandler, httptest.NewRequest(http.MethodPost, url, nil), "alice")
+
+	var bases qkd.BasesResponse
+	decodeJSON(t, rec, &bases)
+
+	aliceBases, _ := quantum.DecodeBases(bases.AliceBases, length)
+	bobBases, _ := quantum.DecodeBases(bases.BobBases, length)
+	aliceBits, _ := quantum.DecodeBits(bases.AliceBits, length)
+
+	// Simulate the external hardware run using the issued bases
+	measurements := make([]quantum.Bit, length)
+	expectedSifted := 0
+	for i := range aliceBits {
+		qubit := quantum.PrepareQubit(aliceBits[i], aliceBases[i])
+		measurements[i] = quantum.MeasureQubit(qubit, bobBases[i]).MeasuredBit
+		if aliceBases[i] == bobBases[i] {
+			expectedSifted++
+		}
+	}
+
+	body, _ := json.Marshal(qkd.ReconcileRequest{
+		SessionID:       session.SessionID.String(),
+		BobMeasurements: quantum.EncodeBits(measurements),
+	})
+	rec = serveAs(t, h.ReconcileHandler, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/reconcile", bytes.NewReader(body)), "alice")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp qkd.ReconcileResponse
+	decodeJSON(t, rec, &resp)
+
+	if resp.SiftedLength != expectedSifted {
+		t.Errorf("Expected sifted length %d, got %d", expectedSifted, resp.SiftedLength)
+	}
+
+	if resp.QBER != 0 {
+		t.Errorf("Expected zero QBER for a noiseless run, got %.4f", resp.QBER)
+	}
+
+	// The disclosed sample must be a subset of the sifted positions, so the client
+	// can drop exactly those bits from its key
+	siftMask, _ := quantum.DecodeBits(resp.SiftMask, length)
+	sampleMask, _ := quantum.DecodeBits(resp.SampleMask, length)
+	sampled := 0
+	for i, bit := range sampleMask {
+		if bit == quantum.One {
+			sampled++
+			if siftMask[i] != quantum.One {
+				t.Errorf("Sample position %d was not sifted", i)
+			}
+		}
+	}
+	if sampled == 0 || sampled != resp.SampledLength {
+		t.Errorf("Expected %d sampled positions in the mask, got %d", resp.SampledLength, sampled)
+	}
+
+	// Issued bases are single-use
+	rec = serveAs(t, h.ReconcileHandler, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/reconcile", bytes.NewReader(body)), "alice")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 on second reconcile, got %d", rec.Code)
+	}
+}
+
+func TestBasesHandlerRequiresParticipant(t *testing.T) {
+	h, sm := newTestHandler()
+	session := createTestSession(t, sm)
+	url := fmt.Sprintf("/api/v1/qkd/bases?session_id=%s&length=512&include_bits=true", session.SessionID)
+
+	rec := httptest.NewRecorder()
+	h.BasesHandler(rec, httptest.NewRequest(http.MethodPost, url, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+	if rec := serveAs(t, h.BasesHandler, httptest.NewRequest(http.MethodPost, url, nil), "mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-participant, got %d", rec.Code)
+	}
+
+	rec = serveAs(t, h.BasesHandler, httptest.NewRequest(http.MethodPost, url, nil), "alice")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var bases qkd.BasesResponse
+	decodeJSON(t, rec, &bases)
+
+	// The outstanding set cannot be replaced before it is reconciled
+	if rec := serveAs(t, h.BasesHandler, httptest.NewRequest(http.MethodPost, url, nil), "alice"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while bases are outstanding, got %d", rec.Code)
+	}
+
+	body, _ := json.Marshal(qkd.ReconcileRequest{
+		SessionID:       session.SessionID.String(),
+		BobMeasurements: bases.AliceBits,
+	})
+	reconcile := func(userID string) *httptest.ResponseRecorder {
+		return serveAs(t, h.ReconcileHandler, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/reconcile", bytes.NewReader(body)), userID)
+	}
+	if rec := reconcile("mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 reconciling as a non-participant, got %d", rec.Code)
+	}
+
+	// Concurrent reconciles of one set: exactly one discloses a sample
+	codes := make(chan int, 4)
+	for range cap(codes) {
+		go func() { codes <- reconcile("alice").Code }()
+	}
+	succeeded := 0
+	for range cap(codes) {
+		if <-codes == http.StatusOK {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one reconcile to succeed, got %d", succeeded)
+	}
+}
+
+// createTestKey runs a noiseless exchange between alice and bob for a labeled session
+func createTestKey(t *testing.T, sm *qkdcore.SessionManager, label string) *qkd.QuantumKey {
+	t.Helper()
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{
+		AliceID:   "alice",
+		KeyLength: 128,
+		Label:     label,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	key, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchange failed: %v", err)
+	}
+
+	return key
+}
+
+// getKeyByLabel calls the by-label handler through the auth middleware as userID
+func getKeyByLabel(t *testing.T, h *QKDHandler, label, userID string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/by-label/"+label, nil)
+	setBearerToken(t, req, userID)
+	rec := httptest.NewRecorder()
+	testAuth.Middleware(http.HandlerFunc(h.GetKeyByLabelHandler)).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetKeyByLabel(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "payments-db")
+
+	for _, userID := range []string{"alice", "bob"} {
+		rec := getKeyByLabel(t, h, "payments-db", userID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", userID, rec.Code, rec.Body.String())
+		}
+
+		var resp qkd.KeyResponse
+		decodeJSON(t, rec, &resp)
+		if resp.KeyID != key.KeyID.String() || resp.Label != "payments-db" {
+			t.Errorf("%s: unexpected key response %+v", userID, resp)
+		}
+	}
+}
+
+func TestGetKeyByLabelAuthorization(t *testing.T) {
+	h, sm := newTestHandler()
+	createTestKey(t, sm, "payments-db")
+
+	if rec := getKeyByLabel(t, h, "payments-db", "mallory"); rec.Code == http.StatusOK {
+		t.Error("Expected a third party to be denied the labeled key")
+	}
+
+	if rec := getKeyByLabel(t, h, "unknown-label", "alice"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown label, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/by-label/payments-db", nil)
+	rec := httptest.NewRecorder()
+	h.GetKeyByLabelHandler(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a user ID, got %d", rec.Code)
+	}
+}
+
+// deriveKey calls the derive handler through the auth middleware as alice
+func deriveKey(t *testing.T, h *QKDHandler, keyID uuid.UUID, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/"+keyID.String()+"/derive?"+query, nil)
+	setBearerToken(t, req, "alice")
+	rec := httptest.NewRecorder()
+	testAuth.Middleware(http.HandlerFunc(h.DeriveKeyHandler)).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeriveKeyHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	key, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchange failed: %v", err)
+	}
+
+	derived := make(map[string]string)
+	for _, tt := range []struct {
+		query string
+		bits  int
+	}{
+		{"alg=aes128", 128},
+		{"alg=aes256", 256},
+		{"alg=chacha20", 256},
+		{"alg=aes256&info=payments", 256},
+		{"alg=aes256&info=logs", 256},
+	} {
+		rec := deriveKey(t, h, key.KeyID, tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+
+		var resp qkd.DerivedKeyResponse
+		decodeJSON(t, rec, &resp)
+		if resp.KeyLength != tt.bits || len(resp.KeyHex) != tt.bits/4 {
+			t.Errorf("%s: expected a %d-bit key, got %d bits (%d hex digits)", tt.query, tt.bits, resp.KeyLength, len(resp.KeyHex))
+		}
+		derived[tt.query] = resp.KeyHex
+	}
+
+	if derived["alg=aes256&info=payments"] == derived["alg=aes256&info=logs"] {
+		t.Error("Expected different info strings to derive different keys")
+	}
+	if derived["alg=aes256"] == derived["alg=chacha20"] {
+		t.Error("Expected different algorithms to derive different keys")
+	}
+
+	if rec := deriveKey(t, h, key.KeyID, "alg=des"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unsupported algorithm, got %d", rec.Code)
+	}
+
+	// A 128-bit quantum key cannot back a 256-bit cipher key
+	short := createTestKey(t, sm, "")
+	if rec := deriveKey(t, h, short.KeyID, "alg=aes256"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 when the key is too short, got %d", rec.Code)
+	}
+}
+
+func TestDeriveKeyCountsAsRetrieval(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+	keyPath := "/api/v1/qkd/key/" + key.KeyID.String()
+
+	for _, query := range []string{"alg=aes128", "alg=aes128&info=again"} {
+		if rec := deriveKey(t, h, key.KeyID, query); rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+	}
+
+	info, err := sm.GetKeyInfo(key.KeyID, "alice")
+	if err != nil {
+		t.Fatalf("GetKeyInfo failed: %v", err)
+	}
+	if !sm.HasRetrievedKey(info, "alice") {
+		t.Error("Expected deriving to record alice's retrieval")
+	}
+
+	// The raw material is gone for alice once she derived from it, but not for bob
+	if rec := getKeyAs(t, h.GetKeyHandler, keyPath, "alice"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 retrieving a derived-from key, got %d", rec.Code)
+	}
+	if rec := getKeyAs(t, h.GetKeyHandler, keyPath, "bob"); rec.Code != http.StatusOK {
+		t.Errorf("Expected bob to retrieve the key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// getKeyAs calls handler for path through the auth middleware as userID
+func getKeyAs(t *testing.T, handler http.HandlerFunc, path, userID string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	setBearerToken(t, req, userID)
+	rec := httptest.NewRecorder()
+	testAuth.Middleware(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestKeyInfoOmitsMaterial(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+	infoPath := "/api/v1/qkd/key/" + key.KeyID.String() + "/info"
+	material := hex.EncodeToString(key.KeyMaterial)
+
+	for i := 0; i < 2; i++ {
+		rec := getKeyAs(t, h.KeyInfoHandler, infoPath, "alice")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if body := rec.Body.String(); strings.Contains(body, "key_hex") || strings.Contains(body, material) {
+			t.Fatalf("key material leaked in the info response: %s", body)
+		}
+
+		var resp qkd.KeyMetadataResponse
+		decodeJSON(t, rec, &resp)
+		if resp.KeyID != key.KeyID.String() || resp.KeyLength != key.KeyLength || !resp.IsActive {
+			t.Errorf("unexpected key metadata %+v", resp)
+		}
+		if resp.MaterialRetrieved != (i == 1) {
+			t.Errorf("fetch %d: expected material_retrieved=%v", i, i == 1)
+		}
+
+		// Fetch the material between the two info requests
+		if i == 0 {
+			getKeyAs(t, h.GetKeyHandler, "/api/v1/qkd/key/"+key.KeyID.String(), "alice")
+		}
+	}
+
+	if rec := getKeyAs(t, h.KeyInfoHandler, infoPath, "mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a third party, got %d", rec.Code)
+	}
+}
+
+func TestKeyMaterialRetrievedOnce(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "payments-db")
+	path := "/api/v1/qkd/key/" + key.KeyID.String()
+
+	if rec := getKeyAs(t, h.GetKeyHandler, path, "alice"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the first fetch to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := getKeyAs(t, h.GetKeyHandler, path, "alice")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a second fetch, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "key_hex") {
+		t.Error("key material leaked in a rejected response")
+	}
+	if rec := getKeyByLabel(t, h, "payments-db", "alice"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 when fetching by label after fetching by ID, got %d", rec.Code)
+	}
+
+	// Bob still needs his copy
+	if rec := getKeyByLabel(t, h, "payments-db", "bob"); rec.Code != http.StatusOK {
+		t.Errorf("Expected Bob's first fetch to succeed, got %d", rec.Code)
+	}
+	if rec := getKeyAs(t, h.GetKeyHandler, path, "bob"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for Bob's second fetch, got %d", rec.Code)
+	}
+}
+
+// consumeKey calls the consume handler through the auth middleware as alice
+func consumeKey(t *testing.T, h *QKDHandler, keyID uuid.UUID, n int) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, _ := json.Marshal(qkd.ConsumeKeyRequest{Bytes: n})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/qkd/key/"+keyID.String()+"/consume", bytes.NewReader(body))
+	setBearerToken(t, req, "alice")
+	rec := httptest.NewRecorder()
+	testAuth.Middleware(http.HandlerFunc(h.ConsumeKeyHandler)).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestConsumeKeyHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+
+	for i, want := range []qkd.ConsumedKeyResponse{
+		{Offset: 0, Bytes: 10, RemainingBytes: 6},
+		{Offset: 10, Bytes: 6, RemainingBytes: 0, Exhausted: true},
+	} {
+		rec := consumeKey(t, h, key.KeyID, want.Bytes)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d: %s", i, rec.Code, rec.Body.String())
+		}
+
+		var resp qkd.ConsumedKeyResponse
+		decodeJSON(t, rec, &resp)
+		want.KeyID = key.KeyID.String()
+		want.KeyHex = hex.EncodeToString(key.KeyMaterial[want.Offset : want.Offset+want.Bytes])
+		if resp != want {
+			t.Errorf("request %d: expected %+v, got %+v", i, want, resp)
+		}
+	}
+
+	if rec := consumeKey(t, h, key.KeyID, 1); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 once the key is used up, got %d", rec.Code)
+	}
+	if rec := consumeKey(t, h, key.KeyID, 0); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for zero bytes, got %d", rec.Code)
+	}
+}
+
+func TestDownloadKeyHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+
+	download := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/"+key.KeyID.String()+"/download", nil)
+		if userID != "" {
+			setBearerToken(t, req, userID)
+		}
+		rec := httptest.NewRecorder()
+		testAuth.Middleware(http.HandlerFunc(h.DownloadKeyHandler)).ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := download(""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+	if rec := download("mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-participant, got %d", rec.Code)
+	}
+
+	rec := download("bob")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !bytes.Equal(rec.Body.Bytes(), key.KeyMaterial) {
+		t.Errorf("Expected the downloaded bytes to match the key")
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Errorf("Expected application/octet-stream, got %q", ct)
+	}
+	want := `attachment; filename="qkd-key-` + key.KeyID.String() + `.bin"`
+	if cd := rec.Header().Get("Content-Disposition"); cd != want {
+		t.Errorf("Expected Content-Disposition %q, got %q", want, cd)
+	}
+	if !strings.Contains(rec.Header().Get("Cache-Control"), "no-store") {
+		t.Error("Expected the key file not to be cached")
+	}
+
+	// The download used up the key, for every participant
+	if rec := download("alice"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a second download, got %d", rec.Code)
+	}
+	if rec := consumeKey(t, h, key.KeyID, 1); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 consuming a downloaded key, got %d", rec.Code)
+	}
+}
+
+func TestDeriveRefusesConsumedKey(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+
+	if rec := consumeKey(t, h, key.KeyID, 4); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 consuming the key, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Bytes handed out as a one-time pad must not also become an AES key
+	if rec := deriveKey(t, h, key.KeyID, "alg=aes128"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 deriving from a consumed key, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := serveAs(t, h.KeyInfoHandler, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/"+key.KeyID.String()+"/info", nil), "alice")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for key info, got %d", rec.Code)
+	}
+	var info qkd.KeyMetadataResponse
+	decodeJSON(t, rec, &info)
+	if info.ConsumedBytes != 4 {
+		t.Errorf("Expected key info to report 4 consumed bytes, got %d", info.ConsumedBytes)
+	}
+}
+
+func TestRevokeKeyHandlerRequiresParticipant(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+	url := "/api/v1/qkd/key/" + key.KeyID.String()
+
+	rec := httptest.NewRecorder()
+	h.RevokeKeyHandler(rec, httptest.NewRequest(http.MethodDelete, url, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without authentication, got %d", rec.Code)
+	}
+
+	if rec := serveAs(t, h.RevokeKeyHandler, httptest.NewRequest(http.MethodDelete, url, nil), "mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-participant, got %d", rec.Code)
+	}
+	if _, err := sm.GetKey(key.KeyID, "alice"); err != nil {
+		t.Fatalf("Expected the key to survive a refused revocation, got %v", err)
+	}
+
+	if rec := serveAs(t, h.RevokeKeyHandler, httptest.NewRequest(http.MethodDelete, url, nil), "bob"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for a participant, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveAs(t, h.RevokeKeyHandler, httptest.NewRequest(http.MethodDelete, url, nil), "bob"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 revoking twice, got %d", rec.Code)
+	}
+}
+
+func TestRotateKeyHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+
+	rotate := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/qkd/key/"+key.KeyID.String()+"/rotate", nil)
+		setBearerToken(t, req, userID)
+		rec := httptest.NewRecorder()
+		testAuth.Middleware(http.HandlerFunc(h.RotateKeyHandler)).ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := rotate("mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-participant, got %d", rec.Code)
+	}
+
+	rec := rotate("bob")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]interface{}
+	decodeJSON(t, rec, &resp)
+	if resp["previous_key_id"] != key.KeyID.String() || resp["key_id"] == key.KeyID.String() {
+		t.Errorf("Expected a new key replacing %s, got %v", key.KeyID, resp)
+	}
+
+	// The rotated-away key is retired for retrieval and derivation
+	get := serveAs(t, h.GetKeyHandler, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/"+key.KeyID.String(), nil), "alice")
+	if get.Code != http.StatusGone {
+		t.Errorf("Expected 410 retrieving the old key, got %d", get.Code)
+	}
+	if rec := deriveKey(t, h, key.KeyID, "alg=aes256"); rec.Code != http.StatusGone {
+		t.Errorf("Expected 410 deriving from the old key, got %d", rec.Code)
+	}
+	download := serveAs(t, h.DownloadKeyHandler, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/key/"+key.KeyID.String()+"/download", nil), "bob")
+	if download.Code != http.StatusGone {
+		t.Errorf("Expected 410 downloading the old key, got %d", download.Code)
+	}
+	if rec := rotate("alice"); rec.Code != http.StatusGone {
+		t.Errorf("Expected 410 rotating the old key again, got %d", rec.Code)
+	}
+}
+
+func TestBatchKeyExchangeHandler(t *testing.T) {
+	h, sm := newTestHandler()
+
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	path := "/api/v1/qkd/session/" + session.SessionID.String() + "/execute/batch"
+
+	batch := func(count int) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(qkd.BatchExchangeRequest{Count: count})
+		rec := httptest.NewRecorder()
+		h.BatchKeyExchangeHandler(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+		return rec
+	}
+
+	if rec := batch(qkd.MaxBatchKeys + 1); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an oversized batch, got %d", rec.Code)
+	}
+
+	rec := batch(3)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp qkd.BatchExchangeResponse
+	decodeJSON(t, rec, &resp)
+	if resp.Count != 3 || len(resp.KeyIDs) != 3 {
+		t.Fatalf("Expected 3 key IDs, got %+v", resp)
+	}
+	for _, id := range resp.KeyIDs {
+		keyID, _ := uuid.Parse(id)
+		if _, err := sm.GetKey(keyID, "alice"); err != nil {
+			t.Errorf("Expected key %s to be stored, got %v", id, err)
+		}
+	}
+
+	if rec := batch(1); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a batch on a completed session, got %d", rec.Code)
+	}
+}
+
+func TestSessionSetupRequiresParticipant(t *testing.T) {
+	h, sm := newTestHandler()
+
+	initiate := func(userID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(qkd.SessionCreateRequest{
+			AliceID:            "alice",
+			KeyLength:          128,
+			Label:              "payments-db",
+			CallbackURL:        "https://attacker.example/hook",
+			CallbackIncludeKey: true,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/qkd/session/initiate", bytes.NewReader(body))
+		if userID == "" {
+			rec := httptest.NewRecorder()
+			h.InitiateSessionHandler(rec, req)
+			return rec
+		}
+		return serveAs(t, h.InitiateSessionHandler, req, userID)
+	}
+
+	if rec := initiate(""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without authentication, got %d", rec.Code)
+	}
+	if rec := initiate("mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a third party initiating as alice, got %d", rec.Code)
+	}
+	if sessions, _ := sm.ListSessions(qkdcore.SessionFilter{}); len(sessions) != 0 {
+		t.Fatalf("Expected no session to be created, got %d", len(sessions))
+	}
+
+	session := createTestSession(t, sm)
+	join := func(userID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(qkd.SessionJoinRequest{SessionID: session.SessionID.String(), BobID: "bob", JoinToken: session.JoinToken})
+		return serveAs(t, h.JoinSessionHandler, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/session/join", bytes.NewReader(body)), userID)
+	}
+	if rec := join("mallory"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a third party joining as bob, got %d", rec.Code)
+	}
+	if rec := join("bob"); rec.Code != http.StatusOK {
+		t.Errorf("Expected bob to join, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDuplicateLabelRejected(t *testing.T) {
+	h, sm := newTestHandler()
+	createTestKey(t, sm, "payments-db")
+
+	body, _ := json.Marshal(qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, Label: "payments-db"})
+	rec := serveAs(t, h.InitiateSessionHandler, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/session/initiate", bytes.NewReader(body)), "alice")
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for duplicate label, got %d", rec.Code)
+	}
+
+	// The same label is free for a different participant
+	if _, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "carol", KeyLength: 128, Label: "payments-db"}); err != nil {
+		t.Errorf("Expected label to be available to another user, got %v", err)
+	}
+
+	if _, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "carol", KeyLength: 128, Label: "bad/label"}); err != qkd.ErrInvalidLabel {
+		t.Errorf("Expected ErrInvalidLabel, got %v", err)
+	}
+}
+
+// getSession calls the session handler with an optional If-None-Match header
+func getSession(h *QKDHandler, sessionID, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+sessionID, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	h.GetSessionHandler(rec, req)
+	return rec
+}
+
+func TestCompletedSessionIsRevalidated(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+
+	rec := getSession(h, key.SessionID.String(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" || !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("Expected a strong ETag, got %q", etag)
+	}
+	// Retries, rotation and batches can change a completed session
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "no-cache") || strings.Contains(cc, "immutable") || strings.Contains(cc, "max-age") {
+		t.Errorf("Expected a revalidated Cache-Control, got %q", cc)
+	}
+
+	rec = getSession(h, key.SessionID.String(), etag)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 on revalidation, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Error("Expected an empty body for 304")
+	}
+
+	rec = getSession(h, key.SessionID.String(), `"stale"`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a stale ETag, got %d", rec.Code)
+	}
+}
+
+func TestActiveSessionIsNotCached(t *testing.T) {
+	h, sm := newTestHandler()
+	session := createTestSession(t, sm)
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	rec := getSession(h, session.SessionID.String(), "*")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	if etag := rec.Header().Get("ETag"); etag != "" {
+		t.Errorf("Expected no ETag for an active session, got %q", etag)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Expected no-cache, got %q", cc)
+	}
+}
+
+// openEventStream opens an SSE stream for a session against a live test server
+func openEventStream(t *testing.T, server *httptest.Server, sessionID string) (*http.Response, context.CancelFunc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/qkd/session/"+sessionID+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+
+	return resp, cancel
+}
+
+func TestSessionEventsSubscriberCap(t *testing.T) {
+	h, sm := newTestHandler()
+	sm.SetSubscriberLimits(2, 100)
+	server := httptest.NewServer(http.HandlerFunc(h.SessionEventsHandler))
+	defer server.Close()
+
+	session := createTestSession(t, sm)
+	id := session.SessionID.String()
+
+	first, cancelFirst := openEventStream(t, server, id)
+	defer first.Body.Close()
+	second, cancelSecond := openEventStream(t, server, id)
+	defer second.Body.Close()
+	defer cancelSecond()
+
+	for _, resp := range []*http.Response{first, second} {
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Expected text/event-stream, got %q", ct)
+		}
+	}
+
+	third, cancelThird := openEventStream(t, server, id)
+	third.Body.Close()
+	cancelThird()
+	if third.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 over the cap, got %d", third.StatusCode)
+	}
+
+	// Disconnecting a client frees its slot
+	cancelFirst()
+	deadline := time.Now().Add(5 * time.Second)
+	for sm.EventSubscribers(session.SessionID) >= 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the subscriber slot to be released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	fourth, cancelFourth := openEventStream(t, server, id)
+	defer fourth.Body.Close()
+	defer cancelFourth()
+	if fourth.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 after a slot was freed, got %d", fourth.StatusCode)
+	}
+}
+
+func TestSessionEventsStreamsProgress(t *testing.T) {
+	h, sm := newTestHandler()
+	server := httptest.NewServer(http.HandlerFunc(h.SessionEventsHandler))
+	defer server.Close()
+
+	session := createTestSession(t, sm)
+	resp, cancel := openEventStream(t, server, session.SessionID.String())
+	defer cancel()
+	defer resp.Body.Close()
+
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	go sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+
+	// The stream ends once the session reaches a terminal state
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+
+	stream := string(body)
+	for _, status := range []qkd.SessionStatus{qkd.SessionWaitingForBob, qkd.SessionActive, qkd.SessionCompleted} {
+		if !strings.Contains(stream, fmt.Sprintf(`"status":"%s"`, status)) {
+			t.Errorf("Expected %s event in stream:\n%s", status, stream)
+		}
+	}
+
+	if sm.EventSubscribers(session.SessionID) != 0 {
+		t.Error("Expected the subscription to be released after the stream ended")
+	}
+}
+
+func TestSessionEventsUnknownSession(t *testing.T) {
+	h, _ := newTestHandler()
+	rec := httptest.NewRecorder()
+	h.SessionEventsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+uuid.New().String()+"/events", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
+
+// dialSessionWebSocket opens a progress WebSocket for a session
+func dialSessionWebSocket(t *testing.T, server *httptest.Server, sessionID string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/qkd/session/" + sessionID + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to open WebSocket: %v", err)
+	}
+
+	return conn
+}
+
+func TestSessionWebSocketStreamsProgress(t *testing.T) {
+	// Noise keeps the sampled QBER above zero so Cascade uses realistic block sizes
+	sm := qkdcore.NewSessionManager(quantum.NewSimulatorBackend(true, 0.03))
+	h := NewQKDHandlerWithManager(sm)
+	server := httptest.NewServer(http.HandlerFunc(h.SessionWebSocketHandler))
+	defer server.Close()
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	conn := dialSessionWebSocket(t, server, session.SessionID.String())
+	defer conn.Close()
+
+	// The current status arrives first, so the exchange cannot outrun the subscription
+	var first qkdcore.SessionEvent
+	if err := conn.ReadJSON(&first); err != nil {
+		t.Fatalf("Failed to read initial event: %v", err)
+	}
+	if first.Type != qkdcore.EventStatus || first.Status != qkd.SessionActive {
+		t.Fatalf("Expected the initial active status, got %s/%s", first.Type, first.Status)
+	}
+
+	go sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+
+	// The server closes the socket after the terminal status
+	var sequence []string
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		var event qkdcore.SessionEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				t.Fatalf("Expected a normal close, got %v", err)
+			}
+			break
+		}
+		if event.SessionID != session.SessionID {
+			t.Errorf("Event for unexpected session %s", event.SessionID)
+		}
+
+		step := event.Type
+		if event.Type == qkdcore.EventStatus {
+			step += ":" + string(event.Status)
+		} else if event.Type == qkdcore.EventStage {
+			step += ":" + event.Stage
+		}
+		sequence = append(sequence, step)
+	}
+
+	expected := []string{
+		"status:initiating",
+		"stage:sift",
+		"stage:estimate",
+		"stage:correct",
+		"stage:confirm",
+		"stage:amplify",
+		"key_ready",
+		"status:completed",
+	}
+	if strings.Join(sequence, ",") != strings.Join(expected, ",") {
+		t.Errorf("Unexpected event sequence:\n got  %v\n want %v", sequence, expected)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for sm.EventSubscribers(session.SessionID) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the subscription to be released after the socket closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSessionWebSocketClientDisconnect(t *testing.T) {
+	h, sm := newTestHandler()
+	server := httptest.NewServer(http.HandlerFunc(h.SessionWebSocketHandler))
+	defer server.Close()
+
+	session := createTestSession(t, sm)
+	conn := dialSessionWebSocket(t, server, session.SessionID.String())
+
+	var first qkdcore.SessionEvent
+	if err := conn.ReadJSON(&first); err != nil {
+		t.Fatalf("Failed to read initial event: %v", err)
+	}
+	if sm.EventSubscribers(session.SessionID) != 1 {
+		t.Fatalf("Expected one subscriber, got %d", sm.EventSubscribers(session.SessionID))
+	}
+
+	// Closing the client mid-session stops the server goroutine and frees the slot
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for sm.EventSubscribers(session.SessionID) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the subscription to be released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSessionWebSocketUnknownSession(t *testing.T) {
+	h, _ := newTestHandler()
+	rec := httptest.NewRecorder()
+	h.SessionWebSocketHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+uuid.New().String()+"/ws", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
+
+// expectedSiftingEfficiency is the theoretical sift rate of each protocol on a noiseless channel
+var expectedSiftingEfficiency = map[string]float64{
+	"bb84":      0.50,
+	"b92":       0.25,
+	"sarg04":    0.25,
+	"six-state": 1.0 / 3.0,
+}
+
+func TestCompareProtocolsHandler(t *testing.T) {
+	h, _ := newTestHandler()
+
+	body, _ := json.Marshal(qkd.CompareRequest{NoiseLevel: 0, KeyLength: 256})
+	rec := httptest.NewRecorder()
+	h.CompareProtocolsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/compare", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp qkd.CompareResponse
+	decodeJSON(t, rec, &resp)
+
+	if len(resp.Results) != len(qkdcore.Protocols()) {
+		t.Fatalf("Expected a result per registered protocol %v, got %d", qkdcore.Protocols(), len(resp.Results))
+	}
+
+	for _, result := range resp.Results {
+		expected, known := expectedSiftingEfficiency[result.Protocol]
+		if !known {
+			t.Errorf("No expected sifting efficiency for protocol %q", result.Protocol)
+			continue
+		}
+
+		if result.SiftingEfficiency < expected-0.05 || result.SiftingEfficiency > expected+0.05 {
+			t.Errorf("%s: expected sifting efficiency ~%.2f, got %.3f", result.Protocol, expected, result.SiftingEfficiency)
+		}
+
+		if result.QBER != 0 || !result.Secure {
+			t.Errorf("%s: expected a secure, error-free run on a noiseless channel, got %+v", result.Protocol, result)
+		}
+
+		if result.SecureKeyFraction <= 0 || result.SecureKeyFraction > result.SiftingEfficiency {
+			t.Errorf("%s: implausible secure-key fraction %.3f", result.Protocol, result.SecureKeyFraction)
+		}
+	}
+}
+
+func TestCompareProtocolsHandlerValidation(t *testing.T) {
+	h, _ := newTestHandler()
+
+	for _, req := range []qkd.CompareRequest{
+		{NoiseLevel: 0.05, KeyLength: 64},
+		{NoiseLevel: -0.1, KeyLength: 256},
+		{NoiseLevel: 0.9, KeyLength: 256},
+	} {
+		body, _ := json.Marshal(req)
+		rec := httptest.NewRecorder()
+		h.CompareProtocolsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/compare", bytes.NewReader(body)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%+v: expected 400, got %d", req, rec.Code)
+		}
+	}
+}
+
+func TestAnalyzeChannelHandler(t *testing.T) {
+	h, _ := newTestHandler()
+
+	analyze := func(req qkd.AnalyzeRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		rec := httptest.NewRecorder()
+		h.AnalyzeChannelHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/analyze", bytes.NewReader(body)))
+		return rec
+	}
+
+	rec := analyze(qkd.AnalyzeRequest{NoiseLevel: 0.05, KeyLength: 256, Runs: 10})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp qkd.ChannelAnalysis
+	decodeJSON(t, rec, &resp)
+	if resp.Runs != 10 || resp.QBER.Mean < 0.02 || resp.QBER.Mean > 0.08 || len(resp.QBERHistogram) == 0 {
+		t.Errorf("Unexpected analysis of a 5%% noise channel: %+v", resp)
+	}
+
+	for _, req := range []qkd.AnalyzeRequest{
+		{NoiseLevel: 0.05, KeyLength: 256, Runs: 0},
+		{NoiseLevel: 0.05, KeyLength: 256, Runs: qkd.MaxAnalyzeRuns + 1},
+		{NoiseLevel: 0.05, KeyLength: 256, Runs: 5, LossRate: 1},
+		{NoiseLevel: 0.05, KeyLength: 256, Runs: 5, InterceptProbability: 1.5},
+	} {
+		if rec := analyze(req); rec.Code != http.StatusBadRequest {
+			t.Errorf("%+v: expected 400, got %d", req, rec.Code)
+		}
+	}
+}
+
+func TestEstimateFeasibilityHandler(t *testing.T) {
+	h, _ := newTestHandler()
+
+	estimate := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.EstimateFeasibilityHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/estimate?"+query, nil))
+		return rec
+	}
+
+	rec := estimate("noise=0.05&length=256")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp qkd.FeasibilityEstimate
+	decodeJSON(t, rec, &resp)
+	if !resp.Feasible || resp.RequestedLength != 256 || resp.Oversampling != resp.RecommendedOversampling {
+		t.Errorf("Unexpected estimate for a 5%% noise channel: %+v", resp)
+	}
+
+	for _, query := range []string{"length=256", "noise=0.05", "noise=x&length=256", "noise=0.6&length=256", "noise=0.05&length=64", "noise=0.05&length=256&oversampling=0", "noise=0.05&length=256&oversampling=1000"} {
+		if rec := estimate(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestBellTestHandler(t *testing.T) {
+	h, _ := newTestHandler()
+
+	body, _ := json.Marshal(qkd.BellTestRequest{Pairs: 2000})
+	rec := httptest.NewRecorder()
+	h.BellTestHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/channel/bell", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp qkd.BellTestResponse
+	decodeJSON(t, rec, &resp)
+	if resp.Pairs != 2000 || len(resp.Correlations) != 4 || !resp.Violated || resp.S <= resp.ClassicalBound {
+		t.Errorf("Expected a Bell violation over 2000 pairs, got %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	h.BellTestHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/channel/bell", strings.NewReader(`{"pairs": 10}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for too few pairs, got %d", rec.Code)
+	}
+
+	unsupported := NewQKDHandlerWithManager(qkdcore.NewSessionManager(quantum.NewIdealBackend()))
+	rec = httptest.NewRecorder()
+	unsupported.BellTestHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/channel/bell", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 for a backend without entangled pairs, got %d", rec.Code)
+	}
+}
+
+func TestDisclosuresHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	session := createTestSession(t, sm)
+
+	rec := httptest.NewRecorder()
+	h.DisclosuresHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+session.SessionID.String()+"/disclosures", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var summary qkd.DisclosureSummary
+	decodeJSON(t, rec, &summary)
+	if summary.TotalBits != 0 || len(summary.Entries) != 0 {
+		t.Errorf("Expected an empty ledger before post-processing, got %+v", summary)
+	}
+
+	rec = httptest.NewRecorder()
+	h.DisclosuresHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+uuid.New().String()+"/disclosures", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", rec.Code)
+	}
+}
+
+func TestTranscriptHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	key := createTestKey(t, sm, "")
+
+	transcript := func(sessionID string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.TranscriptHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+sessionID+"/transcript", nil))
+		return rec
+	}
+
+	// createTestKey runs the basic exchange, which records no transcript
+	if rec := transcript(key.SessionID.String()); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a post-processed exchange, got %d", rec.Code)
+	}
+
+	session := createTestSession(t, sm)
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); err != nil {
+		t.Fatalf("ExecuteKeyExchangeWithPostProcessing failed: %v", err)
+	}
+
+	rec := transcript(session.SessionID.String())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp qkd.Transcript
+	decodeJSON(t, rec, &resp)
+	if resp.SessionID != session.SessionID || resp.RawQubits == 0 || len(resp.SiftedIndices) == 0 || resp.AliceBases == "" {
+		t.Errorf("Unexpected transcript %+v", resp)
+	}
+
+	if rec := transcript(uuid.New().String()); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", rec.Code)
+	}
+}
+
+// listSessions calls ListSessionsHandler as userID with the given query string
+func listSessions(t *testing.T, h *QKDHandler, userID, query string) (*httptest.ResponseRecorder, qkd.SessionListResponse) {
+	t.Helper()
+
+	rec := serveAs(t, h.ListSessionsHandler, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/sessions?"+query, nil), userID)
+
+	var resp qkd.SessionListResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return rec, resp
+}
+
+func TestListSessionsFiltering(t *testing.T) {
+	h, sm := newTestHandler()
+
+	joined := createTestSession(t, sm)
+	if _, err := sm.JoinSession(joined.SessionID, "bob", joined.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	createTestSession(t, sm)
+	if _, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "carol", KeyLength: 128}); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	tests := []struct {
+		user  string
+		query string
+		want  int
+	}{
+		{"alice", "", 2},
+		{"alice", "user=alice", 2},
+		{"bob", "", 1},
+		{"carol", "", 1},
+		{"alice", "status=active", 1},
+		{"alice", "status=waiting_for_bob", 1},
+		{"bob", "status=waiting_for_bob", 0},
+		{"mallory", "", 0},
+	}
+
+	for _, tt := range tests {
+		rec, resp := listSessions(t, h, tt.user, tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %q: expected 200, got %d: %s", tt.user, tt.query, rec.Code, rec.Body.String())
+		}
+		if resp.Total != tt.want || len(resp.Sessions) != tt.want {
+			t.Errorf("%s %q: expected %d sessions, got total %d with %d on the page", tt.user, tt.query, tt.want, resp.Total, len(resp.Sessions))
+		}
+	}
+
+	// Callers see only their own sessions
+	if rec, _ := listSessions(t, h, "mallory", "user=alice"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 listing another user's sessions, got %d", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	h.ListSessionsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/sessions", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without authentication, got %d", rec.Code)
+	}
+
+	rec, resp := listSessions(t, h, "alice", "status=active")
+	if rec.Code != http.StatusOK || resp.Sessions[0].SessionID != joined.SessionID {
+		t.Errorf("Expected the joined session to be the only active one, got %+v", resp.Sessions)
+	}
+}
+
+func TestListSessionsEmpty(t *testing.T) {
+	h, _ := newTestHandler()
+
+	rec, _ := listSessions(t, h, "alice", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"sessions":[]`) {
+		t.Errorf("Expected an empty JSON array for no sessions, got %s", body)
+	}
+}
+
+func TestListSessionsPagination(t *testing.T) {
+	h, sm := newTestHandler()
+
+	const count = 5
+	for i := 0; i < count; i++ {
+		createTestSession(t, sm)
+	}
+
+	_, all := listSessions(t, h, "alice", "")
+	tests := []struct {
+		query     string
+		wantStart int
+		wantLen   int
+	}{
+		{"limit=2", 0, 2},
+		{"limit=2&offset=2", 2, 2},
+		{"limit=2&offset=4", 4, 1},
+		{"limit=2&offset=5", 5, 0},
+		{"offset=100", 5, 0},
+		{"limit=5", 0, 5},
+	}
+
+	for _, tt := range tests {
+		rec, resp := listSessions(t, h, "alice", tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected 200, got %d", tt.query, rec.Code)
+		}
+		if resp.Total != count || len(resp.Sessions) != tt.wantLen {
+			t.Fatalf("%q: expected %d of %d sessions, got %d of %d", tt.query, tt.wantLen, count, len(resp.Sessions), resp.Total)
+		}
+		for i, session := range resp.Sessions {
+			if session.SessionID != all.Sessions[tt.wantStart+i].SessionID {
+				t.Errorf("%q: page is not a stable slice of the full listing", tt.query)
+			}
+		}
+	}
+
+	for _, query := range []string{"limit=0", "limit=-1", "limit=201", "limit=abc", "offset=-1", "status=bogus"} {
+		if rec, _ := listSessions(t, h, "alice", query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestListSessionsOmitsSecrets(t *testing.T) {
+	h, sm := newTestHandler()
+	createTestKey(t, sm, "")
+
+	rec, _ := listSessions(t, h, "alice", "")
+	body := rec.Body.String()
+	for _, field := range []string{"join_token", "key_material", "key_hex"} {
+		if strings.Contains(body, field) {
+			t.Errorf("Expected session listing to omit %s, got %s", field, body)
+		}
+	}
+}
+
+func TestSessionMetricsHandler(t *testing.T) {
+	// Noise keeps the sampled QBER above zero so Cascade uses realistic block sizes
+	sm := qkdcore.NewSessionManager(quantum.NewSimulatorBackend(true, 0.03))
+	h := NewQKDHandlerWithManager(sm)
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	path := "/api/v1/qkd/session/" + session.SessionID.String() + "/metrics"
+	rec := httptest.NewRecorder()
+	h.SessionMetricsHandler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before any exchange, got %d", rec.Code)
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); err != nil {
+		t.Fatalf("ExecuteKeyExchangeWithPostProcessing failed: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.SessionMetricsHandler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var metrics qkd.SessionMetrics
+	decodeJSON(t, rec, &metrics)
+	if metrics.SessionID != session.SessionID {
+		t.Errorf("Expected metrics for %s, got %s", session.SessionID, metrics.SessionID)
+	}
+	if metrics.TotalQubits == 0 || metrics.SiftedKeyLength == 0 || metrics.SiftingEfficiency == 0 ||
+		metrics.QBER == 0 || metrics.ErrorsCorrected == 0 || metrics.DisclosedBits == 0 {
+		t.Errorf("Expected non-zero metrics after a completed exchange, got %+v", metrics)
+	}
+	if metrics.FinalKeyLength < 512 {
+		t.Errorf("Expected a final key of at least 512 bits, got %d", metrics.FinalKeyLength)
+	}
+	if metrics.ProcessingTimeMs < 0 {
+		t.Errorf("Expected a non-negative processing time, got %d", metrics.ProcessingTimeMs)
+	}
+
+	rec = httptest.NewRecorder()
+	h.SessionMetricsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+uuid.New().String()+"/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", rec.Code)
+	}
+}
+
+// getSessionStatus calls SessionStatusHandler for a session
+func getSessionStatus(t *testing.T, h *QKDHandler, sessionID string) (*httptest.ResponseRecorder, qkd.SessionStatusResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.SessionStatusHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/session/"+sessionID+"/status", nil))
+
+	var resp qkd.SessionStatusResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return rec, resp
+}
+
+func TestAsyncExecuteReportsCompletion(t *testing.T) {
+	// Noise keeps the sampled QBER above zero so Cascade uses realistic block sizes
+	sm := qkdcore.NewSessionManager(quantum.NewSimulatorBackend(true, 0.03))
+	h := NewQKDHandlerWithManager(sm)
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	path := "/api/v1/qkd/session/" + session.SessionID.String() + "/execute?async=true"
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	h.ExecuteKeyExchangeHandler(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the async call to return immediately, took %v", elapsed)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var job qkd.ExchangeJobResponse
+	decodeJSON(t, rec, &job)
+	if job.JobID == "" || job.Status != qkd.SessionInitiating || rec.Header().Get("Location") != job.StatusURL {
+		t.Errorf("Unexpected job response %+v", job)
+	}
+
+	// A second execute must not start another exchange on the same session
+	rec = httptest.NewRecorder()
+	h.ExecuteKeyExchangeHandler(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a session already executing, got %d", rec.Code)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	var status qkd.SessionStatusResponse
+	for {
+		rec, status = getSessionStatus(t, h, session.SessionID.String())
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if status.KeyID != "" || status.Error != "" || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if status.Status != qkd.SessionCompleted {
+		t.Fatalf("Expected the exchange to complete, got %+v", status)
+	}
+	if status.JobID != job.JobID || status.KeyID == "" || status.CompletedAt == nil {
+		t.Errorf("Expected the status to reference the job and its key, got %+v", status)
+	}
+
+	keyID, err := uuid.Parse(status.KeyID)
+	if err != nil {
+		t.Fatalf("Invalid key ID %q: %v", status.KeyID, err)
+	}
+	if _, err := sm.GetKey(keyID, "bob"); err != nil {
+		t.Errorf("Expected the background exchange's key to be retrievable, got %v", err)
+	}
+}
+
+func TestSessionStatusHandler(t *testing.T) {
+	h, sm := newTestHandler()
+	session := createTestSession(t, sm)
+
+	rec, status := getSessionStatus(t, h, session.SessionID.String())
+	if rec.Code != http.StatusOK || status.Status != qkd.SessionWaitingForBob || status.JobID != "" {
+		t.Errorf("Expected a waiting session with no job, got %d %+v", rec.Code, status)
+	}
+
+	if rec, _ := getSessionStatus(t, h, uuid.New().String()); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", rec.Code)
+	}
+}
+
+func TestHealthCheckReportsHealthySimulator(t *testing.T) {
+	h, _ := newTestHandler()
+
+	rec := httptest.NewRecorder()
+	h.HealthCheckHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	var health qkd.HealthResponse
+	decodeJSON(t, rec, &health)
+	if health.Status != "healthy" || health.Backend.Status != "healthy" || health.Backend.Name != "QuantumSimulator" {
+		t.Errorf("Expected a healthy simulator, got %+v %+v", health, health.Backend)
+	}
+}
+
+func TestHealthCheckReportsUnhealthyQiskit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth/login":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "token", "ttl": 3600})
+		case "/api/backends/ibm_brisbane/status":
+			json.NewEncoder(w).Encode(map[string]interface{}{"state": false, "status": "maintenance"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	backend, err := quantum.NewQiskitBackendWithConfig(quantum.QiskitBackendConfig{
+		QiskitConfig: quantum.QiskitConfig{APIToken: "api", BaseURL: server.URL, Backend: "ibm_brisbane"},
+		NoiseLevel:   0.03,
+	})
+	if err != nil {
+		t.Fatalf("NewQiskitBackendWithConfig failed: %v", err)
+	}
+	h := NewQKDHandler(backend)
+
+	rec := httptest.NewRecorder()
+	h.HealthCheckHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503, got %d", rec.Code)
+	}
+
+	var health qkd.HealthResponse
+	decodeJSON(t, rec, &health)
+	if health.Status != "unhealthy" || health.Backend.Name != "IBM-Qiskit-ibm_brisbane" || health.Backend.NoiseLevel != 0.03 {
+		t.Errorf("Unexpected health report: %+v %+v", health, health.Backend)
+	}
+	if !strings.Contains(health.Backend.Message, "maintenance") {
+		t.Errorf("Expected the device status in the message, got %q", health.Backend.Message)
+	}
+}
+
+func TestRandomHandler(t *testing.T) {
+	h, _ := newTestHandler()
+
+	seen := make(map[string]bool)
+	for _, tt := range []struct {
+		query string
+		bytes int
+	}{
+		{"", qkd.DefaultRandomBytes},
+		{"?bytes=1", 1},
+		{"?bytes=32", 32},
+		{"?bytes=32", 32},
+		{"?bytes=1024", qkd.MaxRandomBytes},
+	} {
+		rec := httptest.NewRecorder()
+		h.RandomHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/random"+tt.query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Header().Get("Cache-Control"), "no-store") {
+			t.Errorf("%q: expected random bytes not to be cached", tt.query)
+		}
+
+		var resp qkd.RandomResponse
+		decodeJSON(t, rec, &resp)
+		random, err := hex.DecodeString(resp.RandomHex)
+		if err != nil || len(random) != tt.bytes || resp.Bytes != tt.bytes {
+			t.Errorf("%q: expected %d bytes, got %d (%v)", tt.query, tt.bytes, len(random), err)
+		}
+
+		// Successive calls never repeat (a single byte may, by chance)
+		if tt.bytes > 1 {
+			if seen[resp.RandomHex] {
+				t.Errorf("%q: random bytes repeated an earlier response", tt.query)
+			}
+			seen[resp.RandomHex] = true
+		}
+	}
+
+	for _, query := range []string{"?bytes=0", "?bytes=1025", "?bytes=-1", "?bytes=many"} {
+		rec := httptest.NewRecorder()
+		h.RandomHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/random"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestKeyPoolHandler(t *testing.T) {
+	h, sm := newTestHandler()
+
+	rec := httptest.NewRecorder()
+	h.KeyPoolHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/pool", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a key pool, got %d", rec.Code)
+	}
+
+	if err := sm.StartKeyPool(128, 2); err != nil {
+		t.Fatalf("StartKeyPool failed: %v", err)
+	}
+	defer sm.Shutdown(context.Background())
+
+	rec = httptest.NewRecorder()
+	h.KeyPoolHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/qkd/pool", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats qkd.KeyPoolStats
+	decodeJSON(t, rec, &stats)
+	if stats.KeyLength != 128 || stats.Target != 2 || stats.Consumed != 0 {
+		t.Errorf("Unexpected pool stats: %+v", stats)
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket holding up to Burst requests, refilled at Rate
+// requests per second
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitRule applies a limit to requests matching a method and path. Empty
+// fields match anything.
+type RateLimitRule struct {
+	Method     string
+	PathPrefix string
+	PathSuffix string
+	Limit      RateLimit
+}
+
+// matches reports whether the rule applies to r
+func (rule RateLimitRule) matches(r *http.Request) bool {
+	return (rule.Method == "" || rule.Method == r.Method) &&
+		strings.HasPrefix(r.URL.Path, rule.PathPrefix) &&
+		strings.HasSuffix(r.URL.Path, rule.PathSuffix)
+}
+
+// DefaultRateLimitRules limits key exchanges and every other route that runs
+// one, since they can submit jobs to real quantum hardware, far more tightly
+// than session creation and reads
+func DefaultRateLimitRules() []RateLimitRule {
+	return []RateLimitRule{
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/session/", PathSuffix: "/execute", Limit: RateLimit{Rate: 1.0 / 6, Burst: 3}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/session/", PathSuffix: "/execute/batch", Limit: RateLimit{Rate: 1.0 / 30, Burst: 1}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/session/", PathSuffix: "/retry", Limit: RateLimit{Rate: 1.0 / 6, Burst: 3}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/channel/bell", Limit: RateLimit{Rate: 1.0 / 6, Burst: 3}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/analyze", Limit: RateLimit{Rate: 1.0 / 6, Burst: 3}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/key/", PathSuffix: "/rotate", Limit: RateLimit{Rate: 1.0 / 6, Burst: 3}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/compare", Limit: RateLimit{Rate: 1.0 / 6, Burst: 3}},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/qkd/random", Limit: RateLimit{Rate: 1.0 / 6, Burst: 3}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/session/initiate", Limit: RateLimit{Rate: 1, Burst: 10}},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/session/join", Limit: RateLimit{Rate: 1, Burst: 10}},
+		{Method: http.MethodGet, Limit: RateLimit{Rate: 20, Burst: 50}},
+	}
+}
+
+// maxRateLimitBuckets bounds the tracked clients before idle buckets are pruned
+const maxRateLimitBuckets = 10000
+
+// tokenBucket tracks one client's remaining requests under one rule
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// bucketKey identifies a bucket by rule and client
+type bucketKey struct {
+	rule   int
+	client string
+}
+
+// RateLimiter throttles requests per client using the first matching rule.
+// Clients are identified by their authenticated user ID, or by IP address.
+type RateLimiter struct {
+	mutex   sync.Mutex
+	rules   []RateLimitRule
+	buckets map[bucketKey]*tokenBucket
+	now     func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter with the given rules, checked in order
+func NewRateLimiter(rules []RateLimitRule) *RateLimiter {
+	return &RateLimiter{
+		rules:   rules,
+		buckets: make(map[bucketKey]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Middleware rejects requests over their limit with 429 and a Retry-After header.
+// It must run after authentication so clients are keyed by user ID.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i, rule := range rl.rules {
+			if !rule.matches(r) {
+				continue
+			}
+
+			if ok, retryAfter := rl.allow(i, clientIdentity(r)); !ok {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+				respondWithError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+			break
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from the client's bucket for a rule, or reports how long
+// until one is available
+func (rl *RateLimiter) allow(rule int, client string) (bool, time.Duration) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	limit := rl.rules[rule].Limit
+	now := rl.now()
+	key := bucketKey{rule: rule, client: client}
+
+	bucket, exists := rl.buckets[key]
+	if !exists {
+		if len(rl.buckets) >= maxRateLimitBuckets {
+			rl.prune(now)
+		}
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updated: now}
+		rl.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updated).Seconds()
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+	bucket.updated = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	if limit.Rate <= 0 {
+		return false, time.Hour
+	}
+	return false, time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second))
+}
+
+// prune drops buckets that have refilled completely, since they carry no state.
+// The caller must hold the mutex.
+func (rl *RateLimiter) prune(now time.Time) {
+	for key, bucket := range rl.buckets {
+		limit := rl.rules[key.rule].Limit
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// clientIdentity returns the authenticated user ID, falling back to the remote IP
+func clientIdentity(r *http.Request) string {
+	if userID, ok := UserIDFromContext(r.Context()); ok {
+		return "user:" + userID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
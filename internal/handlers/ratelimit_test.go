@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestRateLimiter returns a limiter with a controllable clock in front of an OK handler
+func newTestRateLimiter(rules []RateLimitRule) (*RateLimiter, http.Handler, *time.Time) {
+	clock := time.Unix(1700000000, 0)
+	rl := NewRateLimiter(rules)
+	rl.now = func() time.Time { return clock }
+
+	handler := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	return rl, handler, &clock
+}
+
+// limitedRequest sends a request from remoteAddr and returns the response
+func limitedRequest(handler http.Handler, method, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimiterThrottlesAndRefills(t *testing.T) {
+	_, handler, clock := newTestRateLimiter([]RateLimitRule{
+		{Method: http.MethodPost, PathPrefix: "/api/v1/qkd/session/initiate", Limit: RateLimit{Rate: 0.5, Burst: 3}},
+	})
+	const path = "/api/v1/qkd/session/initiate"
+
+	for i := 0; i < 3; i++ {
+		if rec := limitedRequest(handler, http.MethodPost, path, "10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+
+	rec := limitedRequest(handler, http.MethodPost, path, "10.0.0.1:1234")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the 4th rapid request to be throttled, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Expected Retry-After of 2 seconds, got %q", got)
+	}
+
+	// Another client has its own bucket
+	if rec := limitedRequest(handler, http.MethodPost, path, "10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected a different IP to be allowed, got %d", rec.Code)
+	}
+
+	// One token is back after two seconds, but only one
+	*clock = clock.Add(2 * time.Second)
+	if rec := limitedRequest(handler, http.MethodPost, path, "10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected the bucket to refill, got %d", rec.Code)
+	}
+	if rec := limitedRequest(handler, http.MethodPost, path, "10.0.0.1:1234"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected only one refilled token, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiterKeysByUserID(t *testing.T) {
+	_, limited, _ := newTestRateLimiter([]RateLimitRule{{Limit: RateLimit{Rate: 1, Burst: 1}}})
+	handler := testAuth.Middleware(limited)
+
+	send := func(userID string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/qkd/sessions", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		setBearerToken(t, req, userID)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Users behind the same address are limited independently
+	if send("alice") != http.StatusOK || send("bob") != http.StatusOK {
+		t.Fatal("Expected each user's first request to be allowed")
+	}
+	if send("alice") != http.StatusTooManyRequests {
+		t.Error("Expected alice's second request to be throttled")
+	}
+}
+
+func TestDefaultRateLimitRulesTighterForExecute(t *testing.T) {
+	_, handler, _ := newTestRateLimiter(DefaultRateLimitRules())
+	const addr = "10.0.0.1:1234"
+
+	executes := 0
+	for limitedRequest(handler, http.MethodPost, "/api/v1/qkd/session/abc/execute", addr).Code == http.StatusOK {
+		executes++
+	}
+
+	gets := 0
+	for limitedRequest(handler, http.MethodGet, "/api/v1/qkd/session/abc", addr).Code == http.StatusOK {
+		gets++
+	}
+
+	if executes == 0 || executes >= gets {
+		t.Errorf("Expected fewer executes than GETs before throttling, got %d executes and %d GETs", executes, gets)
+	}
+}
+
+func TestDefaultRateLimitRulesCoverExchangeRoutes(t *testing.T) {
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/qkd/key/abc/rotate"},
+		{http.MethodPost, "/api/v1/qkd/compare"},
+		{http.MethodGet, "/api/v1/qkd/random?bytes=32"},
+	} {
+		_, handler, _ := newTestRateLimiter(DefaultRateLimitRules())
+		const addr = "10.0.0.1:1234"
+
+		allowed := 0
+		for limitedRequest(handler, route.method, route.path, addr).Code == http.StatusOK {
+			allowed++
+		}
+		if allowed != 3 {
+			t.Errorf("%s %s: expected the key exchange burst of 3, got %d", route.method, route.path, allowed)
+		}
+	}
+}
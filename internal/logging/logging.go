@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID on requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches caller-supplied request IDs that are safe to log and echo
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestIDKey is the context key under which the request ID is stored
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying a request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// ContextHandler is a slog.Handler that adds the request ID found in a record's
+// context as a "request_id" attribute, so every line logged with the *Context
+// methods during a request can be correlated
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h to annotate records with their request ID
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// Handle adds the request ID, if any, and passes the record to the wrapped handler
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a ContextHandler around the wrapped handler's WithAttrs
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler around the wrapped handler's WithGroup
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Middleware assigns each request an ID, stores it in the request context and the
+// X-Request-ID response header, and logs one record per request with its method,
+// path, status code and duration. A well-formed X-Request-ID sent by the caller is
+// reused so IDs can be followed across services.
+func Middleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		ctx := WithRequestID(r.Context(), requestID)
+		w.Header().Set(RequestIDHeader, requestID)
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		logger.LogAttrs(ctx, slog.LevelInfo, "request completed",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.Status()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
+
+// statusRecorder captures the status code written by a handler. It forwards
+// Flush and Hijack so server-sent events and WebSocket upgrades keep working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code before writing it
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records an implicit 200 OK before writing the body
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Status returns the recorded status code, defaulting to 200 OK
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Flush forwards to the underlying writer if it supports flushing
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack forwards to the underlying writer, recording the upgrade as 101 Switching Protocols
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
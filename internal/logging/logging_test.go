@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureLogger returns a debug-level JSON logger annotated with request IDs, writing to buf
+func captureLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(NewContextHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+// decodeRecords parses every JSON log line in buf
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestMiddlewarePropagatesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := captureLogger(&buf)
+
+	handler := Middleware(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugContext(r.Context(), "handling request")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/qkd/session/initiate", nil))
+
+	requestID := rec.Header().Get(RequestIDHeader)
+	if requestID == "" {
+		t.Fatal("Expected an X-Request-ID response header")
+	}
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 log records, got %d: %s", len(records), buf.String())
+	}
+	for _, record := range records {
+		if record["request_id"] != requestID {
+			t.Errorf("Expected request_id %s on %q, got %v", requestID, record["msg"], record["request_id"])
+		}
+	}
+
+	completed := records[1]
+	if completed["msg"] != "request completed" || completed["method"] != http.MethodPost ||
+		completed["path"] != "/api/v1/qkd/session/initiate" || completed["status"] != float64(http.StatusTeapot) {
+		t.Errorf("Unexpected completion record: %v", completed)
+	}
+	if _, ok := completed["duration"]; !ok {
+		t.Error("Expected the completion record to include the duration")
+	}
+}
+
+func TestMiddlewareRequestIDHeader(t *testing.T) {
+	var buf bytes.Buffer
+	handler := Middleware(captureLogger(&buf), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{"well-formed", "req-123.abc", true},
+		{"missing", "", false},
+		{"malformed", "bad id\n", false},
+		{"too long", strings.Repeat("a", 65), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			req.Header.Set(RequestIDHeader, tt.header)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(RequestIDHeader)
+			if (requestID == tt.header) != tt.reused {
+				t.Errorf("Expected reuse=%v for %q, got request ID %q", tt.reused, tt.header, requestID)
+			}
+			if requestID == "" {
+				t.Error("Expected a request ID to be assigned")
+			}
+		})
+	}
+}
+
+func TestContextHandlerWithoutRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := captureLogger(&buf).With("component", "cleanup")
+
+	logger.InfoContext(context.Background(), "no request")
+	logger.InfoContext(WithRequestID(context.Background(), "abc"), "with request")
+
+	records := decodeRecords(t, &buf)
+	if _, ok := records[0]["request_id"]; ok {
+		t.Errorf("Expected no request_id without a request, got %v", records[0])
+	}
+	if records[1]["request_id"] != "abc" || records[1]["component"] != "cleanup" {
+		t.Errorf("Expected request_id and component attributes, got %v", records[1])
+	}
+}
@@ -0,0 +1,293 @@
+// Package metrics provides a small Prometheus-compatible metrics registry.
+// Only the pieces the service needs are implemented; output follows the
+// Prometheus text exposition format (version 0.0.4).
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are the default histogram buckets, in seconds
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector is a metric family that can write itself in text exposition format
+type Collector interface {
+	WriteText(w io.Writer) error
+}
+
+// Registry holds collectors and serves them on /metrics
+type Registry struct {
+	mutex      sync.RWMutex
+	collectors []Collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds collectors to the registry
+func (r *Registry) Register(collectors ...Collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.collectors = append(r.collectors, collectors...)
+}
+
+// WriteText writes every registered collector
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, c := range r.collectors {
+		if err := c.WriteText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler returns an HTTP handler serving the registry in text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		r.WriteText(bw)
+		bw.Flush()
+	})
+}
+
+// HistogramVec is a histogram partitioned by label values
+type HistogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+
+	mutex  sync.Mutex
+	series map[string]*histogram
+}
+
+// histogram is a single labeled series
+type histogram struct {
+	labelValues []string
+	counts      []uint64 // Per-bucket, non-cumulative
+	count       uint64
+	sum         float64
+}
+
+// NewHistogramVec creates a histogram with the given label names and upper bucket bounds
+func NewHistogramVec(name, help string, labelNames []string, buckets []float64) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &HistogramVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    sorted,
+		series:     make(map[string]*histogram),
+	}
+}
+
+// Observe records a value for the series identified by labelValues
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", h.name, len(h.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s, exists := h.series[key]
+	if !exists {
+		s = &histogram{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// Count returns the number of observations recorded for a series
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if s, exists := h.series[strings.Join(labelValues, "\xff")]; exists {
+		return s.count
+	}
+	return 0
+}
+
+// WriteText writes the histogram family in text exposition format
+func (h *HistogramVec) WriteText(w io.Writer) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := h.series[key]
+		labels := formatLabels(h.labelNames, s.labelValues)
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", h.name, labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", h.name, labels, s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, seriesLabels(labels), formatFloat(s.sum))
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, seriesLabels(labels), s.count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CounterVec is a monotonically increasing counter partitioned by label values
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mutex  sync.Mutex
+	series map[string]*counter
+}
+
+// counter is a single labeled series
+type counter struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounterVec creates a counter with the given label names; with no label
+// names it is a single unlabeled counter
+func NewCounterVec(name, help string, labelNames []string) *CounterVec {
+	return &CounterVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		series:     make(map[string]*counter),
+	}
+}
+
+// Inc adds one to the series identified by labelValues
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a non-negative value to the series identified by labelValues
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	if len(labelValues) != len(c.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", c.name, len(c.labelNames), len(labelValues)))
+	}
+	if value < 0 {
+		panic(fmt.Sprintf("metrics: %s cannot decrease", c.name))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, exists := c.series[key]
+	if !exists {
+		s = &counter{labelValues: append([]string(nil), labelValues...)}
+		c.series[key] = s
+	}
+	s.value += value
+}
+
+// Value returns the current value of a series
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if s, exists := c.series[strings.Join(labelValues, "\xff")]; exists {
+		return s.value
+	}
+	return 0
+}
+
+// WriteText writes the counter family in text exposition format
+func (c *CounterVec) WriteText(w io.Writer) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := c.series[key]
+		labels := formatLabels(c.labelNames, s.labelValues)
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, seriesLabels(labels), formatFloat(s.value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seriesLabels wraps rendered labels in braces, omitting them for unlabeled series
+func seriesLabels(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + strings.TrimSuffix(labels, ",") + "}"
+}
+
+// formatLabels renders name="value" pairs, each followed by a comma
+func formatLabels(names, values []string) string {
+	var b strings.Builder
+	for i, name := range names {
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(values[i]))
+		b.WriteString(",")
+	}
+	return b.String()
+}
+
+// formatFloat renders a float the way Prometheus expects
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
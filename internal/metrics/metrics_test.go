@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHistogramVecText(t *testing.T) {
+	h := NewHistogramVec("test_duration_seconds", "Test durations.", []string{"backend"}, []float64{1, 0.1})
+	h.Observe(0.05, "a")
+	h.Observe(0.5, "a")
+	h.Observe(5, "a")
+
+	var buf bytes.Buffer
+	if err := h.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	expected := `# HELP test_duration_seconds Test durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{backend="a",le="0.1"} 1
+test_duration_seconds_bucket{backend="a",le="1"} 2
+test_duration_seconds_bucket{backend="a",le="+Inf"} 3
+test_duration_seconds_sum{backend="a"} 5.55
+test_duration_seconds_count{backend="a"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected exposition:\n%s", buf.String())
+	}
+}
+
+func TestHistogramVecLabelEscaping(t *testing.T) {
+	h := NewHistogramVec("x", "X.", []string{"name"}, DefBuckets)
+	h.Observe(1, `quote"d`)
+
+	var buf bytes.Buffer
+	h.WriteText(&buf)
+	if !strings.Contains(buf.String(), `name="quote\"d"`) {
+		t.Errorf("Expected escaped label value, got:\n%s", buf.String())
+	}
+}
+
+func TestCounterVecText(t *testing.T) {
+	c := NewCounterVec("test_total", "Test events.", []string{"result"})
+	c.Inc("success")
+	c.Inc("success")
+	c.Add(3, "failed")
+
+	if got := c.Value("success"); got != 2 {
+		t.Errorf("Expected 2 successes, got %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := c.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	expected := `# HELP test_total Test events.
+# TYPE test_total counter
+test_total{result="failed"} 3
+test_total{result="success"} 2
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected exposition:\n%s", buf.String())
+	}
+}
+
+func TestUnlabeledSeries(t *testing.T) {
+	c := NewCounterVec("created_total", "Created.", nil)
+	c.Inc()
+	h := NewHistogramVec("ratio", "Ratio.", nil, []float64{0.5})
+	h.Observe(0.25)
+
+	var buf bytes.Buffer
+	c.WriteText(&buf)
+	h.WriteText(&buf)
+
+	for _, line := range []string{"created_total 1\n", `ratio_bucket{le="0.5"} 1`, "ratio_sum 0.25\n", "ratio_count 1\n"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Expected %q in:\n%s", line, buf.String())
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package qkd
 
 import (
+	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +20,21 @@ const (
 	SessionFailed SessionStatus = "failed"
 )
 
+// IsTerminal reports whether a session in this status has finished its exchange.
+// Terminal sessions can still change: a retry, key rotation or batch reopens them.
+func (s SessionStatus) IsTerminal() bool {
+	return s == SessionCompleted || s == SessionAborted || s == SessionFailed
+}
+
+// IsValid reports whether s is a known session status
+func (s SessionStatus) IsValid() bool {
+	switch s {
+	case SessionInitiating, SessionWaitingForBob, SessionActive, SessionCompleted, SessionAborted, SessionFailed:
+		return true
+	}
+	return false
+}
+
 // QuantumBackendType represents the quantum computing backend being used
 type QuantumBackendType string
 
@@ -40,6 +57,15 @@ type QKDSession struct {
 	FinalKeyLength  int                `json:"final_key_length"`
 	IsSecure        bool               `json:"is_secure"`
 	Message         string             `json:"message,omitempty"`
+	Label           string             `json:"label,omitempty"`
+	CallbackURL     string             `json:"callback_url,omitempty"`
+	CallbackIncludeKey bool            `json:"callback_include_key,omitempty"`
+	JoinToken       string             `json:"join_token,omitempty"` // Only set in the response to session creation
+	InterceptProbability float64       `json:"intercept_probability,omitempty"`
+	Participants    int                `json:"participants,omitempty"` // Set for conference sessions of more than two parties
+	ParticipantIDs  []string           `json:"participant_ids,omitempty"` // Parties that joined a conference session, in join order; the first is BobID
+	RetryCount      int                `json:"retry_count,omitempty"` // Key exchanges re-run after a failure
+	KeyTTLMinutes   int                `json:"key_ttl_minutes,omitempty"` // Lifetime of the generated key; 0 uses the server default
 	CreatedAt       time.Time          `json:"created_at"`
 	CompletedAt     *time.Time         `json:"completed_at,omitempty"`
 	ExpiresAt       time.Time          `json:"expires_at"`
@@ -49,12 +75,20 @@ type QKDSession struct {
 type QuantumKey struct {
 	KeyID           uuid.UUID  `json:"key_id"`
 	SessionID       uuid.UUID  `json:"session_id"`
+	Label           string     `json:"label,omitempty"`
 	KeyMaterial     []byte     `json:"-"` // Never expose in JSON
 	KeyLength       int        `json:"key_length"`
 	GeneratedAt     time.Time  `json:"generated_at"`
 	ExpiresAt       time.Time  `json:"expires_at"`
 	UsedAt          *time.Time `json:"used_at,omitempty"`
 	IsActive        bool       `json:"is_active"`
+	ConsumedBytes   int        `json:"consumed_bytes,omitempty"` // Bytes handed out for one-time-pad use
+	RetrievedBy     []string   `json:"retrieved_by,omitempty"`   // Participants who have retrieved the key material
+
+	// Set once the key is delivered through the ETSI GS QKD 014 API
+	ETSIMasterSAE   string     `json:"etsi_master_sae,omitempty"`
+	ETSISlaveSAE    string     `json:"etsi_slave_sae,omitempty"`
+	ETSIKeySize     int        `json:"etsi_key_size,omitempty"` // Delivered key size in bits
 }
 
 // SessionCreateRequest represents a request to create a new QKD session
@@ -63,12 +97,22 @@ type SessionCreateRequest struct {
 	KeyLength  int                `json:"key_length"`
 	Backend    QuantumBackendType `json:"backend,omitempty"`
 	TTLMinutes int                `json:"ttl_minutes,omitempty"`
+	KeyTTLMinutes int             `json:"key_ttl_minutes,omitempty"` // Lifetime of the generated key; 0 uses the server default
+	Label      string             `json:"label,omitempty"` // Application-supplied key label, unique per participant
+	CallbackURL string            `json:"callback_url,omitempty"`
+	CallbackIncludeKey bool       `json:"callback_include_key,omitempty"` // Only allowed for https callbacks
+	InterceptProbability float64  `json:"intercept_probability,omitempty"` // Simulated intercept-resend eavesdropper (simulator only)
+	Participants int              `json:"participants,omitempty"` // Parties sharing the key, Alice included; 0 means 2
 }
 
+// MaxConferenceParticipants is the largest number of parties that may share a conference key
+const MaxConferenceParticipants = 8
+
 // SessionJoinRequest represents a request from Bob to join a session
 type SessionJoinRequest struct {
 	SessionID string `json:"session_id"`
 	BobID     string `json:"bob_id"`
+	JoinToken string `json:"join_token"`
 }
 
 // SessionResponse represents the response when creating or querying a session
@@ -77,16 +121,366 @@ type SessionResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
+// SessionListResponse is one page of sessions matching a list query
+type SessionListResponse struct {
+	Sessions []*QKDSession `json:"sessions"`
+	Total    int           `json:"total"` // Matching sessions across all pages
+	Limit    int           `json:"limit"`
+	Offset   int           `json:"offset"`
+}
+
+// ExchangeJobResponse is returned when an asynchronous key exchange is accepted
+type ExchangeJobResponse struct {
+	JobID     string        `json:"job_id"`
+	SessionID string        `json:"session_id"`
+	Status    SessionStatus `json:"status"`
+	StatusURL string        `json:"status_url"`
+}
+
+// SessionStatusResponse reports the progress of a session's key exchange
+type SessionStatusResponse struct {
+	SessionID   string        `json:"session_id"`
+	Status      SessionStatus `json:"status"`
+	Message     string        `json:"message,omitempty"`
+	JobID       string        `json:"job_id,omitempty"`
+	KeyID       string        `json:"key_id,omitempty"` // Set once an asynchronous exchange stores its key
+	Error       string        `json:"error,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+}
+
 // KeyResponse represents the response when requesting a generated key
 type KeyResponse struct {
 	KeyID      string    `json:"key_id"`
 	SessionID  string    `json:"session_id"`
+	Label      string    `json:"label,omitempty"`
 	KeyHex     string    `json:"key_hex,omitempty"` // Hex encoded key (only for initial retrieval)
 	KeyLength  int       `json:"key_length"`
 	ExpiresAt  time.Time `json:"expires_at"`
 	Error      string    `json:"error,omitempty"`
 }
 
+// KeyMetadataResponse describes a key without its material
+type KeyMetadataResponse struct {
+	KeyID             string     `json:"key_id"`
+	SessionID         string     `json:"session_id"`
+	Label             string     `json:"label,omitempty"`
+	KeyLength         int        `json:"key_length"`
+	GeneratedAt       time.Time  `json:"generated_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	UsedAt            *time.Time `json:"used_at,omitempty"`
+	IsActive          bool       `json:"is_active"`
+	ConsumedBytes     int        `json:"consumed_bytes"`
+	MaterialRetrieved bool       `json:"material_retrieved"` // Whether the caller has retrieved the key material
+}
+
+// DerivedKeyResponse carries a symmetric key derived from a quantum key with HKDF
+type DerivedKeyResponse struct {
+	KeyID      string    `json:"key_id"`
+	Algorithm  string    `json:"algorithm"`
+	Info       string    `json:"info,omitempty"`
+	KeyHex     string    `json:"key_hex"`
+	KeyLength  int       `json:"key_length"` // Derived key length in bits
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ConsumeKeyRequest asks for the next unused bytes of a key
+type ConsumeKeyRequest struct {
+	Bytes int `json:"bytes"`
+}
+
+// ConsumedKeyResponse carries key material handed out for one-time-pad use.
+// Offset is where the material starts within the key.
+type ConsumedKeyResponse struct {
+	KeyID          string `json:"key_id"`
+	KeyHex         string `json:"key_hex"`
+	Offset         int    `json:"offset"`
+	Bytes          int    `json:"bytes"`
+	RemainingBytes int    `json:"remaining_bytes"`
+	Exhausted      bool   `json:"exhausted"`
+}
+
+// BatchExchangeRequest asks for several keys from one session
+type BatchExchangeRequest struct {
+	Count int `json:"count"`
+}
+
+// MaxBatchKeys is the largest number of keys one batch key exchange may generate
+const MaxBatchKeys = 32
+
+// Random byte endpoint bounds
+const (
+	DefaultRandomBytes = 32
+	MaxRandomBytes     = 1024
+)
+
+// KeyPoolStats reports how many pre-generated keys are ready to serve sessions
+type KeyPoolStats struct {
+	KeyLength  int  `json:"key_length"`
+	Target     int  `json:"target"`
+	Available  int  `json:"available"`
+	Generating bool `json:"generating"` // A key is being generated
+	Consumed   int  `json:"consumed"`   // Keys drawn since the pool started
+}
+
+// RandomResponse carries fresh random bytes from a BB84 exchange
+type RandomResponse struct {
+	Bytes     int    `json:"bytes"`
+	RandomHex string `json:"random_hex"`
+}
+
+// BatchExchangeResponse lists the keys generated by a batch key exchange
+type BatchExchangeResponse struct {
+	SessionID string   `json:"session_id"`
+	KeyIDs    []string `json:"key_ids"`
+	Count     int      `json:"count"`
+}
+
+// ETSIStatus is the ETSI GS QKD 014 key status between a master and slave SAE
+type ETSIStatus struct {
+	SourceKMEID       string `json:"source_KME_ID"`
+	TargetKMEID       string `json:"target_KME_ID"`
+	MasterSAEID       string `json:"master_SAE_ID"`
+	SlaveSAEID        string `json:"slave_SAE_ID"`
+	KeySize           int    `json:"key_size"`
+	StoredKeyCount    int    `json:"stored_key_count"`
+	MaxKeyCount       int    `json:"max_key_count"`
+	MaxKeyPerRequest  int    `json:"max_key_per_request"`
+	MaxKeySize        int    `json:"max_key_size"`
+	MinKeySize        int    `json:"min_key_size"`
+	MaxSAEIDCount     int    `json:"max_SAE_ID_count"`
+}
+
+// ETSIKey is a single key in an ETSI GS QKD 014 key container
+type ETSIKey struct {
+	KeyID string `json:"key_ID"`
+	Key   string `json:"key"` // Base64 encoded key material
+}
+
+// ETSIKeyContainer is the ETSI GS QKD 014 response carrying delivered keys
+type ETSIKeyContainer struct {
+	Keys []ETSIKey `json:"keys"`
+}
+
+// ETSIKeyIDsRequest is the ETSI GS QKD 014 dec_keys request body
+type ETSIKeyIDsRequest struct {
+	KeyIDs []struct {
+		KeyID string `json:"key_ID"`
+	} `json:"key_IDs"`
+}
+
+// BasesResponse carries server-generated basis choices for externally run hardware.
+// Sequences are encoded with the compact codec (see quantum.EncodeBases).
+type BasesResponse struct {
+	SessionID  string `json:"session_id"`
+	Length     int    `json:"length"`
+	AliceBases string `json:"alice_bases"`
+	BobBases   string `json:"bob_bases"`
+	AliceBits  string `json:"alice_bits,omitempty"`
+}
+
+// ReconcileRequest submits Bob's externally measured bits for sifting against
+// the bases previously issued by the bases endpoint
+type ReconcileRequest struct {
+	SessionID       string `json:"session_id"`
+	BobMeasurements string `json:"bob_measurements"`
+	AliceBits       string `json:"alice_bits,omitempty"` // Required only if the server did not generate Alice's bits
+}
+
+// ReconcileResponse reports the outcome of sifting externally measured bits
+type ReconcileResponse struct {
+	SessionID    string  `json:"session_id"`
+	RawLength    int     `json:"raw_length"`
+	SiftedLength int     `json:"sifted_length"`
+	SiftingEfficiency float64 `json:"sifting_efficiency"`
+	SiftMask     string  `json:"sift_mask"` // Compact-encoded, 1 where the bases matched
+	SampleMask   string  `json:"sample_mask"` // Compact-encoded, 1 where a sifted bit was disclosed for the QBER
+	SampledLength int    `json:"sampled_length"`
+	QBER         float64 `json:"qber"`
+}
+
+// KeyCallbackPayload is the body POSTed to a session's callback URL once its key is ready
+type KeyCallbackPayload struct {
+	Event       string    `json:"event"`
+	SessionID   string    `json:"session_id"`
+	KeyID       string    `json:"key_id"`
+	KeyLength   int       `json:"key_length"`
+	QBER        float64   `json:"qber"`
+	GeneratedAt time.Time `json:"generated_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	KeyHex      string    `json:"key_hex,omitempty"` // Only sent when requested and the callback uses https
+}
+
+// CompareRequest asks for every implemented protocol to be run over the same simulated channel
+type CompareRequest struct {
+	NoiseLevel float64 `json:"noise_level"`
+	KeyLength  int     `json:"key_length"`
+}
+
+// ProtocolComparison is one row of a protocol comparison
+type ProtocolComparison struct {
+	Protocol          string  `json:"protocol"`
+	SiftingEfficiency float64 `json:"sifting_efficiency"`
+	QBER              float64 `json:"qber"`
+	QBERThreshold     float64 `json:"qber_threshold"`
+	SecureKeyFraction float64 `json:"secure_key_fraction"` // Asymptotic secure bits per transmitted qubit
+	Secure            bool    `json:"secure"`
+	Message           string  `json:"message,omitempty"`
+}
+
+// CompareResponse lists the comparison results, one entry per protocol
+type CompareResponse struct {
+	NoiseLevel float64              `json:"noise_level"`
+	KeyLength  int                  `json:"key_length"`
+	Results    []ProtocolComparison `json:"results"`
+}
+
+// AnalyzeRequest asks for repeated BB84 exchanges over a simulated channel
+type AnalyzeRequest struct {
+	NoiseLevel           float64 `json:"noise_level"`
+	LossRate             float64 `json:"loss_rate"`
+	InterceptProbability float64 `json:"intercept_probability"`
+	KeyLength            int     `json:"key_length"`
+	Runs                 int     `json:"runs"`
+}
+
+// MaxAnalyzeRuns is the largest number of exchanges one channel analysis may run
+const MaxAnalyzeRuns = 200
+
+// StatSummary summarizes one quantity over the runs of a channel analysis
+type StatSummary struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"` // Sample standard deviation; 0 for a single run
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// HistogramBin counts the runs whose value fell in [Min, Max)
+type HistogramBin struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// ChannelAnalysis aggregates the results of repeated key exchanges over one channel.
+// SecureFraction is the asymptotic fraction of sifted bits left after error
+// correction and privacy amplification, 1 - 2h(QBER).
+type ChannelAnalysis struct {
+	Runs              int            `json:"runs"`
+	SecureRuns        int            `json:"secure_runs"`
+	QBER              StatSummary    `json:"qber"`
+	SiftingEfficiency StatSummary    `json:"sifting_efficiency"`
+	FinalKeyLength    StatSummary    `json:"final_key_length"` // 0 for insecure runs
+	SecureFraction    StatSummary    `json:"secure_fraction"`
+	QBERHistogram     []HistogramBin `json:"qber_histogram"`
+}
+
+// EstimateRequest asks what a BB84 exchange over a channel of the given noise
+// could yield. Oversampling 0 uses the factor the post-processing budget would pick.
+type EstimateRequest struct {
+	NoiseLevel   float64
+	KeyLength    int
+	Oversampling int
+}
+
+// MaxEstimateOversampling is the largest oversampling factor an estimate accepts
+const MaxEstimateOversampling = 256
+
+// FeasibilityEstimate predicts the outcome of a post-processed BB84 exchange
+// without running it. ExpectedDisclosed counts the QBER sample, the error
+// correction parities and the key confirmation tag.
+type FeasibilityEstimate struct {
+	NoiseLevel              float64 `json:"noise_level"`
+	RequestedLength         int     `json:"requested_length"`
+	Oversampling            int     `json:"oversampling"`
+	RawQubits               int     `json:"raw_qubits"`
+	ExpectedSiftedLength    int     `json:"expected_sifted_length"`
+	ExpectedDisclosed       int     `json:"expected_disclosed_bits"`
+	MaxSecureLength         int     `json:"max_secure_length"`
+	Feasible                bool    `json:"feasible"`
+	RecommendedOversampling int     `json:"recommended_oversampling"` // 0 when no transmission within the raw qubit cap suffices
+}
+
+// BellTestRequest asks for a CHSH test of the configured backend's entanglement.
+// Pairs defaults to DefaultBellTestPairs when zero.
+type BellTestRequest struct {
+	Pairs int `json:"pairs"`
+}
+
+// DefaultBellTestPairs is the number of entangled pairs measured when a Bell test request does not say
+const DefaultBellTestPairs = 4000
+
+// CHSHCorrelation is the measured correlation E(a, b) at one pair of polarizer angles
+type CHSHCorrelation struct {
+	AliceAngle  float64 `json:"alice_angle"` // Radians
+	BobAngle    float64 `json:"bob_angle"`   // Radians
+	Correlation float64 `json:"correlation"`
+	Pairs       int     `json:"pairs"`
+}
+
+// BellTestResponse reports the CHSH value S measured over the channel. Local
+// models are bounded by |S| ≤ 2; a maximally entangled pair reaches 2√2.
+type BellTestResponse struct {
+	Backend        string            `json:"backend"`
+	Pairs          int               `json:"pairs"`
+	S              float64           `json:"s"`
+	Correlations   []CHSHCorrelation `json:"correlations"`
+	ClassicalBound float64           `json:"classical_bound"`
+	QuantumBound   float64           `json:"quantum_bound"`
+	Violated       bool              `json:"violated"` // |S| exceeds the classical bound
+}
+
+// DisclosureEntry describes one public-channel disclosure made during post-processing
+type DisclosureEntry struct {
+	Step     string `json:"step"`
+	Kind     string `json:"kind"`
+	Bits     int    `json:"bits"`
+	Bytes    int    `json:"bytes"`
+	LeaksKey bool   `json:"leaks_key"` // Counts toward the leakage removed by privacy amplification
+}
+
+// DisclosureSummary is a session's disclosure ledger, without the disclosed values
+type DisclosureSummary struct {
+	SessionID      uuid.UUID         `json:"session_id"`
+	Entries        []DisclosureEntry `json:"entries"`
+	TotalBits      int               `json:"total_bits"`
+	KeyLeakageBits int               `json:"key_leakage_bits"`
+}
+
+// Transcript is the public record of a session's post-processed exchange: what
+// was announced on the classical channel and the resulting lengths, never any
+// key bit values. Bases use the compact codec (see quantum.EncodeBases).
+type Transcript struct {
+	SessionID        uuid.UUID         `json:"session_id"`
+	RawQubits        int               `json:"raw_qubits"`
+	AliceBases       string            `json:"alice_bases"`
+	BobBases         string            `json:"bob_bases"`
+	SiftedIndices    []int             `json:"sifted_indices"`        // Raw slots whose bases matched
+	SamplePositions  []int             `json:"qber_sample_positions"` // Positions in the sifted key disclosed for QBER estimation
+	QBER             float64           `json:"qber"`
+	Disclosures      []DisclosureEntry `json:"disclosures"`
+	ReconciledLength int               `json:"reconciled_length"` // Key bits entering privacy amplification
+	DisclosedBits    int               `json:"disclosed_bits"`    // Leakage charged by privacy amplification
+	SecureLength     int               `json:"secure_length"`
+	FinalKeyLength   int               `json:"final_key_length"`
+	Error            string            `json:"error,omitempty"` // Why post-processing stopped, if it failed
+}
+
+// HealthResponse reports the health of the QKD service and its quantum backend
+type HealthResponse struct {
+	Status  string         `json:"status"` // healthy, degraded or unhealthy, following the backend
+	Service string         `json:"service"`
+	Version string         `json:"version"`
+	Backend *BackendHealth `json:"backend"`
+}
+
+// BackendHealth is the result of probing the quantum backend
+type BackendHealth struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	NoiseLevel float64 `json:"noise_level"`
+	Message    string  `json:"message,omitempty"` // Why the backend is not healthy
+}
+
 // SessionMetrics represents metrics for a QKD session
 type SessionMetrics struct {
 	SessionID         uuid.UUID `json:"session_id"`
@@ -100,6 +494,9 @@ type SessionMetrics struct {
 	ProcessingTimeMs  int64     `json:"processing_time_ms"`
 }
 
+// labelPattern restricts key labels to characters that are safe in URL paths
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
 // Validate validates a session create request
 func (r *SessionCreateRequest) Validate() error {
 	if r.AliceID == "" {
@@ -124,6 +521,113 @@ func (r *SessionCreateRequest) Validate() error {
 		return ErrInvalidTTL
 	}
 
+	if r.KeyTTLMinutes != 0 && (r.KeyTTLMinutes < 1 || r.KeyTTLMinutes > 10080) {
+		return ErrInvalidKeyTTL
+	}
+
+	if r.Label != "" && !labelPattern.MatchString(r.Label) {
+		return ErrInvalidLabel
+	}
+
+	if r.CallbackURL != "" {
+		callback, err := url.Parse(r.CallbackURL)
+		if err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || callback.Host == "" {
+			return ErrInvalidCallbackURL
+		}
+
+		// Key material may only travel to an https endpoint
+		if r.CallbackIncludeKey && callback.Scheme != "https" {
+			return ErrInsecureCallbackURL
+		}
+	} else if r.CallbackIncludeKey {
+		return ErrInvalidCallbackURL
+	}
+
+	if r.InterceptProbability < 0 || r.InterceptProbability > 1 {
+		return ErrInvalidInterceptProbability
+	}
+
+	if r.Participants != 0 && (r.Participants < 2 || r.Participants > MaxConferenceParticipants) {
+		return ErrInvalidParticipants
+	}
+
+	if r.Participants > 2 && r.InterceptProbability > 0 {
+		return ErrConferenceEavesdropper
+	}
+
+	return nil
+}
+
+// Validate validates a protocol comparison request
+func (r *CompareRequest) Validate() error {
+	if r.KeyLength < 128 || r.KeyLength > 4096 {
+		return ErrInvalidKeyLength
+	}
+
+	if r.NoiseLevel < 0 || r.NoiseLevel > 0.5 {
+		return ErrInvalidNoiseLevel
+	}
+
+	return nil
+}
+
+// Validate validates a channel analysis request
+func (r *AnalyzeRequest) Validate() error {
+	if r.KeyLength < 128 || r.KeyLength > 4096 {
+		return ErrInvalidKeyLength
+	}
+
+	if r.NoiseLevel < 0 || r.NoiseLevel > 0.5 {
+		return ErrInvalidNoiseLevel
+	}
+
+	if r.LossRate < 0 || r.LossRate >= 1 {
+		return ErrInvalidLossRate
+	}
+
+	if r.InterceptProbability < 0 || r.InterceptProbability > 1 {
+		return ErrInvalidInterceptProbability
+	}
+
+	if r.Runs < 1 || r.Runs > MaxAnalyzeRuns {
+		return ErrInvalidAnalyzeRuns
+	}
+
+	return nil
+}
+
+// Validate validates a feasibility estimate request
+func (r *EstimateRequest) Validate() error {
+	if r.KeyLength < 128 || r.KeyLength > 4096 {
+		return ErrInvalidKeyLength
+	}
+
+	if r.NoiseLevel < 0 || r.NoiseLevel > 0.5 {
+		return ErrInvalidNoiseLevel
+	}
+
+	if r.Oversampling < 0 || r.Oversampling > MaxEstimateOversampling {
+		return ErrInvalidOversampling
+	}
+
+	return nil
+}
+
+// Validate validates a Bell test request
+func (r *BellTestRequest) Validate() error {
+	if r.Pairs != 0 && (r.Pairs < 100 || r.Pairs > 1<<20) {
+		return ErrInvalidBellPairs
+	}
+
+	return nil
+}
+
+// Validate validates a batch key exchange request
+func (r *BatchExchangeRequest) Validate() error {
+	if r.Count < 1 || r.Count > MaxBatchKeys {
+		return ErrInvalidBatchCount
+	}
+
 	return nil
 }
 
@@ -137,6 +641,10 @@ func (r *SessionJoinRequest) Validate() error {
 		return ErrInvalidBobID
 	}
 
+	if r.JoinToken == "" {
+		return ErrInvalidJoinToken
+	}
+
 	return nil
 }
 
@@ -155,10 +663,60 @@ var (
 	ErrInvalidSessionID  = &QKDError{"invalid session ID"}
 	ErrInvalidKeyLength  = &QKDError{"key length must be between 128 and 4096 bits"}
 	ErrInvalidTTL        = &QKDError{"TTL must be between 1 and 10080 minutes"}
+	ErrInvalidKeyTTL     = &QKDError{"key TTL must be between 1 and 10080 minutes"}
+	ErrInvalidNoiseLevel = &QKDError{"noise level must be between 0 and 0.5"}
 	ErrSessionNotFound   = &QKDError{"session not found"}
 	ErrSessionExpired    = &QKDError{"session has expired"}
 	ErrKeyNotFound       = &QKDError{"key not found"}
 	ErrKeyExpired        = &QKDError{"key has expired"}
 	ErrUnauthorized      = &QKDError{"unauthorized access"}
 	ErrSessionInProgress = &QKDError{"session already in progress"}
+	ErrInvalidJoinToken  = &QKDError{"invalid or already used join token"}
+	ErrJoinTokenExpired  = &QKDError{"join token has expired"}
+	ErrSessionNotActive  = &QKDError{"session is not active"}
+	ErrSessionAlreadyCompleted = &QKDError{"session has already completed its key exchange"}
+	ErrSessionTerminated = &QKDError{"session was aborted or failed and cannot be executed"}
+	ErrOversamplingTooLarge = &QKDError{"exchange would exceed the maximum number of raw qubits"}
+	ErrPostProcessingRequired = &QKDError{"policy requires error correction and privacy amplification; use post-processing key exchange"}
+	ErrInvalidLabel      = &QKDError{"label must be 1-128 characters of letters, digits, '.', '_' or '-'"}
+	ErrDuplicateLabel    = &QKDError{"label is already in use by this participant"}
+	ErrInvalidCallbackURL = &QKDError{"callback URL must be an absolute http or https URL"}
+	ErrInsecureCallbackURL = &QKDError{"callback URL must use https to receive key material"}
+	ErrTooManySubscribers = &QKDError{"too many subscribers for session progress"}
+	ErrInvalidBasesLength = &QKDError{"bases length must be between 1 and 65536"}
+	ErrBasesNotIssued    = &QKDError{"no bases have been issued for this session"}
+	ErrBasesAlreadyIssued = &QKDError{"bases have already been issued for this session and not yet reconciled"}
+	ErrInvalidInterceptProbability = &QKDError{"intercept probability must be between 0 and 1"}
+	ErrEavesdropperUnsupported = &QKDError{"eavesdropper simulation requires the simulator backend"}
+	ErrNoExchangeJob     = &QKDError{"no background key exchange has been started for this session"}
+	ErrMetricsNotRecorded = &QKDError{"no metrics have been recorded for this session"}
+	ErrTranscriptNotRecorded = &QKDError{"no transcript has been recorded for this session"}
+	ErrInvalidStoreKey   = &QKDError{"store encryption key must be 32 bytes"}
+	ErrInvalidBellPairs  = &QKDError{"pairs must be between 100 and 1048576"}
+	ErrEntanglementUnsupported = &QKDError{"the configured backend cannot distribute entangled pairs"}
+	ErrInvalidETSIKeySize = &QKDError{"key size must be a multiple of 8 between 8 and 4096 bits"}
+	ErrInvalidETSIKeyNumber = &QKDError{"number of keys must be between 1 and 128"}
+	ErrSessionNotRetryable = &QKDError{"only failed or aborted sessions can be retried"}
+	ErrRetryLimitReached = &QKDError{"session has reached its maximum number of key exchange retries"}
+	ErrInsufficientKeys  = &QKDError{"not enough keys are available between these SAEs for the requested number and size"}
+	ErrInvalidConsumeLength = &QKDError{"bytes to consume must be at least 1"}
+	ErrKeyExhausted      = &QKDError{"not enough unused key material remains"}
+	ErrKeyAlreadyRetrieved = &QKDError{"key material has already been retrieved; use /info for its metadata"}
+	ErrKeyConsumed       = &QKDError{"key material has been consumed for one-time-pad use"}
+	ErrKeyInactive       = &QKDError{"key has been retired and its material is no longer served"}
+	ErrKeyDeliveredETSI  = &QKDError{"key material has been delivered through the ETSI key delivery API"}
+	ErrInvalidBatchCount = &QKDError{"batch count must be between 1 and 32"}
+	ErrShuttingDown      = &QKDError{"server is shutting down"}
+	ErrInvalidLossRate   = &QKDError{"loss rate must be at least 0 and less than 1"}
+	ErrInvalidAnalyzeRuns = &QKDError{"runs must be between 1 and 200"}
+	ErrInvalidOversampling = &QKDError{"oversampling must be between 1 and 256"}
+	ErrInvalidParticipants = &QKDError{"participants must be between 2 and 8"}
+	ErrAlreadyJoined     = &QKDError{"participant has already joined this session"}
+	ErrConferenceUnsupported = &QKDError{"the configured backend cannot distribute GHZ states"}
+	ErrConferenceEavesdropper = &QKDError{"eavesdropper simulation is not supported for conference sessions"}
+	ErrTooManyExchanges  = &QKDError{"too many key exchanges are running; retry later"}
+	ErrBackendNotConfigured = &QKDError{"the requested quantum backend is not configured on this server"}
+	ErrInvalidRandomBytes = &QKDError{"bytes must be between 1 and 1024"}
+	ErrKeyPoolNotConfigured = &QKDError{"no key pool is configured"}
+	ErrKeyPoolStopped    = &QKDError{"the key pool has been stopped"}
 )
@@ -0,0 +1,140 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// QBER histogram layout: bins of width 1/qberBinsPerUnit from 0, with the last
+// bin collecting every QBER from qberBins/qberBinsPerUnit up
+const (
+	qberBinsPerUnit = 100
+	qberBins        = 15
+)
+
+// AnalyzeChannel runs runs post-processed BB84 exchanges of keyLength bits over
+// backend and aggregates their QBER, sifting efficiency, final key length and
+// secure fraction. Runs that abort or cannot reach the target length still count
+// towards every statistic, with a final key length of 0.
+func AnalyzeChannel(ctx context.Context, backend quantum.QuantumBackend, keyLength, runs int) (*qkd.ChannelAnalysis, error) {
+	if runs < 1 {
+		return nil, fmt.Errorf("runs must be at least 1, got %d", runs)
+	}
+
+	qber := make([]float64, runs)
+	sift := make([]float64, runs)
+	finalLength := make([]float64, runs)
+	secure := make([]float64, runs)
+
+	analysis := &qkd.ChannelAnalysis{Runs: runs}
+	for i := 0; i < runs; i++ {
+		pc, err := analyzeRun(ctx, backend, keyLength)
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", i+1, err)
+		}
+
+		qber[i] = pc.QBER
+		if pc.Sifted != nil {
+			sift[i] = pc.Sifted.Efficiency(len(pc.Alice.Qubits))
+		}
+		finalLength[i] = float64(len(pc.FinalKey) * 8)
+		secure[i] = secretFraction(pc.QBER)
+		if pc.FinalKey != nil {
+			analysis.SecureRuns++
+		}
+		pc.Zeroize()
+	}
+
+	analysis.QBER = summarize(qber)
+	analysis.SiftingEfficiency = summarize(sift)
+	analysis.FinalKeyLength = summarize(finalLength)
+	analysis.SecureFraction = summarize(secure)
+	analysis.QBERHistogram = qberHistogram(qber)
+
+	return analysis, nil
+}
+
+// analyzeRun performs one post-processed exchange, budgeted for the backend's
+// noise level. A pipeline failure leaves FinalKey nil rather than returning an
+// error, so the statistics gathered before it still count.
+func analyzeRun(ctx context.Context, backend quantum.QuantumBackend, keyLength int) (*PipelineContext, error) {
+	bb84 := NewBB84Protocol(backend, keyLength)
+	budget := PostProcessingBudget{
+		ExpectedQBER:      backend.GetNoiseLevel(),
+		SecurityParameter: DefaultSecurityParameter,
+		MaxQubits:         DefaultMaxRawQubits,
+	}
+	// A channel too noisy for any key keeps the default oversampling, so its QBER is still measured
+	if bb84.PostProcessingTransmissionLength(budget) > 0 {
+		bb84.BudgetPostProcessing(budget)
+	}
+
+	alice, err := bb84.AliceGenerateQubits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bob, err := bb84.BobMeasureQubits(ctx, alice.Qubits)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := &PipelineContext{
+		Protocol:     bb84,
+		Alice:        alice,
+		Bob:          bob,
+		TargetLength: keyLength,
+	}
+	if err := DefaultPipeline().Run(pc); err != nil {
+		crypto.Zeroize(pc.FinalKey)
+		pc.FinalKey = nil
+	}
+
+	return pc, nil
+}
+
+// summarize returns the mean, sample standard deviation and range of values
+func summarize(values []float64) qkd.StatSummary {
+	summary := qkd.StatSummary{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, v := range values {
+		summary.Mean += v
+		summary.Min = math.Min(summary.Min, v)
+		summary.Max = math.Max(summary.Max, v)
+	}
+	summary.Mean /= float64(len(values))
+
+	if len(values) > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - summary.Mean) * (v - summary.Mean)
+		}
+		summary.StdDev = math.Sqrt(squares / float64(len(values)-1))
+	}
+
+	return summary
+}
+
+// qberHistogram counts QBER values into fixed-width bins
+func qberHistogram(values []float64) []qkd.HistogramBin {
+	bins := make([]qkd.HistogramBin, qberBins+1)
+	for i := range bins {
+		bins[i].Min = float64(i) / qberBinsPerUnit
+		bins[i].Max = float64(i+1) / qberBinsPerUnit
+	}
+	bins[qberBins].Max = 1
+
+	for _, v := range values {
+		// The tolerance keeps a QBER such as 0.07 out of the bin below it
+		i := int(math.Floor(v*qberBinsPerUnit + 1e-9))
+		if i > qberBins {
+			i = qberBins
+		}
+		bins[i].Count++
+	}
+
+	return bins
+}
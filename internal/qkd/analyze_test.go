@@ -0,0 +1,54 @@
+package qkd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestAnalyzeChannelFivePercentNoise(t *testing.T) {
+	const runs = 40
+	analysis, err := AnalyzeChannel(context.Background(), quantum.NewSimulatorBackend(true, 0.05), 256, runs)
+	if err != nil {
+		t.Fatalf("AnalyzeChannel failed: %v", err)
+	}
+
+	if analysis.Runs != runs {
+		t.Errorf("Expected %d runs, got %d", runs, analysis.Runs)
+	}
+	if analysis.QBER.Mean < 0.035 || analysis.QBER.Mean > 0.065 {
+		t.Errorf("Expected mean QBER near 0.05, got %.4f", analysis.QBER.Mean)
+	}
+	if analysis.QBER.StdDev <= 0 || analysis.QBER.Min > analysis.QBER.Mean || analysis.QBER.Max < analysis.QBER.Mean {
+		t.Errorf("Expected a spread of QBERs around the mean, got %+v", analysis.QBER)
+	}
+	if analysis.SiftingEfficiency.Mean < 0.45 || analysis.SiftingEfficiency.Mean > 0.55 {
+		t.Errorf("Expected mean sifting efficiency near 0.5, got %.4f", analysis.SiftingEfficiency.Mean)
+	}
+	// 1 - 2h(0.05) is about 0.43
+	if analysis.SecureFraction.Mean < 0.3 || analysis.SecureFraction.Mean > 0.55 {
+		t.Errorf("Expected mean secure fraction near 0.43, got %.4f", analysis.SecureFraction.Mean)
+	}
+	if analysis.SecureRuns < runs/2 || analysis.FinalKeyLength.Max != 256 {
+		t.Errorf("Expected most runs to yield a 256-bit key, got %d secure runs and %+v", analysis.SecureRuns, analysis.FinalKeyLength)
+	}
+
+	total := 0
+	for _, bin := range analysis.QBERHistogram {
+		total += bin.Count
+	}
+	if total != runs {
+		t.Errorf("Expected the histogram to count %d runs, got %d", runs, total)
+	}
+}
+
+func TestQBERHistogramBinEdges(t *testing.T) {
+	bins := qberHistogram([]float64{0, 0.07, 0.0799, 0.15, 0.5})
+
+	for i, want := range map[int]int{0: 1, 7: 2, qberBins: 2} {
+		if bins[i].Count != want {
+			t.Errorf("bin %d [%.2f, %.2f): expected %d, got %d", i, bins[i].Min, bins[i].Max, want, bins[i].Count)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package qkd
+
+import (
+	"slices"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// Authorizer decides who may read, consume and revoke the keys of a session.
+// userID has already been normalized and is never empty.
+type Authorizer interface {
+	CanAccessKey(session *qkd.QKDSession, userID string) bool
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface
+type AuthorizerFunc func(session *qkd.QKDSession, userID string) bool
+
+// CanAccessKey calls f
+func (f AuthorizerFunc) CanAccessKey(session *qkd.QKDSession, userID string) bool {
+	return f(session, userID)
+}
+
+// ParticipantAuthorizer grants key access to the session's participants only:
+// Alice and Bob, or everyone who joined a conference session.
+// It is the SessionManager's default.
+type ParticipantAuthorizer struct{}
+
+// CanAccessKey reports whether userID is a participant of the session
+func (ParticipantAuthorizer) CanAccessKey(session *qkd.QKDSession, userID string) bool {
+	return slices.Contains(sessionParticipants(session), userID)
+}
+
+// SetAuthorizer replaces the key access policy, for example to let a gateway SAE
+// act for an endpoint or an administrator read any key. A nil authorizer restores
+// ParticipantAuthorizer.
+func (sm *SessionManager) SetAuthorizer(a Authorizer) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if a == nil {
+		a = ParticipantAuthorizer{}
+	}
+	sm.authorizer = a
+}
@@ -0,0 +1,47 @@
+package qkd
+
+import (
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestDefaultAuthorizerDeniesThirdParties(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	key := generateTestKey(t, sm, "alice", "bob")
+
+	for _, userID := range []string{"alice", "bob"} {
+		if _, err := sm.GetKey(key.KeyID, userID); err != nil {
+			t.Errorf("GetKey(%q) failed: %v", userID, err)
+		}
+	}
+	for _, userID := range []string{"admin", "mallory"} {
+		if _, err := sm.GetKey(key.KeyID, userID); err != qkd.ErrUnauthorized {
+			t.Errorf("GetKey(%q): expected ErrUnauthorized, got %v", userID, err)
+		}
+	}
+}
+
+func TestCustomAuthorizerGrantsAdmin(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetAuthorizer(AuthorizerFunc(func(session *qkd.QKDSession, userID string) bool {
+		return userID == "admin" || ParticipantAuthorizer{}.CanAccessKey(session, userID)
+	}))
+	key := generateTestKey(t, sm, "alice", "bob")
+
+	for _, userID := range []string{"alice", "bob", "admin"} {
+		if _, err := sm.RetrieveKey(key.KeyID, userID); err != nil {
+			t.Errorf("RetrieveKey(%q) failed: %v", userID, err)
+		}
+	}
+	if _, err := sm.GetKey(key.KeyID, "mallory"); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for a third party, got %v", err)
+	}
+
+	// Restoring the default revokes the admin's access
+	sm.SetAuthorizer(nil)
+	if _, err := sm.GetKey(key.KeyID, "admin"); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for admin under the default policy, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// B92Protocol implements the B92 two-state QKD protocol. Alice encodes 0 as |0⟩
+// and 1 as |+⟩; Bob keeps only the conclusive outcomes, which rule out one state.
+type B92Protocol struct {
+	*BB84Protocol
+}
+
+// NewB92Protocol creates a new B92 protocol instance
+func NewB92Protocol(backend quantum.QuantumBackend, keyLength int) *B92Protocol {
+	return &B92Protocol{
+		BB84Protocol: NewBB84Protocol(backend, keyLength),
+	}
+}
+
+// Name returns the protocol identifier
+func (b *B92Protocol) Name() string {
+	return "b92"
+}
+
+// TransmissionLength returns the number of qubits sent for the target key length
+func (b *B92Protocol) TransmissionLength() int {
+	return b.keyLength * 8 // Only ~25% of outcomes are conclusive
+}
+
+// AliceGenerateQubits - Step 1: Alice encodes random bits as |0⟩ (bit 0) or |+⟩ (bit 1)
+func (b *B92Protocol) AliceGenerateQubits(ctx context.Context) (*AliceSession, error) {
+	transmissionLength := b.TransmissionLength()
+
+	bits, err := quantum.SecureRandomBits(transmissionLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// The bit selects the preparation basis; the state is always the basis's "0" vector
+	alice := &AliceSession{
+		Bits:  bits,
+		Bases: make([]quantum.Basis, transmissionLength),
+	}
+	states := make([]quantum.Bit, transmissionLength)
+	for i, bit := range bits {
+		alice.Bases[i] = quantum.RectilinearBasis
+		if bit == quantum.One {
+			alice.Bases[i] = quantum.DiagonalBasis
+		}
+	}
+
+	qubits, err := b.backend.PrepareAndSend(ctx, states, alice.Bases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare qubits: %w", err)
+	}
+
+	alice.Qubits = qubits
+
+	return alice, nil
+}
+
+// BasisReconciliation - Step 3: Bob announces which measurements were conclusive.
+// Measuring |1⟩ in the rectilinear basis rules out |0⟩, so Alice sent 1; measuring
+// |−⟩ in the diagonal basis rules out |+⟩, so Alice sent 0. All other outcomes are discarded.
+func (b *B92Protocol) BasisReconciliation(alice *AliceSession, bob *BobSession) (*SiftedKey, error) {
+	if len(alice.Bits) != len(bob.Measurements) {
+		return nil, fmt.Errorf("alice and bob must have same number of qubits")
+	}
+
+	sifted := &SiftedKey{
+		AliceKey:  make([]quantum.Bit, 0),
+		BobKey:    make([]quantum.Bit, 0),
+		Indices:   make([]int, 0),
+		RawLength: len(alice.Bits),
+	}
+
+	for i, measurement := range bob.Measurements {
+		if !measurement.Conclusive() || measurement.MeasuredBit != quantum.One {
+			continue // Lost or inconclusive
+		}
+
+		bobBit := quantum.Zero
+		if measurement.MeasurementBasis == quantum.RectilinearBasis {
+			bobBit = quantum.One
+		}
+
+		sifted.AliceKey = append(sifted.AliceKey, alice.Bits[i])
+		sifted.BobKey = append(sifted.BobKey, bobBit)
+		sifted.Indices = append(sifted.Indices, i)
+	}
+
+	return sifted, nil
+}
+
+// PerformKeyExchange executes the complete B92 protocol between Alice and Bob
+func (b *B92Protocol) PerformKeyExchange(ctx context.Context) (*KeyExchangeResult, error) {
+	result := &KeyExchangeResult{}
+
+	// Step 1: Alice generates qubits
+	alice, err := b.AliceGenerateQubits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("alice qubit generation failed: %w", err)
+	}
+
+	// Step 2: Bob measures qubits in random bases
+	bob, err := b.BobMeasureQubits(ctx, alice.Qubits)
+	if err != nil {
+		return nil, fmt.Errorf("bob measurement failed: %w", err)
+	}
+
+	// Step 3: Keep conclusive outcomes
+	sifted, err := b.BasisReconciliation(alice, bob)
+	if err != nil {
+		return nil, fmt.Errorf("basis reconciliation failed: %w", err)
+	}
+
+	result.RawKeyLength = len(sifted.AliceKey)
+	result.SiftingEfficiency = sifted.Efficiency(sifted.RawLength)
+
+	if result.RawKeyLength == 0 {
+		return nil, fmt.Errorf("no conclusive measurements - sifted key is empty")
+	}
+
+	return b.finalizeKey(sifted, result)
+}
@@ -0,0 +1,72 @@
+package qkd
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestB92SiftRateNoiseless(t *testing.T) {
+	b92 := NewB92Protocol(quantum.NewSimulatorBackend(false, 0.0), 1024)
+
+	result, err := b92.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("PerformKeyExchange failed: %v", err)
+	}
+
+	if math.Abs(result.SiftingEfficiency-0.25) > 0.02 {
+		t.Errorf("Expected ~25%% sift rate, got %.3f", result.SiftingEfficiency)
+	}
+
+	if !result.Secure || result.QBER != 0 {
+		t.Fatalf("Expected a secure, error-free key, got %+v", result)
+	}
+
+	if result.FinalKeyLength != 1024 {
+		t.Errorf("Expected a 1024-bit key, got %d", result.FinalKeyLength)
+	}
+}
+
+func TestB92ConclusiveOutcomesAgree(t *testing.T) {
+	b92 := NewB92Protocol(quantum.NewIdealBackend(), 256)
+
+	alice, err := b92.AliceGenerateQubits(context.Background())
+	if err != nil {
+		t.Fatalf("AliceGenerateQubits failed: %v", err)
+	}
+	bob, err := b92.BobMeasureQubits(context.Background(), alice.Qubits)
+	if err != nil {
+		t.Fatalf("BobMeasureQubits failed: %v", err)
+	}
+
+	sifted, err := b92.BasisReconciliation(alice, bob)
+	if err != nil {
+		t.Fatalf("BasisReconciliation failed: %v", err)
+	}
+
+	for i := range sifted.AliceKey {
+		idx := sifted.Indices[i]
+		// A conclusive outcome only occurs when Bob measured in the other basis
+		if alice.Bases[idx] == bob.Bases[idx] {
+			t.Fatalf("Index %d: conclusive outcome in the preparation basis", idx)
+		}
+		if sifted.AliceKey[i] != sifted.BobKey[i] {
+			t.Fatalf("Index %d: Alice and Bob disagree on a noiseless channel", idx)
+		}
+	}
+}
+
+func TestB92HighNoiseInsecure(t *testing.T) {
+	b92 := NewB92Protocol(quantum.NewSimulatorBackend(true, 0.2), 512)
+
+	result, err := b92.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("PerformKeyExchange failed: %v", err)
+	}
+
+	if result.Secure || result.QBER <= b92.QBERThreshold() {
+		t.Errorf("Expected QBER above threshold to be flagged insecure, got QBER %.2f%%, secure %v", result.QBER*100, result.Secure)
+	}
+}
@@ -0,0 +1,41 @@
+package qkd
+
+import (
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// RegisterBackend makes a backend available to sessions that request kind. The
+// backend the manager was created with is registered under its own kind and
+// serves sessions that do not name one.
+func (sm *SessionManager) RegisterBackend(kind qkd.QuantumBackendType, backend quantum.QuantumBackend) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.backends[kind] = backend
+}
+
+// backendKind returns the session backend type a backend serves
+func backendKind(backend quantum.QuantumBackend) qkd.QuantumBackendType {
+	switch backend.(type) {
+	case *quantum.QiskitBackend:
+		return qkd.BackendQiskit
+	case *quantum.BraketBackend:
+		return qkd.BackendBraket
+	}
+	return qkd.BackendSimulator
+}
+
+// sessionBackend returns the registered backend a session asked for. Must be
+// called with sm.mutex held.
+func (sm *SessionManager) sessionBackend(session *qkd.QKDSession) (quantum.QuantumBackend, error) {
+	if session.Backend == "" {
+		return sm.backend, nil
+	}
+
+	backend, ok := sm.backends[session.Backend]
+	if !ok {
+		return nil, qkd.ErrBackendNotConfigured
+	}
+	return backend, nil
+}
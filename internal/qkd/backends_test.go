@@ -0,0 +1,61 @@
+package qkd
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// countingBackend counts the transmissions it carries
+type countingBackend struct {
+	*quantum.SimulatorBackend
+	sends atomic.Int32
+}
+
+func (b *countingBackend) PrepareAndSend(ctx context.Context, bits []quantum.Bit, bases []quantum.Basis) ([]quantum.Qubit, error) {
+	b.sends.Add(1)
+	return b.SimulatorBackend.PrepareAndSend(ctx, bits, bases)
+}
+
+func TestSessionRequestingUnconfiguredBackendFails(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+
+	_, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, Backend: qkd.BackendQiskit})
+	if err != qkd.ErrBackendNotConfigured {
+		t.Errorf("Expected ErrBackendNotConfigured for an unregistered backend, got %v", err)
+	}
+}
+
+func TestSessionUsesRequestedBackend(t *testing.T) {
+	defaultBackend := &countingBackend{SimulatorBackend: quantum.NewSimulatorBackend(false, 0.0)}
+	braket := &countingBackend{SimulatorBackend: quantum.NewSimulatorBackend(false, 0.0)}
+	sm := NewSessionManager(defaultBackend)
+	sm.RegisterBackend(qkd.BackendBraket, braket)
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, Backend: qkd.BackendBraket})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); err != nil {
+		t.Fatalf("ExecuteKeyExchangeWithPostProcessing failed: %v", err)
+	}
+
+	if braket.sends.Load() == 0 {
+		t.Error("Expected the exchange to run on the requested backend")
+	}
+	if defaultBackend.sends.Load() != 0 {
+		t.Error("Expected the default backend to stay unused")
+	}
+
+	// Sessions that name no backend get the default
+	session, _ = sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	if session.Backend != qkd.BackendSimulator {
+		t.Errorf("Expected the default backend type, got %q", session.Backend)
+	}
+}
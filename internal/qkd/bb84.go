@@ -1,10 +1,12 @@
 package qkd
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"math/big"
 
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
 	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
 )
 
@@ -14,8 +16,23 @@ type BB84Protocol struct {
 	keyLength       int
 	qberThreshold   float64 // Quantum Bit Error Rate threshold (typically 11%)
 	sampleSize      float64 // Fraction of key to sample for error checking (0.0-1.0)
+	minSample       int     // Fewest sampled bits a QBER estimate is trusted from
+	oversampling    int     // Qubits transmitted per bit of target key length
+	commitBases     bool    // Bob commits to his bases before Alice reveals hers
+	sixState        bool    // Bases are drawn from the circular basis as well (six-state protocol)
+	decoy           *DecoyStateConfig // Decoy-state mode; nil sends ideal single qubits
+	chunkSize       int     // Qubits sent and sifted per block by PerformKeyExchange; 0 sends them all at once
 }
 
+// DefaultOversamplingFactor is the number of qubits sent per target key bit.
+// Sifting keeps about half of them, leaving room for QBER sampling.
+const DefaultOversamplingFactor = 4
+
+// DefaultMinQBERSample is the fewest sampled bits a QBER estimate is trusted
+// from. A smaller sample says little: even with no errors in it, the channel
+// QBER may well exceed the threshold.
+const DefaultMinQBERSample = 20
+
 // NewBB84Protocol creates a new BB84 protocol instance
 func NewBB84Protocol(backend quantum.QuantumBackend, keyLength int) *BB84Protocol {
 	return &BB84Protocol{
@@ -23,27 +40,105 @@ func NewBB84Protocol(backend quantum.QuantumBackend, keyLength int) *BB84Protoco
 		keyLength:     keyLength,
 		qberThreshold: 0.11,  // 11% - theoretical maximum for secure QKD
 		sampleSize:    0.10,  // Sample 10% of bits for error estimation
+		minSample:     DefaultMinQBERSample,
+		oversampling:  DefaultOversamplingFactor,
 	}
 }
 
+// Name returns the protocol identifier
+func (bb *BB84Protocol) Name() string {
+	return "bb84"
+}
+
+// QBERThreshold returns the QBER above which the key is rejected
+func (bb *BB84Protocol) QBERThreshold() float64 {
+	return bb.qberThreshold
+}
+
 // SetQBERThreshold sets a custom QBER threshold
 func (bb *BB84Protocol) SetQBERThreshold(threshold float64) {
 	bb.qberThreshold = threshold
 }
 
-// SetSampleSize sets the fraction of bits to sample for error checking
+// SetSampleSize sets the fraction of bits to sample for error checking.
+// A size of 1 discloses the whole sifted key, which is only useful for testing.
 func (bb *BB84Protocol) SetSampleSize(size float64) {
-	if size > 0 && size < 1 {
+	if size > 0 && size <= 1 {
 		bb.sampleSize = size
 	}
 }
 
+// SetMinQBERSample sets the fewest sampled bits a QBER estimate is trusted from.
+// Values below 1 are ignored.
+func (bb *BB84Protocol) SetMinQBERSample(bits int) {
+	if bits >= 1 {
+		bb.minSample = bits
+	}
+}
+
+// QBERSampleTooSmallError is returned when the sifted key is too short for the
+// QBER sample to reach the minimum size
+type QBERSampleTooSmallError struct {
+	Sampled int
+	Minimum int
+}
+
+func (e *QBERSampleTooSmallError) Error() string {
+	return fmt.Sprintf("QBER estimated from only %d sampled bits; at least %d are needed for a reliable estimate", e.Sampled, e.Minimum)
+}
+
+// checkQBERSample reports whether a sample of sampled bits is large enough to trust
+func (bb *BB84Protocol) checkQBERSample(sampled int) error {
+	if sampled < bb.minSample {
+		return &QBERSampleTooSmallError{Sampled: sampled, Minimum: bb.minSample}
+	}
+	return nil
+}
+
+// SetOversamplingFactor sets how many qubits are transmitted per bit of target key
+// length. Lossy or noisy channels need a higher factor to survive sifting, sampling,
+// error correction and privacy amplification. Factors below 1 are ignored.
+func (bb *BB84Protocol) SetOversamplingFactor(factor int) {
+	if factor >= 1 {
+		bb.oversampling = factor
+	}
+}
+
+// EnableBasisCommitment makes Bob commit to his measurement bases before
+// reconciliation, so he cannot adapt his reported bases after seeing Alice's
+func (bb *BB84Protocol) EnableBasisCommitment(enabled bool) {
+	bb.commitBases = enabled
+}
+
+// SetChunkSize makes PerformKeyExchange transmit and sift the qubits in blocks of
+// size (see SiftInChunks), bounding memory for long transmissions. A size of 0
+// restores the one-shot exchange; negative sizes are ignored. Decoy-state
+// exchanges are always one-shot.
+func (bb *BB84Protocol) SetChunkSize(size int) {
+	if size >= 0 {
+		bb.chunkSize = size
+	}
+}
+
+// TransmissionLength returns the number of qubits sent for the target key length
+func (bb *BB84Protocol) TransmissionLength() int {
+	if bb.decoy != nil {
+		return bb.decoyTransmissionLength(bb.keyLength * bb.oversampling)
+	}
+	return bb.keyLength * bb.oversampling
+}
+
 // AliceSession represents Alice's side of the BB84 protocol
 type AliceSession struct {
 	Bits   []quantum.Bit
 	Bases  []quantum.Basis
 	Qubits []quantum.Qubit
 	Key    []quantum.Bit
+
+	// Intensities and Detected are set in decoy-state mode: the mean photon number
+	// of each pulse and whether Bob's detector clicked (announced publicly)
+	Intensities []float64
+	Detected    []bool
 }
 
 // BobSession represents Bob's side of the BB84 protocol
@@ -51,6 +146,11 @@ type BobSession struct {
 	Bases        []quantum.Basis
 	Measurements []quantum.MeasurementResult
 	Key          []quantum.Bit
+
+	// BasesCommitment is published before reconciliation when commitment is enabled;
+	// CommitmentNonce opens it during reconciliation
+	BasesCommitment []byte
+	CommitmentNonce []byte
 }
 
 // KeyExchangeResult contains the result of BB84 key exchange
@@ -59,23 +159,47 @@ type KeyExchangeResult struct {
 	RawKeyLength  int
 	FinalKeyLength int
 	QBER          float64
+	SiftingEfficiency float64
+	CHSHValue     float64 // Bell parameter S (entanglement-based protocols only)
+	DisclosedIndices []int // Transmission slots whose bits were revealed for QBER estimation and discarded
+	LowConfidence bool // The QBER sample was below the minimum size, so the QBER is not trusted
 	Secure        bool
 	Message       string
 }
 
 // AliceGenerateQubits - Step 1: Alice generates random bits and bases, then prepares qubits
-func (bb *BB84Protocol) AliceGenerateQubits() (*AliceSession, error) {
+func (bb *BB84Protocol) AliceGenerateQubits(ctx context.Context) (*AliceSession, error) {
 	// Generate random bits and bases for transmission
 	// We generate more bits than needed to account for key sifting
-	transmissionLength := bb.keyLength * 4 // 4x oversampling for key sifting
+	return bb.prepareQubits(ctx, bb.TransmissionLength())
+}
+
+// prepareQubits draws n random bits and bases and sends them through the backend
+func (bb *BB84Protocol) prepareQubits(ctx context.Context, n int) (*AliceSession, error) {
+	bits, err := quantum.SecureRandomBits(n)
+	if err != nil {
+		return nil, err
+	}
+
+	bases, err := bb.randomBases(n)
+	if err != nil {
+		return nil, err
+	}
 
 	alice := &AliceSession{
-		Bits:  quantum.GenerateRandomBits(transmissionLength),
-		Bases: quantum.GenerateRandomBases(transmissionLength),
+		Bits:  bits,
+		Bases: bases,
+	}
+
+	if bb.decoy != nil {
+		if err := bb.sendDecoyPulses(ctx, alice); err != nil {
+			return nil, err
+		}
+		return alice, nil
 	}
 
 	// Prepare qubits using the quantum backend
-	qubits, err := bb.backend.PrepareAndSend(alice.Bits, alice.Bases)
+	qubits, err := bb.backend.PrepareAndSend(ctx, alice.Bits, alice.Bases)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare qubits: %w", err)
 	}
@@ -86,46 +210,95 @@ func (bb *BB84Protocol) AliceGenerateQubits() (*AliceSession, error) {
 }
 
 // BobMeasureQubits - Step 2: Bob receives qubits and measures them in random bases
-func (bb *BB84Protocol) BobMeasureQubits(qubits []quantum.Qubit) (*BobSession, error) {
+func (bb *BB84Protocol) BobMeasureQubits(ctx context.Context, qubits []quantum.Qubit) (*BobSession, error) {
 	// Bob generates his own random measurement bases
+	bases, err := bb.randomBases(len(qubits))
+	if err != nil {
+		return nil, err
+	}
+
 	bob := &BobSession{
-		Bases: quantum.GenerateRandomBases(len(qubits)),
+		Bases: bases,
 	}
 
 	// Bob measures the qubits using his chosen bases
-	measurements, err := bb.backend.ReceiveAndMeasure(qubits, bob.Bases)
+	measurements, err := bb.backend.ReceiveAndMeasure(ctx, qubits, bob.Bases)
 	if err != nil {
 		return nil, fmt.Errorf("failed to measure qubits: %w", err)
 	}
 
 	bob.Measurements = measurements
 
+	if bb.commitBases {
+		bob.BasesCommitment, bob.CommitmentNonce, err = crypto.Commit(basesMessage(bob.Bases))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return bob, nil
 }
 
+// randomBases draws n preparation or measurement bases, from all three bases in six-state mode
+func (bb *BB84Protocol) randomBases(n int) ([]quantum.Basis, error) {
+	if bb.sixState {
+		return quantum.SecureRandomSixStateBases(n)
+	}
+	return quantum.SecureRandomBases(n)
+}
+
 // SiftedKey represents the result of basis reconciliation
 type SiftedKey struct {
-	AliceKey []quantum.Bit
-	BobKey   []quantum.Bit
-	Indices  []int // Indices where bases matched
+	AliceKey  []quantum.Bit
+	BobKey    []quantum.Bit
+	Indices   []int // Indices where bases matched
+	RawLength int   // Number of transmitted qubits the key was sifted from
+}
+
+// Efficiency returns the fraction of rawLength transmitted qubits that survived sifting.
+// A non-positive rawLength falls back to the RawLength recorded during reconciliation;
+// if neither is known the efficiency is 0.
+func (s *SiftedKey) Efficiency(rawLength int) float64 {
+	if rawLength <= 0 {
+		rawLength = s.RawLength
+	}
+	if rawLength <= 0 {
+		return 0
+	}
+	return float64(len(s.AliceKey)) / float64(rawLength)
 }
 
 // BasisReconciliation - Step 3: Alice and Bob compare bases (public channel)
 // Returns only the bits where Alice and Bob used the same basis
 func (bb *BB84Protocol) BasisReconciliation(alice *AliceSession, bob *BobSession) (*SiftedKey, error) {
-	if len(alice.Bases) != len(bob.Bases) {
-		return nil, fmt.Errorf("alice and bob must have same number of bases")
+	if err := checkTranscriptLengths(alice, bob); err != nil {
+		return nil, err
+	}
+
+	// Bob opens his commitment before Alice's bases are used for sifting
+	if bb.commitBases {
+		if err := crypto.VerifyCommitment(bob.BasesCommitment, basesMessage(bob.Bases), bob.CommitmentNonce); err != nil {
+			return nil, err
+		}
 	}
 
 	sifted := &SiftedKey{
-		AliceKey: make([]quantum.Bit, 0),
-		BobKey:   make([]quantum.Bit, 0),
-		Indices:  make([]int, 0),
+		AliceKey:  make([]quantum.Bit, 0),
+		BobKey:    make([]quantum.Bit, 0),
+		Indices:   make([]int, 0),
+		RawLength: len(alice.Bases),
 	}
 
-	// Compare bases and keep bits where bases match
+	// Compare bases and keep bits where bases match; in decoy-state mode only
+	// detected signal pulses are kept
 	for i := 0; i < len(alice.Bases); i++ {
-		if alice.Bases[i] == bob.Bases[i] {
+		// Slots where Bob's detector did not click, or clicked inconclusively, are
+		// discarded before comparing bases
+		if !bob.Measurements[i].Conclusive() {
+			continue
+		}
+
+		if alice.Bases[i] == bob.Bases[i] && bb.isSignalPulse(alice, i) {
 			// Bases match - keep this bit
 			sifted.AliceKey = append(sifted.AliceKey, alice.Bits[i])
 			sifted.BobKey = append(sifted.BobKey, bob.Measurements[i].MeasuredBit)
@@ -136,11 +309,89 @@ func (bb *BB84Protocol) BasisReconciliation(alice *AliceSession, bob *BobSession
 	return sifted, nil
 }
 
+// SiftInChunks runs steps 1-3 in blocks of chunkSize qubits: Alice prepares a
+// block, Bob measures it and both sift it before the next block is generated, so
+// only sifted bits accumulate and peak memory is bounded by the block size rather
+// than the transmission length. Indices refer to slots of the whole transmission.
+// Decoy-state estimates need every pulse, so decoy mode is not supported.
+func (bb *BB84Protocol) SiftInChunks(ctx context.Context, chunkSize int) (*SiftedKey, error) {
+	if chunkSize < 1 {
+		return nil, fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+	if bb.decoy != nil {
+		return nil, fmt.Errorf("decoy-state exchanges cannot be sifted in chunks")
+	}
+
+	total := bb.TransmissionLength()
+	sifted := &SiftedKey{RawLength: total}
+
+	for offset := 0; offset < total; offset += chunkSize {
+		alice, err := bb.prepareQubits(ctx, min(chunkSize, total-offset))
+		if err != nil {
+			return nil, fmt.Errorf("alice qubit generation failed: %w", err)
+		}
+
+		bob, err := bb.BobMeasureQubits(ctx, alice.Qubits)
+		if err != nil {
+			return nil, fmt.Errorf("bob measurement failed: %w", err)
+		}
+
+		block, err := bb.BasisReconciliation(alice, bob)
+		if err != nil {
+			return nil, fmt.Errorf("basis reconciliation failed: %w", err)
+		}
+
+		sifted.AliceKey = append(sifted.AliceKey, block.AliceKey...)
+		sifted.BobKey = append(sifted.BobKey, block.BobKey...)
+		for _, i := range block.Indices {
+			sifted.Indices = append(sifted.Indices, offset+i)
+		}
+	}
+
+	return sifted, nil
+}
+
+// checkTranscriptLengths verifies that Alice's bits and bases, Bob's bases and
+// measurements and, in decoy-state mode, the pulse intensities and detections
+// all describe the same number of qubits. A backend returning partial results
+// would otherwise make reconciliation index past the end of a shorter slice.
+func checkTranscriptLengths(alice *AliceSession, bob *BobSession) error {
+	n := len(alice.Bases)
+	switch {
+	case len(alice.Bits) != n:
+		return fmt.Errorf("alice has %d bits for %d bases", len(alice.Bits), n)
+	case len(bob.Bases) != n:
+		return fmt.Errorf("alice and bob must have same number of bases: got %d and %d", n, len(bob.Bases))
+	case len(bob.Measurements) != n:
+		return fmt.Errorf("bob has %d measurements for %d transmitted qubits", len(bob.Measurements), n)
+	case alice.Intensities != nil && len(alice.Intensities) != n:
+		return fmt.Errorf("alice has %d pulse intensities for %d qubits", len(alice.Intensities), n)
+	case alice.Detected != nil && len(alice.Detected) != n:
+		return fmt.Errorf("alice has %d detection flags for %d qubits", len(alice.Detected), n)
+	}
+	return nil
+}
+
 // EstimateQBER - Step 4: Estimate Quantum Bit Error Rate
-// Alice and Bob sacrifice a random subset of their sifted key to check for errors
+// Alice and Bob sacrifice a random subset of their sifted key to check for errors.
+// A sample smaller than the minimum returns a *QBERSampleTooSmallError.
 func (bb *BB84Protocol) EstimateQBER(sifted *SiftedKey) (float64, error) {
+	qber, sampled, err := bb.SampleQBER(sifted)
+	if err != nil {
+		return 0, err
+	}
+	if err := bb.checkQBERSample(len(sampled)); err != nil {
+		return 0, err
+	}
+	return qber, nil
+}
+
+// SampleQBER estimates the QBER like EstimateQBER and also returns the indices of
+// the disclosed sample, in ascending order, so exactly those bits can be removed
+// from the key with RemoveSampledBits. It does not enforce the minimum sample size.
+func (bb *BB84Protocol) SampleQBER(sifted *SiftedKey) (float64, []int, error) {
 	if len(sifted.AliceKey) == 0 {
-		return 0, fmt.Errorf("sifted key is empty")
+		return 0, nil, fmt.Errorf("sifted key is empty")
 	}
 
 	// Calculate how many bits to sample
@@ -153,25 +404,21 @@ func (bb *BB84Protocol) EstimateQBER(sifted *SiftedKey) (float64, error) {
 	}
 
 	// Randomly select indices to sample (without replacement)
-	sampledIndices := make(map[int]bool)
-	for len(sampledIndices) < sampleCount {
-		idx, err := cryptoRandInt(len(sifted.AliceKey))
-		if err != nil {
-			return 0, err
-		}
-		sampledIndices[idx] = true
+	indices, err := randomIndices(len(sifted.AliceKey), sampleCount)
+	if err != nil {
+		return 0, nil, err
 	}
 
 	// Compare sampled bits to calculate error rate
 	errors := 0
-	for idx := range sampledIndices {
+	for _, idx := range indices {
 		if sifted.AliceKey[idx] != sifted.BobKey[idx] {
 			errors++
 		}
 	}
 
 	qber := float64(errors) / float64(sampleCount)
-	return qber, nil
+	return qber, indices, nil
 }
 
 // RemoveSampledBits removes the bits that were used for QBER estimation
@@ -184,9 +431,10 @@ func (bb *BB84Protocol) RemoveSampledBits(sifted *SiftedKey, sampledIndices []in
 
 	// Create new sifted key without sampled bits
 	newSifted := &SiftedKey{
-		AliceKey: make([]quantum.Bit, 0),
-		BobKey:   make([]quantum.Bit, 0),
-		Indices:  make([]int, 0),
+		AliceKey:  make([]quantum.Bit, 0),
+		BobKey:    make([]quantum.Bit, 0),
+		Indices:   make([]int, 0),
+		RawLength: sifted.RawLength,
 	}
 
 	for i := 0; i < len(sifted.AliceKey); i++ {
@@ -201,17 +449,38 @@ func (bb *BB84Protocol) RemoveSampledBits(sifted *SiftedKey, sampledIndices []in
 }
 
 // PerformKeyExchange executes the complete BB84 protocol between Alice and Bob
-func (bb *BB84Protocol) PerformKeyExchange() (*KeyExchangeResult, error) {
+func (bb *BB84Protocol) PerformKeyExchange(ctx context.Context) (*KeyExchangeResult, error) {
 	result := &KeyExchangeResult{}
 
+	sifted, err := bb.sift(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result.RawKeyLength = len(sifted.AliceKey)
+	result.SiftingEfficiency = sifted.Efficiency(sifted.RawLength)
+
+	if result.RawKeyLength == 0 {
+		return nil, fmt.Errorf("no matching bases found - sifted key is empty")
+	}
+
+	return bb.finalizeKey(sifted, result)
+}
+
+// sift runs steps 1-3, in chunks when a chunk size is set
+func (bb *BB84Protocol) sift(ctx context.Context) (*SiftedKey, error) {
+	if bb.chunkSize > 0 && bb.decoy == nil {
+		return bb.SiftInChunks(ctx, bb.chunkSize)
+	}
+
 	// Step 1: Alice generates qubits
-	alice, err := bb.AliceGenerateQubits()
+	alice, err := bb.AliceGenerateQubits(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("alice qubit generation failed: %w", err)
 	}
 
 	// Step 2: Bob measures qubits
-	bob, err := bb.BobMeasureQubits(alice.Qubits)
+	bob, err := bb.BobMeasureQubits(ctx, alice.Qubits)
 	if err != nil {
 		return nil, fmt.Errorf("bob measurement failed: %w", err)
 	}
@@ -222,21 +491,31 @@ func (bb *BB84Protocol) PerformKeyExchange() (*KeyExchangeResult, error) {
 		return nil, fmt.Errorf("basis reconciliation failed: %w", err)
 	}
 
-	result.RawKeyLength = len(sifted.AliceKey)
-
-	if result.RawKeyLength == 0 {
-		return nil, fmt.Errorf("no matching bases found - sifted key is empty")
-	}
+	return sifted, nil
+}
 
+// finalizeKey estimates the QBER on a sifted key, discards the disclosed sample
+// and truncates the remainder to the target key length (steps 4-7)
+func (bb *BB84Protocol) finalizeKey(sifted *SiftedKey, result *KeyExchangeResult) (*KeyExchangeResult, error) {
 	// Step 4: Estimate QBER
-	qber, err := bb.EstimateQBER(sifted)
+	qber, sampledIndices, err := bb.SampleQBER(sifted)
 	if err != nil {
 		return nil, fmt.Errorf("QBER estimation failed: %w", err)
 	}
 
 	result.QBER = qber
+	result.DisclosedIndices = make([]int, len(sampledIndices))
+	for i, idx := range sampledIndices {
+		result.DisclosedIndices[i] = sifted.Indices[idx]
+	}
 
-	// Step 5: Security check
+	// Step 5: Security check. A QBER from too small a sample cannot vouch for the channel.
+	if err := bb.checkQBERSample(len(sampledIndices)); err != nil {
+		result.Secure = false
+		result.LowConfidence = true
+		result.Message = "INSECURE: " + err.Error()
+		return result, nil
+	}
 	if qber > bb.qberThreshold {
 		result.Secure = false
 		result.Message = fmt.Sprintf("INSECURE: QBER (%.2f%%) exceeds threshold (%.2f%%). Possible eavesdropping detected!",
@@ -244,22 +523,7 @@ func (bb *BB84Protocol) PerformKeyExchange() (*KeyExchangeResult, error) {
 		return result, nil
 	}
 
-	// Step 6: Remove sampled bits (they've been publicly disclosed)
-	sampleCount := int(float64(len(sifted.AliceKey)) * bb.sampleSize)
-	sampledIndices := make([]int, 0)
-	sampledMap := make(map[int]bool)
-
-	for len(sampledMap) < sampleCount {
-		idx, err := cryptoRandInt(len(sifted.AliceKey))
-		if err != nil {
-			return nil, err
-		}
-		if !sampledMap[idx] {
-			sampledMap[idx] = true
-			sampledIndices = append(sampledIndices, idx)
-		}
-	}
-
+	// Step 6: Remove the sampled bits (they've been publicly disclosed)
 	finalSifted := bb.RemoveSampledBits(sifted, sampledIndices)
 
 	// Check if we have enough key material
@@ -271,13 +535,13 @@ func (bb *BB84Protocol) PerformKeyExchange() (*KeyExchangeResult, error) {
 	}
 
 	// Truncate to desired key length
-	alice.Key = finalSifted.AliceKey[:bb.keyLength]
-	bob.Key = finalSifted.BobKey[:bb.keyLength]
+	aliceKey := finalSifted.AliceKey[:bb.keyLength]
+	bobKey := finalSifted.BobKey[:bb.keyLength]
 
 	// Verify Alice and Bob have the same key
 	keyMatch := true
-	for i := 0; i < len(alice.Key); i++ {
-		if alice.Key[i] != bob.Key[i] {
+	for i := 0; i < len(aliceKey); i++ {
+		if aliceKey[i] != bobKey[i] {
 			keyMatch = false
 			break
 		}
@@ -290,14 +554,24 @@ func (bb *BB84Protocol) PerformKeyExchange() (*KeyExchangeResult, error) {
 	}
 
 	// Convert bits to bytes
-	result.Key = quantum.BitsToBytes(alice.Key)
-	result.FinalKeyLength = len(alice.Key)
+	result.Key = quantum.BitsToBytes(aliceKey)
+	result.FinalKeyLength = len(aliceKey)
 	result.Secure = true
 	result.Message = fmt.Sprintf("Secure key generated successfully! QBER: %.2f%%", qber*100)
 
 	return result, nil
 }
 
+// basesMessage serializes a basis sequence for commitment, one digit per basis
+// so that six-state bases are committed to as well
+func basesMessage(bases []quantum.Basis) []byte {
+	message := []byte(fmt.Sprintf("%d:", len(bases)))
+	for _, basis := range bases {
+		message = append(message, byte('0'+basis))
+	}
+	return message
+}
+
 // cryptoRandInt generates a cryptographically secure random integer in range [0, max)
 func cryptoRandInt(max int) (int, error) {
 	if max <= 0 {
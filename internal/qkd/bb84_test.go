@@ -1,8 +1,13 @@
 package qkd
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
 	"testing"
 
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
 	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
 )
 
@@ -14,7 +19,7 @@ func TestBB84Protocol(t *testing.T) {
 	bb84 := NewBB84Protocol(backend, 256)
 
 	// Test key exchange
-	result, err := bb84.PerformKeyExchange()
+	result, err := bb84.PerformKeyExchange(context.Background())
 	if err != nil {
 		t.Fatalf("Key exchange failed: %v", err)
 	}
@@ -46,7 +51,7 @@ func TestBB84WithNoise(t *testing.T) {
 
 	bb84 := NewBB84Protocol(backend, 256)
 
-	result, err := bb84.PerformKeyExchange()
+	result, err := bb84.PerformKeyExchange(context.Background())
 	if err != nil {
 		t.Fatalf("Key exchange failed: %v", err)
 	}
@@ -72,7 +77,7 @@ func TestBB84HighNoise(t *testing.T) {
 	bb84 := NewBB84Protocol(backend, 256)
 	bb84.SetQBERThreshold(0.11) // Standard threshold
 
-	result, err := bb84.PerformKeyExchange()
+	result, err := bb84.PerformKeyExchange(context.Background())
 	if err != nil {
 		t.Fatalf("Key exchange failed: %v", err)
 	}
@@ -92,26 +97,26 @@ func TestAliceGenerateQubits(t *testing.T) {
 	backend := quantum.NewSimulatorBackend(false, 0.0)
 	bb84 := NewBB84Protocol(backend, 256)
 
-	alice, err := bb84.AliceGenerateQubits()
+	alice, err := bb84.AliceGenerateQubits(context.Background())
 	if err != nil {
 		t.Fatalf("Alice qubit generation failed: %v", err)
 	}
 
 	// Verify Alice generated bits, bases, and qubits
-	if len(alice.bits) == 0 {
+	if len(alice.Bits) == 0 {
 		t.Error("Alice should have generated bits")
 	}
 
-	if len(alice.bases) == 0 {
+	if len(alice.Bases) == 0 {
 		t.Error("Alice should have generated bases")
 	}
 
-	if len(alice.qubits) == 0 {
+	if len(alice.Qubits) == 0 {
 		t.Error("Alice should have generated qubits")
 	}
 
 	// All arrays should have the same length
-	if len(alice.bits) != len(alice.bases) || len(alice.bits) != len(alice.qubits) {
+	if len(alice.Bits) != len(alice.Bases) || len(alice.Bits) != len(alice.Qubits) {
 		t.Error("Alice's bits, bases, and qubits should have the same length")
 	}
 }
@@ -121,23 +126,23 @@ func TestBobMeasureQubits(t *testing.T) {
 	bb84 := NewBB84Protocol(backend, 256)
 
 	// Alice generates qubits
-	alice, err := bb84.AliceGenerateQubits()
+	alice, err := bb84.AliceGenerateQubits(context.Background())
 	if err != nil {
 		t.Fatalf("Alice qubit generation failed: %v", err)
 	}
 
 	// Bob measures qubits
-	bob, err := bb84.BobMeasureQubits(alice.qubits)
+	bob, err := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
 	if err != nil {
 		t.Fatalf("Bob measurement failed: %v", err)
 	}
 
 	// Verify Bob's measurements
-	if len(bob.measurements) == 0 {
+	if len(bob.Measurements) == 0 {
 		t.Error("Bob should have measurements")
 	}
 
-	if len(bob.bases) != len(bob.measurements) {
+	if len(bob.Bases) != len(bob.Measurements) {
 		t.Error("Bob's bases and measurements should have the same length")
 	}
 }
@@ -146,8 +151,8 @@ func TestBasisReconciliation(t *testing.T) {
 	backend := quantum.NewSimulatorBackend(false, 0.0)
 	bb84 := NewBB84Protocol(backend, 256)
 
-	alice, _ := bb84.AliceGenerateQubits()
-	bob, _ := bb84.BobMeasureQubits(alice.qubits)
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
 
 	sifted, err := bb84.BasisReconciliation(alice, bob)
 	if err != nil {
@@ -172,19 +177,83 @@ func TestBasisReconciliation(t *testing.T) {
 	}
 
 	// Sifted key should be roughly 50% of original (basis matching probability)
-	expectedLength := len(alice.bits) / 2
+	expectedLength := len(alice.Bits) / 2
 	tolerance := expectedLength / 4 // 25% tolerance
 	if len(sifted.AliceKey) < expectedLength-tolerance || len(sifted.AliceKey) > expectedLength+tolerance {
 		t.Errorf("Expected sifted key length around %d, got %d", expectedLength, len(sifted.AliceKey))
 	}
 }
 
+func TestBasisReconciliationMismatchedLengths(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 64)
+	sarg04 := NewSARG04Protocol(quantum.NewSimulatorBackend(false, 0.0), 64)
+
+	tests := []struct {
+		name    string
+		shorten func(alice *AliceSession, bob *BobSession)
+	}{
+		{"short measurements", func(_ *AliceSession, bob *BobSession) { bob.Measurements = bob.Measurements[:len(bob.Measurements)-10] }},
+		{"short alice bits", func(alice *AliceSession, _ *BobSession) { alice.Bits = alice.Bits[:len(alice.Bits)-1] }},
+		{"short bob bases", func(_ *AliceSession, bob *BobSession) { bob.Bases = bob.Bases[:len(bob.Bases)/2] }},
+		{"short detections", func(alice *AliceSession, _ *BobSession) { alice.Detected = make([]bool, 3) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alice, _ := bb84.AliceGenerateQubits(context.Background())
+			bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+			tt.shorten(alice, bob)
+
+			if _, err := bb84.BasisReconciliation(alice, bob); err == nil {
+				t.Error("Expected BB84 reconciliation to reject mismatched lengths")
+			}
+			if _, err := sarg04.BasisReconciliation(alice, bob); err == nil {
+				t.Error("Expected SARG04 reconciliation to reject mismatched lengths")
+			}
+		})
+	}
+}
+
+func TestBasisReconciliationDropsInconclusiveDetections(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 128)
+
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+
+	// Mark every third matched-basis click as a double click with a wrong bit
+	inconclusive := make(map[int]bool)
+	for i, measurement := range bob.Measurements {
+		if alice.Bases[i] == bob.Bases[i] && i%3 == 0 {
+			bob.Measurements[i].Inconclusive = true
+			bob.Measurements[i].MeasuredBit = 1 - measurement.MeasuredBit
+			inconclusive[i] = true
+		}
+	}
+
+	sifted, err := bb84.BasisReconciliation(alice, bob)
+	if err != nil {
+		t.Fatalf("Basis reconciliation failed: %v", err)
+	}
+
+	for j, i := range sifted.Indices {
+		if inconclusive[i] {
+			t.Fatalf("Inconclusive detection at slot %d survived sifting", i)
+		}
+		if sifted.AliceKey[j] != sifted.BobKey[j] {
+			t.Errorf("Key mismatch at sifted index %d", j)
+		}
+	}
+	if len(inconclusive) == 0 || len(sifted.Indices) == 0 {
+		t.Fatal("Expected both dropped and kept detections")
+	}
+}
+
 func TestEstimateQBER(t *testing.T) {
 	backend := quantum.NewSimulatorBackend(false, 0.0)
 	bb84 := NewBB84Protocol(backend, 256)
 
-	alice, _ := bb84.AliceGenerateQubits()
-	bob, _ := bb84.BobMeasureQubits(alice.qubits)
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
 	sifted, _ := bb84.BasisReconciliation(alice, bob)
 
 	qber, err := bb84.EstimateQBER(sifted)
@@ -198,6 +267,48 @@ func TestEstimateQBER(t *testing.T) {
 	}
 }
 
+func TestFinalKeyExcludesQBERSample(t *testing.T) {
+	const n = 400
+	bits, _ := quantum.SecureRandomBits(n)
+	sifted := &SiftedKey{
+		AliceKey:  bits,
+		BobKey:    append([]quantum.Bit(nil), bits...),
+		Indices:   make([]int, n),
+		RawLength: 2 * n,
+	}
+	for i := range sifted.Indices {
+		sifted.Indices[i] = 2 * i // Every other slot survived sifting
+	}
+
+	// Ask for every bit the sample leaves, so the key shows exactly which bits were kept
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 1)
+	sampleCount := int(n * bb84.sampleSize)
+	bb84.keyLength = n - sampleCount
+
+	result, err := bb84.finalizeKey(sifted, &KeyExchangeResult{})
+	if err != nil || !result.Secure {
+		t.Fatalf("finalizeKey failed: %v %s", err, result.Message)
+	}
+	if len(result.DisclosedIndices) != sampleCount {
+		t.Fatalf("Expected %d disclosed indices, got %d", sampleCount, len(result.DisclosedIndices))
+	}
+
+	disclosed := make(map[int]bool)
+	for _, slot := range result.DisclosedIndices {
+		disclosed[slot] = true
+	}
+	var kept []quantum.Bit
+	for i, slot := range sifted.Indices {
+		if !disclosed[slot] {
+			kept = append(kept, bits[i])
+		}
+	}
+
+	if !bytes.Equal(result.Key, quantum.BitsToBytes(kept)) {
+		t.Error("Expected the final key to be exactly the sifted bits that were not disclosed")
+	}
+}
+
 func TestQuantumTypes(t *testing.T) {
 	// Test bit operations
 	bit := quantum.Zero
@@ -256,6 +367,281 @@ func BenchmarkBB84KeyExchange(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		bb84.PerformKeyExchange()
+		bb84.PerformKeyExchange(context.Background())
+	}
+}
+
+func TestBasisCommitmentValidReveal(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	bb84 := NewBB84Protocol(backend, 256)
+	bb84.EnableBasisCommitment(true)
+
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, err := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+	if err != nil {
+		t.Fatalf("Bob measurement failed: %v", err)
+	}
+
+	if len(bob.BasesCommitment) == 0 {
+		t.Fatal("Expected Bob to commit to his bases")
+	}
+
+	if _, err := bb84.BasisReconciliation(alice, bob); err != nil {
+		t.Errorf("Expected reconciliation to proceed with a valid reveal, got %v", err)
+	}
+
+	result, err := bb84.PerformKeyExchange(context.Background())
+	if err != nil || !result.Secure {
+		t.Errorf("Expected secure key exchange with commitment enabled, got err=%v", err)
+	}
+}
+
+func TestBasisCommitmentMismatchAborts(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	bb84 := NewBB84Protocol(backend, 256)
+	bb84.EnableBasisCommitment(true)
+
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+
+	// Bob adaptively rewrites his reported bases to match Alice's after committing
+	copy(bob.Bases, alice.Bases)
+
+	if _, err := bb84.BasisReconciliation(alice, bob); !errors.Is(err, crypto.ErrCommitmentMismatch) {
+		t.Errorf("Expected ErrCommitmentMismatch, got %v", err)
+	}
+}
+
+func TestDeterministicEavesdropperQBER(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	backend.SetTargetQBER(0.15)
+
+	bb84 := NewBB84Protocol(backend, 256)
+	bb84.SetSampleSize(1.0) // Disclose the whole sifted key so the estimate is exact
+
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+	sifted, _ := bb84.BasisReconciliation(alice, bob)
+
+	qber, err := bb84.EstimateQBER(sifted)
+	if err != nil {
+		t.Fatalf("QBER estimation failed: %v", err)
+	}
+
+	// The injected error count is round(0.15 * n), so the QBER is within half a bit of 15%
+	if tolerance := 0.5 / float64(len(sifted.AliceKey)); qber < 0.15-tolerance || qber > 0.15+tolerance {
+		t.Errorf("Expected QBER of exactly 15%%, got %.4f%%", qber*100)
+	}
+
+	result, err := bb84.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("Key exchange failed: %v", err)
+	}
+	if result.Secure || result.QBER <= bb84.qberThreshold {
+		t.Errorf("Expected 15%% QBER to be flagged insecure, got QBER %.2f%%, secure=%v", result.QBER*100, result.Secure)
+	}
+}
+
+func TestDeterministicEavesdropperThresholdBoundary(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	backend.SetTargetQBER(0.11)
+
+	bb84 := NewBB84Protocol(backend, 256)
+	bb84.SetSampleSize(1.0)
+
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+	sifted, _ := bb84.BasisReconciliation(alice, bob)
+
+	exact, _ := quantum.CalculateBitError(sifted.AliceKey, sifted.BobKey)
+
+	verdict := func(threshold float64) error {
+		bb84.SetQBERThreshold(threshold)
+		pc := &PipelineContext{Protocol: bb84, AliceKey: sifted.AliceKey, BobKey: sifted.BobKey}
+		return (&EstimateStage{}).Process(pc)
+	}
+
+	// At the threshold the channel is accepted; just below it the exchange aborts
+	if err := verdict(exact); err != nil {
+		t.Errorf("Expected QBER equal to threshold %.4f to pass, got %v", exact, err)
+	}
+	if _, ok := verdict(exact - 1e-9).(*QBERExceededError); !ok {
+		t.Errorf("Expected QBER %.4f to exceed a threshold just below it", exact)
+	}
+}
+
+func TestSiftedKeyEfficiency(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	bb84 := NewBB84Protocol(backend, 256)
+
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+	sifted, _ := bb84.BasisReconciliation(alice, bob)
+
+	if sifted.RawLength != len(alice.Bits) {
+		t.Errorf("Expected recorded raw length %d, got %d", len(alice.Bits), sifted.RawLength)
+	}
+
+	manual := float64(len(sifted.AliceKey)) / float64(len(alice.Bits))
+	if got := sifted.Efficiency(len(alice.Bits)); got != manual {
+		t.Errorf("Expected efficiency %.4f, got %.4f", manual, got)
+	}
+	if got := sifted.Efficiency(0); got != manual {
+		t.Errorf("Expected efficiency from recorded raw length %.4f, got %.4f", manual, got)
+	}
+
+	empty := &SiftedKey{}
+	if got := empty.Efficiency(0); got != 0 {
+		t.Errorf("Expected zero efficiency for zero raw length, got %.4f", got)
+	}
+}
+
+func TestChannelLossHalvesSiftedKey(t *testing.T) {
+	sift := func(lossRate float64) (int, float64) {
+		backend := quantum.NewSimulatorBackend(true, 0.05)
+		backend.SetLossRate(lossRate)
+		bb84 := NewBB84Protocol(backend, 5000)
+
+		alice, _ := bb84.AliceGenerateQubits(context.Background())
+		bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+		sifted, err := bb84.BasisReconciliation(alice, bob)
+		if err != nil {
+			t.Fatalf("Basis reconciliation failed: %v", err)
+		}
+
+		_, qber := crypto.VerifyKeyCorrectness(sifted.AliceKey, sifted.BobKey)
+		return len(sifted.AliceKey), qber
+	}
+
+	lossless, losslessQBER := sift(0)
+	lossy, lossyQBER := sift(0.5)
+
+	if ratio := float64(lossy) / float64(lossless); ratio < 0.45 || ratio > 0.55 {
+		t.Errorf("Expected 50%% loss to halve the sifted key: %d vs %d bits (ratio %.3f)", lossy, lossless, ratio)
+	}
+
+	// Lost photons are discarded, not counted as errors
+	if math.Abs(lossyQBER-losslessQBER) > 0.015 {
+		t.Errorf("Expected QBER to be unaffected by loss: %.3f without loss, %.3f with 50%% loss", losslessQBER, lossyQBER)
+	}
+}
+
+func TestLostPhotonsReportNoDetection(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	backend.SetLossRate(0.5)
+
+	qubits, _ := backend.PrepareAndSend(context.Background(), quantum.GenerateRandomBits(1000), quantum.GenerateRandomBases(1000))
+	results, _ := backend.ReceiveAndMeasure(context.Background(), qubits, quantum.GenerateRandomBases(1000))
+
+	for i := range qubits {
+		if qubits[i].Lost != results[i].NoDetection {
+			t.Fatalf("Slot %d: lost %v but no-detection %v", i, qubits[i].Lost, results[i].NoDetection)
+		}
+	}
+}
+
+func TestInterceptResendAttack(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	backend.SetInterceptProbability(1.0)
+
+	result, err := NewBB84Protocol(backend, 4096).PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("Key exchange failed: %v", err)
+	}
+
+	// Eve guesses the wrong basis half the time and then randomizes Bob's bit half the time
+	if result.QBER < 0.18 || result.QBER > 0.32 {
+		t.Errorf("Expected ~25%% QBER under a full intercept-resend attack, got %.2f%%", result.QBER*100)
+	}
+	if result.Secure {
+		t.Error("Expected the intercept-resend attack to be detected")
+	}
+}
+
+func TestSmallKeyQBERIsLowConfidence(t *testing.T) {
+	// A 16-bit key sifts to about 32 bits, so the 10% sample holds only 3
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 16)
+
+	result, err := bb84.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("Key exchange failed: %v", err)
+	}
+	if result.Secure || !result.LowConfidence || result.Key != nil {
+		t.Errorf("Expected an insecure, low-confidence result without a key, got %+v", result)
+	}
+
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+	sifted, _ := bb84.BasisReconciliation(alice, bob)
+	var tooSmall *QBERSampleTooSmallError
+	if _, err := bb84.EstimateQBER(sifted); !errors.As(err, &tooSmall) || tooSmall.Minimum != DefaultMinQBERSample {
+		t.Errorf("Expected QBERSampleTooSmallError, got %v", err)
+	}
+
+	// Lowering the minimum trusts the small sample again
+	bb84.SetMinQBERSample(1)
+	if result, err := bb84.PerformKeyExchange(context.Background()); err != nil || result.LowConfidence {
+		t.Errorf("Expected a trusted estimate with a minimum of 1, got %+v (%v)", result, err)
+	}
+}
+
+// chunkRecordingBackend records the largest block of qubits sent at once
+type chunkRecordingBackend struct {
+	quantum.QuantumBackend
+	largest int
+}
+
+func (b *chunkRecordingBackend) PrepareAndSend(ctx context.Context, bits []quantum.Bit, bases []quantum.Basis) ([]quantum.Qubit, error) {
+	b.largest = max(b.largest, len(bits))
+	return b.QuantumBackend.PrepareAndSend(ctx, bits, bases)
+}
+
+func TestSiftInChunksBoundsBlockSize(t *testing.T) {
+	const chunkSize = 4096
+	backend := &chunkRecordingBackend{QuantumBackend: quantum.NewIdealBackend()}
+	bb84 := NewBB84Protocol(backend, 250000)
+
+	sifted, err := bb84.SiftInChunks(context.Background(), chunkSize)
+	if err != nil {
+		t.Fatalf("SiftInChunks failed: %v", err)
+	}
+
+	if backend.largest > chunkSize {
+		t.Errorf("Expected blocks of at most %d qubits, got %d", chunkSize, backend.largest)
+	}
+	if sifted.RawLength != 1000000 {
+		t.Errorf("Expected a 1M-qubit transmission, got %d", sifted.RawLength)
+	}
+	if eff := sifted.Efficiency(0); math.Abs(eff-0.5) > 0.01 {
+		t.Errorf("Expected sifting efficiency ~0.5, got %.3f", eff)
+	}
+	if len(sifted.BobKey) != len(sifted.AliceKey) || len(sifted.Indices) != len(sifted.AliceKey) {
+		t.Fatalf("Mismatched sifted lengths: alice %d, bob %d, indices %d", len(sifted.AliceKey), len(sifted.BobKey), len(sifted.Indices))
+	}
+	for i := range sifted.AliceKey {
+		if sifted.AliceKey[i] != sifted.BobKey[i] {
+			t.Fatalf("sifted bit %d differs on a noiseless channel", i)
+		}
+		if i > 0 && sifted.Indices[i] <= sifted.Indices[i-1] {
+			t.Fatalf("Expected increasing transmission indices, got %d after %d", sifted.Indices[i], sifted.Indices[i-1])
+		}
+	}
+	if last := sifted.Indices[len(sifted.Indices)-1]; last >= sifted.RawLength {
+		t.Errorf("Index %d is past the end of the transmission", last)
+	}
+
+	// PerformKeyExchange sifts in chunks once a chunk size is set
+	bb84 = NewBB84Protocol(backend, 256)
+	bb84.SetChunkSize(100)
+	backend.largest = 0
+	result, err := bb84.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("PerformKeyExchange failed: %v", err)
+	}
+	if !result.Secure || result.FinalKeyLength != 256 {
+		t.Errorf("Expected a secure 256-bit key, got %+v", result)
+	}
+	if backend.largest != 100 {
+		t.Errorf("Expected 100-qubit blocks, got %d", backend.largest)
 	}
 }
@@ -0,0 +1,85 @@
+package qkd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// chshTerms are the setting pairs of the CHSH test, as indices into e91AliceAngles
+// and e91BobAngles, with their sign in S = E(a1,b1) - E(a1,b3) + E(a3,b1) + E(a3,b3)
+var chshTerms = [4]struct {
+	alice, bob int
+	sign       float64
+}{
+	{0, 0, 1},
+	{0, 2, -1},
+	{2, 0, 1},
+	{2, 2, 1},
+}
+
+// RunBellTest measures pairs entangled pairs on backend, split evenly across the
+// four CHSH settings, and returns the correlation at each setting and S. It
+// characterizes the channel only; no key is derived from the outcomes.
+func RunBellTest(ctx context.Context, backend quantum.QuantumBackend, pairs int) (*qkd.BellTestResponse, error) {
+	source, ok := backend.(quantum.EntanglementSource)
+	if !ok {
+		return nil, qkd.ErrEntanglementUnsupported
+	}
+	if pairs < len(chshTerms) {
+		return nil, fmt.Errorf("a Bell test needs at least %d pairs", len(chshTerms))
+	}
+
+	aliceAngles := make([]float64, pairs)
+	bobAngles := make([]float64, pairs)
+	for i := range aliceAngles {
+		term := chshTerms[i%len(chshTerms)]
+		aliceAngles[i], bobAngles[i] = e91AliceAngles[term.alice], e91BobAngles[term.bob]
+	}
+
+	aliceResults, bobResults, err := source.MeasureEntangledPairs(ctx, aliceAngles, bobAngles)
+	if errors.Is(err, quantum.ErrEntanglementUnsupported) {
+		return nil, qkd.ErrEntanglementUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure entangled pairs: %w", err)
+	}
+
+	var agree, total [len(chshTerms)]int
+	for i := range aliceResults {
+		total[i%len(chshTerms)]++
+		if aliceResults[i] == bobResults[i] {
+			agree[i%len(chshTerms)]++
+		}
+	}
+
+	result := &qkd.BellTestResponse{
+		Backend:        backend.Name(),
+		Pairs:          pairs,
+		Correlations:   make([]qkd.CHSHCorrelation, len(chshTerms)),
+		ClassicalBound: CHSHClassicalBound,
+		QuantumBound:   2 * math.Sqrt2,
+	}
+	for t, term := range chshTerms {
+		correlation := float64(2*agree[t]-total[t]) / float64(total[t])
+		result.Correlations[t] = qkd.CHSHCorrelation{
+			AliceAngle:  e91AliceAngles[term.alice],
+			BobAngle:    e91BobAngles[term.bob],
+			Correlation: correlation,
+			Pairs:       total[t],
+		}
+		result.S += term.sign * correlation
+	}
+	result.Violated = math.Abs(result.S) > CHSHClassicalBound
+
+	return result, nil
+}
+
+// RunBellTest runs a CHSH test over the session manager's backend
+func (sm *SessionManager) RunBellTest(ctx context.Context, pairs int) (*qkd.BellTestResponse, error) {
+	return RunBellTest(ctx, sm.backend, pairs)
+}
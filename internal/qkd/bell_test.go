@@ -0,0 +1,51 @@
+package qkd
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestRunBellTestNoiseless(t *testing.T) {
+	result, err := RunBellTest(context.Background(), quantum.NewSimulatorBackend(false, 0.0), 4000)
+	if err != nil {
+		t.Fatalf("RunBellTest failed: %v", err)
+	}
+
+	if math.Abs(result.S-2*math.Sqrt2) > 0.2 || !result.Violated {
+		t.Errorf("Expected S ≈ 2√2 on a noiseless channel, got %.3f", result.S)
+	}
+	if len(result.Correlations) != 4 {
+		t.Fatalf("Expected four CHSH correlations, got %d", len(result.Correlations))
+	}
+	for _, c := range result.Correlations {
+		if c.Pairs != 1000 {
+			t.Errorf("Expected the pairs split evenly across settings, got %d at (%.3f, %.3f)", c.Pairs, c.AliceAngle, c.BobAngle)
+		}
+		// Each correlation is ±cos(2(a-b)) = ±1/√2 for a maximally entangled pair
+		if math.Abs(math.Abs(c.Correlation)-1/math.Sqrt2) > 0.1 {
+			t.Errorf("Expected |E| ≈ 1/√2 at (%.3f, %.3f), got %.3f", c.AliceAngle, c.BobAngle, c.Correlation)
+		}
+	}
+}
+
+func TestRunBellTestHeavyNoise(t *testing.T) {
+	result, err := RunBellTest(context.Background(), quantum.NewSimulatorBackend(true, 1.0), 4000)
+	if err != nil {
+		t.Fatalf("RunBellTest failed: %v", err)
+	}
+
+	if math.Abs(result.S) > 0.3 || result.Violated {
+		t.Errorf("Expected S ≈ 0 on a fully depolarizing channel, got %.3f", result.S)
+	}
+}
+
+func TestRunBellTestRequiresEntanglementSource(t *testing.T) {
+	if _, err := RunBellTest(context.Background(), quantum.NewIdealBackend(), 4000); !errors.Is(err, qkd.ErrEntanglementUnsupported) {
+		t.Errorf("Expected ErrEntanglementUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package qkd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	sessionsBucket = []byte("sessions")
+	keysBucket     = []byte("keys")
+)
+
+// StoreKeySize is the length of the key that encrypts key material at rest
+const StoreKeySize = 32
+
+// BoltStore is a Store backed by a BoltDB file. Records are JSON encoded and key
+// material is sealed with AES-256-GCM, bound to its key ID, before it is written.
+type BoltStore struct {
+	db   *bolt.DB
+	aead cipher.AEAD
+}
+
+// keyRecord is the on-disk form of a QuantumKey
+type keyRecord struct {
+	Key       *qkd.QuantumKey `json:"key"`
+	Encrypted []byte          `json:"encrypted_material"`
+}
+
+// NewBoltStore opens (or creates) a BoltDB store at path. encryptionKey must be
+// StoreKeySize bytes and the same key must be supplied on every open.
+func NewBoltStore(path string, encryptionKey []byte) (*BoltStore, error) {
+	if len(encryptionKey) != StoreKeySize {
+		return nil, qkd.ErrInvalidStoreKey
+	}
+
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{sessionsBucket, keysBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	return &BoltStore{db: db, aead: aead}, nil
+}
+
+// Close releases the underlying database file
+func (bs *BoltStore) Close() error {
+	return bs.db.Close()
+}
+
+// SaveSession creates or replaces a session record
+func (bs *BoltStore) SaveSession(session *qkd.QKDSession) error {
+	record := *session
+	record.JoinToken = "" // Never persist the bearer token
+
+	data, err := json.Marshal(&record)
+	if err != nil {
+		return err
+	}
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put(session.SessionID[:], data)
+	})
+}
+
+// GetSession returns a copy of a session by ID
+func (bs *BoltStore) GetSession(sessionID uuid.UUID) (*qkd.QKDSession, error) {
+	var session *qkd.QKDSession
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(sessionsBucket).Get(sessionID[:])
+		if data == nil {
+			return qkd.ErrSessionNotFound
+		}
+		session = &qkd.QKDSession{}
+		return json.Unmarshal(data, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ListSessions returns copies of every stored session
+func (bs *BoltStore) ListSessions() ([]*qkd.QKDSession, error) {
+	var sessions []*qkd.QKDSession
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(_, data []byte) error {
+			session := &qkd.QKDSession{}
+			if err := json.Unmarshal(data, session); err != nil {
+				return err
+			}
+			sessions = append(sessions, session)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// DeleteExpiredSessions removes sessions that expired before now and returns them
+func (bs *BoltStore) DeleteExpiredSessions(now time.Time) ([]*qkd.QKDSession, error) {
+	var expired []*qkd.QKDSession
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sessionsBucket)
+		var ids [][]byte
+		err := bucket.ForEach(func(id, data []byte) error {
+			session := &qkd.QKDSession{}
+			if err := json.Unmarshal(data, session); err != nil {
+				return err
+			}
+			if now.After(session.ExpiresAt) {
+				expired = append(expired, session)
+				ids = append(ids, append([]byte(nil), id...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := bucket.Delete(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// SaveKey encrypts the key material and stores the key
+func (bs *BoltStore) SaveKey(key *qkd.QuantumKey) error {
+	nonce := make([]byte, bs.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(&keyRecord{
+		Key:       key,
+		Encrypted: bs.aead.Seal(nonce, nonce, key.KeyMaterial, key.KeyID[:]),
+	})
+	if err != nil {
+		return err
+	}
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(keysBucket).Put(key.KeyID[:], data)
+	})
+}
+
+// GetKey returns a decrypted copy of a key by ID
+func (bs *BoltStore) GetKey(keyID uuid.UUID) (*qkd.QuantumKey, error) {
+	var key *qkd.QuantumKey
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(keysBucket).Get(keyID[:])
+		if data == nil {
+			return qkd.ErrKeyNotFound
+		}
+		var err error
+		key, err = bs.decodeKey(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// KeysForSession returns every stored key generated in a session
+func (bs *BoltStore) KeysForSession(sessionID uuid.UUID) ([]*qkd.QuantumKey, error) {
+	var keys []*qkd.QuantumKey
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(keysBucket).ForEach(func(_, data []byte) error {
+			key, err := bs.decodeKey(data)
+			if err != nil {
+				return err
+			}
+			if key.SessionID == sessionID {
+				keys = append(keys, key)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ExpiredKeys returns the IDs of keys that expired before now
+func (bs *BoltStore) ExpiredKeys(now time.Time) ([]uuid.UUID, error) {
+	var expired []uuid.UUID
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(keysBucket).ForEach(func(_, data []byte) error {
+			var record keyRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			if now.After(record.Key.ExpiresAt) {
+				expired = append(expired, record.Key.KeyID)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// SecureDelete overwrites a key's record with zeros before removing it. BoltDB
+// is copy-on-write, so freed pages may retain the old record; they only ever
+// held ciphertext, which is useless without the store's encryption key.
+func (bs *BoltStore) SecureDelete(keyID uuid.UUID) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(keysBucket)
+		data := bucket.Get(keyID[:])
+		if data == nil {
+			return qkd.ErrKeyNotFound
+		}
+		if err := bucket.Put(keyID[:], make([]byte, len(data))); err != nil {
+			return err
+		}
+		return bucket.Delete(keyID[:])
+	})
+}
+
+// decodeKey unmarshals a key record and decrypts its material
+func (bs *BoltStore) decodeKey(data []byte) (*qkd.QuantumKey, error) {
+	var record keyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	nonceSize := bs.aead.NonceSize()
+	if len(record.Encrypted) < nonceSize {
+		return nil, fmt.Errorf("key %s: truncated ciphertext", record.Key.KeyID)
+	}
+	nonce, sealed := record.Encrypted[:nonceSize], record.Encrypted[nonceSize:]
+	material, err := bs.aead.Open(nil, nonce, sealed, record.Key.KeyID[:])
+	if err != nil {
+		return nil, fmt.Errorf("key %s: failed to decrypt material: %w", record.Key.KeyID, err)
+	}
+
+	record.Key.KeyMaterial = material
+	return record.Key, nil
+}
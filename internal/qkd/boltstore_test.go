@@ -0,0 +1,113 @@
+package qkd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// testStoreKey returns a fixed encryption key for test stores
+func testStoreKey() []byte {
+	return bytes.Repeat([]byte{0x42}, StoreKeySize)
+}
+
+func newTestBoltStore(t *testing.T, path string, encryptionKey []byte) *BoltStore {
+	t.Helper()
+
+	store, err := NewBoltStore(path, encryptionKey)
+	if err != nil {
+		t.Fatalf("NewBoltStore failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestBoltStoreRejectsShortKey(t *testing.T) {
+	_, err := NewBoltStore(filepath.Join(t.TempDir(), "qkd.db"), []byte("too short"))
+	if err != qkd.ErrInvalidStoreKey {
+		t.Errorf("Expected ErrInvalidStoreKey, got %v", err)
+	}
+}
+
+func TestBoltStoreSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qkd.db")
+
+	store := newTestBoltStore(t, path, testStoreKey())
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetStore(store)
+	key := generateTestKey(t, sm, "alice", "bob")
+	store.Close()
+
+	restarted := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	restarted.SetStore(newTestBoltStore(t, path, testStoreKey()))
+
+	got, err := restarted.GetKey(key.KeyID, "alice")
+	if err != nil {
+		t.Fatalf("Expected the key to survive a restart, got %v", err)
+	}
+	if !bytes.Equal(got.KeyMaterial, key.KeyMaterial) {
+		t.Error("Expected the same key material after a restart")
+	}
+}
+
+func TestBoltStoreRestoresLabels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qkd.db")
+
+	store := newTestBoltStore(t, path, testStoreKey())
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetStore(store)
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, Label: "payments-db"})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	key, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchange failed: %v", err)
+	}
+	store.Close()
+
+	restarted := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	restarted.SetStore(newTestBoltStore(t, path, testStoreKey()))
+
+	for _, participant := range []string{"alice", "bob"} {
+		got, err := restarted.GetKeyByLabel("payments-db", participant)
+		if err != nil || got.KeyID != key.KeyID {
+			t.Errorf("Expected %s to find the labeled key after a restart, got %v", participant, err)
+		}
+	}
+	if _, err := restarted.CreateSession(&qkd.SessionCreateRequest{AliceID: "bob", KeyLength: 128, Label: "payments-db"}); err != qkd.ErrDuplicateLabel {
+		t.Errorf("Expected ErrDuplicateLabel after a restart, got %v", err)
+	}
+}
+
+func TestBoltStoreEncryptsKeyMaterial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qkd.db")
+
+	store := newTestBoltStore(t, path, testStoreKey())
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetStore(store)
+	key := generateTestKey(t, sm, "alice", "bob")
+	store.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read store file: %v", err)
+	}
+	if bytes.Contains(data, key.KeyMaterial) {
+		t.Error("Expected key material to be encrypted at rest")
+	}
+
+	wrongKey := bytes.Repeat([]byte{0x24}, StoreKeySize)
+	reopened := newTestBoltStore(t, path, wrongKey)
+	if _, err := reopened.GetKey(key.KeyID); err == nil {
+		t.Error("Expected decryption to fail with the wrong store key")
+	}
+}
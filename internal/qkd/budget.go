@@ -0,0 +1,123 @@
+package qkd
+
+import (
+	"math"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// Assumptions of the post-processing budget
+const (
+	budgetSiftRate   = 0.5 // Fraction of qubits measured in Alice's basis
+	budgetConfidence = 2.0 // Standard deviations of QBER sampling error to plan for
+)
+
+// correctionEfficiency is the number of parities an error correction method is
+// planned to disclose per key bit, as a multiple of h(QBER)
+func correctionEfficiency(method crypto.CorrectionMethod) float64 {
+	if method == crypto.WinnowMethod {
+		return 3.0
+	}
+	return 1.5
+}
+
+// PostProcessingBudget describes the exchange a transmission length is planned for
+type PostProcessingBudget struct {
+	ExpectedQBER      float64                 // Channel QBER the exchange is expected to see
+	Correction        crypto.CorrectionMethod // Error correction method; empty means Cascade
+	SecurityParameter int                     // Bits removed by privacy amplification for security
+	MaxQubits         int                     // Largest transmission the exchange may use
+}
+
+// ExpectedSecureLength estimates the secure key length left from a sifted key of
+// siftedLength bits. The QBER sample is removed; error correction and privacy
+// amplification are charged at an upper bound of the QBER the sample may report,
+// because that is what the pipeline acts on. The key confirmation tag is charged too.
+func (bb *BB84Protocol) ExpectedSecureLength(siftedLength int, budget PostProcessingBudget) int {
+	sampled := math.Max(float64(siftedLength)*bb.sampleSize, 1)
+	remaining := float64(siftedLength) - sampled
+
+	// The rule of three bounds the QBER when the sample may contain no errors
+	qber := upperQBER(budget.ExpectedQBER, sampled)
+	if qber >= 0.5 {
+		return 0
+	}
+
+	h := crypto.BinaryEntropy(qber)
+	secret := 1 - h
+	if bb.decoy != nil {
+		secret = bb.expectedDecoySecureFraction(siftedLength, budget.ExpectedQBER)
+	}
+
+	secure := remaining*(secret-correctionEfficiency(budget.Correction)*h) - float64(DefaultConfirmTagBits+budget.SecurityParameter)
+	if secure < 0 {
+		return 0
+	}
+	return int(secure)
+}
+
+// upperQBER is the QBER a sample of n bits may report when the channel QBER is qber
+func upperQBER(qber, n float64) float64 {
+	upper := qber + budgetConfidence*math.Sqrt(qber*(1-qber)/n)
+	return math.Max(upper, 3/n)
+}
+
+// expectedDecoySecureFraction estimates the SecureFraction the decoy bound will
+// report: only single-photon signals count, and the single-photon error rate is
+// bounded from the far fewer sifted decoy pulses, so its sampling error is larger
+func (bb *BB84Protocol) expectedDecoySecureFraction(siftedLength int, expectedQBER float64) float64 {
+	transmittance := 1.0
+	if source, ok := bb.backend.(quantum.PulseSource); ok {
+		transmittance = source.Transmittance()
+	}
+
+	mu, nu := bb.decoy.SignalIntensity, bb.decoy.DecoyIntensity
+	signalGain := 1 - math.Exp(-mu*transmittance)
+	decoyGain := 1 - math.Exp(-nu*transmittance)
+	single := mu * transmittance * math.Exp(-mu) / signalGain
+
+	decoySifted := float64(siftedLength) * bb.decoy.DecoyProbability / bb.decoy.SignalProbability * decoyGain / signalGain
+	e1 := upperQBER(expectedQBER, math.Max(decoySifted, 1))
+	if e1 >= 0.5 {
+		return 0
+	}
+
+	return single * (1 - crypto.BinaryEntropy(e1))
+}
+
+// PostProcessingTransmissionLength returns the number of qubits to send so that
+// sifting, QBER sampling, error correction and privacy amplification still leave
+// the target key length. It returns 0 if no transmission of at most
+// budget.MaxQubits qubits is expected to be enough.
+func (bb *BB84Protocol) PostProcessingTransmissionLength(budget PostProcessingBudget) int {
+	maxSifted := int(float64(budget.MaxQubits) * budgetSiftRate)
+	if bb.ExpectedSecureLength(maxSifted, budget) < bb.keyLength {
+		return 0
+	}
+
+	// The expected secure length grows with the sifted length, so search for the
+	// shortest sifted key that suffices
+	low, high := bb.keyLength, maxSifted
+	for low < high {
+		mid := low + (high-low)/2
+		if bb.ExpectedSecureLength(mid, budget) >= bb.keyLength {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	return int(math.Ceil(float64(low) / budgetSiftRate))
+}
+
+// BudgetPostProcessing sets the oversampling factor from PostProcessingTransmissionLength.
+// When no transmission within budget.MaxQubits suffices, the factor is set so that
+// the exchange exceeds the cap and is rejected before any generation.
+func (bb *BB84Protocol) BudgetPostProcessing(budget PostProcessingBudget) {
+	length := bb.PostProcessingTransmissionLength(budget)
+	if length == 0 {
+		length = budget.MaxQubits + 1
+	}
+	bb.SetOversamplingFactor((length + bb.keyLength - 1) / bb.keyLength)
+}
@@ -0,0 +1,44 @@
+package qkd
+
+import (
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestPostProcessingTransmissionLength(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 256)
+	budget := PostProcessingBudget{SecurityParameter: DefaultSecurityParameter, MaxQubits: DefaultMaxRawQubits}
+
+	noiseless := bb84.PostProcessingTransmissionLength(budget)
+	if noiseless < 2*256 {
+		t.Fatalf("Expected at least twice the key length to survive sifting, got %d", noiseless)
+	}
+
+	budget.ExpectedQBER = 0.03
+	noisy := bb84.PostProcessingTransmissionLength(budget)
+	if noisy <= noiseless {
+		t.Errorf("Expected a noisy channel to need more than %d qubits, got %d", noiseless, noisy)
+	}
+
+	budget.Correction = crypto.WinnowMethod
+	if winnow := bb84.PostProcessingTransmissionLength(budget); winnow <= noisy {
+		t.Errorf("Expected Winnow to need more than Cascade's %d qubits, got %d", noisy, winnow)
+	}
+
+	// Above the abort threshold no key is expected, however many qubits are sent
+	budget.ExpectedQBER = 0.2
+	if length := bb84.PostProcessingTransmissionLength(budget); length != 0 {
+		t.Errorf("Expected no transmission length at QBER 0.2, got %d", length)
+	}
+}
+
+func TestBudgetPostProcessingExceedsCap(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 256)
+	bb84.BudgetPostProcessing(PostProcessingBudget{ExpectedQBER: 0.2, SecurityParameter: DefaultSecurityParameter, MaxQubits: 4096})
+
+	if bb84.TransmissionLength() <= 4096 {
+		t.Errorf("Expected an unreachable budget to exceed the cap, got %d qubits", bb84.TransmissionLength())
+	}
+}
@@ -0,0 +1,88 @@
+package qkd
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultCleanupInterval is how often the background loop removes expired sessions and keys
+const DefaultCleanupInterval = time.Minute
+
+// StartCleanupLoop calls CleanupExpiredSessions every interval in a background
+// goroutine until Stop is called. Starting a running loop has no effect.
+func (sm *SessionManager) StartCleanupLoop(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCleanupInterval
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if sm.cleanupStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	sm.cleanupStop = stop
+	sm.cleanupDone = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				removed := sm.CleanupExpiredSessions()
+				sm.Logger().Info("removed expired sessions and keys", "removed", removed)
+			}
+		}
+	}()
+}
+
+// Stop shuts down the background cleanup loop and waits for it to exit
+func (sm *SessionManager) Stop() {
+	sm.mutex.Lock()
+	stop, done := sm.cleanupStop, sm.cleanupDone
+	sm.cleanupStop, sm.cleanupDone = nil, nil
+	sm.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Shutdown stops the cleanup loop and the key pool and refuses new background
+// exchanges, then waits for the background exchanges and key callbacks already in
+// flight. If ctx ends first, the remaining exchanges are aborted, which marks their
+// sessions failed, and ctx's error is returned once they have stopped. Call it
+// after the HTTP server has stopped serving requests.
+func (sm *SessionManager) Shutdown(ctx context.Context) error {
+	sm.Stop()
+	sm.stopKeyPool()
+
+	sm.mutex.Lock()
+	sm.shuttingDown = true
+	sm.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		sm.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		sm.abortJobs()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,119 @@
+package qkd
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestCleanupLoopRemovesExpiredSessions(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	expired, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	live, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+
+	sm.mutex.Lock()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	sm.store.SaveSession(expired)
+	sm.mutex.Unlock()
+
+	sm.StartCleanupLoop(10 * time.Millisecond)
+	defer sm.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := sm.GetSession(expired.SessionID); err == qkd.ErrSessionNotFound {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the cleanup loop to remove the expired session")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := sm.GetSession(live.SessionID); err != nil {
+		t.Errorf("Expected the live session to remain, got %v", err)
+	}
+}
+
+func TestCleanupLoopStop(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.StartCleanupLoop(time.Millisecond)
+	sm.StartCleanupLoop(time.Millisecond) // No second loop
+
+	sm.Stop()
+	sm.Stop() // Safe to call again
+
+	expired, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	sm.mutex.Lock()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	sm.mutex.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := sm.store.GetSession(expired.SessionID); err != nil {
+		t.Errorf("Expected no cleanup after Stop, got %v", err)
+	}
+}
+
+func TestShutdownWaitsForBackgroundExchanges(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+
+	newSession := func() *qkd.QKDSession {
+		session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+		sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+		return session
+	}
+
+	session := newSession()
+	if _, err := sm.ExecuteKeyExchangeAsync(context.Background(), session.SessionID); err != nil {
+		t.Fatalf("ExecuteKeyExchangeAsync failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sm.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	job, _ := sm.GetExchangeJob(session.SessionID)
+	if !job.Done() || job.Err != "" {
+		t.Errorf("Expected the background exchange to finish before Shutdown returned, got %+v", job)
+	}
+
+	late := newSession()
+	if _, err := sm.ExecuteKeyExchangeAsync(context.Background(), late.SessionID); err != qkd.ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown after shutdown, got %v", err)
+	}
+}
+
+func TestShutdownAbandonsRetryingCallbacks(t *testing.T) {
+	receiver := newCallbackReceiver(1000)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]byte("secret"))
+	notifier.SetAllowPrivateNetworks(true)
+	notifier.SetRetryPolicy(1000, time.Hour)
+
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetWebhookNotifier(notifier)
+	runCallbackSession(t, sm, server.URL, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- sm.Shutdown(ctx) }()
+
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Expected Shutdown to report its deadline, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Shutdown to abandon a callback stuck in backoff")
+	}
+}
@@ -0,0 +1,39 @@
+package qkd
+
+import (
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// SetMaxConcurrentExchanges bounds how many key exchanges may run at once, so a
+// burst of requests cannot exceed a hardware backend's job quota or exhaust the
+// simulator's CPU. An exchange started while n are running fails with
+// ErrTooManyExchanges. n <= 0 removes the limit, which is the default.
+func (sm *SessionManager) SetMaxConcurrentExchanges(n int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if n <= 0 {
+		sm.exchangeSlots = nil
+		return
+	}
+	sm.exchangeSlots = make(chan struct{}, n)
+}
+
+// acquireExchangeSlot reserves a slot for one exchange without waiting. The
+// returned function releases it.
+func (sm *SessionManager) acquireExchangeSlot() (func(), error) {
+	sm.mutex.RLock()
+	slots := sm.exchangeSlots
+	sm.mutex.RUnlock()
+
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	default:
+		return nil, qkd.ErrTooManyExchanges
+	}
+}
@@ -0,0 +1,100 @@
+package qkd
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// gatedBackend holds every transmission until release is closed and records the
+// most transmissions in flight at once
+type gatedBackend struct {
+	*quantum.SimulatorBackend
+	entered chan struct{}
+	release chan struct{}
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (b *gatedBackend) PrepareAndSend(ctx context.Context, bits []quantum.Bit, bases []quantum.Basis) ([]quantum.Qubit, error) {
+	n := b.running.Add(1)
+	defer b.running.Add(-1)
+	for {
+		peak := b.peak.Load()
+		if n <= peak || b.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	b.entered <- struct{}{}
+	<-b.release
+	return b.SimulatorBackend.PrepareAndSend(ctx, bits, bases)
+}
+
+func TestMaxConcurrentExchanges(t *testing.T) {
+	const limit = 2
+	backend := &gatedBackend{
+		SimulatorBackend: quantum.NewSimulatorBackend(false, 0.0),
+		entered:          make(chan struct{}, limit+1),
+		release:          make(chan struct{}),
+	}
+	sm := NewSessionManager(backend)
+	sm.SetMaxConcurrentExchanges(limit)
+
+	sessions := make([]*qkd.QKDSession, limit+1)
+	for i := range sessions {
+		session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+			t.Fatalf("JoinSession failed: %v", err)
+		}
+		sessions[i] = session
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), sessions[i].SessionID)
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-backend.entered
+	}
+
+	// Every slot is taken, so the next exchange is refused without touching the session
+	extra := sessions[limit].SessionID
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), extra); err != qkd.ErrTooManyExchanges {
+		t.Errorf("Expected ErrTooManyExchanges over the limit, got %v", err)
+	}
+	if _, err := sm.ExecuteKeyExchangeAsync(context.Background(), extra); err != qkd.ErrTooManyExchanges {
+		t.Errorf("Expected ErrTooManyExchanges for a background exchange, got %v", err)
+	}
+	if session, _ := sm.GetSession(extra); session.Status != qkd.SessionActive {
+		t.Errorf("Expected the refused session to stay active, got %s", session.Status)
+	}
+
+	close(backend.release)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("exchange %d failed: %v", i, err)
+		}
+	}
+	if peak := backend.peak.Load(); peak != limit {
+		t.Errorf("Expected at most %d exchanges in flight, got %d", limit, peak)
+	}
+
+	// Finished exchanges free their slots
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), extra); err != nil {
+		t.Errorf("Expected the exchange to run once slots freed up, got %v", err)
+	}
+}
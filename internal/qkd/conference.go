@@ -0,0 +1,413 @@
+package qkd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// DefaultConferenceTestFraction is the fraction of GHZ rounds measured in the
+// diagonal basis to bound the eavesdropper's information
+const DefaultConferenceTestFraction = 0.25
+
+// ConferenceProtocol distributes one key to several parties from GHZ states
+// (N-party BB84). Each round is either a key round, measured by every party in
+// the rectilinear basis so that all outcomes agree, or a test round measured in
+// the diagonal basis, whose outcomes have even parity. The server coordinates
+// the rounds, so no basis sifting is needed. Alice is party 0; every other party
+// corrects its key towards hers.
+type ConferenceProtocol struct {
+	source        quantum.GHZSource
+	parties       int
+	keyLength     int
+	rounds        int
+	testFraction  float64
+	qberThreshold float64
+	sampleSize    float64 // Fraction of key rounds disclosed to estimate the QBER
+	minSample     int
+	correction    crypto.CorrectionMethod
+}
+
+// ConferenceResult is the outcome of a conference key agreement
+type ConferenceResult struct {
+	Keys          [][]byte // Final key of each party, Alice first; all equal
+	Rounds        int
+	KeyRounds     int     // Rounds measured in the rectilinear basis
+	QBER          float64 // Highest sampled error rate between Alice and another party
+	PhaseError    float64 // Fraction of test rounds whose outcomes have odd parity
+	DisclosedBits int     // Parities and confirmation tags disclosed by all parties
+	SecureLength  int
+}
+
+// NewConferenceProtocol creates a conference key agreement between parties
+// parties, Alice included, sending DefaultOversamplingFactor rounds per key bit
+func NewConferenceProtocol(source quantum.GHZSource, parties, keyLength int) *ConferenceProtocol {
+	return &ConferenceProtocol{
+		source:        source,
+		parties:       parties,
+		keyLength:     keyLength,
+		rounds:        keyLength * DefaultOversamplingFactor,
+		testFraction:  DefaultConferenceTestFraction,
+		qberThreshold: 0.11,
+		sampleSize:    0.10,
+		minSample:     DefaultMinQBERSample,
+	}
+}
+
+// SetCorrection selects the error correction algorithm each party uses
+func (cp *ConferenceProtocol) SetCorrection(method crypto.CorrectionMethod) {
+	cp.correction = method
+}
+
+// SetRounds sets the number of GHZ states distributed. Values below 1 are ignored.
+func (cp *ConferenceProtocol) SetRounds(rounds int) {
+	if rounds >= 1 {
+		cp.rounds = rounds
+	}
+}
+
+// Rounds returns the number of GHZ states distributed
+func (cp *ConferenceProtocol) Rounds() int {
+	return cp.rounds
+}
+
+// TransmissionLength returns the number of qubits distributed, one per party per round
+func (cp *ConferenceProtocol) TransmissionLength() int {
+	return cp.rounds * cp.parties
+}
+
+// ExpectedSecureLength estimates the secure key length left from rounds GHZ
+// rounds when each party's key differs from Alice's with probability
+// expectedQBER. Like the BB84 budget, it charges correction and amplification at
+// an upper bound of the error rates the samples may report.
+func (cp *ConferenceProtocol) ExpectedSecureLength(rounds int, expectedQBER float64, correction crypto.CorrectionMethod) int {
+	testRounds := math.Max(float64(rounds)*cp.testFraction, 1)
+	keyRounds := float64(rounds) - testRounds
+	sampled := math.Max(keyRounds*cp.sampleSize, 1)
+	remaining := keyRounds - sampled
+
+	// A test round has odd parity when an odd number of the other parties err
+	others := float64(cp.parties - 1)
+	phaseError := (1 - math.Pow(1-2*expectedQBER, others)) / 2
+
+	qber := upperQBER(expectedQBER, sampled)
+	phase := upperQBER(phaseError, testRounds)
+	if qber >= 0.5 || phase >= 0.5 {
+		return 0
+	}
+
+	secure := remaining*(1-crypto.BinaryEntropy(phase)-others*correctionEfficiency(correction)*crypto.BinaryEntropy(qber)) -
+		others*DefaultConfirmTagBits - DefaultSecurityParameter
+	if secure < 0 {
+		return 0
+	}
+	return int(secure)
+}
+
+// PlanRounds sets the number of rounds to the fewest expected to leave the target
+// key length. It returns false, leaving the rounds unchanged, if more than
+// maxRounds would be needed.
+func (cp *ConferenceProtocol) PlanRounds(expectedQBER float64, maxRounds int) bool {
+	if cp.ExpectedSecureLength(maxRounds, expectedQBER, cp.correction) < cp.keyLength {
+		return false
+	}
+
+	low, high := cp.keyLength, maxRounds
+	for low < high {
+		mid := low + (high-low)/2
+		if cp.ExpectedSecureLength(mid, expectedQBER, cp.correction) >= cp.keyLength {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	cp.rounds = low
+	return true
+}
+
+// Run distributes and measures the GHZ states, estimates the error rates, corrects
+// and confirms every party's key against Alice's and amplifies them all to the
+// target length. Intermediate key material is wiped before returning.
+func (cp *ConferenceProtocol) Run(ctx context.Context) (*ConferenceResult, error) {
+	result := &ConferenceResult{Rounds: cp.rounds}
+
+	// Step 1: Choose the test rounds and measure
+	test, err := randomIndices(cp.rounds, int(float64(cp.rounds)*cp.testFraction))
+	if err != nil {
+		return nil, err
+	}
+	isTest := make([]bool, cp.rounds)
+	for _, i := range test {
+		isTest[i] = true
+	}
+
+	bases := make([][]quantum.Basis, cp.parties)
+	for p := range bases {
+		bases[p] = make([]quantum.Basis, cp.rounds)
+		for _, i := range test {
+			bases[p][i] = quantum.DiagonalBasis
+		}
+	}
+
+	outcomes, err := cp.source.MeasureGHZ(ctx, bases)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Bound the phase error from the test rounds and keep the key rounds
+	oddParity := 0
+	keys := make([][]quantum.Bit, cp.parties)
+	for i := 0; i < cp.rounds; i++ {
+		if !isTest[i] {
+			for p := range keys {
+				keys[p] = append(keys[p], outcomes[p][i])
+			}
+			continue
+		}
+
+		parity := quantum.Zero
+		for p := range outcomes {
+			parity ^= outcomes[p][i]
+		}
+		if parity == quantum.One {
+			oddParity++
+		}
+	}
+	for p := range outcomes {
+		crypto.ZeroizeBits(outcomes[p])
+	}
+	defer func() {
+		for p := range keys {
+			crypto.ZeroizeBits(keys[p])
+		}
+	}()
+
+	result.KeyRounds = len(keys[0])
+	if len(test) < cp.minSample {
+		return nil, &QBERSampleTooSmallError{Sampled: len(test), Minimum: cp.minSample}
+	}
+	result.PhaseError = float64(oddParity) / float64(len(test))
+
+	// Step 3: Estimate each party's QBER against Alice from a disclosed sample
+	sampled, err := randomIndices(result.KeyRounds, int(float64(result.KeyRounds)*cp.sampleSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(sampled) < cp.minSample {
+		return nil, &QBERSampleTooSmallError{Sampled: len(sampled), Minimum: cp.minSample}
+	}
+	for p := 1; p < cp.parties; p++ {
+		mismatches := 0
+		for _, i := range sampled {
+			if keys[p][i] != keys[0][i] {
+				mismatches++
+			}
+		}
+		result.QBER = math.Max(result.QBER, float64(mismatches)/float64(len(sampled)))
+	}
+	for p := range keys {
+		keys[p] = removeIndices(keys[p], sampled)
+	}
+
+	if worst := math.Max(result.QBER, result.PhaseError); worst > cp.qberThreshold {
+		return nil, &QBERExceededError{QBER: worst, Threshold: cp.qberThreshold}
+	}
+
+	// Step 4: Every party corrects its key towards Alice's and confirms it
+	correctionQBER := math.Max(result.QBER, 3/float64(len(sampled)))
+	for p := 1; p < cp.parties; p++ {
+		corrector, err := crypto.NewCorrector(cp.correction, correctionQBER)
+		if err != nil {
+			return nil, err
+		}
+		corrected, disclosed, err := corrector.Correct(keys[0], keys[p])
+		if err != nil {
+			return nil, fmt.Errorf("party %d: %w", p, err)
+		}
+		crypto.ZeroizeBits(keys[p])
+		keys[p] = corrected
+		result.DisclosedBits += disclosed
+
+		equal, err := crypto.ConfirmKeyEquality(keys[0], keys[p], DefaultConfirmTagBits)
+		if err != nil {
+			return nil, err
+		}
+		result.DisclosedBits += DefaultConfirmTagBits
+		if !equal {
+			return nil, fmt.Errorf("key confirmation failed for party %d: tags differ", p)
+		}
+	}
+
+	// Step 5: Privacy amplification, with Eve's information bounded by the phase error
+	amplifier, err := crypto.NewPrivacyAmplifierWithSecurity(crypto.SHA3_256Method, DefaultSecurityParameter)
+	if err != nil {
+		return nil, err
+	}
+	result.SecureLength = amplifier.SecureKeyLength(len(keys[0]), result.PhaseError, result.DisclosedBits)
+	if result.SecureLength < cp.keyLength {
+		return nil, fmt.Errorf("Cannot generate requested key length: max secure length is %d bits", result.SecureLength)
+	}
+
+	leakage := float64(result.DisclosedBits) / float64(len(keys[0]))
+	result.Keys = make([][]byte, cp.parties)
+	for p := range keys {
+		if result.Keys[p], err = amplifier.Amplify(keys[p], leakage, cp.keyLength); err != nil {
+			result.Zeroize()
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Zeroize wipes every party's final key
+func (r *ConferenceResult) Zeroize() {
+	for _, key := range r.Keys {
+		crypto.Zeroize(key)
+	}
+}
+
+// randomIndices returns k distinct indices below n in ascending order
+func randomIndices(n, k int) ([]int, error) {
+	chosen := make(map[int]bool, k)
+	for len(chosen) < min(k, n) {
+		idx, err := cryptoRandInt(n)
+		if err != nil {
+			return nil, err
+		}
+		chosen[idx] = true
+	}
+
+	indices := make([]int, 0, len(chosen))
+	for idx := range chosen {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+// removeIndices returns bits without the positions in sorted, wiping the original
+func removeIndices(bits []quantum.Bit, sorted []int) []quantum.Bit {
+	kept := make([]quantum.Bit, 0, len(bits)-len(sorted))
+	for i, bit := range bits {
+		if _, found := slices.BinarySearch(sorted, i); !found {
+			kept = append(kept, bit)
+		}
+	}
+	crypto.ZeroizeBits(bits)
+	return kept
+}
+
+// sessionParticipants returns everyone who shares a session's key, Alice first
+func sessionParticipants(session *qkd.QKDSession) []string {
+	if session.Participants > 2 {
+		return append([]string{session.AliceID}, session.ParticipantIDs...)
+	}
+	return []string{session.AliceID, session.BobID}
+}
+
+// initiateConference plans the rounds of a conference session's exchange and
+// marks the session initiating. The caller must hold the write lock.
+func (sm *SessionManager) initiateConference(session *qkd.QKDSession) (*postProcessingRun, error) {
+	backend, err := sm.sessionBackend(session)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := backend.(quantum.GHZSource)
+	if !ok {
+		return nil, qkd.ErrConferenceUnsupported
+	}
+
+	conference := NewConferenceProtocol(source, session.Participants, session.KeyLength)
+	conference.SetCorrection(sm.correction)
+	maxRounds := sm.maxRawQubits / session.Participants
+	if sm.oversampling > 0 {
+		conference.SetRounds(session.KeyLength * sm.oversampling)
+	} else if !conference.PlanRounds(backend.GetNoiseLevel(), maxRounds) {
+		return nil, &RawQubitLimitError{KeyLength: session.KeyLength, Max: sm.maxRawQubits}
+	}
+	if required := conference.TransmissionLength(); required > sm.maxRawQubits {
+		return nil, &RawQubitLimitError{KeyLength: session.KeyLength, Required: required, Max: sm.maxRawQubits}
+	}
+
+	session.Status = qkd.SessionInitiating
+	if err := sm.store.SaveSession(session); err != nil {
+		return nil, err
+	}
+	sm.publishStatus(session.SessionID, session.Status, "Conference key exchange started")
+
+	return &postProcessingRun{
+		session:    session,
+		backend:    backend,
+		conference: conference,
+		logger:     sm.logger.With("session_id", session.SessionID),
+	}, nil
+}
+
+// runConference runs a started conference exchange and stores the shared key
+func (sm *SessionManager) runConference(ctx context.Context, run *postProcessingRun) (*qkd.QuantumKey, error) {
+	session, conference, logger := run.session, run.conference, run.logger
+	sessionID := session.SessionID
+
+	defer sm.observeExchange(run.backend, time.Now())
+	outcome := ExchangeFailed
+	defer func() { sm.countExchange(outcome) }()
+	logger.DebugContext(ctx, "conference key exchange started", "parties", session.Participants, "rounds", conference.Rounds())
+
+	result, err := conference.Run(ctx)
+	if err != nil {
+		err = timeoutCause(ctx, err)
+		status := qkd.SessionFailed
+		var qberErr *QBERExceededError
+		if errors.As(err, &qberErr) {
+			status = qkd.SessionAborted
+			outcome = ExchangeAborted
+		}
+		logger.DebugContext(ctx, "conference key exchange failed", "status", status, "error", err)
+		sm.updateSessionStatus(sessionID, status, 0, 0, 0, false, err.Error())
+		return nil, err
+	}
+	sm.observeChannel(result.QBER, float64(result.KeyRounds)/float64(result.Rounds))
+
+	// Every party holds the same key, so only Alice's copy is stored
+	finalKey := result.Keys[0]
+	for _, key := range result.Keys[1:] {
+		crypto.Zeroize(key)
+	}
+
+	now := time.Now()
+	quantumKey := &qkd.QuantumKey{
+		KeyID:       uuid.New(),
+		SessionID:   sessionID,
+		Label:       session.Label,
+		KeyMaterial: finalKey,
+		KeyLength:   len(finalKey) * 8,
+		GeneratedAt: now,
+		ExpiresAt:   sm.keyExpiry(session, now),
+		IsActive:    true,
+	}
+
+	if err := sm.store.SaveKey(quantumKey); err != nil {
+		crypto.Zeroize(finalKey)
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, result.QBER, result.KeyRounds, 0, false, err.Error())
+		return nil, fmt.Errorf("failed to store key: %w", err)
+	}
+	logger.DebugContext(ctx, "key stored", "key_id", quantumKey.KeyID, "key_length", quantumKey.KeyLength, "qber", result.QBER)
+	outcome = ExchangeSucceeded
+
+	msg := fmt.Sprintf("Conference key shared by %d parties! QBER: %.2f%%, Phase error: %.2f%%, Disclosed bits: %d",
+		session.Participants, result.QBER*100, result.PhaseError*100, result.DisclosedBits)
+	sm.completeExchange(run, quantumKey, result.QBER, result.KeyRounds, msg)
+
+	return quantumKey, nil
+}
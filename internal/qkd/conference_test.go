@@ -0,0 +1,131 @@
+package qkd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// createConferenceSession creates a session for alice and joins every other participant
+func createConferenceSession(t *testing.T, sm *SessionManager, alice string, others ...string) *qkd.QKDSession {
+	t.Helper()
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{
+		AliceID:      alice,
+		KeyLength:    256,
+		Participants: len(others) + 1,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	for i, id := range others {
+		joined, err := sm.JoinSession(session.SessionID, id, session.JoinToken)
+		if err != nil {
+			t.Fatalf("JoinSession(%q) failed: %v", id, err)
+		}
+		want := qkd.SessionWaitingForBob
+		if i == len(others)-1 {
+			want = qkd.SessionActive
+		}
+		if joined.Status != want {
+			t.Fatalf("after %d joins: expected status %s, got %s", i+1, want, joined.Status)
+		}
+	}
+
+	return session
+}
+
+func TestConferenceProtocolSharesOneKey(t *testing.T) {
+	conference := NewConferenceProtocol(quantum.NewSimulatorBackend(true, 0.02), 3, 256)
+	if !conference.PlanRounds(0.02, DefaultMaxRawQubits) {
+		t.Fatal("Expected a 3-party conference at 2% noise to be feasible")
+	}
+
+	result, err := conference.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Keys) != 3 || len(result.Keys[0]) != 32 {
+		t.Fatalf("Expected 3 keys of 32 bytes, got %d", len(result.Keys))
+	}
+	for p, key := range result.Keys[1:] {
+		if !bytes.Equal(key, result.Keys[0]) {
+			t.Errorf("party %d's key differs from Alice's", p+1)
+		}
+	}
+	if result.PhaseError == 0 && result.QBER == 0 {
+		t.Error("Expected the noisy channel to show some errors")
+	}
+}
+
+func TestConferenceSessionKeyAccess(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	session := createConferenceSession(t, sm, "alice", "bob", "carol")
+
+	key, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchange failed: %v", err)
+	}
+
+	for _, userID := range []string{"alice", "bob", "carol"} {
+		got, err := sm.RetrieveKey(key.KeyID, userID)
+		if err != nil {
+			t.Errorf("RetrieveKey(%q) failed: %v", userID, err)
+			continue
+		}
+		if !bytes.Equal(got.KeyMaterial, key.KeyMaterial) {
+			t.Errorf("RetrieveKey(%q) returned different key material", userID)
+		}
+	}
+	if _, err := sm.GetKey(key.KeyID, "mallory"); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for a non-participant, got %v", err)
+	}
+
+	listed, err := sm.ListSessions(SessionFilter{UserID: "carol"})
+	if err != nil || len(listed) != 1 {
+		t.Errorf("Expected carol to see 1 session, got %d (%v)", len(listed), err)
+	}
+}
+
+func TestConferenceRequiresGHZBackend(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.RegisterBackend(qkd.BackendQiskit, quantum.NewQiskitBackend("token", "ibm_brisbane"))
+
+	_, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256, Participants: 3, Backend: qkd.BackendQiskit})
+	if err != qkd.ErrConferenceUnsupported {
+		t.Errorf("Expected ErrConferenceUnsupported on Qiskit, got %v", err)
+	}
+}
+
+func TestConferenceJoinRules(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256, Participants: 3})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, "alice", session.JoinToken); err != qkd.ErrAlreadyJoined {
+		t.Errorf("Expected ErrAlreadyJoined for Alice, got %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != qkd.ErrAlreadyJoined {
+		t.Errorf("Expected ErrAlreadyJoined for a second join, got %v", err)
+	}
+	if _, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID); err != qkd.ErrSessionNotActive {
+		t.Errorf("Expected ErrSessionNotActive before everyone joined, got %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "carol", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	// The token is spent once the session is full
+	if _, err := sm.JoinSession(session.SessionID, "dave", session.JoinToken); err != qkd.ErrInvalidJoinToken {
+		t.Errorf("Expected ErrInvalidJoinToken after the session filled, got %v", err)
+	}
+}
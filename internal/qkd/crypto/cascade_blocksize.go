@@ -0,0 +1,68 @@
+package crypto
+
+import "math"
+
+// maxCascadeBlockSize bounds the search for the optimal initial block size
+const maxCascadeBlockSize = 1 << 16
+
+// OptimalCascadeBlockSize returns the initial Cascade block size that minimizes the
+// expected number of disclosed bits per key bit for the given QBER and number of passes.
+//
+// Each pass with block size k discloses one parity per block plus ceil(log2 k)
+// bits for the binary search in every block with an odd number of errors, which
+// happens with probability (1-(1-2e)^k)/2 at residual error rate e. From the
+// second pass on, each correction also exposes a partner error in a first-pass
+// block (the cascade effect). Errors left after the last pass are charged at the
+// cost of a first-pass binary search. Small blocks waste parities; large blocks
+// leave errors behind and make each search longer. The 0.73/QBER heuristic
+// ignores the search cost and tends to pick blocks that are too small.
+func OptimalCascadeBlockSize(qber float64, passes int) int {
+	if passes < 1 {
+		passes = 4
+	}
+
+	if qber <= 0 {
+		return maxCascadeBlockSize
+	}
+	if qber >= 0.5 {
+		return 1
+	}
+
+	// Beyond ~8 expected errors per block every block is odd and leakage only grows
+	limit := int(math.Min(math.Ceil(8/qber), maxCascadeBlockSize))
+
+	best := 1
+	bestLeakage := math.Inf(1)
+	for k := 2; k <= limit; k++ {
+		if leakage := expectedCascadeLeakage(k, qber, passes); leakage < bestLeakage {
+			best = k
+			bestLeakage = leakage
+		}
+	}
+
+	return best
+}
+
+// expectedCascadeLeakage estimates the disclosed bits per key bit for initial block size k
+func expectedCascadeLeakage(k int, qber float64, passes int) float64 {
+	firstSearch := math.Ceil(math.Log2(float64(k)))
+	errorRate := qber
+	leakage := 0.0
+
+	for pass := 0; pass < passes; pass++ {
+		blockSize := float64(k) * math.Pow(2, float64(pass))
+		oddBlocks := (1 - math.Pow(1-2*errorRate, blockSize)) / 2 / blockSize
+
+		leakage += 1/blockSize + oddBlocks*math.Ceil(math.Log2(blockSize))
+		corrected := oddBlocks
+
+		if pass > 0 {
+			leakage += oddBlocks * firstSearch
+			corrected *= 2
+		}
+
+		errorRate = math.Max(errorRate-corrected, 0)
+	}
+
+	return leakage + errorRate*(firstSearch+1)
+}
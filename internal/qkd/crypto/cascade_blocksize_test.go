@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// injectErrors returns a random key and a copy with exactly qber*n flipped bits
+func injectErrors(r *rand.Rand, n int, qber float64) ([]quantum.Bit, []quantum.Bit) {
+	alice := make([]quantum.Bit, n)
+	bob := make([]quantum.Bit, n)
+	for i := range alice {
+		alice[i] = quantum.Bit(r.Intn(2))
+		bob[i] = alice[i]
+	}
+
+	for _, i := range r.Perm(n)[:int(qber*float64(n))] {
+		bob[i] = 1 - bob[i]
+	}
+
+	return alice, bob
+}
+
+func TestOptimalCascadeBlockSizeDisclosesLess(t *testing.T) {
+	const n = 4096
+	const trials = 10
+
+	for _, qber := range []float64{0.01, 0.02, 0.05, 0.08} {
+		heuristic := NewCascadeCorrector(qber)
+		optimized := NewCascadeCorrector(qber)
+		optimized.OptimizeBlockSize(true)
+
+		heuristicDisclosed, optimizedDisclosed := 0, 0
+		for trial := 0; trial < trials; trial++ {
+			alice, bob := injectErrors(rand.New(rand.NewSource(int64(trial))), n, qber)
+
+			for _, c := range []struct {
+				corrector *CascadeCorrector
+				total     *int
+			}{{heuristic, &heuristicDisclosed}, {optimized, &optimizedDisclosed}} {
+				corrected, disclosed, err := c.corrector.Correct(alice, bob)
+				if err != nil {
+					t.Fatalf("Correct failed: %v", err)
+				}
+				for i := range alice {
+					if corrected[i] != alice[i] {
+						t.Fatalf("QBER %.2f: block size %d left errors", qber, c.corrector.blockSize)
+					}
+				}
+				*c.total += disclosed
+			}
+		}
+
+		t.Logf("QBER %.2f: heuristic k=%d disclosed %d, optimized k=%d disclosed %d",
+			qber, heuristic.blockSize, heuristicDisclosed/trials, optimized.blockSize, optimizedDisclosed/trials)
+
+		if optimizedDisclosed >= heuristicDisclosed {
+			t.Errorf("QBER %.2f: expected optimized block size to disclose fewer bits (%d vs %d)",
+				qber, optimizedDisclosed/trials, heuristicDisclosed/trials)
+		}
+	}
+}
+
+func TestOptimalCascadeBlockSizeBounds(t *testing.T) {
+	if k := OptimalCascadeBlockSize(0.5, 4); k != 1 {
+		t.Errorf("Expected block size 1 at QBER 0.5, got %d", k)
+	}
+
+	// Lower error rates call for larger blocks
+	previous := OptimalCascadeBlockSize(0.11, 4)
+	for _, qber := range []float64{0.08, 0.05, 0.02, 0.01} {
+		k := OptimalCascadeBlockSize(qber, 4)
+		if k < previous {
+			t.Errorf("Expected block size to grow as QBER falls: %d at %.2f after %d", k, qber, previous)
+		}
+		previous = k
+	}
+}
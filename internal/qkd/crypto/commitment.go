@@ -0,0 +1,47 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Commitment schemes let a party bind itself to a value (e.g. Bob's measurement
+// bases) before seeing the other party's announcement, and reveal it later.
+// The hash-based scheme below is computationally binding and hiding:
+// commitment = SHA3-256(nonce || message) with a fresh 256-bit nonce.
+
+// CommitmentNonceSize is the size of the random nonce used to hide the committed message
+const CommitmentNonceSize = 32
+
+// ErrCommitmentMismatch is returned when a revealed value does not open its commitment
+var ErrCommitmentMismatch = errors.New("revealed value does not match commitment")
+
+// Commit returns a commitment to message and the nonce needed to open it
+func Commit(message []byte) (commitment []byte, nonce []byte, err error) {
+	nonce = make([]byte, CommitmentNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate commitment nonce: %w", err)
+	}
+
+	return commitmentDigest(message, nonce), nonce, nil
+}
+
+// VerifyCommitment checks that message and nonce open the commitment
+func VerifyCommitment(commitment, message, nonce []byte) error {
+	if subtle.ConstantTimeCompare(commitment, commitmentDigest(message, nonce)) != 1 {
+		return ErrCommitmentMismatch
+	}
+	return nil
+}
+
+// commitmentDigest computes SHA3-256(nonce || message)
+func commitmentDigest(message, nonce []byte) []byte {
+	h := sha3.New256()
+	h.Write(nonce)
+	h.Write(message)
+	return h.Sum(nil)
+}
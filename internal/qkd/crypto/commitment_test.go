@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	"testing"
+)
+
+func TestCommitAndVerify(t *testing.T) {
+	message := []byte("bases:0110")
+
+	commitment, nonce, err := Commit(message)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if err := VerifyCommitment(commitment, message, nonce); err != nil {
+		t.Errorf("Expected valid reveal to verify, got %v", err)
+	}
+
+	if err := VerifyCommitment(commitment, []byte("bases:0111"), nonce); err != ErrCommitmentMismatch {
+		t.Errorf("Expected ErrCommitmentMismatch for altered message, got %v", err)
+	}
+
+	otherNonce := make([]byte, len(nonce))
+	if err := VerifyCommitment(commitment, message, otherNonce); err != ErrCommitmentMismatch {
+		t.Errorf("Expected ErrCommitmentMismatch for wrong nonce, got %v", err)
+	}
+}
+
+func TestCommitIsHiding(t *testing.T) {
+	message := []byte("same message")
+
+	c1, _, _ := Commit(message)
+	c2, _, _ := Commit(message)
+
+	if string(c1) == string(c2) {
+		t.Error("Expected fresh nonces to produce different commitments for the same message")
+	}
+}
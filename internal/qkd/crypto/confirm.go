@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// Key confirmation: after error correction Alice and Bob cannot see each other's
+// key, so they compare short tags instead. Alice picks a random Toeplitz matrix
+// and announces it with her tag; Bob hashes his key with the same matrix. The
+// Toeplitz family is 2-universal, so two different keys collide with probability
+// 2^-tagBits. The tag is public and costs tagBits bits of key leakage.
+
+// ConfirmKeyEquality reports whether two corrected keys are identical by comparing
+// tagBits-bit universal-hash tags of them under a fresh random hash function
+func ConfirmKeyEquality(aliceKey, bobKey []quantum.Bit, tagBits int) (bool, error) {
+	if len(aliceKey) == 0 {
+		return false, fmt.Errorf("input key is empty")
+	}
+	if len(aliceKey) != len(bobKey) {
+		return false, fmt.Errorf("key lengths differ: %d and %d bits", len(aliceKey), len(bobKey))
+	}
+	if tagBits <= 0 || tagBits > len(aliceKey) {
+		return false, fmt.Errorf("tag length must be between 1 and %d bits, got %d", len(aliceKey), tagBits)
+	}
+
+	seed := make([]byte, (ToeplitzSeedBits(len(aliceKey), tagBits)+7)/8)
+	if _, err := rand.Read(seed); err != nil {
+		return false, fmt.Errorf("failed to generate confirmation hash seed: %w", err)
+	}
+
+	amplifier := NewPrivacyAmplifier(SHA3_256Method)
+	aliceTag, err := amplifier.AmplifyWithToeplitz(aliceKey, seed, tagBits)
+	if err != nil {
+		return false, err
+	}
+	bobTag, err := amplifier.AmplifyWithToeplitz(bobKey, seed, tagBits)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range aliceTag {
+		if aliceTag[i] != bobTag[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// keyPairWithOneError returns a random key and a copy with one flipped bit
+func keyPairWithOneError(t *testing.T, n int) ([]quantum.Bit, []quantum.Bit) {
+	t.Helper()
+
+	alice, err := quantum.SecureRandomBits(n)
+	if err != nil {
+		t.Fatalf("SecureRandomBits failed: %v", err)
+	}
+	bob := append([]quantum.Bit(nil), alice...)
+	bob[n/3] ^= 1
+	return alice, bob
+}
+
+func TestConfirmKeyEqualityAcceptsEqualKeys(t *testing.T) {
+	key, err := quantum.SecureRandomBits(512)
+	if err != nil {
+		t.Fatalf("SecureRandomBits failed: %v", err)
+	}
+
+	equal, err := ConfirmKeyEquality(key, append([]quantum.Bit(nil), key...), 64)
+	if err != nil {
+		t.Fatalf("ConfirmKeyEquality failed: %v", err)
+	}
+	if !equal {
+		t.Error("Expected identical keys to be confirmed")
+	}
+}
+
+func TestConfirmKeyEqualityCatchesSingleError(t *testing.T) {
+	// A 32-bit tag misses a differing key with probability 2^-32
+	for i := 0; i < 200; i++ {
+		alice, bob := keyPairWithOneError(t, 256)
+		equal, err := ConfirmKeyEquality(alice, bob, 32)
+		if err != nil {
+			t.Fatalf("ConfirmKeyEquality failed: %v", err)
+		}
+		if equal {
+			t.Fatalf("trial %d: a single residual error went undetected", i)
+		}
+	}
+}
+
+func TestConfirmKeyEqualityMissRate(t *testing.T) {
+	// With a 4-bit tag about 1 in 16 differing keys collide
+	const trials = 2000
+	missed := 0
+	for i := 0; i < trials; i++ {
+		alice, bob := keyPairWithOneError(t, 128)
+		equal, err := ConfirmKeyEquality(alice, bob, 4)
+		if err != nil {
+			t.Fatalf("ConfirmKeyEquality failed: %v", err)
+		}
+		if equal {
+			missed++
+		}
+	}
+
+	if missed < 60 || missed > 200 {
+		t.Errorf("Expected about %d of %d single errors to be missed with a 4-bit tag, got %d", trials/16, trials, missed)
+	}
+}
+
+func TestConfirmKeyEqualityRejectsInvalidInput(t *testing.T) {
+	key := make([]quantum.Bit, 64)
+
+	tests := []struct {
+		name    string
+		alice   []quantum.Bit
+		bob     []quantum.Bit
+		tagBits int
+	}{
+		{"empty key", nil, nil, 8},
+		{"length mismatch", key, key[:32], 8},
+		{"zero tag", key, key, 0},
+		{"tag longer than key", key, key, 65},
+	}
+
+	for _, tt := range tests {
+		if _, err := ConfirmKeyEquality(tt.alice, tt.bob, tt.tagBits); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// KeyAlgorithm names a symmetric cipher whose key size a derived key must match
+type KeyAlgorithm string
+
+const (
+	AES128   KeyAlgorithm = "aes128"
+	AES192   KeyAlgorithm = "aes192"
+	AES256   KeyAlgorithm = "aes256"
+	ChaCha20 KeyAlgorithm = "chacha20"
+)
+
+// keySizes maps each algorithm to its key size in bytes
+var keySizes = map[KeyAlgorithm]int{
+	AES128:   16,
+	AES192:   24,
+	AES256:   32,
+	ChaCha20: 32,
+}
+
+// ErrInsufficientKeyMaterial is returned when a key would be stretched beyond
+// the entropy of the quantum key it is derived from
+var ErrInsufficientKeyMaterial = errors.New("key material is shorter than the requested key size")
+
+// KeySize returns the key size in bytes for alg
+func KeySize(alg KeyAlgorithm) (int, error) {
+	size, ok := keySizes[alg]
+	if !ok {
+		return 0, fmt.Errorf("unsupported key algorithm: %s", alg)
+	}
+	return size, nil
+}
+
+// DeriveKey runs HKDF-SHA256 over material to produce a key sized for alg.
+// Both parties holding the same material and info derive the same key, while
+// different info strings give independent keys.
+func DeriveKey(material []byte, alg KeyAlgorithm, info string) ([]byte, error) {
+	size, err := KeySize(alg)
+	if err != nil {
+		return nil, err
+	}
+	if len(material) < size {
+		return nil, ErrInsufficientKeyMaterial
+	}
+
+	return hkdf.Key(sha256.New, material, nil, "go-okd/"+string(alg)+"/"+info, size)
+}
@@ -0,0 +1,58 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveKeySizes(t *testing.T) {
+	material := bytes.Repeat([]byte{0x5a}, 32)
+
+	tests := []struct {
+		alg  KeyAlgorithm
+		size int
+	}{
+		{AES128, 16},
+		{AES192, 24},
+		{AES256, 32},
+		{ChaCha20, 32},
+	}
+
+	for _, tt := range tests {
+		key, err := DeriveKey(material, tt.alg, "")
+		if err != nil {
+			t.Fatalf("%s: DeriveKey failed: %v", tt.alg, err)
+		}
+		if len(key) != tt.size {
+			t.Errorf("%s: expected %d bytes, got %d", tt.alg, tt.size, len(key))
+		}
+	}
+}
+
+func TestDeriveKeyContextSeparation(t *testing.T) {
+	material := bytes.Repeat([]byte{0x5a}, 32)
+
+	a, _ := DeriveKey(material, AES256, "payments")
+	again, _ := DeriveKey(material, AES256, "payments")
+	b, _ := DeriveKey(material, AES256, "logs")
+	chacha, _ := DeriveKey(material, ChaCha20, "payments")
+
+	if !bytes.Equal(a, again) {
+		t.Error("Expected the same material and info to derive the same key")
+	}
+	if bytes.Equal(a, b) {
+		t.Error("Expected different info strings to derive different keys")
+	}
+	if bytes.Equal(a, chacha) {
+		t.Error("Expected different algorithms of the same size to derive different keys")
+	}
+}
+
+func TestDeriveKeyErrors(t *testing.T) {
+	if _, err := DeriveKey(make([]byte, 32), KeyAlgorithm("des"), ""); err == nil {
+		t.Error("Expected error for an unsupported algorithm")
+	}
+	if _, err := DeriveKey(make([]byte, 16), AES256, ""); err != ErrInsufficientKeyMaterial {
+		t.Errorf("Expected ErrInsufficientKeyMaterial, got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package crypto
 
 import (
+	"crypto/rand"
 	"fmt"
+	"math/big"
+	mrand "math/rand"
 
 	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
 )
@@ -9,29 +12,137 @@ import (
 // ErrorCorrection implements error correction algorithms for QKD
 // Primary algorithm: Cascade - interactive error correction protocol
 
+// Corrector reconciles Bob's key against Alice's reference key.
+// It returns Bob's corrected key and the number of bits disclosed on the public channel.
+type Corrector interface {
+	Correct(aliceKey, bobKey []quantum.Bit) ([]quantum.Bit, int, error)
+}
+
+// CorrectionMethod selects the error correction algorithm
+type CorrectionMethod string
+
+const (
+	// CascadeMethod uses the interactive Cascade protocol
+	CascadeMethod CorrectionMethod = "cascade"
+	// WinnowMethod uses Hamming-syndrome based Winnow
+	WinnowMethod CorrectionMethod = "winnow"
+)
+
+// NewCorrector creates the corrector for a method and estimated error rate.
+// An empty method selects Cascade.
+func NewCorrector(method CorrectionMethod, errorRate float64) (Corrector, error) {
+	switch method {
+	case "", CascadeMethod:
+		return NewCascadeCorrector(errorRate), nil
+	case WinnowMethod:
+		return NewWinnowCorrector(errorRate), nil
+	default:
+		return nil, fmt.Errorf("unknown error correction method: %s", method)
+	}
+}
+
+// Cascade defaults
+const (
+	DefaultCascadePasses      = 4 // Standard number of Cascade passes
+	DefaultCascadeBlockGrowth = 2 // Block size multiplier between passes
+)
+
+// CascadeConfig tunes the Cascade protocol. Zero fields keep the defaults.
+type CascadeConfig struct {
+	Passes               int                    // Number of passes
+	InitialBlockSizeFunc func(qber float64) int // First-pass block size for an error rate; defaults to DefaultCascadeBlockSize
+	BlockGrowthFactor    int                    // Block size multiplier between passes
+}
+
+// DefaultCascadeBlockSize is the 0.73/QBER initial block size heuristic
+func DefaultCascadeBlockSize(qber float64) int {
+	if qber <= 0 {
+		return 1
+	}
+	return max(int(0.73/qber), 1)
+}
+
 // CascadeCorrector implements the Cascade error correction algorithm
 type CascadeCorrector struct {
-	passes    int     // Number of Cascade passes
-	blockSize int     // Initial block size
-	errorRate float64 // Estimated error rate
+	passes           int                    // Number of Cascade passes
+	blockSize        int                    // Initial block size
+	growth           int                    // Block size multiplier between passes
+	initialBlockSize func(qber float64) int // Block size strategy OptimizeBlockSize(false) restores
+	errorRate        float64                // Estimated error rate
+	shuffle          bool                   // Randomly permute bit positions before each pass
+	biconfRounds     int                    // Consecutive matching BICONF parities required; 0 disables BICONF
+	shuffleRand      *mrand.Rand            // Seeded permutation source; nil draws from crypto/rand
+
+	residualErrorRate float64 // Fraction of bits still wrong after the last Correct call
 }
 
-// NewCascadeCorrector creates a new Cascade error corrector
+// NewCascadeCorrector creates a new Cascade error corrector with the default configuration
 func NewCascadeCorrector(errorRate float64) *CascadeCorrector {
-	// Initial block size based on error rate (heuristic)
-	blockSize := 1
-	if errorRate > 0 {
-		blockSize = int(0.73 / errorRate)
-		if blockSize < 1 {
-			blockSize = 1
+	return NewCascadeCorrectorWithConfig(errorRate, nil)
+}
+
+// NewCascadeCorrectorWithConfig creates a Cascade error corrector with the given
+// pass count and block size strategy. A nil config keeps the defaults.
+func NewCascadeCorrectorWithConfig(errorRate float64, config *CascadeConfig) *CascadeCorrector {
+	c := &CascadeCorrector{
+		passes:           DefaultCascadePasses,
+		growth:           DefaultCascadeBlockGrowth,
+		initialBlockSize: DefaultCascadeBlockSize,
+		errorRate:        errorRate,
+		shuffle:          true,
+	}
+	if config != nil {
+		if config.Passes > 0 {
+			c.passes = config.Passes
+		}
+		if config.InitialBlockSizeFunc != nil {
+			c.initialBlockSize = config.InitialBlockSizeFunc
+		}
+		if config.BlockGrowthFactor > 0 {
+			c.growth = config.BlockGrowthFactor
 		}
 	}
+	c.blockSize = max(c.initialBlockSize(errorRate), 1)
+
+	return c
+}
+
+// ShufflePasses enables or disables the random permutation applied before each
+// pass. Without it, errors in adjacent positions stay in the same block in every
+// pass and are never detected.
+func (c *CascadeCorrector) ShufflePasses(enabled bool) {
+	c.shuffle = enabled
+}
+
+// SeedShuffle derives the pass permutations from a seed instead of crypto/rand.
+// The permutations are public, so a shared seed is as good as fresh randomness
+// and makes corrections reproducible.
+func (c *CascadeCorrector) SeedShuffle(seed int64) {
+	c.shuffleRand = mrand.New(mrand.NewSource(seed))
+}
+
+// EnableBICONF adds a final BICONF stage that compares the parities of random
+// subsets until rounds consecutive parities agree. Each undetected residual error
+// survives a round with probability 1/2. A non-positive rounds disables it.
+func (c *CascadeCorrector) EnableBICONF(rounds int) {
+	c.biconfRounds = max(rounds, 0)
+}
+
+// ResidualErrorRate returns the fraction of bits that differed from Alice's key
+// after the last call to Correct
+func (c *CascadeCorrector) ResidualErrorRate() float64 {
+	return c.residualErrorRate
+}
 
-	return &CascadeCorrector{
-		passes:    4,         // Standard: 4 passes
-		blockSize: blockSize,
-		errorRate: errorRate,
+// OptimizeBlockSize switches the initial block size from the configured strategy
+// (by default the 0.73/errorRate heuristic) to OptimalCascadeBlockSize, or back
+func (c *CascadeCorrector) OptimizeBlockSize(enabled bool) {
+	if enabled {
+		c.blockSize = OptimalCascadeBlockSize(c.errorRate, c.passes)
+		return
 	}
+
+	c.blockSize = max(c.initialBlockSize(c.errorRate), 1)
 }
 
 // Block represents a block of bits with parity
@@ -63,51 +174,28 @@ func (c *CascadeCorrector) Correct(aliceKey, bobKey []quantum.Bit) ([]quantum.Bi
 
 	totalDisclosedBits := 0
 	blockSize := c.blockSize
+	passes := make([]*cascadePass, 0, c.passes)
 
 	// Perform multiple Cascade passes
 	for pass := 0; pass < c.passes; pass++ {
-		// Divide key into blocks
-		numBlocks := (keyLength + blockSize - 1) / blockSize
-		blocks := make([]Block, numBlocks)
-
-		for i := 0; i < numBlocks; i++ {
-			startIdx := i * blockSize
-			endIdx := startIdx + blockSize
-			if endIdx > keyLength {
-				endIdx = keyLength
-			}
-
-			blocks[i] = Block{
-				StartIndex: startIdx,
-				EndIndex:   endIdx,
-			}
+		positions, err := c.passOrder(keyLength)
+		if err != nil {
+			return nil, 0, err
 		}
+		current := newCascadePass(positions, blockSize)
+		passes = append(passes, current)
 
-		// Binary search for errors in each block
-		for i := range blocks {
-			aliceBlock := aliceKey[blocks[i].StartIndex:blocks[i].EndIndex]
-			bobBlock := corrected[blocks[i].StartIndex:blocks[i].EndIndex]
-
-			aliceParity := CalculateParity(aliceBlock)
-			bobParity := CalculateParity(bobBlock)
+		for block := 0; block < current.numBlocks(); block++ {
+			totalDisclosedBits++ // Each parity comparison discloses 1 bit of information
 
 			// If parities differ, there's an odd number of errors in this block
-			if aliceParity != bobParity {
-				// Binary search to find and correct the error
-				errorIdx, disclosed := c.binarySearch(aliceKey, corrected, blocks[i].StartIndex, blocks[i].EndIndex)
-				totalDisclosedBits += disclosed
-
-				if errorIdx >= 0 {
-					// Flip the erroneous bit
-					corrected[errorIdx] = 1 - corrected[errorIdx]
-				}
+			if !current.parityMatches(aliceKey, corrected, block) {
+				totalDisclosedBits += cascadeCorrect(aliceKey, corrected, passes, cascadeBlock{pass, block})
 			}
-
-			totalDisclosedBits++ // Each parity comparison discloses 1 bit of information
 		}
 
-		// Double block size for next pass (Cascade heuristic)
-		blockSize *= 2
+		// Grow the block size for the next pass (doubling by default)
+		blockSize *= c.growth
 	}
 
 	// Additional cleanup passes to catch remaining errors
@@ -168,24 +256,16 @@ func (c *CascadeCorrector) Correct(aliceKey, bobKey []quantum.Bit) ([]quantum.Bi
 		}
 	}
 
-	// Final verification pass - if there are still errors, fix them directly
-	// This ensures 100% correction but at the cost of more information disclosure
-	remainingErrors := 0
-	for i := 0; i < keyLength; i++ {
-		if aliceKey[i] != corrected[i] {
-			remainingErrors++
+	if c.biconfRounds > 0 {
+		disclosed, err := c.biconf(aliceKey, corrected, passes)
+		if err != nil {
+			return nil, 0, err
 		}
+		totalDisclosedBits += disclosed
 	}
 
-	if remainingErrors > 0 && remainingErrors < keyLength/10 {
-		// If less than 10% errors remain, just fix them directly
-		for i := 0; i < keyLength; i++ {
-			if aliceKey[i] != corrected[i] {
-				corrected[i] = aliceKey[i]
-				totalDisclosedBits++
-			}
-		}
-	}
+	// Errors that survived every pass are reported rather than silently fixed
+	_, c.residualErrorRate = VerifyKeyCorrectness(aliceKey, corrected)
 
 	return corrected, totalDisclosedBits, nil
 }
@@ -213,6 +293,184 @@ func (c *CascadeCorrector) binarySearch(aliceKey, bobKey []quantum.Bit, start, e
 	return start, disclosedBits
 }
 
+// cascadePass is one Cascade pass: a permutation of the key's bit positions cut
+// into consecutive blocks
+type cascadePass struct {
+	positions []int // positions[i] is the key index at permuted position i
+	blockSize int
+	blockOf   []int // blockOf[k] is the block containing key index k
+}
+
+// newCascadePass indexes the blocks of a pass over the given permutation
+func newCascadePass(positions []int, blockSize int) *cascadePass {
+	blockOf := make([]int, len(positions))
+	for i, k := range positions {
+		blockOf[k] = i / blockSize
+	}
+	return &cascadePass{positions: positions, blockSize: blockSize, blockOf: blockOf}
+}
+
+// numBlocks returns the number of blocks in the pass
+func (p *cascadePass) numBlocks() int {
+	return (len(p.positions) + p.blockSize - 1) / p.blockSize
+}
+
+// block returns the key indices of the given block
+func (p *cascadePass) block(i int) []int {
+	return p.positions[i*p.blockSize : min((i+1)*p.blockSize, len(p.positions))]
+}
+
+// parityMatches reports whether Alice's and Bob's parities agree on a block
+func (p *cascadePass) parityMatches(aliceKey, bobKey []quantum.Bit, block int) bool {
+	positions := p.block(block)
+	return parityAt(aliceKey, positions) == parityAt(bobKey, positions)
+}
+
+// cascadeBlock identifies a block within a pass
+type cascadeBlock struct {
+	pass  int
+	block int
+}
+
+// cascadeCorrect fixes one error in the given odd-parity block, then revisits
+// every block of the earlier passes that contained the flipped bit: their parity
+// has changed, so any that are now odd hide a further error. Alice's parities for
+// those blocks were disclosed when the pass ran, so only the binary searches
+// disclose new bits. It returns the number of bits disclosed.
+func cascadeCorrect(aliceKey, bobKey []quantum.Bit, passes []*cascadePass, start cascadeBlock) int {
+	disclosedBits := 0
+	queue := []cascadeBlock{start}
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		pass := passes[next.pass]
+		if pass.parityMatches(aliceKey, bobKey, next.block) {
+			continue
+		}
+
+		errorIdx, disclosed := binarySearchPositions(aliceKey, bobKey, pass.block(next.block))
+		disclosedBits += disclosed
+		bobKey[errorIdx] = 1 - bobKey[errorIdx]
+
+		for i, other := range passes {
+			if i != next.pass {
+				queue = append(queue, cascadeBlock{i, other.blockOf[errorIdx]})
+			}
+		}
+	}
+
+	return disclosedBits
+}
+
+// passOrder returns the bit order for a pass: a fresh random permutation when
+// shuffling is enabled, the identity otherwise. The permutation is public.
+func (c *CascadeCorrector) passOrder(n int) ([]int, error) {
+	if !c.shuffle {
+		positions := make([]int, n)
+		for i := range positions {
+			positions[i] = i
+		}
+		return positions, nil
+	}
+
+	return randomPermutation(n, c.shuffleRand)
+}
+
+// randomPermutation returns a random permutation of [0, n), drawn from src when
+// it is non-nil and from crypto/rand otherwise
+func randomPermutation(n int, src *mrand.Rand) ([]int, error) {
+	positions := make([]int, n)
+	for i := range positions {
+		positions[i] = i
+	}
+
+	// Fisher-Yates shuffle
+	for i := n - 1; i > 0; i-- {
+		var j int
+		if src != nil {
+			j = src.Intn(i + 1)
+		} else {
+			jBig, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to shuffle key: %w", err)
+			}
+			j = int(jBig.Int64())
+		}
+		positions[i], positions[j] = positions[j], positions[i]
+	}
+
+	return positions, nil
+}
+
+// biconf compares the parities of random subsets of the key until biconfRounds
+// consecutive parities agree. A mismatched subset is binary searched and the fix
+// cascades through the earlier passes. It returns the number of bits disclosed.
+func (c *CascadeCorrector) biconf(aliceKey, bobKey []quantum.Bit, passes []*cascadePass) (int, error) {
+	disclosedBits := 0
+	keyLength := len(aliceKey)
+	mask := make([]byte, (keyLength+7)/8)
+
+	for clean := 0; clean < c.biconfRounds; {
+		if _, err := rand.Read(mask); err != nil {
+			return 0, fmt.Errorf("failed to choose BICONF subset: %w", err)
+		}
+
+		subset := make([]int, 0, keyLength/2+1)
+		for i := 0; i < keyLength; i++ {
+			if mask[i/8]&(1<<(i%8)) != 0 {
+				subset = append(subset, i)
+			}
+		}
+
+		disclosedBits++
+		if parityAt(aliceKey, subset) == parityAt(bobKey, subset) {
+			clean++
+			continue
+		}
+		clean = 0
+
+		errorIdx, disclosed := binarySearchPositions(aliceKey, bobKey, subset)
+		disclosedBits += disclosed
+		bobKey[errorIdx] = 1 - bobKey[errorIdx]
+
+		for i, pass := range passes {
+			disclosedBits += cascadeCorrect(aliceKey, bobKey, passes, cascadeBlock{i, pass.blockOf[errorIdx]})
+		}
+	}
+
+	return disclosedBits, nil
+}
+
+// parityAt calculates the XOR parity of the bits at the given positions
+func parityAt(bits []quantum.Bit, positions []int) quantum.Bit {
+	parity := quantum.Zero
+	for _, i := range positions {
+		parity ^= bits[i]
+	}
+	return parity
+}
+
+// binarySearchPositions finds an error within a set of positions whose parities
+// differ, returning its key index and the number of parity bits disclosed
+func binarySearchPositions(aliceKey, bobKey []quantum.Bit, positions []int) (int, int) {
+	disclosedBits := 0
+
+	for len(positions) > 1 {
+		mid := len(positions) / 2
+		disclosedBits++
+
+		if parityAt(aliceKey, positions[:mid]) != parityAt(bobKey, positions[:mid]) {
+			positions = positions[:mid]
+		} else {
+			positions = positions[mid:]
+		}
+	}
+
+	return positions[0], disclosedBits
+}
+
 // SimpleParityCorrector implements a simple parity-based error correction
 // Less efficient than Cascade but simpler to understand and implement
 type SimpleParityCorrector struct{}
@@ -274,28 +532,6 @@ func (s *SimpleParityCorrector) Correct(aliceKey, bobKey []quantum.Bit, blockSiz
 	return corrected, totalDisclosedBits, nil
 }
 
-// LDPCCorrector implements LDPC (Low-Density Parity-Check) error correction
-// This is a placeholder for a more sophisticated error correction method
-type LDPCCorrector struct {
-	codeRate float64 // Code rate (k/n)
-}
-
-// NewLDPCCorrector creates a new LDPC corrector
-func NewLDPCCorrector(codeRate float64) *LDPCCorrector {
-	return &LDPCCorrector{
-		codeRate: codeRate,
-	}
-}
-
-// Correct performs LDPC error correction
-// TODO: Implement full LDPC decoding algorithm
-func (l *LDPCCorrector) Correct(aliceKey, bobKey []quantum.Bit) ([]quantum.Bit, int, error) {
-	// Placeholder: In production, implement belief propagation algorithm
-	// For now, fall back to Cascade
-	cascade := NewCascadeCorrector(0.05)
-	return cascade.Correct(aliceKey, bobKey)
-}
-
 // VerifyKeyCorrectness checks if Alice and Bob's keys match after error correction
 func VerifyKeyCorrectness(aliceKey, bobKey []quantum.Bit) (bool, float64) {
 	if len(aliceKey) != len(bobKey) {
@@ -0,0 +1,217 @@
+package crypto
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// injectClusteredErrors returns a random key and a copy with adjacent bit pairs
+// (2j, 2j+1) flipped, so every aligned block of even size keeps an even parity
+func injectClusteredErrors(r *rand.Rand, n, pairs int) ([]quantum.Bit, []quantum.Bit) {
+	alice := make([]quantum.Bit, n)
+	bob := make([]quantum.Bit, n)
+	for i := range alice {
+		alice[i] = quantum.Bit(r.Intn(2))
+		bob[i] = alice[i]
+	}
+
+	for _, j := range r.Perm(n / 2)[:pairs] {
+		bob[2*j] = 1 - bob[2*j]
+		bob[2*j+1] = 1 - bob[2*j+1]
+	}
+
+	return alice, bob
+}
+
+func TestCascadeShuffleCorrectsClusteredErrors(t *testing.T) {
+	const n = 4096
+	const qber = 0.02 // 40 pairs flip ~2% of the bits; initial block size 36: every pass and cleanup block is even-sized
+
+	for trial := 0; trial < 5; trial++ {
+		alice, bob := injectClusteredErrors(rand.New(rand.NewSource(int64(trial))), n, 40)
+
+		unshuffled := NewCascadeCorrector(qber)
+		unshuffled.ShufflePasses(false)
+		if _, _, err := unshuffled.Correct(alice, bob); err != nil {
+			t.Fatalf("Correct failed: %v", err)
+		}
+		if unshuffled.ResidualErrorRate() == 0 {
+			t.Errorf("Trial %d: expected unshuffled Cascade to miss the paired errors", trial)
+		}
+
+		shuffled := NewCascadeCorrector(qber)
+		corrected, _, err := shuffled.Correct(alice, bob)
+		if err != nil {
+			t.Fatalf("Correct failed: %v", err)
+		}
+		if match, rate := VerifyKeyCorrectness(alice, corrected); !match {
+			t.Errorf("Trial %d: shuffled Cascade left errors (rate %.4f)", trial, rate)
+		}
+		if shuffled.ResidualErrorRate() != 0 {
+			t.Errorf("Trial %d: expected zero residual error rate, got %.4f", trial, shuffled.ResidualErrorRate())
+		}
+	}
+}
+
+// injectBurstErrors returns a random key and the copy Bob measures after it crosses
+// a channel with burst errors
+func injectBurstErrors(r *rand.Rand, n int, noise *quantum.BurstNoise) ([]quantum.Bit, []quantum.Bit) {
+	alice := make([]quantum.Bit, n)
+	bob := make([]quantum.Bit, n)
+	for i := range alice {
+		alice[i] = quantum.Bit(r.Intn(2))
+		bob[i] = noise.Apply(quantum.PrepareQubit(alice[i], quantum.RectilinearBasis), r).ClassicalValue
+	}
+	return alice, bob
+}
+
+func TestCascadeUnderBurstErrors(t *testing.T) {
+	const n = 4096
+	const trials = 10
+	noise := quantum.NewBurstNoise(0.004, 6)
+	qber := noise.ErrorRate()
+
+	burstErrors, missed := 0, 0
+	for trial := 0; trial < trials; trial++ {
+		r := rand.New(rand.NewSource(int64(trial)))
+		alice, bob := injectBurstErrors(r, n, noise)
+		for i := range alice {
+			if alice[i] != bob[i] {
+				burstErrors++
+			}
+		}
+
+		unshuffled := NewCascadeCorrector(qber)
+		unshuffled.ShufflePasses(false)
+		if _, _, err := unshuffled.Correct(alice, bob); err != nil {
+			t.Fatalf("Correct failed: %v", err)
+		}
+		if unshuffled.ResidualErrorRate() > 0 {
+			missed++
+		}
+
+		shuffled := NewCascadeCorrector(qber)
+		corrected, _, err := shuffled.Correct(alice, bob)
+		if err != nil {
+			t.Fatalf("Correct failed: %v", err)
+		}
+		if match, rate := VerifyKeyCorrectness(alice, corrected); !match {
+			t.Errorf("Trial %d: shuffled Cascade left burst errors (rate %.4f)", trial, rate)
+		}
+	}
+
+	// Bursts change where the errors fall, not how many there are
+	if mean := float64(burstErrors) / (n * trials); mean < 0.7*qber || mean > 1.3*qber {
+		t.Errorf("Expected an average QBER near the i.i.d. rate %.4f, got %.4f", qber, mean)
+	}
+	if missed < trials/2 {
+		t.Errorf("Expected unshuffled Cascade to leave residual errors under bursts, missed in %d of %d trials", missed, trials)
+	}
+}
+
+func TestCascadeBICONFRemovesResidualErrors(t *testing.T) {
+	const n = 4096
+	const qber = 0.02
+
+	alice, bob := injectClusteredErrors(rand.New(rand.NewSource(1)), n, 40)
+
+	// Without shuffling the passes leave every pair behind; BICONF alone must find them
+	c := NewCascadeCorrector(qber)
+	c.ShufflePasses(false)
+	_, baseline, err := c.Correct(alice, bob)
+	if err != nil {
+		t.Fatalf("Correct failed: %v", err)
+	}
+
+	c.EnableBICONF(32)
+	corrected, disclosed, err := c.Correct(alice, bob)
+	if err != nil {
+		t.Fatalf("Correct failed: %v", err)
+	}
+	if match, rate := VerifyKeyCorrectness(alice, corrected); !match || c.ResidualErrorRate() != 0 {
+		t.Errorf("BICONF left errors (rate %.4f)", rate)
+	}
+	if disclosed <= baseline {
+		t.Errorf("Expected BICONF parities to be counted as disclosed (%d vs %d)", disclosed, baseline)
+	}
+}
+
+func TestCascadeDoesNotModifyInputs(t *testing.T) {
+	alice, bob := injectErrors(rand.New(rand.NewSource(3)), 1024, 0.05)
+	original := append([]quantum.Bit(nil), bob...)
+
+	c := NewCascadeCorrector(0.05)
+	c.EnableBICONF(16)
+	if _, _, err := c.Correct(alice, bob); err != nil {
+		t.Fatalf("Correct failed: %v", err)
+	}
+	for i := range bob {
+		if bob[i] != original[i] {
+			t.Fatalf("Bob's input key was modified at bit %d", i)
+		}
+	}
+}
+
+func TestCascadePassCountTradeoff(t *testing.T) {
+	// Every pass discloses its block parities; fewer passes leave more errors for
+	// the cleanup stage to find. At 5% errors the extra parities cost more than
+	// the searches they save.
+	const n = 4096
+	const qber = 0.05
+	const trials = 10
+
+	disclosed := make(map[int]int)
+	for _, passes := range []int{2, 4, 6} {
+		for trial := 0; trial < trials; trial++ {
+			alice, bob := injectErrors(rand.New(rand.NewSource(int64(trial))), n, qber)
+			c := NewCascadeCorrectorWithConfig(qber, &CascadeConfig{Passes: passes})
+			c.SeedShuffle(int64(trial))
+
+			corrected, bits, err := c.Correct(alice, bob)
+			if err != nil {
+				t.Fatalf("Correct failed: %v", err)
+			}
+			if equal, _ := VerifyKeyCorrectness(alice, corrected); !equal {
+				t.Fatalf("%d passes: errors left after correction", passes)
+			}
+			disclosed[passes] += bits
+		}
+		t.Logf("%d passes: %d bits disclosed on average", passes, disclosed[passes]/trials)
+	}
+
+	if disclosed[2] >= disclosed[4] || disclosed[4] >= disclosed[6] {
+		t.Errorf("Expected disclosure to grow with the pass count, got %v", disclosed)
+	}
+}
+
+func TestCascadeConfigBlockSizeStrategy(t *testing.T) {
+	c := NewCascadeCorrectorWithConfig(0.05, &CascadeConfig{
+		InitialBlockSizeFunc: func(qber float64) int { return 8 },
+		BlockGrowthFactor:    3,
+	})
+	if c.blockSize != 8 || c.growth != 3 || c.passes != DefaultCascadePasses {
+		t.Errorf("Expected block size 8, growth 3 and default passes, got %d, %d, %d", c.blockSize, c.growth, c.passes)
+	}
+
+	// Optimizing and restoring returns to the configured strategy
+	c.OptimizeBlockSize(true)
+	c.OptimizeBlockSize(false)
+	if c.blockSize != 8 {
+		t.Errorf("Expected OptimizeBlockSize(false) to restore block size 8, got %d", c.blockSize)
+	}
+
+	if d := NewCascadeCorrectorWithConfig(0.05, nil); d.blockSize != DefaultCascadeBlockSize(0.05) || d.growth != 2 {
+		t.Errorf("Expected the defaults with a nil config, got block size %d, growth %d", d.blockSize, d.growth)
+	}
+
+	alice, bob := injectErrors(rand.New(rand.NewSource(1)), 2048, 0.05)
+	corrected, _, err := c.Correct(alice, bob)
+	if err != nil {
+		t.Fatalf("Correct failed: %v", err)
+	}
+	if equal, _ := VerifyKeyCorrectness(alice, corrected); !equal {
+		t.Error("Expected a tripling block size to still correct every error")
+	}
+}
@@ -0,0 +1,265 @@
+package crypto
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+const (
+	// ldpcColumnWeight is the number of parity checks each key bit takes part in
+	ldpcColumnWeight = 3
+
+	// ldpcMaxIterations bounds the number of belief-propagation rounds
+	ldpcMaxIterations = 100
+
+	// ldpcMaxLLR clamps messages to keep tanh/atanh numerically stable
+	ldpcMaxLLR = 30.0
+)
+
+// LDPCCorrector implements one-way LDPC (Low-Density Parity-Check) error correction.
+// Alice discloses the syndrome of her key under a sparse parity-check matrix and
+// Bob recovers her key with sum-product belief propagation.
+type LDPCCorrector struct {
+	codeRate      float64 // Code rate (k/n); the syndrome is (1-codeRate)*n bits
+	errorRate     float64 // Estimated error rate used as the decoder prior
+	maxIterations int     // Maximum belief-propagation iterations
+}
+
+// NewLDPCCorrector creates a new LDPC corrector
+func NewLDPCCorrector(codeRate float64) *LDPCCorrector {
+	return &LDPCCorrector{
+		codeRate:      codeRate,
+		errorRate:     0.05,
+		maxIterations: ldpcMaxIterations,
+	}
+}
+
+// SetErrorRate sets the estimated error rate used to initialise the decoder
+func (l *LDPCCorrector) SetErrorRate(errorRate float64) {
+	l.errorRate = errorRate
+}
+
+// parityCheckMatrix is a sparse binary matrix stored as adjacency lists
+type parityCheckMatrix struct {
+	rows [][]int // Variable (bit) indices taking part in each check
+	cols [][]int // Check indices each variable takes part in
+}
+
+// newParityCheckMatrix builds a pseudo-random m×n parity-check matrix with
+// column weight ldpcColumnWeight and near-uniform row weights. The construction
+// is deterministic in (n, m) so both parties derive the same public matrix.
+func newParityCheckMatrix(n, m int) *parityCheckMatrix {
+	r := rand.New(rand.NewSource(int64(n)<<32 | int64(m)))
+	weight := ldpcColumnWeight
+	if weight > m {
+		weight = m
+	}
+
+	// Deal check "sockets" round-robin so every row gets a near-equal share
+	sockets := make([]int, n*weight)
+	for i := range sockets {
+		sockets[i] = i % m
+	}
+	r.Shuffle(len(sockets), func(i, j int) { sockets[i], sockets[j] = sockets[j], sockets[i] })
+
+	h := &parityCheckMatrix{
+		rows: make([][]int, m),
+		cols: make([][]int, n),
+	}
+
+	for v := 0; v < n; v++ {
+		for k := 0; k < weight; k++ {
+			idx := v*weight + k
+			// Swap in a socket from later in the list if this check is already used by v
+			for attempt := 0; attempt < 32 && containsInt(h.cols[v], sockets[idx]); attempt++ {
+				if idx+1 >= len(sockets) {
+					break
+				}
+				swap := idx + 1 + r.Intn(len(sockets)-idx-1)
+				sockets[idx], sockets[swap] = sockets[swap], sockets[idx]
+			}
+
+			check := sockets[idx]
+			if containsInt(h.cols[v], check) {
+				continue
+			}
+			h.cols[v] = append(h.cols[v], check)
+			h.rows[check] = append(h.rows[check], v)
+		}
+	}
+
+	return h
+}
+
+// syndrome computes H·key over GF(2)
+func (h *parityCheckMatrix) syndrome(key []quantum.Bit) []quantum.Bit {
+	s := make([]quantum.Bit, len(h.rows))
+	for c, row := range h.rows {
+		for _, v := range row {
+			s[c] ^= key[v]
+		}
+	}
+	return s
+}
+
+// Correct performs LDPC error correction. Alice's syndrome is the only
+// information disclosed, so the returned disclosed bit count is the number of checks.
+func (l *LDPCCorrector) Correct(aliceKey, bobKey []quantum.Bit) ([]quantum.Bit, int, error) {
+	if len(aliceKey) != len(bobKey) {
+		return nil, 0, fmt.Errorf("keys must have the same length")
+	}
+	if l.codeRate <= 0 || l.codeRate >= 1 {
+		return nil, 0, fmt.Errorf("LDPC code rate must be between 0 and 1, got %.3f", l.codeRate)
+	}
+
+	n := len(aliceKey)
+	corrected := make([]quantum.Bit, n)
+	copy(corrected, bobKey)
+	if n == 0 {
+		return corrected, 0, nil
+	}
+
+	m := int(math.Ceil((1 - l.codeRate) * float64(n)))
+	h := newParityCheckMatrix(n, m)
+
+	// Alice sends her syndrome; Bob decodes the error pattern whose syndrome
+	// is the difference between Alice's and his own
+	aliceSyndrome := h.syndrome(aliceKey)
+	bobSyndrome := h.syndrome(bobKey)
+	target := make([]quantum.Bit, m)
+	for c := range target {
+		target[c] = aliceSyndrome[c] ^ bobSyndrome[c]
+	}
+
+	errorPattern, err := l.decode(h, target)
+	if err != nil {
+		return nil, m, err
+	}
+
+	for i, e := range errorPattern {
+		corrected[i] ^= e
+	}
+
+	return corrected, m, nil
+}
+
+// decode runs sum-product belief propagation to find the most likely error
+// pattern e with H·e = target
+func (l *LDPCCorrector) decode(h *parityCheckMatrix, target []quantum.Bit) ([]quantum.Bit, error) {
+	n := len(h.cols)
+
+	p := l.errorRate
+	if p <= 0 {
+		p = 1e-4
+	}
+	if p >= 0.5 {
+		p = 0.4999
+	}
+	prior := math.Log((1 - p) / p)
+
+	// Index each edge by its position in the check's row
+	edgeStart := make([]int, len(h.rows)+1)
+	for c, row := range h.rows {
+		edgeStart[c+1] = edgeStart[c] + len(row)
+	}
+	varToCheck := make([]float64, edgeStart[len(h.rows)])
+	checkToVar := make([]float64, len(varToCheck))
+
+	// Edges incident to each variable, for the variable-node update
+	varEdges := make([][]int, n)
+	for c, row := range h.rows {
+		for k, v := range row {
+			varEdges[v] = append(varEdges[v], edgeStart[c]+k)
+		}
+	}
+
+	for e := range varToCheck {
+		varToCheck[e] = prior
+	}
+
+	estimate := make([]quantum.Bit, n)
+	tanhs := make([]float64, 0, 16)
+
+	for iteration := 0; iteration < l.maxIterations; iteration++ {
+		// Check-node update
+		for c, row := range h.rows {
+			start := edgeStart[c]
+			tanhs = tanhs[:0]
+			for k := range row {
+				tanhs = append(tanhs, math.Tanh(varToCheck[start+k]/2))
+			}
+
+			sign := 1.0
+			if target[c] == quantum.One {
+				sign = -1.0
+			}
+
+			for k := range row {
+				product := sign
+				for j, t := range tanhs {
+					if j != k {
+						product *= t
+					}
+				}
+				checkToVar[start+k] = clampLLR(2 * math.Atanh(clampTanh(product)))
+			}
+		}
+
+		// Variable-node update and tentative decision
+		for v, edges := range varEdges {
+			total := prior
+			for _, e := range edges {
+				total += checkToVar[e]
+			}
+			for _, e := range edges {
+				varToCheck[e] = clampLLR(total - checkToVar[e])
+			}
+
+			estimate[v] = quantum.Zero
+			if total < 0 {
+				estimate[v] = quantum.One
+			}
+		}
+
+		if syndromeMatches(h, estimate, target) {
+			return estimate, nil
+		}
+	}
+
+	return nil, fmt.Errorf("LDPC decoding did not converge after %d iterations", l.maxIterations)
+}
+
+// syndromeMatches reports whether H·estimate equals target
+func syndromeMatches(h *parityCheckMatrix, estimate, target []quantum.Bit) bool {
+	for c, row := range h.rows {
+		parity := quantum.Zero
+		for _, v := range row {
+			parity ^= estimate[v]
+		}
+		if parity != target[c] {
+			return false
+		}
+	}
+	return true
+}
+
+func clampLLR(x float64) float64 {
+	return math.Max(-ldpcMaxLLR, math.Min(ldpcMaxLLR, x))
+}
+
+func clampTanh(x float64) float64 {
+	const limit = 1 - 1e-12
+	return math.Max(-limit, math.Min(limit, x))
+}
+
+func containsInt(values []int, x int) bool {
+	for _, v := range values {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
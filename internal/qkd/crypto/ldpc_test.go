@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLDPCCorrectsInjectedErrors(t *testing.T) {
+	const n = 4096
+
+	tests := []struct {
+		qber     float64
+		codeRate float64
+	}{
+		{0.02, 0.7},
+		{0.05, 0.5},
+		{0.08, 0.35},
+	}
+
+	for _, tt := range tests {
+		for trial := 0; trial < 5; trial++ {
+			alice, bob := injectErrors(rand.New(rand.NewSource(int64(trial))), n, tt.qber)
+
+			corrector := NewLDPCCorrector(tt.codeRate)
+			corrector.SetErrorRate(tt.qber)
+
+			corrected, disclosed, err := corrector.Correct(alice, bob)
+			if err != nil {
+				t.Fatalf("QBER %.2f trial %d: %v", tt.qber, trial, err)
+			}
+
+			if match, rate := VerifyKeyCorrectness(alice, corrected); !match {
+				t.Fatalf("QBER %.2f trial %d: residual error rate %.4f", tt.qber, trial, rate)
+			}
+
+			if want := int(math.Ceil((1 - tt.codeRate) * n)); disclosed != want {
+				t.Errorf("QBER %.2f: expected %d syndrome bits disclosed, got %d", tt.qber, want, disclosed)
+			}
+		}
+	}
+}
+
+func TestLDPCDoesNotModifyInputs(t *testing.T) {
+	alice, bob := injectErrors(rand.New(rand.NewSource(1)), 1024, 0.03)
+	original := append(bob[:0:0], bob...)
+
+	if _, _, err := NewLDPCCorrector(0.6).Correct(alice, bob); err != nil {
+		t.Fatalf("Correct failed: %v", err)
+	}
+
+	for i := range bob {
+		if bob[i] != original[i] {
+			t.Fatal("Correct modified Bob's input key")
+		}
+	}
+}
+
+func TestLDPCReportsNonConvergence(t *testing.T) {
+	// 20% errors are far beyond what a rate-0.8 code can correct
+	alice, bob := injectErrors(rand.New(rand.NewSource(1)), 1024, 0.2)
+
+	if _, _, err := NewLDPCCorrector(0.8).Correct(alice, bob); err == nil {
+		t.Error("Expected decoding failure for an error rate above the code's capability")
+	}
+}
+
+func TestLDPCRejectsInvalidInput(t *testing.T) {
+	alice, bob := injectErrors(rand.New(rand.NewSource(1)), 64, 0)
+
+	if _, _, err := NewLDPCCorrector(0.5).Correct(alice, bob[:32]); err == nil {
+		t.Error("Expected error for mismatched key lengths")
+	}
+	if _, _, err := NewLDPCCorrector(1.0).Correct(alice, bob); err == nil {
+		t.Error("Expected error for code rate 1")
+	}
+}
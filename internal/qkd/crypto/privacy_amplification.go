@@ -3,8 +3,11 @@ package crypto
 import (
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"hash"
+	"math"
 
 	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
 	"golang.org/x/crypto/sha3"
@@ -27,16 +30,59 @@ const (
 	SHA3_512Method AmplificationMethod = "SHA3-512"
 )
 
+// DefaultSecurityBits is the security parameter NewPrivacyAmplifier uses. Eve
+// distinguishes the final key from random with probability about 2^-bits.
+const DefaultSecurityBits = 64
+
+// ErrEntropyExceeded is returned when a requested key is longer than the input
+// key's min-entropy after leakage and the security parameter are subtracted.
+// Amplify refuses such requests rather than stretching the key by hash expansion,
+// which would produce bits Eve may know.
+var ErrEntropyExceeded = errors.New("requested key length exceeds the available entropy")
+
 // PrivacyAmplifier performs privacy amplification on quantum keys
 type PrivacyAmplifier struct {
-	method AmplificationMethod
+	method       AmplificationMethod
+	securityBits int // Bits sacrificed beyond the information leakage
 }
 
 // NewPrivacyAmplifier creates a new privacy amplifier with specified method
 func NewPrivacyAmplifier(method AmplificationMethod) *PrivacyAmplifier {
 	return &PrivacyAmplifier{
-		method: method,
+		method:       method,
+		securityBits: DefaultSecurityBits,
+	}
+}
+
+// NewPrivacyAmplifierWithSecurity creates a privacy amplifier that sacrifices
+// securityBits bits, e.g. 40, 64 or 128 depending on the threat model
+func NewPrivacyAmplifierWithSecurity(method AmplificationMethod, securityBits int) (*PrivacyAmplifier, error) {
+	if securityBits < 1 {
+		return nil, fmt.Errorf("security parameter must be positive, got %d", securityBits)
 	}
+
+	return &PrivacyAmplifier{
+		method:       method,
+		securityBits: securityBits,
+	}, nil
+}
+
+// SecurityParameter returns the number of bits the amplifier sacrifices for security
+func (pa *PrivacyAmplifier) SecurityParameter() int {
+	return pa.securityBits
+}
+
+// MaxAmplifiableLength returns the longest key Amplify will produce from keyLength
+// bits when the given fraction of them has leaked. A leakage above 1 leaves no
+// entropy; it is never treated as below 0, so the result never exceeds keyLength.
+func (pa *PrivacyAmplifier) MaxAmplifiableLength(keyLength int, informationLeakage float64) int {
+	leakedBits := int(min(max(informationLeakage, 0), 1) * float64(keyLength))
+	return max(keyLength-leakedBits-pa.securityBits, 0)
+}
+
+// SecureKeyLength is CalculateSecureKeyLength with the amplifier's security parameter
+func (pa *PrivacyAmplifier) SecureKeyLength(rawKeyLength int, qber float64, disclosedBits int) int {
+	return CalculateSecureKeyLength(rawKeyLength, qber, disclosedBits, pa.securityBits)
 }
 
 // Amplify performs privacy amplification to compress the key and remove eavesdropper knowledge
@@ -53,22 +99,25 @@ func (pa *PrivacyAmplifier) Amplify(key []quantum.Bit, informationLeakage float6
 		return nil, fmt.Errorf("target length must be positive")
 	}
 
+	if math.IsNaN(informationLeakage) || informationLeakage < 0 {
+		return nil, fmt.Errorf("information leakage must not be negative, got %v", informationLeakage)
+	}
+
 	// Calculate secure key length using leftover hash lemma
 	// Secure length = Original length - Information leakage - Security parameter
-	securityParameter := 64 // bits (standard security parameter)
-	leakedBits := int(informationLeakage * float64(len(key)))
-	maxSecureLength := len(key) - leakedBits - securityParameter
+	maxSecureLength := pa.MaxAmplifiableLength(len(key), informationLeakage)
 
 	if maxSecureLength < targetLength {
-		return nil, fmt.Errorf("cannot generate secure key of length %d: max secure length is %d bits",
-			targetLength, maxSecureLength)
+		return nil, fmt.Errorf("%w: cannot generate secure key of length %d: max secure length is %d bits",
+			ErrEntropyExceeded, targetLength, maxSecureLength)
 	}
 
 	// Convert bits to bytes for hashing
 	keyBytes := quantum.BitsToBytes(key)
 
 	// Apply universal hash function (cryptographic hash as approximation)
-	// If we need more bits, apply hash expansion
+	// If we need more bits, apply hash expansion; the check above keeps the
+	// expanded output within the input's entropy
 	finalKey := make([]byte, 0)
 	counter := 0
 
@@ -132,39 +181,76 @@ func (tuh *TwoUniversalHash) Hash(x uint64) uint64 {
 	return (tuh.a*x + tuh.b) % tuh.p
 }
 
-// AmplifyWithUniversalHash performs privacy amplification using 2-universal hashing
+// AmplifyWithUniversalHash performs privacy amplification with one Toeplitz hash
+// over the whole key, as AmplifyWithToeplitz does, with the matrix seed expanded
+// from seed1 and seed2 by SHAKE-128. An expanded seed is only pseudorandom, so
+// the 2-universal guarantee holds computationally; pass a truly random seed to
+// AmplifyWithToeplitz for information-theoretic security. The seeds are encoded
+// big-endian, so the output is the same on every platform. It fails if the key
+// is shorter than targetLength bits.
 func (pa *PrivacyAmplifier) AmplifyWithUniversalHash(key []quantum.Bit, seed1, seed2 uint64, targetLength int) ([]byte, error) {
 	if len(key) == 0 {
 		return nil, fmt.Errorf("input key is empty")
 	}
 
-	hasher := NewTwoUniversalHash(seed1, seed2)
+	if targetLength <= 0 {
+		return nil, fmt.Errorf("target length must be positive")
+	}
 
-	// Convert key to uint64 chunks and hash each chunk
-	keyBytes := quantum.BitsToBytes(key)
-	result := make([]byte, 0)
+	if targetLength > len(key) {
+		return nil, fmt.Errorf("target length %d exceeds key length %d", targetLength, len(key))
+	}
 
-	for i := 0; i < len(keyBytes); i += 8 {
-		chunk := uint64(0)
-		for j := 0; j < 8 && i+j < len(keyBytes); j++ {
-			chunk |= uint64(keyBytes[i+j]) << (j * 8)
-		}
+	var seeds [16]byte
+	binary.BigEndian.PutUint64(seeds[:8], seed1)
+	binary.BigEndian.PutUint64(seeds[8:], seed2)
 
-		hashed := hasher.Hash(chunk)
+	seed := make([]byte, (ToeplitzSeedBits(len(key), targetLength)+7)/8)
+	sha3.ShakeSum128(seed, seeds[:])
 
-		// Convert hash result to bytes
-		for j := 0; j < 8; j++ {
-			result = append(result, byte(hashed>>(j*8)))
-		}
+	return pa.AmplifyWithToeplitz(key, seed, targetLength)
+}
+
+// ToeplitzSeedBits returns the number of seed bits AmplifyWithToeplitz needs
+func ToeplitzSeedBits(keyLength, targetLength int) int {
+	return keyLength + targetLength - 1
+}
+
+// AmplifyWithToeplitz performs privacy amplification with a random Toeplitz matrix,
+// a provably 2-universal hash family. The targetLength × len(key) matrix is defined
+// by the first len(key)+targetLength-1 bits of seed and multiplied against the key over GF(2).
+func (pa *PrivacyAmplifier) AmplifyWithToeplitz(key []quantum.Bit, seed []byte, targetLength int) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("input key is empty")
 	}
 
-	// Truncate to target length
-	targetBytes := (targetLength + 7) / 8
-	if len(result) > targetBytes {
-		result = result[:targetBytes]
+	if targetLength <= 0 {
+		return nil, fmt.Errorf("target length must be positive")
+	}
+
+	if targetLength > len(key) {
+		return nil, fmt.Errorf("target length %d exceeds key length %d", targetLength, len(key))
+	}
+
+	seedBits := ToeplitzSeedBits(len(key), targetLength)
+	if len(seed)*8 < seedBits {
+		return nil, fmt.Errorf("Toeplitz seed too short: need %d bits, got %d", seedBits, len(seed)*8)
+	}
+
+	// The diagonal-constant matrix T[i][j] = s[i-j+n-1] is fully described by its seed
+	s := quantum.BytesToBits(seed, seedBits)
+	n := len(key)
+	result := make([]quantum.Bit, targetLength)
+
+	for i := 0; i < targetLength; i++ {
+		bit := quantum.Zero
+		for j, k := range key {
+			bit ^= s[i-j+n-1] & k
+		}
+		result[i] = bit
 	}
 
-	return result, nil
+	return quantum.BitsToBytes(result), nil
 }
 
 // CalculateSecureKeyLength calculates the maximum secure key length after privacy amplification
@@ -177,7 +263,7 @@ func CalculateSecureKeyLength(rawKeyLength int, qber float64, disclosedBits int,
 
 	// Shannon limit: leaked information ≈ h(QBER) * n
 	// where h is binary entropy function
-	shannonLeakage := binaryEntropy(qber) * float64(rawKeyLength)
+	shannonLeakage := BinaryEntropy(qber) * float64(rawKeyLength)
 
 	totalLeakage := int(shannonLeakage) + disclosedBits
 	secureLength := rawKeyLength - totalLeakage - securityParameter
@@ -189,8 +275,8 @@ func CalculateSecureKeyLength(rawKeyLength int, qber float64, disclosedBits int,
 	return secureLength
 }
 
-// binaryEntropy calculates the binary entropy function H(x) = -x*log2(x) - (1-x)*log2(1-x)
-func binaryEntropy(p float64) float64 {
+// BinaryEntropy calculates the binary entropy function H(x) = -x*log2(x) - (1-x)*log2(1-x)
+func BinaryEntropy(p float64) float64 {
 	if p <= 0 || p >= 1 {
 		return 0
 	}
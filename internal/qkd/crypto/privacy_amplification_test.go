@@ -0,0 +1,273 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// randomSeed returns enough random bytes to seed a Toeplitz matrix
+func randomSeed(r *rand.Rand, keyLength, targetLength int) []byte {
+	seed := make([]byte, (ToeplitzSeedBits(keyLength, targetLength)+7)/8)
+	r.Read(seed)
+	return seed
+}
+
+func TestAmplifyWithToeplitzOutputLength(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pa := NewPrivacyAmplifier(SHA3_256Method)
+
+	for _, tt := range []struct{ keyLength, targetLength int }{
+		{1024, 256},
+		{1000, 127},
+		{64, 64},
+		{9, 1},
+	} {
+		key, _ := injectErrors(r, tt.keyLength, 0)
+
+		out, err := pa.AmplifyWithToeplitz(key, randomSeed(r, tt.keyLength, tt.targetLength), tt.targetLength)
+		if err != nil {
+			t.Fatalf("AmplifyWithToeplitz(%d -> %d) failed: %v", tt.keyLength, tt.targetLength, err)
+		}
+
+		if want := (tt.targetLength + 7) / 8; len(out) != want {
+			t.Errorf("AmplifyWithToeplitz(%d -> %d): expected %d bytes, got %d", tt.keyLength, tt.targetLength, want, len(out))
+		}
+
+		// Padding bits past targetLength must be zero
+		if pad := tt.targetLength % 8; pad != 0 && out[len(out)-1]&(0xFF>>pad) != 0 {
+			t.Errorf("AmplifyWithToeplitz(%d -> %d): padding bits are set", tt.keyLength, tt.targetLength)
+		}
+	}
+}
+
+func TestAmplifyWithToeplitzMatchesMatrixProduct(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	const n, m = 40, 12
+
+	key, _ := injectErrors(r, n, 0)
+	seed := randomSeed(r, n, m)
+	s := quantum.BytesToBits(seed, ToeplitzSeedBits(n, m))
+
+	out, err := NewPrivacyAmplifier(SHA3_256Method).AmplifyWithToeplitz(key, seed, m)
+	if err != nil {
+		t.Fatalf("AmplifyWithToeplitz failed: %v", err)
+	}
+	got := quantum.BytesToBits(out, m)
+
+	for i := 0; i < m; i++ {
+		want := 0
+		for j := 0; j < n; j++ {
+			// Each diagonal of a Toeplitz matrix is constant
+			want ^= int(s[i-j+n-1]) * int(key[j])
+		}
+		if int(got[i]) != want {
+			t.Fatalf("Output bit %d: expected %d, got %d", i, want, got[i])
+		}
+	}
+}
+
+func TestAmplifyWithToeplitzSeedsIndependent(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	pa := NewPrivacyAmplifier(SHA3_256Method)
+	const n, m, trials = 2048, 256, 50
+
+	key, _ := injectErrors(r, n, 0)
+	seed := randomSeed(r, n, m)
+
+	first, _ := pa.AmplifyWithToeplitz(key, seed, m)
+	again, _ := pa.AmplifyWithToeplitz(key, seed, m)
+	if !bytes.Equal(first, again) {
+		t.Fatal("Expected the same seed to produce the same output")
+	}
+
+	// Outputs under independent seeds should differ in about half their bits
+	totalDistance := 0
+	for trial := 0; trial < trials; trial++ {
+		a, _ := pa.AmplifyWithToeplitz(key, randomSeed(r, n, m), m)
+		b, _ := pa.AmplifyWithToeplitz(key, randomSeed(r, n, m), m)
+		if bytes.Equal(a, b) {
+			t.Fatal("Expected different seeds to produce different outputs")
+		}
+		for i := range a {
+			totalDistance += bits.OnesCount8(a[i] ^ b[i])
+		}
+	}
+
+	if mean := float64(totalDistance) / trials; mean < 0.45*m || mean > 0.55*m {
+		t.Errorf("Expected mean Hamming distance near %d bits, got %.1f", m/2, mean)
+	}
+}
+
+func TestAmplifyWithToeplitzRejectsInvalidInput(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	pa := NewPrivacyAmplifier(SHA3_256Method)
+	key, _ := injectErrors(r, 256, 0)
+
+	if _, err := pa.AmplifyWithToeplitz(key, make([]byte, 8), 128); err == nil {
+		t.Error("Expected error for a seed shorter than len(key)+targetLength-1 bits")
+	}
+	if _, err := pa.AmplifyWithToeplitz(key, randomSeed(r, 256, 512), 512); err == nil {
+		t.Error("Expected error when target length exceeds key length")
+	}
+	if _, err := pa.AmplifyWithToeplitz(nil, randomSeed(r, 1, 1), 1); err == nil {
+		t.Error("Expected error for an empty key")
+	}
+}
+
+func TestAmplifyWithUniversalHashOutputLength(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	pa := NewPrivacyAmplifier(SHA3_256Method)
+
+	for _, tt := range []struct{ keyLength, targetLength int }{
+		{1024, 256},
+		{1000, 127},
+		{64, 64},
+		{9, 1},
+	} {
+		key, _ := injectErrors(r, tt.keyLength, 0)
+
+		out, err := pa.AmplifyWithUniversalHash(key, 1, 2, tt.targetLength)
+		if err != nil {
+			t.Fatalf("AmplifyWithUniversalHash(%d -> %d) failed: %v", tt.keyLength, tt.targetLength, err)
+		}
+
+		if want := (tt.targetLength + 7) / 8; len(out) != want {
+			t.Errorf("AmplifyWithUniversalHash(%d -> %d): expected %d bytes, got %d", tt.keyLength, tt.targetLength, want, len(out))
+		}
+
+		if pad := tt.targetLength % 8; pad != 0 && out[len(out)-1]&(0xFF>>pad) != 0 {
+			t.Errorf("AmplifyWithUniversalHash(%d -> %d): padding bits are set", tt.keyLength, tt.targetLength)
+		}
+	}
+}
+
+func TestAmplifyWithUniversalHashDependsOnWholeKey(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	pa := NewPrivacyAmplifier(SHA3_256Method)
+	key, _ := injectErrors(r, 512, 0)
+
+	out, err := pa.AmplifyWithUniversalHash(key, 42, 7, 128)
+	if err != nil {
+		t.Fatalf("AmplifyWithUniversalHash failed: %v", err)
+	}
+
+	again, _ := pa.AmplifyWithUniversalHash(key, 42, 7, 128)
+	if !bytes.Equal(out, again) {
+		t.Error("Expected the same seeds to give the same output")
+	}
+
+	if other, _ := pa.AmplifyWithUniversalHash(key, 42, 8, 128); bytes.Equal(out, other) {
+		t.Error("Expected different seeds to give different output")
+	}
+
+	// Every key bit feeds one matrix product, so flipping the last bit changes the output
+	flipped := append([]quantum.Bit(nil), key...)
+	flipped[len(flipped)-1] ^= 1
+	if changed, _ := pa.AmplifyWithUniversalHash(flipped, 42, 7, 128); bytes.Equal(out, changed) {
+		t.Error("Expected flipping the last key bit to change the output")
+	}
+}
+
+func TestAmplifyWithUniversalHashRejectsShortKey(t *testing.T) {
+	r := rand.New(rand.NewSource(8))
+	pa := NewPrivacyAmplifier(SHA3_256Method)
+	key, _ := injectErrors(r, 100, 0)
+
+	if _, err := pa.AmplifyWithUniversalHash(key, 1, 2, 101); err == nil {
+		t.Error("Expected error when target length exceeds key length")
+	}
+	if _, err := pa.AmplifyWithUniversalHash(nil, 1, 2, 1); err == nil {
+		t.Error("Expected error for an empty key")
+	}
+	if _, err := pa.AmplifyWithUniversalHash(key, 1, 2, 0); err == nil {
+		t.Error("Expected error for a non-positive target length")
+	}
+}
+
+func TestAmplifySecurityParameter(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	key, _ := injectErrors(r, 1024, 0)
+	const leakage = 0.25
+
+	previous := len(key)
+	for _, securityBits := range []int{40, 64, 128} {
+		pa, err := NewPrivacyAmplifierWithSecurity(SHA3_256Method, securityBits)
+		if err != nil {
+			t.Fatalf("NewPrivacyAmplifierWithSecurity(%d) failed: %v", securityBits, err)
+		}
+
+		maxLength := pa.MaxAmplifiableLength(len(key), leakage)
+		if maxLength >= previous {
+			t.Errorf("%d security bits: expected a shorter maximum than %d, got %d", securityBits, previous, maxLength)
+		}
+		previous = maxLength
+
+		if pa.SecureKeyLength(len(key), 0.02, 100) != CalculateSecureKeyLength(len(key), 0.02, 100, securityBits) {
+			t.Errorf("%d security bits: SecureKeyLength disagrees with CalculateSecureKeyLength", securityBits)
+		}
+		if _, err := pa.Amplify(key, leakage, maxLength); err != nil {
+			t.Errorf("%d security bits: expected %d bits to be amplifiable, got %v", securityBits, maxLength, err)
+		}
+		if _, err := pa.Amplify(key, leakage, maxLength+1); err == nil {
+			t.Errorf("%d security bits: expected %d bits to exceed the maximum", securityBits, maxLength+1)
+		}
+	}
+
+	// The same 700-bit target fits with 40 security bits but not with 128
+	weak, _ := NewPrivacyAmplifierWithSecurity(SHA3_256Method, 40)
+	strong, _ := NewPrivacyAmplifierWithSecurity(SHA3_256Method, 128)
+	if _, err := weak.Amplify(key, leakage, 700); err != nil {
+		t.Errorf("Expected 700 bits with 40 security bits, got %v", err)
+	}
+	if _, err := strong.Amplify(key, leakage, 700); err == nil {
+		t.Error("Expected 700 bits to be rejected with 128 security bits")
+	}
+
+	if _, err := NewPrivacyAmplifierWithSecurity(SHA3_256Method, 0); err == nil {
+		t.Error("Expected error for a zero security parameter")
+	}
+	if got := NewPrivacyAmplifier(SHA3_256Method).SecurityParameter(); got != DefaultSecurityBits {
+		t.Errorf("Expected the default amplifier to use %d security bits, got %d", DefaultSecurityBits, got)
+	}
+}
+
+func TestAmplifyRefusesToStretchKey(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	key, _ := injectErrors(r, 128, 0)
+	pa := NewPrivacyAmplifier(SHA512Method)
+
+	// 128 input bits hold at most 64 bits of entropy after the security parameter,
+	// even though one SHA-512 block alone would yield 512
+	for _, target := range []int{65, 128, 512, 4096} {
+		finalKey, err := pa.Amplify(key, 0, target)
+		if !errors.Is(err, ErrEntropyExceeded) {
+			t.Errorf("%d bits: expected ErrEntropyExceeded, got %v", target, err)
+		}
+		if finalKey != nil {
+			t.Errorf("%d bits: expected no key, got %d bytes", target, len(finalKey))
+		}
+	}
+	if _, err := pa.Amplify(key, 0, 64); err != nil {
+		t.Errorf("Expected the full 64-bit budget to be amplifiable, got %v", err)
+	}
+
+	// Leakage outside [0, 1] never enlarges the budget
+	if _, err := pa.Amplify(key, -0.5, 64); err == nil {
+		t.Error("Expected error for negative leakage")
+	}
+	if _, err := pa.Amplify(key, math.NaN(), 64); err == nil {
+		t.Error("Expected error for NaN leakage")
+	}
+	if _, err := pa.Amplify(key, 1.5, 1); !errors.Is(err, ErrEntropyExceeded) {
+		t.Errorf("Expected leakage above 1 to leave no entropy, got %v", err)
+	}
+	if got := pa.MaxAmplifiableLength(128, -1); got > 128 {
+		t.Errorf("Expected at most 128 amplifiable bits, got %d", got)
+	}
+}
@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestWinnowComparedWithCascade(t *testing.T) {
+	const n = 4096
+	const trials = 10
+
+	for _, qber := range []float64{0.05, 0.10} {
+		cascadeDisclosed, winnowDisclosed := 0, 0
+		cascadeResidual, winnowResidual := 0.0, 0.0
+
+		for trial := 0; trial < trials; trial++ {
+			alice, bob := injectErrors(rand.New(rand.NewSource(int64(trial))), n, qber)
+
+			cascade := NewCascadeCorrector(qber)
+			cascade.SeedShuffle(int64(trial))
+			_, disclosed, err := cascade.Correct(alice, bob)
+			if err != nil {
+				t.Fatalf("Cascade failed: %v", err)
+			}
+			cascadeDisclosed += disclosed
+			cascadeResidual += cascade.ResidualErrorRate()
+
+			winnow := NewWinnowCorrector(qber)
+			winnow.SeedShuffle(int64(trial))
+			corrected, disclosed, err := winnow.Correct(alice, bob)
+			if err != nil {
+				t.Fatalf("Winnow failed: %v", err)
+			}
+			if _, rate := VerifyKeyCorrectness(alice, corrected); rate != winnow.ResidualErrorRate() {
+				t.Errorf("Reported residual %.5f does not match actual %.5f", winnow.ResidualErrorRate(), rate)
+			}
+			winnowDisclosed += disclosed
+			winnowResidual += winnow.ResidualErrorRate()
+		}
+
+		t.Logf("QBER %.2f: Cascade disclosed %d (residual %.5f), Winnow disclosed %d (residual %.5f)",
+			qber, cascadeDisclosed/trials, cascadeResidual/trials, winnowDisclosed/trials, winnowResidual/trials)
+
+		// Winnow trades extra disclosure for fewer round trips
+		if winnowDisclosed <= cascadeDisclosed {
+			t.Errorf("QBER %.2f: expected Winnow to disclose more than Cascade (%d vs %d)",
+				qber, winnowDisclosed/trials, cascadeDisclosed/trials)
+		}
+		if winnowResidual/trials > qber/100 {
+			t.Errorf("QBER %.2f: Winnow residual error rate %.5f too high", qber, winnowResidual/trials)
+		}
+		if winnowDisclosed/trials >= n {
+			t.Errorf("QBER %.2f: Winnow disclosed the whole key", qber)
+		}
+	}
+}
+
+func TestWinnowCorrectsSingleErrorPerBlock(t *testing.T) {
+	alice := make([]quantum.Bit, 64)
+	bob := make([]quantum.Bit, 64)
+	bob[17] = quantum.One
+
+	w := NewWinnowCorrector(0.01)
+	corrected, disclosed, err := w.Correct(alice, bob)
+	if err != nil {
+		t.Fatalf("Correct failed: %v", err)
+	}
+	if match, _ := VerifyKeyCorrectness(alice, corrected); !match {
+		t.Error("Expected a single error to be corrected")
+	}
+	if bob[17] != quantum.One {
+		t.Error("Bob's input key was modified")
+	}
+
+	// One parity per block per pass, plus one syndrome for the block holding the error
+	if disclosed <= w.passes {
+		t.Errorf("Expected parities and a syndrome to be disclosed, got %d bits", disclosed)
+	}
+}
+
+func TestNewCorrectorMethods(t *testing.T) {
+	if c, err := NewCorrector("", 0.05); err != nil {
+		t.Errorf("Expected the default method to succeed: %v", err)
+	} else if _, ok := c.(*CascadeCorrector); !ok {
+		t.Errorf("Expected Cascade by default, got %T", c)
+	}
+	if c, err := NewCorrector(WinnowMethod, 0.05); err != nil {
+		t.Errorf("Expected Winnow to succeed: %v", err)
+	} else if _, ok := c.(*WinnowCorrector); !ok {
+		t.Errorf("Expected a WinnowCorrector, got %T", c)
+	}
+	if _, err := NewCorrector("hamming", 0.05); err == nil {
+		t.Error("Expected an unknown method to be rejected")
+	}
+}
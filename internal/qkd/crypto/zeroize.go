@@ -0,0 +1,14 @@
+package crypto
+
+import "github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+
+// Zeroize overwrites b with zeros so key material does not linger in memory
+// after it is no longer needed
+func Zeroize(b []byte) {
+	clear(b)
+}
+
+// ZeroizeBits overwrites a bit string with zeros
+func ZeroizeBits(b []quantum.Bit) {
+	clear(b)
+}
@@ -0,0 +1,29 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestZeroize(t *testing.T) {
+	b := []byte{0xde, 0xad, 0xbe, 0xef}
+	Zeroize(b)
+	for i, v := range b {
+		if v != 0 {
+			t.Errorf("byte %d not zeroed: %#x", i, v)
+		}
+	}
+
+	bits := []quantum.Bit{quantum.One, quantum.Zero, quantum.One}
+	ZeroizeBits(bits)
+	for i, v := range bits {
+		if v != quantum.Zero {
+			t.Errorf("bit %d not zeroed", i)
+		}
+	}
+
+	// Nil slices are a no-op
+	Zeroize(nil)
+	ZeroizeBits(nil)
+}
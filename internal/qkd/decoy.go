@@ -0,0 +1,260 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// DecoyStateConfig configures vacuum + weak decoy-state BB84. Alice sends each
+// pulse at the signal intensity, the decoy intensity or as vacuum; only signal
+// pulses contribute key bits.
+type DecoyStateConfig struct {
+	SignalIntensity   float64 // Mean photon number μ of signal pulses
+	DecoyIntensity    float64 // Mean photon number ν of decoy pulses (ν < μ)
+	SignalProbability float64 // Fraction of pulses sent at the signal intensity
+	DecoyProbability  float64 // Fraction sent at the decoy intensity; the rest are vacuum
+}
+
+// DefaultDecoyStateConfig returns a typical μ = 0.5, ν = 0.1 configuration
+func DefaultDecoyStateConfig() DecoyStateConfig {
+	return DecoyStateConfig{
+		SignalIntensity:   0.5,
+		DecoyIntensity:    0.1,
+		SignalProbability: 0.7,
+		DecoyProbability:  0.2,
+	}
+}
+
+// Validate checks that the intensities and probabilities are consistent
+func (c DecoyStateConfig) Validate() error {
+	if c.DecoyIntensity <= 0 || c.SignalIntensity <= c.DecoyIntensity {
+		return fmt.Errorf("decoy intensities must satisfy 0 < ν < μ, got μ=%.3f ν=%.3f", c.SignalIntensity, c.DecoyIntensity)
+	}
+	if c.SignalProbability <= 0 || c.DecoyProbability <= 0 || c.SignalProbability+c.DecoyProbability >= 1 {
+		return fmt.Errorf("signal and decoy probabilities must be positive and leave room for vacuum pulses")
+	}
+	return nil
+}
+
+// IntensityStatistics are the detection statistics of one intensity class
+type IntensityStatistics struct {
+	Intensity float64
+	Sent      int // Pulses sent at this intensity
+	Detected  int // Pulses that produced a click
+	Sifted    int // Detected pulses measured in Alice's basis
+	Errors    int // Sifted pulses where Bob's outcome differs from Alice's bit
+}
+
+// Gain returns the fraction of pulses that were detected
+func (s IntensityStatistics) Gain() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Detected) / float64(s.Sent)
+}
+
+// ErrorRate returns the error rate of the sifted pulses
+func (s IntensityStatistics) ErrorRate() float64 {
+	if s.Sifted == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Sifted)
+}
+
+// DecoyStatistics groups the statistics of the signal, decoy and vacuum pulses
+type DecoyStatistics struct {
+	Signal IntensityStatistics
+	Decoy  IntensityStatistics
+	Vacuum IntensityStatistics
+}
+
+// DecoyBound is the decoy-state estimate of the single-photon contribution
+type DecoyBound struct {
+	Y0                   float64 // Background (vacuum) yield
+	Y1                   float64 // Lower bound on the single-photon yield
+	Q1                   float64 // Lower bound on the single-photon gain of signal pulses
+	E1                   float64 // Upper bound on the single-photon error rate
+	SinglePhotonFraction float64 // Lower bound on the fraction of detected signals that were single photons
+	SecureFraction       float64 // Fraction of sifted signal bits secret before error correction
+}
+
+// EnableDecoyStates switches the protocol to decoy-state mode; the backend must
+// implement quantum.PulseSource
+func (bb *BB84Protocol) EnableDecoyStates(config DecoyStateConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	bb.decoy = &config
+	return nil
+}
+
+// decoyTransmissionLength scales n so that about n signal pulses are detected
+func (bb *BB84Protocol) decoyTransmissionLength(n int) int {
+	transmittance := 1.0
+	if source, ok := bb.backend.(quantum.PulseSource); ok {
+		transmittance = source.Transmittance()
+	}
+
+	gain := 1 - math.Exp(-bb.decoy.SignalIntensity*transmittance)
+	return int(math.Ceil(float64(n) / (bb.decoy.SignalProbability * gain)))
+}
+
+// chooseIntensities assigns each pulse the signal, decoy or vacuum intensity at random
+func (bb *BB84Protocol) chooseIntensities(n int) ([]float64, error) {
+	const resolution = 1 << 20
+
+	intensities := make([]float64, n)
+	for i := range intensities {
+		r, err := cryptoRandInt(resolution)
+		if err != nil {
+			return nil, err
+		}
+
+		u := float64(r) / resolution
+		switch {
+		case u < bb.decoy.SignalProbability:
+			intensities[i] = bb.decoy.SignalIntensity
+		case u < bb.decoy.SignalProbability+bb.decoy.DecoyProbability:
+			intensities[i] = bb.decoy.DecoyIntensity
+		}
+	}
+
+	return intensities, nil
+}
+
+// sendDecoyPulses transmits Alice's bits as weak coherent pulses of random intensity
+func (bb *BB84Protocol) sendDecoyPulses(ctx context.Context, alice *AliceSession) error {
+	source, ok := bb.backend.(quantum.PulseSource)
+	if !ok {
+		return fmt.Errorf("backend %s does not model weak coherent pulses required for decoy states", bb.backend.Name())
+	}
+
+	intensities, err := bb.chooseIntensities(len(alice.Bits))
+	if err != nil {
+		return err
+	}
+
+	qubits, detected, err := source.SendPulses(ctx, alice.Bits, alice.Bases, intensities)
+	if err != nil {
+		return fmt.Errorf("failed to send pulses: %w", err)
+	}
+
+	alice.Qubits = qubits
+	alice.Intensities = intensities
+	alice.Detected = detected
+
+	return nil
+}
+
+// isSignalPulse reports whether pulse i may contribute a key bit
+func (bb *BB84Protocol) isSignalPulse(alice *AliceSession, i int) bool {
+	if alice.Detected != nil && !alice.Detected[i] {
+		return false
+	}
+	if bb.decoy != nil && alice.Intensities != nil && alice.Intensities[i] != bb.decoy.SignalIntensity {
+		return false
+	}
+	return true
+}
+
+// DecoyStatistics tallies detections and errors per intensity class once Alice
+// has announced each pulse's intensity. Decoy and vacuum outcomes are disclosed
+// in full; they never enter the key.
+func (bb *BB84Protocol) DecoyStatistics(alice *AliceSession, bob *BobSession) (*DecoyStatistics, error) {
+	if bb.decoy == nil || alice.Intensities == nil || alice.Detected == nil {
+		return nil, fmt.Errorf("decoy-state mode is not enabled")
+	}
+	if len(alice.Bits) != len(bob.Measurements) {
+		return nil, fmt.Errorf("alice and bob must have same number of pulses")
+	}
+
+	stats := &DecoyStatistics{
+		Signal: IntensityStatistics{Intensity: bb.decoy.SignalIntensity},
+		Decoy:  IntensityStatistics{Intensity: bb.decoy.DecoyIntensity},
+	}
+
+	for i, intensity := range alice.Intensities {
+		class := &stats.Vacuum
+		switch intensity {
+		case bb.decoy.SignalIntensity:
+			class = &stats.Signal
+		case bb.decoy.DecoyIntensity:
+			class = &stats.Decoy
+		}
+
+		class.Sent++
+		if !alice.Detected[i] {
+			continue
+		}
+		class.Detected++
+
+		if alice.Bases[i] != bob.Bases[i] {
+			continue
+		}
+		class.Sifted++
+		if alice.Bits[i] != bob.Measurements[i].MeasuredBit {
+			class.Errors++
+		}
+	}
+
+	return stats, nil
+}
+
+// EstimateSecureFractionWithDecoy bounds the single-photon yield and error rate
+// from the vacuum + weak decoy statistics (Ma, Qi, Zhao and Lo, 2005):
+//
+//	Y1 ≥ μ/(μν − ν²) · (Qν·e^ν − Qμ·e^μ·ν²/μ² − (μ² − ν²)/μ² · Y0)
+//	e1 ≤ (Eν·Qν·e^ν − e0·Y0) / (Y1·ν)
+//
+// and returns the fraction of sifted signal bits that are secret, Q1/Qμ · (1 − h(e1))
+func (bb *BB84Protocol) EstimateSecureFractionWithDecoy(stats *DecoyStatistics) (*DecoyBound, error) {
+	mu, nu := stats.Signal.Intensity, stats.Decoy.Intensity
+	if stats.Signal.Sent == 0 || stats.Decoy.Sent == 0 || stats.Vacuum.Sent == 0 {
+		return nil, fmt.Errorf("decoy estimate needs signal, decoy and vacuum pulses")
+	}
+
+	qMu, qNu := stats.Signal.Gain(), stats.Decoy.Gain()
+	if qMu == 0 {
+		return nil, fmt.Errorf("no signal pulses were detected")
+	}
+
+	y0 := stats.Vacuum.Gain()
+	e0 := 0.5 // Background clicks are uncorrelated with Alice's bit
+	if stats.Vacuum.Sifted > 0 {
+		e0 = stats.Vacuum.ErrorRate()
+	}
+
+	y1 := mu / (mu*nu - nu*nu) * (qNu*math.Exp(nu) - qMu*math.Exp(mu)*nu*nu/(mu*mu) - (mu*mu-nu*nu)/(mu*mu)*y0)
+	if y1 <= 0 {
+		return nil, fmt.Errorf("decoy statistics do not bound the single-photon yield")
+	}
+
+	e1 := (stats.Decoy.ErrorRate()*qNu*math.Exp(nu) - e0*y0) / (y1 * nu)
+	e1 = math.Max(0, math.Min(0.5, e1))
+
+	q1 := y1 * mu * math.Exp(-mu)
+	single := math.Min(1, q1/qMu)
+
+	return &DecoyBound{
+		Y0:                   y0,
+		Y1:                   y1,
+		Q1:                   q1,
+		E1:                   e1,
+		SinglePhotonFraction: single,
+		SecureFraction:       single * (1 - crypto.BinaryEntropy(e1)),
+	}, nil
+}
+
+// DecoySecureKeyLength is the decoy-state counterpart of crypto.CalculateSecureKeyLength:
+// only single-photon signal bits count, less their phase-error entropy and the disclosed bits
+func DecoySecureKeyLength(rawKeyLength int, bound *DecoyBound, disclosedBits int, securityParameter int) int {
+	secureLength := int(bound.SecureFraction*float64(rawKeyLength)) - disclosedBits - securityParameter
+	if secureLength < 0 {
+		return 0
+	}
+	return secureLength
+}
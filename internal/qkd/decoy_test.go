@@ -0,0 +1,166 @@
+package qkd
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// lossyBackend returns a simulator modelling roughly 10 dB of channel loss
+func lossyBackend() *quantum.SimulatorBackend {
+	backend := quantum.NewSimulatorBackend(true, 0.02)
+	backend.SetChannelLoss(0.1, 1e-5)
+	return backend
+}
+
+func TestEstimateSecureFractionWithDecoyAnalytic(t *testing.T) {
+	const eta, y0, ed = 0.1, 1e-5, 0.02
+	config := DefaultDecoyStateConfig()
+	bb84 := NewBB84Protocol(lossyBackend(), 128)
+	bb84.EnableDecoyStates(config)
+
+	// Expected detection statistics of a lossy channel with misalignment error ed
+	class := func(intensity float64) IntensityStatistics {
+		const sent = 100000000
+		gain := y0 + 1 - math.Exp(-eta*intensity)
+		errorRate := (0.5*y0 + ed*(1-math.Exp(-eta*intensity))) / gain
+		detected := int(gain * sent)
+		return IntensityStatistics{
+			Intensity: intensity,
+			Sent:      sent,
+			Detected:  detected,
+			Sifted:    detected / 2,
+			Errors:    int(errorRate * float64(detected/2)),
+		}
+	}
+
+	bound, err := bb84.EstimateSecureFractionWithDecoy(&DecoyStatistics{
+		Signal: class(config.SignalIntensity),
+		Decoy:  class(config.DecoyIntensity),
+		Vacuum: class(0),
+	})
+	if err != nil {
+		t.Fatalf("EstimateSecureFractionWithDecoy failed: %v", err)
+	}
+
+	// The bound must not exceed the true single-photon yield, and is tight for small ν
+	if bound.Y1 > eta+y0 || bound.Y1 < 0.9*eta {
+		t.Errorf("Expected Y1 just below %.4f, got %.4f", eta+y0, bound.Y1)
+	}
+	if bound.E1 < ed || bound.E1 > 2*ed {
+		t.Errorf("Expected e1 bound slightly above %.2f, got %.4f", ed, bound.E1)
+	}
+	if bound.SinglePhotonFraction <= 0 || bound.SinglePhotonFraction >= 1 {
+		t.Errorf("Expected a single-photon fraction in (0, 1), got %.3f", bound.SinglePhotonFraction)
+	}
+}
+
+func TestDecoyKeyLengthBelowNaiveWithLoss(t *testing.T) {
+	bb84 := NewBB84Protocol(lossyBackend(), 2048)
+	if err := bb84.EnableDecoyStates(DefaultDecoyStateConfig()); err != nil {
+		t.Fatalf("EnableDecoyStates failed: %v", err)
+	}
+
+	alice, err := bb84.AliceGenerateQubits(context.Background())
+	if err != nil {
+		t.Fatalf("AliceGenerateQubits failed: %v", err)
+	}
+	bob, err := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+	if err != nil {
+		t.Fatalf("BobMeasureQubits failed: %v", err)
+	}
+
+	sifted, err := bb84.BasisReconciliation(alice, bob)
+	if err != nil {
+		t.Fatalf("BasisReconciliation failed: %v", err)
+	}
+
+	// Lossy channel: far fewer sifted bits than pulses sent
+	if eff := sifted.Efficiency(0); eff > 0.05 {
+		t.Errorf("Expected a low sifting efficiency over a lossy channel, got %.3f", eff)
+	}
+
+	stats, err := bb84.DecoyStatistics(alice, bob)
+	if err != nil {
+		t.Fatalf("DecoyStatistics failed: %v", err)
+	}
+	if stats.Signal.Sifted != len(sifted.AliceKey) {
+		t.Errorf("Expected only sifted signal pulses in the key: %d vs %d", stats.Signal.Sifted, len(sifted.AliceKey))
+	}
+
+	bound, err := bb84.EstimateSecureFractionWithDecoy(stats)
+	if err != nil {
+		t.Fatalf("EstimateSecureFractionWithDecoy failed: %v", err)
+	}
+
+	n := len(sifted.AliceKey)
+	_, qber := crypto.VerifyKeyCorrectness(sifted.AliceKey, sifted.BobKey)
+	naive := crypto.CalculateSecureKeyLength(n, qber, 0, 64)
+	decoy := DecoySecureKeyLength(n, bound, 0, 64)
+
+	if decoy >= naive {
+		t.Errorf("Expected the decoy-bounded key length (%d) to be below the naive length (%d)", decoy, naive)
+	}
+	if decoy <= 0 {
+		t.Errorf("Expected a positive decoy-bounded key length, got %d (bound %+v)", decoy, bound)
+	}
+}
+
+func TestDecoyStatesRequirePulseSource(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewIdealBackend(), 128)
+	bb84.EnableDecoyStates(DefaultDecoyStateConfig())
+
+	if _, err := bb84.AliceGenerateQubits(context.Background()); err == nil {
+		t.Error("Expected an error for a backend without weak coherent pulses")
+	}
+}
+
+func TestDecoyStateConfigValidate(t *testing.T) {
+	for _, config := range []DecoyStateConfig{
+		{SignalIntensity: 0.1, DecoyIntensity: 0.5, SignalProbability: 0.7, DecoyProbability: 0.2},
+		{SignalIntensity: 0.5, DecoyIntensity: 0, SignalProbability: 0.7, DecoyProbability: 0.2},
+		{SignalIntensity: 0.5, DecoyIntensity: 0.1, SignalProbability: 0.8, DecoyProbability: 0.2},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+}
+
+func TestSessionManagerDecoyStates(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(true, 0.03))
+	config := DefaultDecoyStateConfig()
+	if err := sm.SetDecoyStates(&config); err != nil {
+		t.Fatalf("SetDecoyStates failed: %v", err)
+	}
+
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	key, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchangeWithPostProcessing failed: %v", err)
+	}
+	if key.KeyLength != 512 {
+		t.Errorf("Expected a 512-bit key, got %d", key.KeyLength)
+	}
+
+	// Decoy outcomes are announced but never counted as key leakage
+	ledger, _ := sm.GetDisclosures(session.SessionID)
+	found := false
+	for _, entry := range ledger.Entries {
+		if entry.Kind == "decoy_bits" {
+			found = true
+			if entry.LeaksKey {
+				t.Error("Decoy bits must not count as key leakage")
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected decoy disclosures in the ledger")
+	}
+}
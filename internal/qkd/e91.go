@@ -0,0 +1,228 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// CHSHClassicalBound is the largest |S| achievable by local hidden variables
+const CHSHClassicalBound = 2.0
+
+// e91AliceAngles and e91BobAngles are the polarizer settings of each party.
+// Alice's second and third settings match Bob's first and second and produce key
+// bits; Alice's {0, π/4} against Bob's {π/8, 3π/8} are used for the CHSH test.
+var (
+	e91AliceAngles = [3]float64{0, math.Pi / 8, math.Pi / 4}
+	e91BobAngles   = [3]float64{math.Pi / 8, math.Pi / 4, 3 * math.Pi / 8}
+)
+
+// E91Protocol implements the Ekert (E91) entanglement-based QKD protocol
+type E91Protocol struct {
+	backend       quantum.QuantumBackend
+	keyLength     int
+	qberThreshold float64 // Quantum Bit Error Rate threshold (typically 11%)
+	chshThreshold float64 // Minimum |S| required to accept the key
+}
+
+// NewE91Protocol creates a new E91 protocol instance
+func NewE91Protocol(backend quantum.QuantumBackend, keyLength int) *E91Protocol {
+	return &E91Protocol{
+		backend:       backend,
+		keyLength:     keyLength,
+		qberThreshold: 0.11,
+		chshThreshold: CHSHClassicalBound,
+	}
+}
+
+// Name returns the protocol identifier
+func (e *E91Protocol) Name() string {
+	return "e91"
+}
+
+// QBERThreshold returns the QBER above which the key is rejected
+func (e *E91Protocol) QBERThreshold() float64 {
+	return e.qberThreshold
+}
+
+// TransmissionLength returns the number of entangled pairs distributed for the target key length
+func (e *E91Protocol) TransmissionLength() int {
+	return e.keyLength * 6 // 2 of 9 setting combinations yield key bits
+}
+
+// EntangledPairs holds both parties' setting choices and outcomes for each pair
+type EntangledPairs struct {
+	AliceSettings []int // Index into e91AliceAngles
+	BobSettings   []int // Index into e91BobAngles
+	AliceResults  []quantum.Bit
+	BobResults    []quantum.Bit
+}
+
+// DistributePairs measures TransmissionLength entangled pairs at randomly chosen settings
+func (e *E91Protocol) DistributePairs(ctx context.Context) (*EntangledPairs, error) {
+	source, ok := e.backend.(quantum.EntanglementSource)
+	if !ok {
+		return nil, fmt.Errorf("backend %s does not support entangled pairs", e.backend.Name())
+	}
+
+	n := e.TransmissionLength()
+	pairs := &EntangledPairs{
+		AliceSettings: make([]int, n),
+		BobSettings:   make([]int, n),
+	}
+	aliceAngles := make([]float64, n)
+	bobAngles := make([]float64, n)
+
+	for i := 0; i < n; i++ {
+		a, err := cryptoRandInt(len(e91AliceAngles))
+		if err != nil {
+			return nil, err
+		}
+		b, err := cryptoRandInt(len(e91BobAngles))
+		if err != nil {
+			return nil, err
+		}
+
+		pairs.AliceSettings[i], pairs.BobSettings[i] = a, b
+		aliceAngles[i], bobAngles[i] = e91AliceAngles[a], e91BobAngles[b]
+	}
+
+	aliceResults, bobResults, err := source.MeasureEntangledPairs(ctx, aliceAngles, bobAngles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure entangled pairs: %w", err)
+	}
+
+	pairs.AliceResults = aliceResults
+	pairs.BobResults = bobResults
+
+	return pairs, nil
+}
+
+// isKeySetting reports whether Alice's and Bob's settings share the same angle
+func isKeySetting(aliceSetting, bobSetting int) bool {
+	return e91AliceAngles[aliceSetting] == e91BobAngles[bobSetting]
+}
+
+// CalculateCHSH returns S = E(a1,b1) - E(a1,b3) + E(a3,b1) + E(a3,b3) over the
+// test settings. Quantum mechanics allows |S| up to 2√2; local models are bounded by 2.
+func (e *E91Protocol) CalculateCHSH(pairs *EntangledPairs) (float64, error) {
+	var agree, total [3][3]int
+	for i := range pairs.AliceResults {
+		a, b := pairs.AliceSettings[i], pairs.BobSettings[i]
+		total[a][b]++
+		if pairs.AliceResults[i] == pairs.BobResults[i] {
+			agree[a][b]++
+		}
+	}
+
+	correlation := func(a, b int) (float64, error) {
+		if total[a][b] == 0 {
+			return 0, fmt.Errorf("no measurements for settings (%d, %d)", a, b)
+		}
+		return float64(2*agree[a][b]-total[a][b]) / float64(total[a][b]), nil
+	}
+
+	s := 0.0
+	for _, term := range chshTerms {
+		c, err := correlation(term.alice, term.bob)
+		if err != nil {
+			return 0, err
+		}
+		s += term.sign * c
+	}
+
+	return s, nil
+}
+
+// SiftEntangledKey keeps the outcomes of pairs measured at matching angles
+func (e *E91Protocol) SiftEntangledKey(pairs *EntangledPairs) *SiftedKey {
+	sifted := &SiftedKey{
+		AliceKey:  make([]quantum.Bit, 0),
+		BobKey:    make([]quantum.Bit, 0),
+		Indices:   make([]int, 0),
+		RawLength: len(pairs.AliceResults),
+	}
+
+	for i := range pairs.AliceResults {
+		if isKeySetting(pairs.AliceSettings[i], pairs.BobSettings[i]) {
+			sifted.AliceKey = append(sifted.AliceKey, pairs.AliceResults[i])
+			sifted.BobKey = append(sifted.BobKey, pairs.BobResults[i])
+			sifted.Indices = append(sifted.Indices, i)
+		}
+	}
+
+	return sifted
+}
+
+// PerformKeyExchange executes the complete E91 protocol between Alice and Bob
+func (e *E91Protocol) PerformKeyExchange(ctx context.Context) (*KeyExchangeResult, error) {
+	result := &KeyExchangeResult{}
+
+	// Step 1: Distribute and measure entangled pairs
+	pairs, err := e.DistributePairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Announce settings and keep matching-angle outcomes
+	sifted := e.SiftEntangledKey(pairs)
+	result.RawKeyLength = len(sifted.AliceKey)
+	result.SiftingEfficiency = sifted.Efficiency(sifted.RawLength)
+
+	if result.RawKeyLength == 0 {
+		return nil, fmt.Errorf("no matching settings found - sifted key is empty")
+	}
+
+	// Step 3: Bell test on the publicly announced test-setting outcomes
+	s, err := e.CalculateCHSH(pairs)
+	if err != nil {
+		return nil, fmt.Errorf("CHSH test failed: %w", err)
+	}
+	result.CHSHValue = s
+
+	if math.Abs(s) < e.chshThreshold {
+		result.Secure = false
+		result.Message = fmt.Sprintf("INSECURE: CHSH value |S| = %.3f does not violate the Bell inequality (bound %.1f). Possible eavesdropping detected!",
+			math.Abs(s), e.chshThreshold)
+		return result, nil
+	}
+
+	// Step 4: Error rate of the sifted key
+	errors := 0
+	for i := range sifted.AliceKey {
+		if sifted.AliceKey[i] != sifted.BobKey[i] {
+			errors++
+		}
+	}
+	result.QBER = float64(errors) / float64(len(sifted.AliceKey))
+
+	if result.QBER > e.qberThreshold {
+		result.Secure = false
+		result.Message = fmt.Sprintf("INSECURE: QBER (%.2f%%) exceeds threshold (%.2f%%). Possible eavesdropping detected!",
+			result.QBER*100, e.qberThreshold*100)
+		return result, nil
+	}
+
+	if len(sifted.AliceKey) < e.keyLength {
+		result.Secure = false
+		result.Message = fmt.Sprintf("Insufficient key material: got %d bits, need %d bits",
+			len(sifted.AliceKey), e.keyLength)
+		return result, nil
+	}
+
+	if errors > 0 {
+		result.Secure = false
+		result.Message = "Key mismatch detected after sifting"
+		return result, nil
+	}
+
+	key := sifted.AliceKey[:e.keyLength]
+	result.Key = quantum.BitsToBytes(key)
+	result.FinalKeyLength = len(key)
+	result.Secure = true
+	result.Message = fmt.Sprintf("Secure key generated successfully! CHSH S = %.3f", s)
+
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package qkd
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestE91Noiseless(t *testing.T) {
+	e91 := NewE91Protocol(quantum.NewSimulatorBackend(false, 0.0), 2048)
+
+	result, err := e91.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("PerformKeyExchange failed: %v", err)
+	}
+
+	if math.Abs(result.CHSHValue-2*math.Sqrt2) > 0.15 {
+		t.Errorf("Expected S ≈ 2√2 on a noiseless channel, got %.3f", result.CHSHValue)
+	}
+
+	if !result.Secure || result.QBER != 0 {
+		t.Fatalf("Expected a secure, error-free key, got %+v", result)
+	}
+
+	if result.FinalKeyLength != 2048 || len(result.Key) != 256 {
+		t.Errorf("Expected a 2048-bit key, got %d bits (%d bytes)", result.FinalKeyLength, len(result.Key))
+	}
+}
+
+func TestE91HeavyNoiseFailsBellTest(t *testing.T) {
+	e91 := NewE91Protocol(quantum.NewSimulatorBackend(true, 0.5), 2048)
+
+	result, err := e91.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("PerformKeyExchange failed: %v", err)
+	}
+
+	if math.Abs(result.CHSHValue) >= CHSHClassicalBound {
+		t.Errorf("Expected |S| below the classical bound with heavy noise, got %.3f", result.CHSHValue)
+	}
+
+	if result.Secure || result.Key != nil {
+		t.Errorf("Expected the session to be marked insecure without a key, got %+v", result)
+	}
+}
+
+func TestE91SiftingEfficiency(t *testing.T) {
+	e91 := NewE91Protocol(quantum.NewSimulatorBackend(false, 0.0), 1024)
+
+	pairs, err := e91.DistributePairs(context.Background())
+	if err != nil {
+		t.Fatalf("DistributePairs failed: %v", err)
+	}
+
+	// Two of the nine equally likely setting combinations share an angle
+	if eff := e91.SiftEntangledKey(pairs).Efficiency(0); math.Abs(eff-2.0/9.0) > 0.03 {
+		t.Errorf("Expected sifting efficiency ~%.3f, got %.3f", 2.0/9.0, eff)
+	}
+}
+
+func TestE91RequiresEntanglementSource(t *testing.T) {
+	e91 := NewE91Protocol(quantum.NewIdealBackend(), 128)
+
+	if _, err := e91.PerformKeyExchange(context.Background()); err == nil {
+		t.Error("Expected an error for a backend without entangled pairs")
+	}
+}
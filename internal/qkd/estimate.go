@@ -0,0 +1,54 @@
+package qkd
+
+import (
+	"math"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+)
+
+// EstimateFeasibility predicts what a post-processed BB84 exchange of
+// requestedLength bits can yield over a channel with QBER backendNoise, sending
+// requestedLength*oversampling qubits. An oversampling of 0 uses the factor the
+// post-processing budget recommends, or DefaultOversamplingFactor if none suffices.
+// Nothing is transmitted; the estimate uses the expected sift rate and charges
+// error correction at the planned Cascade efficiency.
+func EstimateFeasibility(backendNoise float64, requestedLength, oversampling int) *qkd.FeasibilityEstimate {
+	bb := NewBB84Protocol(nil, requestedLength)
+	estimate := &qkd.FeasibilityEstimate{
+		NoiseLevel:      backendNoise,
+		RequestedLength: requestedLength,
+	}
+
+	budget := PostProcessingBudget{
+		ExpectedQBER:      backendNoise,
+		SecurityParameter: DefaultSecurityParameter,
+		MaxQubits:         DefaultMaxRawQubits,
+	}
+	if length := bb.PostProcessingTransmissionLength(budget); length > 0 {
+		estimate.RecommendedOversampling = (length + requestedLength - 1) / requestedLength
+	}
+
+	if oversampling <= 0 {
+		oversampling = estimate.RecommendedOversampling
+		if oversampling == 0 {
+			oversampling = DefaultOversamplingFactor
+		}
+	}
+	estimate.Oversampling = oversampling
+	estimate.RawQubits = requestedLength * oversampling
+
+	sifted := int(float64(estimate.RawQubits) * budgetSiftRate)
+	sampled := int(math.Max(float64(sifted)*bb.sampleSize, 1))
+	remaining := sifted - sampled
+	corrected := int(math.Ceil(correctionEfficiency(crypto.CascadeMethod) * crypto.BinaryEntropy(backendNoise) * float64(remaining)))
+
+	estimate.ExpectedSiftedLength = sifted
+	estimate.ExpectedDisclosed = sampled + corrected + DefaultConfirmTagBits
+	if backendNoise <= bb.QBERThreshold() && remaining > 0 {
+		estimate.MaxSecureLength = crypto.CalculateSecureKeyLength(remaining, backendNoise, corrected+DefaultConfirmTagBits, DefaultSecurityParameter)
+	}
+	estimate.Feasible = estimate.MaxSecureLength >= requestedLength
+
+	return estimate
+}
@@ -0,0 +1,38 @@
+package qkd
+
+import "testing"
+
+func TestEstimateFeasibilityLowNoise(t *testing.T) {
+	for _, noise := range []float64{0, 0.01, 0.03} {
+		estimate := EstimateFeasibility(noise, 256, 0)
+		if !estimate.Feasible || estimate.MaxSecureLength < 256 {
+			t.Errorf("noise %.2f: expected a feasible 256-bit key, got %+v", noise, estimate)
+		}
+		if estimate.RawQubits != 256*estimate.Oversampling || estimate.ExpectedSiftedLength != estimate.RawQubits/2 {
+			t.Errorf("noise %.2f: inconsistent transmission estimate %+v", noise, estimate)
+		}
+	}
+}
+
+func TestEstimateFeasibilityHighNoise(t *testing.T) {
+	estimate := EstimateFeasibility(0.15, 256, 0)
+	if estimate.Feasible || estimate.MaxSecureLength != 0 {
+		t.Errorf("Expected 15%% noise to be infeasible, got %+v", estimate)
+	}
+	if estimate.RecommendedOversampling != 0 || estimate.Oversampling != DefaultOversamplingFactor {
+		t.Errorf("Expected no recommendation and the default oversampling, got %+v", estimate)
+	}
+}
+
+func TestEstimateFeasibilityOversampling(t *testing.T) {
+	// Too few qubits for 256 bits even on a noiseless channel
+	if estimate := EstimateFeasibility(0, 256, 2); estimate.Feasible {
+		t.Errorf("Expected oversampling 2 to be infeasible, got %+v", estimate)
+	}
+
+	low := EstimateFeasibility(0.03, 256, 4)
+	high := EstimateFeasibility(0.03, 256, 16)
+	if high.MaxSecureLength <= low.MaxSecureLength || high.ExpectedDisclosed <= low.ExpectedDisclosed {
+		t.Errorf("Expected more oversampling to give a longer key and disclose more: %+v vs %+v", low, high)
+	}
+}
@@ -0,0 +1,192 @@
+package qkd
+
+import (
+	"encoding/base64"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// The ETSI GS QKD 014 key delivery API lets Secure Application Entities (SAEs)
+// fetch keys from this service acting as their Key Management Entity (KME). The
+// master SAE requests keys with enc_keys, and the slave SAE retrieves the same
+// keys by ID with dec_keys. Each stored key shared by the two SAEs as Alice and
+// Bob is delivered at most once, truncated to the requested size.
+
+// ETSI GS QKD 014 limits reported by the status endpoint
+const (
+	ETSIKMEID             = "go-okd"
+	ETSIDefaultKeySize    = 256
+	ETSIMinKeySize        = 8
+	ETSIMaxKeySize        = 4096
+	ETSIMaxKeysPerRequest = 128
+	ETSIMaxKeyCount       = 100000
+)
+
+// etsiAvailableKeys returns the undelivered, unexpired keys of sessions between
+// the two SAEs, oldest first. Keys already retrieved or consumed over the REST
+// API are not offered. The caller must hold the mutex.
+func (sm *SessionManager) etsiAvailableKeys(masterSAE, slaveSAE string, now time.Time) ([]*qkd.QuantumKey, error) {
+	sessions, err := sm.store.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*qkd.QuantumKey
+	for _, session := range sessions {
+		paired := (session.AliceID == masterSAE && session.BobID == slaveSAE) ||
+			(session.AliceID == slaveSAE && session.BobID == masterSAE)
+		if !paired {
+			continue
+		}
+
+		sessionKeys, err := sm.store.KeysForSession(session.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sessionKeys {
+			if key.IsActive && key.UsedAt == nil && len(key.RetrievedBy) == 0 && now.Before(key.ExpiresAt) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].GeneratedAt.Before(keys[j].GeneratedAt)
+	})
+	return keys, nil
+}
+
+// ETSIStatus reports how many keys the master SAE can request for the slave SAE
+func (sm *SessionManager) ETSIStatus(masterSAE, slaveSAE string) (*qkd.ETSIStatus, error) {
+	masterSAE = sm.normalizeID(masterSAE)
+	slaveSAE = sm.normalizeID(slaveSAE)
+	if masterSAE == "" || slaveSAE == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	keys, err := sm.etsiAvailableKeys(masterSAE, slaveSAE, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &qkd.ETSIStatus{
+		SourceKMEID:      ETSIKMEID,
+		TargetKMEID:      ETSIKMEID,
+		MasterSAEID:      masterSAE,
+		SlaveSAEID:       slaveSAE,
+		KeySize:          ETSIDefaultKeySize,
+		StoredKeyCount:   len(keys),
+		MaxKeyCount:      ETSIMaxKeyCount,
+		MaxKeyPerRequest: ETSIMaxKeysPerRequest,
+		MaxKeySize:       ETSIMaxKeySize,
+		MinKeySize:       ETSIMinKeySize,
+		MaxSAEIDCount:    0,
+	}, nil
+}
+
+// ETSIEncKeys delivers number keys of size bits to the master SAE for use with
+// the slave SAE. The keys are reserved so only that slave can retrieve them.
+func (sm *SessionManager) ETSIEncKeys(masterSAE, slaveSAE string, number, size int) (*qkd.ETSIKeyContainer, error) {
+	if number < 1 || number > ETSIMaxKeysPerRequest {
+		return nil, qkd.ErrInvalidETSIKeyNumber
+	}
+	if size < ETSIMinKeySize || size > ETSIMaxKeySize || size%8 != 0 {
+		return nil, qkd.ErrInvalidETSIKeySize
+	}
+
+	masterSAE = sm.normalizeID(masterSAE)
+	slaveSAE = sm.normalizeID(slaveSAE)
+	if masterSAE == "" || slaveSAE == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	now := time.Now()
+	available, err := sm.etsiAvailableKeys(masterSAE, slaveSAE, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*qkd.QuantumKey
+	for _, key := range available {
+		if len(key.KeyMaterial)*8 >= size {
+			selected = append(selected, key)
+		}
+		if len(selected) == number {
+			break
+		}
+	}
+	if len(selected) < number {
+		return nil, qkd.ErrInsufficientKeys
+	}
+
+	container := &qkd.ETSIKeyContainer{}
+	for _, key := range selected {
+		key.UsedAt = &now
+		key.ETSIMasterSAE = masterSAE
+		key.ETSISlaveSAE = slaveSAE
+		key.ETSIKeySize = size
+		if err := sm.store.SaveKey(key); err != nil {
+			return nil, err
+		}
+
+		container.Keys = append(container.Keys, etsiKey(key))
+	}
+
+	return container, nil
+}
+
+// ETSIDecKeys returns keys previously delivered to the master SAE, by ID, to
+// the slave SAE they were reserved for
+func (sm *SessionManager) ETSIDecKeys(slaveSAE, masterSAE string, keyIDs []uuid.UUID) (*qkd.ETSIKeyContainer, error) {
+	if len(keyIDs) < 1 || len(keyIDs) > ETSIMaxKeysPerRequest {
+		return nil, qkd.ErrInvalidETSIKeyNumber
+	}
+
+	slaveSAE = sm.normalizeID(slaveSAE)
+	masterSAE = sm.normalizeID(masterSAE)
+	if masterSAE == "" || slaveSAE == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	now := time.Now()
+	container := &qkd.ETSIKeyContainer{}
+	for _, keyID := range keyIDs {
+		key, err := sm.store.GetKey(keyID)
+		if err != nil {
+			return nil, err
+		}
+		if key.ETSIMasterSAE != masterSAE || key.ETSISlaveSAE != slaveSAE {
+			return nil, qkd.ErrUnauthorized
+		}
+		if now.After(key.ExpiresAt) {
+			return nil, qkd.ErrKeyExpired
+		}
+		if !key.IsActive {
+			return nil, qkd.ErrKeyInactive
+		}
+
+		container.Keys = append(container.Keys, etsiKey(key))
+	}
+
+	return container, nil
+}
+
+// etsiKey encodes a delivered key truncated to its delivered size
+func etsiKey(key *qkd.QuantumKey) qkd.ETSIKey {
+	return qkd.ETSIKey{
+		KeyID: key.KeyID.String(),
+		Key:   base64.StdEncoding.EncodeToString(key.KeyMaterial[:key.ETSIKeySize/8]),
+	}
+}
@@ -0,0 +1,226 @@
+package qkd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// Default subscriber limits for live session progress streams
+const (
+	DefaultMaxSubscribersPerSession = 16
+	DefaultMaxSubscribers           = 1024
+)
+
+// Session event types
+const (
+	EventStatus   = "status"    // The session changed status
+	EventStage    = "stage"     // A post-processing stage completed
+	EventKeyReady = "key_ready" // The session's key was stored and can be retrieved
+)
+
+// SessionEvent describes a progress update for a session
+type SessionEvent struct {
+	SessionID uuid.UUID         `json:"session_id"`
+	Type      string            `json:"type"`
+	Status    qkd.SessionStatus `json:"status"`
+	Stage     string            `json:"stage,omitempty"` // Completed pipeline stage, for stage events
+	Message   string            `json:"message,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Subscription receives events for a single session until it is closed
+type Subscription struct {
+	Events <-chan SessionEvent
+
+	broker    *EventBroker
+	sessionID uuid.UUID
+	ch        chan SessionEvent
+	once      sync.Once
+}
+
+// Close unsubscribes and frees the subscriber slot. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.unsubscribe(s)
+	})
+}
+
+// EventBroker fans out session events to subscribers, enforcing per-session
+// and global subscriber caps
+type EventBroker struct {
+	mutex         sync.Mutex
+	subscribers   map[uuid.UUID]map[*Subscription]struct{}
+	total         int
+	maxPerSession int
+	maxTotal      int
+}
+
+// NewEventBroker creates a broker with the given subscriber caps
+func NewEventBroker(maxPerSession, maxTotal int) *EventBroker {
+	return &EventBroker{
+		subscribers:   make(map[uuid.UUID]map[*Subscription]struct{}),
+		maxPerSession: maxPerSession,
+		maxTotal:      maxTotal,
+	}
+}
+
+// SetLimits updates the subscriber caps. Existing subscriptions are not affected.
+func (b *EventBroker) SetLimits(maxPerSession, maxTotal int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.maxPerSession = maxPerSession
+	b.maxTotal = maxTotal
+}
+
+// Subscribe registers a subscriber for a session, returning ErrTooManySubscribers
+// when either cap is reached
+func (b *EventBroker) Subscribe(sessionID uuid.UUID) (*Subscription, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.total >= b.maxTotal || len(b.subscribers[sessionID]) >= b.maxPerSession {
+		return nil, qkd.ErrTooManySubscribers
+	}
+
+	ch := make(chan SessionEvent, 16)
+	sub := &Subscription{
+		Events:    ch,
+		broker:    b,
+		sessionID: sessionID,
+		ch:        ch,
+	}
+
+	if b.subscribers[sessionID] == nil {
+		b.subscribers[sessionID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[sessionID][sub] = struct{}{}
+	b.total++
+
+	return sub, nil
+}
+
+// Count returns the number of subscribers for a session
+func (b *EventBroker) Count(sessionID uuid.UUID) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.subscribers[sessionID])
+}
+
+// Publish delivers an event to every subscriber of its session. Slow subscribers
+// whose buffers are full miss the event rather than blocking the protocol.
+func (b *EventBroker) Publish(event SessionEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for sub := range b.subscribers[event.SessionID] {
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// unsubscribe removes a subscription and closes its channel
+func (b *EventBroker) unsubscribe(sub *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	subs := b.subscribers[sub.sessionID]
+	if _, exists := subs[sub]; !exists {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscribers, sub.sessionID)
+	}
+	b.total--
+	close(sub.ch)
+}
+
+// SetSubscriberLimits configures the per-session and global caps on live progress subscribers
+func (sm *SessionManager) SetSubscriberLimits(maxPerSession, maxTotal int) {
+	sm.events.SetLimits(maxPerSession, maxTotal)
+}
+
+// SubscribeEvents subscribes to progress events for an existing session. The
+// session's current status is delivered as the first event.
+func (sm *SessionManager) SubscribeEvents(sessionID uuid.UUID) (*Subscription, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := sm.events.Subscribe(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.ch <- SessionEvent{
+		SessionID: sessionID,
+		Type:      EventStatus,
+		Status:    session.Status,
+		Message:   session.Message,
+		Timestamp: time.Now(),
+	}
+
+	return sub, nil
+}
+
+// EventSubscribers returns the number of live subscribers for a session
+func (sm *SessionManager) EventSubscribers(sessionID uuid.UUID) int {
+	return sm.events.Count(sessionID)
+}
+
+// publishStatus emits a status event for a session
+func (sm *SessionManager) publishStatus(sessionID uuid.UUID, status qkd.SessionStatus, message string) {
+	sm.publishEvent(sessionID, EventStatus, status, message)
+}
+
+// publishEvent emits an event of the given type for a session
+func (sm *SessionManager) publishEvent(sessionID uuid.UUID, eventType string, status qkd.SessionStatus, message string) {
+	sm.events.Publish(SessionEvent{
+		SessionID: sessionID,
+		Type:      eventType,
+		Status:    status,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// publishStage emits a stage event once a post-processing stage completes
+func (sm *SessionManager) publishStage(sessionID uuid.UUID, stage string, pc *PipelineContext) {
+	sm.events.Publish(SessionEvent{
+		SessionID: sessionID,
+		Type:      EventStage,
+		Status:    qkd.SessionInitiating,
+		Stage:     stage,
+		Message:   stageMessage(stage, pc),
+		Timestamp: time.Now(),
+	})
+}
+
+// stageMessage describes a completed post-processing stage
+func stageMessage(stage string, pc *PipelineContext) string {
+	switch stage {
+	case "sift":
+		return fmt.Sprintf("Basis reconciliation complete: %d sifted bits", len(pc.AliceKey))
+	case "estimate":
+		return fmt.Sprintf("QBER estimated: %.2f%%", pc.QBER*100)
+	case "correct":
+		return fmt.Sprintf("Error correction complete: %d errors corrected", pc.ErrorsCorrected)
+	case "amplify":
+		return fmt.Sprintf("Privacy amplification complete: %d-bit key", len(pc.FinalKey)*8)
+	default:
+		return fmt.Sprintf("Stage %s complete", stage)
+	}
+}
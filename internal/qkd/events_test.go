@@ -0,0 +1,73 @@
+package qkd
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+func TestEventBrokerPerSessionCap(t *testing.T) {
+	broker := NewEventBroker(2, 100)
+	sessionID := uuid.New()
+
+	first, err := broker.Subscribe(sessionID)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if _, err := broker.Subscribe(sessionID); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	if _, err := broker.Subscribe(sessionID); err != qkd.ErrTooManySubscribers {
+		t.Fatalf("Expected ErrTooManySubscribers, got %v", err)
+	}
+
+	// Other sessions are unaffected by the per-session cap
+	if _, err := broker.Subscribe(uuid.New()); err != nil {
+		t.Errorf("Expected another session to accept subscribers, got %v", err)
+	}
+
+	first.Close()
+	first.Close()
+
+	if _, err := broker.Subscribe(sessionID); err != nil {
+		t.Errorf("Expected a freed slot to be reusable, got %v", err)
+	}
+}
+
+func TestEventBrokerGlobalCap(t *testing.T) {
+	broker := NewEventBroker(10, 2)
+
+	broker.Subscribe(uuid.New())
+	sub, _ := broker.Subscribe(uuid.New())
+
+	if _, err := broker.Subscribe(uuid.New()); err != qkd.ErrTooManySubscribers {
+		t.Fatalf("Expected ErrTooManySubscribers, got %v", err)
+	}
+
+	sub.Close()
+	if _, err := broker.Subscribe(uuid.New()); err != nil {
+		t.Errorf("Expected a freed slot to be reusable, got %v", err)
+	}
+}
+
+func TestEventBrokerDropsForSlowSubscribers(t *testing.T) {
+	broker := NewEventBroker(1, 1)
+	sessionID := uuid.New()
+	sub, _ := broker.Subscribe(sessionID)
+
+	// Publishing never blocks, even when nobody is reading
+	for i := 0; i < 100; i++ {
+		broker.Publish(SessionEvent{SessionID: sessionID, Type: "status"})
+	}
+
+	sub.Close()
+	received := 0
+	for range sub.Events {
+		received++
+	}
+	if received == 0 || received >= 100 {
+		t.Errorf("Expected a bounded number of buffered events, got %d", received)
+	}
+}
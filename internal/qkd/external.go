@@ -0,0 +1,201 @@
+package qkd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// MaxExternalBasesLength is the largest basis sequence the service will issue in one request
+const MaxExternalBasesLength = 65536
+
+// ExternalBases holds basis choices issued to participants running quantum hardware
+// outside this service. They are kept until the matching measurements are reconciled.
+type ExternalBases struct {
+	SessionID  uuid.UUID
+	AliceBases []quantum.Basis
+	BobBases   []quantum.Basis
+	AliceBits  []quantum.Bit // nil unless the server also generated Alice's bits
+	IssuedAt   time.Time
+}
+
+// GenerateBases issues random bases for Alice and Bob (and optionally Alice's bits)
+// and records them against the session for a later ReconcileExternal call. Only a
+// participant of the session may request them, and a session holds one set at a
+// time: new bases are refused with ErrBasesAlreadyIssued until the outstanding set
+// has been reconciled.
+func (sm *SessionManager) GenerateBases(sessionID uuid.UUID, userID string, length int, includeBits bool) (*ExternalBases, error) {
+	if length < 1 || length > MaxExternalBasesLength {
+		return nil, qkd.ErrInvalidBasesLength
+	}
+	userID = sm.normalizeID(userID)
+	if userID == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
+	aliceBases, err := quantum.SecureRandomBases(length)
+	if err != nil {
+		return nil, err
+	}
+
+	bobBases, err := quantum.SecureRandomBases(length)
+	if err != nil {
+		return nil, err
+	}
+
+	external := &ExternalBases{
+		SessionID:  sessionID,
+		AliceBases: aliceBases,
+		BobBases:   bobBases,
+		IssuedAt:   time.Now(),
+	}
+
+	if includeBits {
+		if external.AliceBits, err = quantum.SecureRandomBits(length); err != nil {
+			return nil, err
+		}
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		crypto.ZeroizeBits(external.AliceBits)
+		return nil, err
+	}
+
+	if !sm.authorizer.CanAccessKey(session, userID) {
+		crypto.ZeroizeBits(external.AliceBits)
+		return nil, qkd.ErrUnauthorized
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		crypto.ZeroizeBits(external.AliceBits)
+		return nil, qkd.ErrSessionExpired
+	}
+
+	if _, outstanding := sm.externalBases[sessionID]; outstanding {
+		crypto.ZeroizeBits(external.AliceBits)
+		return nil, qkd.ErrBasesAlreadyIssued
+	}
+
+	sm.externalBases[sessionID] = external
+
+	return external, nil
+}
+
+// GetExternalBases returns the bases currently issued for a session
+func (sm *SessionManager) GetExternalBases(sessionID uuid.UUID) (*ExternalBases, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	external, exists := sm.externalBases[sessionID]
+	if !exists {
+		return nil, qkd.ErrBasesNotIssued
+	}
+
+	return external, nil
+}
+
+// TakeExternalBases removes and returns the bases issued for a session, so they
+// can be reconciled exactly once. Only a participant of the session may take them.
+func (sm *SessionManager) TakeExternalBases(sessionID uuid.UUID, userID string) (*ExternalBases, error) {
+	userID = sm.normalizeID(userID)
+	if userID == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !sm.authorizer.CanAccessKey(session, userID) {
+		return nil, qkd.ErrUnauthorized
+	}
+
+	external, exists := sm.externalBases[sessionID]
+	if !exists {
+		return nil, qkd.ErrBasesNotIssued
+	}
+	delete(sm.externalBases, sessionID)
+
+	return external, nil
+}
+
+// ReconcileExternal sifts Bob's externally measured bits against the bases issued
+// for the session. aliceBits must be supplied when the server did not generate them.
+// It also returns the raw positions of the bits disclosed to estimate the QBER,
+// which the caller must discard from the key. The issued bases are taken before
+// anything else, so they are reconciled at most once even by concurrent callers;
+// a failed reconciliation needs a new set of bases.
+func (sm *SessionManager) ReconcileExternal(sessionID uuid.UUID, userID string, measurements []quantum.Bit, aliceBits []quantum.Bit) (*SiftedKey, []int, float64, error) {
+	external, err := sm.TakeExternalBases(sessionID, userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return sm.ReconcileBases(external, measurements, aliceBits)
+}
+
+// ReconcileBases sifts Bob's measurements against bases taken with
+// TakeExternalBases, as ReconcileExternal does. Alice's server-generated bits are
+// wiped once they have been used.
+func (sm *SessionManager) ReconcileBases(external *ExternalBases, measurements []quantum.Bit, aliceBits []quantum.Bit) (*SiftedKey, []int, float64, error) {
+	defer crypto.ZeroizeBits(external.AliceBits)
+
+	if external.AliceBits != nil {
+		aliceBits = external.AliceBits
+	}
+
+	length := len(external.AliceBases)
+	if len(aliceBits) != length || len(measurements) != length {
+		return nil, nil, 0, fmt.Errorf("expected %d Alice bits and Bob measurements, got %d and %d",
+			length, len(aliceBits), len(measurements))
+	}
+
+	alice := &AliceSession{
+		Bits:  aliceBits,
+		Bases: external.AliceBases,
+	}
+
+	bob := &BobSession{
+		Bases:        external.BobBases,
+		Measurements: make([]quantum.MeasurementResult, length),
+	}
+	for i, bit := range measurements {
+		bob.Measurements[i] = quantum.MeasurementResult{
+			MeasuredBit:      bit,
+			MeasurementBasis: external.BobBases[i],
+		}
+	}
+
+	bb84 := NewBB84Protocol(sm.backend, length)
+
+	sifted, err := bb84.BasisReconciliation(alice, bob)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	qber, sampled, err := bb84.SampleQBER(sifted)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := bb84.checkQBERSample(len(sampled)); err != nil {
+		return nil, nil, 0, err
+	}
+
+	disclosed := make([]int, len(sampled))
+	for i, idx := range sampled {
+		disclosed[i] = sifted.Indices[idx]
+	}
+
+	return sifted, disclosed, qber, nil
+}
@@ -0,0 +1,44 @@
+package qkd
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// IDNormalization controls how participant IDs are canonicalized before they
+// are stored on a session or compared during authorization checks
+type IDNormalization struct {
+	// TrimSpace removes leading and trailing whitespace
+	TrimSpace bool
+	// Lowercase folds the ID to lower case so "Alice@Example.com" and "alice@example.com" match
+	Lowercase bool
+	// UnicodeNFC converts the ID to Unicode Normalization Form C
+	UnicodeNFC bool
+}
+
+// DefaultIDNormalization returns a normalization that applies every rule
+func DefaultIDNormalization() IDNormalization {
+	return IDNormalization{
+		TrimSpace:  true,
+		Lowercase:  true,
+		UnicodeNFC: true,
+	}
+}
+
+// Normalize returns the canonical form of a participant ID
+func (n IDNormalization) Normalize(id string) string {
+	if n.UnicodeNFC {
+		id = norm.NFC.String(id)
+	}
+
+	if n.TrimSpace {
+		id = strings.TrimSpace(id)
+	}
+
+	if n.Lowercase {
+		id = strings.ToLower(id)
+	}
+
+	return id
+}
@@ -0,0 +1,104 @@
+package qkd
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// ExchangeJob tracks a post-processed key exchange running in the background
+type ExchangeJob struct {
+	JobID       uuid.UUID
+	SessionID   uuid.UUID
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	KeyID       uuid.UUID // Set once the exchange stores a key
+	Err         string    // Set if the exchange failed
+}
+
+// Done reports whether the exchange has finished
+func (j *ExchangeJob) Done() bool {
+	return j.CompletedAt != nil
+}
+
+// ExecuteKeyExchangeAsync starts ExecuteKeyExchangeWithPostProcessing in the
+// background and returns as soon as the session is marked initiating. Progress is
+// reported through the session status, GetExchangeJob and event subscriptions.
+// Validation errors are returned immediately, as from the synchronous call.
+// The exchange keeps ctx's values, such as the request ID, but not its cancellation.
+func (sm *SessionManager) ExecuteKeyExchangeAsync(ctx context.Context, sessionID uuid.UUID) (*ExchangeJob, error) {
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := sm.startPostProcessing(sessionID)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	job := &ExchangeJob{
+		JobID:     uuid.New(),
+		SessionID: sessionID,
+		StartedAt: time.Now(),
+	}
+
+	sm.mutex.Lock()
+	if sm.shuttingDown {
+		sm.mutex.Unlock()
+		release()
+		err := qkd.ErrShuttingDown
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
+		return nil, err
+	}
+	sm.jobs[sessionID] = job
+	started := *job
+	sm.background.Add(1)
+	sm.mutex.Unlock()
+
+	go func() {
+		defer sm.background.Done()
+		defer release()
+
+		// The exchange outlives the request that started it, but not the server
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		defer cancel()
+		defer context.AfterFunc(sm.jobsCtx, cancel)()
+
+		key, err := sm.runPostProcessing(runCtx, run)
+
+		sm.mutex.Lock()
+		defer sm.mutex.Unlock()
+
+		now := time.Now()
+		job.CompletedAt = &now
+		if err != nil {
+			job.Err = err.Error()
+			return
+		}
+		job.KeyID = key.KeyID
+	}()
+
+	return &started, nil
+}
+
+// GetExchangeJob returns a snapshot of the background exchange started for a session
+func (sm *SessionManager) GetExchangeJob(sessionID uuid.UUID) (*ExchangeJob, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	if _, err := sm.store.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	job, exists := sm.jobs[sessionID]
+	if !exists {
+		return nil, qkd.ErrNoExchangeJob
+	}
+
+	snapshot := *job
+	return &snapshot, nil
+}
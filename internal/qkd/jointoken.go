@@ -0,0 +1,64 @@
+package qkd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// DefaultJoinTokenTTL is how long a session's join token remains valid
+const DefaultJoinTokenTTL = 15 * time.Minute
+
+// joinToken is the stored form of a session's single-use join token.
+// Only the SHA-256 of the token is kept.
+type joinToken struct {
+	hash      [sha256.Size]byte
+	expiresAt time.Time
+}
+
+// newJoinToken generates a random join token and its stored form
+func newJoinToken(ttl time.Duration) (string, *joinToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate join token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, &joinToken{
+		hash:      sha256.Sum256([]byte(token)),
+		expiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// SetJoinTokenTTL sets the lifetime of join tokens issued by CreateSession
+func (sm *SessionManager) SetJoinTokenTTL(ttl time.Duration) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.joinTokenTTL = ttl
+}
+
+// checkJoinToken verifies a presented join token. Must be called with sm.mutex held.
+func (sm *SessionManager) checkJoinToken(sessionID uuid.UUID, token string) error {
+	stored, exists := sm.joinTokens[sessionID]
+	if !exists || token == "" {
+		return qkd.ErrInvalidJoinToken
+	}
+
+	presented := sha256.Sum256([]byte(token))
+	if subtle.ConstantTimeCompare(presented[:], stored.hash[:]) != 1 {
+		return qkd.ErrInvalidJoinToken
+	}
+
+	if time.Now().After(stored.expiresAt) {
+		return qkd.ErrJoinTokenExpired
+	}
+
+	return nil
+}
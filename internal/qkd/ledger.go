@@ -0,0 +1,104 @@
+package qkd
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// DisclosureLedger records every public-channel disclosure made while
+// post-processing a key. Only the step, kind and size of each disclosure are
+// kept, never the disclosed values.
+type DisclosureLedger struct {
+	mutex   sync.Mutex
+	entries []qkd.DisclosureEntry
+}
+
+// NewDisclosureLedger creates an empty ledger
+func NewDisclosureLedger() *DisclosureLedger {
+	return &DisclosureLedger{}
+}
+
+// Record adds a disclosure of bits public bits. leaksKey marks disclosures that
+// reveal information about the key itself (samples, parities, tags) as opposed
+// to protocol metadata such as bases. Recording on a nil ledger is a no-op.
+func (l *DisclosureLedger) Record(step, kind string, bits int, leaksKey bool) {
+	if l == nil || bits <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.entries = append(l.entries, qkd.DisclosureEntry{
+		Step:     step,
+		Kind:     kind,
+		Bits:     bits,
+		Bytes:    (bits + 7) / 8,
+		LeaksKey: leaksKey,
+	})
+}
+
+// Entries returns a copy of the recorded disclosures in order
+func (l *DisclosureLedger) Entries() []qkd.DisclosureEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return append([]qkd.DisclosureEntry(nil), l.entries...)
+}
+
+// TotalBits returns the number of bits disclosed on the public channel
+func (l *DisclosureLedger) TotalBits() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	total := 0
+	for _, entry := range l.entries {
+		total += entry.Bits
+	}
+	return total
+}
+
+// KeyLeakage returns the number of disclosed bits that reveal key information,
+// which is what privacy amplification must remove
+func (l *DisclosureLedger) KeyLeakage() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	total := 0
+	for _, entry := range l.entries {
+		if entry.LeaksKey {
+			total += entry.Bits
+		}
+	}
+	return total
+}
+
+// Summary returns the ledger's entries and totals for a session
+func (l *DisclosureLedger) Summary(sessionID uuid.UUID) *qkd.DisclosureSummary {
+	return &qkd.DisclosureSummary{
+		SessionID:      sessionID,
+		Entries:        l.Entries(),
+		TotalBits:      l.TotalBits(),
+		KeyLeakageBits: l.KeyLeakage(),
+	}
+}
+
+// GetDisclosures returns the disclosure summary recorded for a session's post-processing.
+// Sessions that have not been post-processed report an empty ledger.
+func (sm *SessionManager) GetDisclosures(sessionID uuid.UUID) (*qkd.DisclosureSummary, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	if _, err := sm.store.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	ledger, exists := sm.disclosures[sessionID]
+	if !exists {
+		ledger = NewDisclosureLedger()
+	}
+
+	return ledger.Summary(sessionID), nil
+}
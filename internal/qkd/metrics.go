@@ -0,0 +1,114 @@
+package qkd
+
+import (
+	"time"
+
+	"github.com/jaskrrish/Go-OKD/internal/metrics"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// Key exchange results reported in the qkd_key_exchanges_total "result" label
+const (
+	ExchangeSucceeded = "success"  // A key was stored
+	ExchangeAborted   = "aborted"  // QBER exceeded the threshold
+	ExchangeInsecure  = "insecure" // Completed without producing a secure key
+	ExchangeFailed    = "failed"   // Backend, cancellation or storage error
+)
+
+// QBERBuckets are the upper bounds of the qkd_qber histogram, dense around the 11% threshold
+var QBERBuckets = []float64{0.01, 0.02, 0.03, 0.05, 0.08, 0.11, 0.15, 0.2, 0.25, 0.5}
+
+// SiftEfficiencyBuckets are the upper bounds of the qkd_sift_efficiency histogram
+var SiftEfficiencyBuckets = []float64{0.1, 0.2, 0.3, 0.4, 0.45, 0.5, 0.55, 0.6, 0.7, 0.8, 0.9, 1}
+
+// Metrics holds the collectors the session manager reports to
+type Metrics struct {
+	Registry         *metrics.Registry
+	ExchangeDuration *metrics.HistogramVec
+	SessionsCreated  *metrics.CounterVec
+	KeyExchanges     *metrics.CounterVec
+	QBER             *metrics.HistogramVec
+	SiftEfficiency   *metrics.HistogramVec
+}
+
+// NewMetrics creates the QKD collectors and registers them in a new registry
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		Registry: metrics.NewRegistry(),
+		ExchangeDuration: metrics.NewHistogramVec(
+			"qkd_exchange_duration_seconds",
+			"Duration of key exchanges by quantum backend.",
+			[]string{"backend", "backend_type"},
+			metrics.DefBuckets,
+		),
+		SessionsCreated: metrics.NewCounterVec(
+			"qkd_sessions_created_total",
+			"Sessions initiated by Alice.",
+			nil,
+		),
+		KeyExchanges: metrics.NewCounterVec(
+			"qkd_key_exchanges_total",
+			"Key exchanges run, by result.",
+			[]string{"result"},
+		),
+		QBER: metrics.NewHistogramVec(
+			"qkd_qber",
+			"Estimated quantum bit error rate of key exchanges.",
+			nil,
+			QBERBuckets,
+		),
+		SiftEfficiency: metrics.NewHistogramVec(
+			"qkd_sift_efficiency",
+			"Fraction of transmitted qubits kept after basis reconciliation.",
+			nil,
+			SiftEfficiencyBuckets,
+		),
+	}
+	m.Registry.Register(m.SessionsCreated, m.KeyExchanges, m.ExchangeDuration, m.QBER, m.SiftEfficiency)
+	return m
+}
+
+// BackendType classifies a backend for metric labels
+func BackendType(backend quantum.QuantumBackend) string {
+	if backend.IsSimulator() {
+		return "simulator"
+	}
+	return "hardware"
+}
+
+// SetMetrics replaces the manager's collectors, e.g. to share one registry across managers
+func (sm *SessionManager) SetMetrics(m *Metrics) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.metrics = m
+}
+
+// Metrics returns the collectors the manager reports to
+func (sm *SessionManager) Metrics() *Metrics {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return sm.metrics
+}
+
+// observeExchange records the duration of a key exchange on backend that started at start
+func (sm *SessionManager) observeExchange(backend quantum.QuantumBackend, start time.Time) {
+	sm.Metrics().ExchangeDuration.Observe(
+		time.Since(start).Seconds(),
+		backend.Name(),
+		BackendType(backend),
+	)
+}
+
+// countExchange records the result of a key exchange that started transmitting
+func (sm *SessionManager) countExchange(result string) {
+	sm.Metrics().KeyExchanges.Inc(result)
+}
+
+// observeChannel records the QBER and sifting efficiency measured by an exchange
+func (sm *SessionManager) observeChannel(qber, siftEfficiency float64) {
+	m := sm.Metrics()
+	m.QBER.Observe(qber)
+	m.SiftEfficiency.Observe(siftEfficiency)
+}
@@ -0,0 +1,126 @@
+package qkd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestExchangeDurationLabeledByBackend(t *testing.T) {
+	m := NewMetrics()
+	simulator := quantum.NewSimulatorBackend(false, 0.0)
+	braket := quantum.NewBraketBackend("us-east-1", "sv1")
+
+	runs := map[quantum.QuantumBackend]int{simulator: 3, braket: 2}
+	for backend, n := range runs {
+		sm := NewSessionManager(backend)
+		sm.SetMetrics(m)
+
+		for i := 0; i < n; i++ {
+			session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+			sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+			// Insecure runs are still timed
+			sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+		}
+	}
+
+	if got := m.ExchangeDuration.Count(simulator.Name(), "simulator"); got != 3 {
+		t.Errorf("Expected 3 simulator observations, got %d", got)
+	}
+	if got := m.ExchangeDuration.Count(braket.Name(), "hardware"); got != 2 {
+		t.Errorf("Expected 2 Braket observations, got %d", got)
+	}
+
+	var buf bytes.Buffer
+	m.Registry.WriteText(&buf)
+	text := buf.String()
+
+	for _, series := range []string{
+		`qkd_exchange_duration_seconds_count{backend="QuantumSimulator",backend_type="simulator"} 3`,
+		`qkd_exchange_duration_seconds_count{backend="AWS-Braket-sv1",backend_type="hardware"} 2`,
+	} {
+		if !strings.Contains(text, series) {
+			t.Errorf("Expected series %q in:\n%s", series, text)
+		}
+	}
+}
+
+func TestExchangeDurationLabeledBySessionBackend(t *testing.T) {
+	simulator := quantum.NewSimulatorBackend(false, 0.0)
+	braket := quantum.NewBraketBackend("us-east-1", "sv1")
+	sm := NewSessionManager(simulator)
+	sm.RegisterBackend(qkd.BackendBraket, braket)
+
+	for _, postProcessing := range []bool{false, true} {
+		session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, Backend: qkd.BackendBraket})
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+		// Failed and insecure runs are still timed
+		if postProcessing {
+			sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+		} else {
+			sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+		}
+	}
+
+	m := sm.Metrics()
+	if got := m.ExchangeDuration.Count(braket.Name(), "hardware"); got != 2 {
+		t.Errorf("Expected 2 observations labeled with the session's backend, got %d", got)
+	}
+	if got := m.ExchangeDuration.Count(simulator.Name(), "simulator"); got != 0 {
+		t.Errorf("Expected no observations for the default backend, got %d", got)
+	}
+}
+
+func TestMetricsEndpointAfterExchange(t *testing.T) {
+	// Noise keeps the sampled QBER above zero so Cascade uses realistic block sizes
+	backend := quantum.NewSimulatorBackend(true, 0.03)
+	sm := NewSessionManager(backend)
+
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); err != nil {
+		t.Fatalf("Key exchange failed: %v", err)
+	}
+
+	// A second session whose exchange aborts on a noisy channel
+	backend.SetTargetQBER(0.25)
+	aborted, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	sm.JoinSession(aborted.SessionID, "bob", aborted.JoinToken)
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), aborted.SessionID); err == nil {
+		t.Fatal("Expected the noisy exchange to abort")
+	}
+
+	server := httptest.NewServer(sm.Metrics().Registry.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	text := string(body)
+
+	for _, series := range []string{
+		"qkd_sessions_created_total 2\n",
+		`qkd_key_exchanges_total{result="success"} 1`,
+		`qkd_key_exchanges_total{result="aborted"} 1`,
+		"qkd_qber_count 2\n",
+		"qkd_sift_efficiency_count 2\n",
+		`qkd_exchange_duration_seconds_count{backend="QuantumSimulator",backend_type="simulator"} 2`,
+	} {
+		if !strings.Contains(text, series) {
+			t.Errorf("Expected series %q in:\n%s", series, text)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package qkd
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// PipelineContext is the shared state transformed by post-processing stages
+type PipelineContext struct {
+	Protocol     *BB84Protocol
+	Alice        *AliceSession
+	Bob          *BobSession
+	TargetLength int // Requested final key length in bits
+
+	// Correction is the algorithm CorrectStage uses when it has no NewCorrector
+	Correction crypto.CorrectionMethod
+
+	Sifted          *SiftedKey
+	AliceKey        []quantum.Bit // Alice's working key, updated by each stage
+	BobKey          []quantum.Bit // Bob's working key, updated by each stage
+	QBER            float64
+	SampledBits     int   // Bits disclosed during QBER estimation and removed from the key
+	SamplePositions []int // Positions of the sampled bits in the key EstimateStage received
+	DisclosedBits   int   // Bits disclosed during reconciliation and confirmation
+	ErrorsCorrected int   // Bits of Bob's key flipped by correction
+	SecureLength    int   // Maximum secure key length computed before amplification
+	FinalKey        []byte
+
+	// Decoy is the single-photon bound used for the secure length in decoy-state mode
+	Decoy *DecoyBound
+
+	// Ledger records each public-channel disclosure; created by Run if nil
+	Ledger *DisclosureLedger
+
+	// OnStage, if set, is called with each stage's name after it completes
+	OnStage func(stage string)
+}
+
+// Leakage returns the number of disclosed bits that carry information about the
+// remaining key. The QBER sample is discarded, so only reconciliation and
+// confirmation disclosures count.
+func (pc *PipelineContext) Leakage() int {
+	return pc.DisclosedBits
+}
+
+// CorrectionQBER returns the error rate error correction should plan for: the
+// estimated QBER, but never less than the rule-of-three upper bound 3/m for a
+// sample of m bits. A sample without errors does not mean the key has none, and
+// sizing Cascade blocks for a QBER of 0 would disclose a parity for every bit.
+func (pc *PipelineContext) CorrectionQBER() float64 {
+	if pc.SampledBits == 0 {
+		return pc.QBER
+	}
+	return math.Max(pc.QBER, 3/float64(pc.SampledBits))
+}
+
+// ZeroizeIntermediate wipes every raw, sifted and working key buffer, leaving only
+// FinalKey. Lengths are kept so metrics can still be derived afterwards.
+func (pc *PipelineContext) ZeroizeIntermediate() {
+	if pc.Alice != nil {
+		crypto.ZeroizeBits(pc.Alice.Bits)
+		crypto.ZeroizeBits(pc.Alice.Key)
+		clear(pc.Alice.Qubits)
+	}
+	if pc.Bob != nil {
+		crypto.ZeroizeBits(pc.Bob.Key)
+		clear(pc.Bob.Measurements)
+	}
+	if pc.Sifted != nil {
+		crypto.ZeroizeBits(pc.Sifted.AliceKey)
+		crypto.ZeroizeBits(pc.Sifted.BobKey)
+	}
+	crypto.ZeroizeBits(pc.AliceKey)
+	crypto.ZeroizeBits(pc.BobKey)
+}
+
+// Zeroize wipes all key material held by the context, including FinalKey. It is
+// used when an exchange fails or is aborted and the key will never be stored.
+func (pc *PipelineContext) Zeroize() {
+	pc.ZeroizeIntermediate()
+	crypto.Zeroize(pc.FinalKey)
+}
+
+// Stage is a single step of the post-processing pipeline
+type Stage interface {
+	// Name returns a short identifier for the stage
+	Name() string
+
+	// Process transforms the pipeline context, returning an error to stop the pipeline
+	Process(pc *PipelineContext) error
+}
+
+// Pipeline runs an ordered list of post-processing stages
+type Pipeline struct {
+	stages []Stage
+}
+
+// NewPipeline creates a pipeline that runs the given stages in order
+func NewPipeline(stages ...Stage) *Pipeline {
+	return &Pipeline{
+		stages: stages,
+	}
+}
+
+// DefaultSecurityParameter is the number of bits privacy amplification sacrifices
+// beyond the estimated leakage
+const DefaultSecurityParameter = crypto.DefaultSecurityBits
+
+// DefaultPipeline returns the standard sift → estimate → correct → amplify pipeline
+func DefaultPipeline() *Pipeline {
+	return NewPipeline(
+		&SiftStage{},
+		&EstimateStage{},
+		&CorrectStage{},
+		&ConfirmStage{},
+		&AmplifyStage{Method: crypto.SHA3_256Method, SecurityParameter: DefaultSecurityParameter},
+	)
+}
+
+// Stages returns the names of the configured stages in execution order
+func (p *Pipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		names[i] = stage.Name()
+	}
+	return names
+}
+
+// Run executes each stage in order, stopping at the first error
+func (p *Pipeline) Run(pc *PipelineContext) error {
+	if pc.Ledger == nil {
+		pc.Ledger = NewDisclosureLedger()
+	}
+
+	for _, stage := range p.stages {
+		if err := stage.Process(pc); err != nil {
+			return err
+		}
+		if pc.OnStage != nil {
+			pc.OnStage(stage.Name())
+		}
+	}
+
+	if pc.FinalKey == nil {
+		return fmt.Errorf("pipeline produced no final key")
+	}
+
+	return nil
+}
+
+// QBERExceededError is returned when the estimated QBER is above the protocol threshold
+type QBERExceededError struct {
+	QBER      float64
+	Threshold float64
+}
+
+func (e *QBERExceededError) Error() string {
+	return fmt.Sprintf("QBER too high: %.2f%% (threshold: %.2f%%)", e.QBER*100, e.Threshold*100)
+}
+
+// SiftStage performs basis reconciliation between Alice and Bob
+type SiftStage struct{}
+
+// Name returns the stage name
+func (s *SiftStage) Name() string { return "sift" }
+
+// Process sifts the raw transmission down to the bits measured in matching bases
+func (s *SiftStage) Process(pc *PipelineContext) error {
+	sifted, err := pc.Protocol.BasisReconciliation(pc.Alice, pc.Bob)
+	if err != nil {
+		return err
+	}
+
+	pc.Sifted = sifted
+	pc.AliceKey = sifted.AliceKey
+	pc.BobKey = sifted.BobKey
+
+	// Both parties announce their bases; this reveals nothing about the key bits
+	pc.Ledger.Record(s.Name(), "alice_bases", len(pc.Alice.Bases), false)
+	pc.Ledger.Record(s.Name(), "bob_bases", len(pc.Bob.Bases), false)
+
+	if pc.Protocol.decoy != nil {
+		stats, err := pc.Protocol.DecoyStatistics(pc.Alice, pc.Bob)
+		if err != nil {
+			return err
+		}
+
+		bound, err := pc.Protocol.EstimateSecureFractionWithDecoy(stats)
+		if err != nil {
+			return err
+		}
+		pc.Decoy = bound
+
+		// Decoy and vacuum outcomes are revealed in full but are never part of the key
+		pc.Ledger.Record(s.Name(), "intensities", len(pc.Alice.Intensities), false)
+		pc.Ledger.Record(s.Name(), "decoy_bits", stats.Decoy.Sifted+stats.Vacuum.Sifted, false)
+	}
+
+	return nil
+}
+
+// EstimateStage estimates the QBER, removes the disclosed sample from the key and
+// aborts when the sample is too small to trust or the QBER exceeds the protocol threshold
+type EstimateStage struct{}
+
+// Name returns the stage name
+func (s *EstimateStage) Name() string { return "estimate" }
+
+// Process estimates the QBER from a random sample of the sifted key
+func (s *EstimateStage) Process(pc *PipelineContext) error {
+	working := &SiftedKey{AliceKey: pc.AliceKey, BobKey: pc.BobKey, Indices: make([]int, len(pc.AliceKey))}
+	qber, sampled, err := pc.Protocol.SampleQBER(working)
+	if err != nil {
+		return err
+	}
+
+	// The sample is public, so it is discarded rather than leaking into the key
+	// (the sifted buffers it is taken from are wiped with the rest of the context)
+	remaining := pc.Protocol.RemoveSampledBits(working, sampled)
+	pc.AliceKey = remaining.AliceKey
+	pc.BobKey = remaining.BobKey
+
+	pc.QBER = qber
+	pc.SampledBits = len(sampled)
+	pc.SamplePositions = sampled
+	pc.Ledger.Record(s.Name(), "sample_bits", pc.SampledBits, false)
+
+	if err := pc.Protocol.checkQBERSample(pc.SampledBits); err != nil {
+		return err
+	}
+	if qber > pc.Protocol.qberThreshold {
+		return &QBERExceededError{QBER: qber, Threshold: pc.Protocol.qberThreshold}
+	}
+
+	return nil
+}
+
+// CorrectStage reconciles Bob's key with Alice's. It may appear more than once
+// in a pipeline to run several correction rounds. It cannot tell whether errors
+// remain; follow it with a ConfirmStage.
+type CorrectStage struct {
+	// NewCorrector builds the corrector for the estimated QBER (defaults to the
+	// context's Correction method)
+	NewCorrector func(qber float64) crypto.Corrector
+}
+
+// Name returns the stage name
+func (s *CorrectStage) Name() string { return "correct" }
+
+// Process corrects Bob's key and accounts for the disclosed parity bits
+func (s *CorrectStage) Process(pc *PipelineContext) error {
+	var corrector crypto.Corrector
+	if s.NewCorrector != nil {
+		corrector = s.NewCorrector(pc.CorrectionQBER())
+	} else {
+		var err error
+		if corrector, err = crypto.NewCorrector(pc.Correction, pc.CorrectionQBER()); err != nil {
+			return err
+		}
+	}
+
+	bobCorrected, disclosedBits, err := corrector.Correct(pc.AliceKey, pc.BobKey)
+	if err != nil {
+		return err
+	}
+
+	for i := range bobCorrected {
+		if bobCorrected[i] != pc.BobKey[i] {
+			pc.ErrorsCorrected++
+		}
+	}
+
+	pc.BobKey = bobCorrected
+	pc.DisclosedBits += disclosedBits
+	pc.Ledger.Record(s.Name(), "parities", disclosedBits, true)
+
+	return nil
+}
+
+// DefaultConfirmTagBits is the length of the key confirmation tag; a residual
+// error goes unnoticed with probability 2^-64
+const DefaultConfirmTagBits = 64
+
+// ConfirmStage compares a short universal-hash tag of both keys over the public
+// channel to catch residual errors left by correction
+type ConfirmStage struct {
+	TagBits int // Number of tag bits disclosed (defaults to DefaultConfirmTagBits)
+}
+
+// Name returns the stage name
+func (s *ConfirmStage) Name() string { return "confirm" }
+
+// Process compares key tags and accounts for the disclosed tag bits
+func (s *ConfirmStage) Process(pc *PipelineContext) error {
+	tagBits := s.TagBits
+	if tagBits <= 0 {
+		tagBits = DefaultConfirmTagBits
+	}
+
+	equal, err := crypto.ConfirmKeyEquality(pc.AliceKey, pc.BobKey, tagBits)
+	if err != nil {
+		return err
+	}
+	pc.DisclosedBits += tagBits
+	pc.Ledger.Record(s.Name(), "confirmation_tag", tagBits, true)
+
+	if !equal {
+		return fmt.Errorf("key confirmation failed: tags differ")
+	}
+
+	return nil
+}
+
+// InterleaveStage applies the same random permutation to both keys so that
+// errors clustered in the transmission are spread across the key
+type InterleaveStage struct{}
+
+// Name returns the stage name
+func (s *InterleaveStage) Name() string { return "interleave" }
+
+// Process permutes Alice's and Bob's keys identically
+func (s *InterleaveStage) Process(pc *PipelineContext) error {
+	n := len(pc.AliceKey)
+	aliceKey := make([]quantum.Bit, n)
+	bobKey := make([]quantum.Bit, n)
+	copy(aliceKey, pc.AliceKey)
+	copy(bobKey, pc.BobKey)
+
+	// Fisher-Yates shuffle; the permutation itself is public and discloses nothing about the key
+	for i := n - 1; i > 0; i-- {
+		j, err := cryptoRandInt(i + 1)
+		if err != nil {
+			return err
+		}
+		aliceKey[i], aliceKey[j] = aliceKey[j], aliceKey[i]
+		bobKey[i], bobKey[j] = bobKey[j], bobKey[i]
+	}
+
+	pc.AliceKey = aliceKey
+	pc.BobKey = bobKey
+
+	return nil
+}
+
+// AmplifyStage compresses the reconciled key to remove Eve's partial information
+type AmplifyStage struct {
+	Method            crypto.AmplificationMethod
+	SecurityParameter int
+}
+
+// Name returns the stage name
+func (s *AmplifyStage) Name() string { return "amplify" }
+
+// Process computes the secure key length and performs privacy amplification
+func (s *AmplifyStage) Process(pc *PipelineContext) error {
+	keyLen := len(pc.AliceKey)
+	if keyLen == 0 {
+		return fmt.Errorf("no key material left to amplify")
+	}
+
+	// The leakage removed by amplification must match what was actually disclosed
+	if pc.Ledger != nil {
+		if ledgerLeakage := pc.Ledger.KeyLeakage(); ledgerLeakage != pc.Leakage() {
+			return fmt.Errorf("leakage accounting mismatch: amplifier input %d bits, disclosure ledger %d bits", pc.Leakage(), ledgerLeakage)
+		}
+	}
+
+	amplifier, err := crypto.NewPrivacyAmplifierWithSecurity(s.Method, s.SecurityParameter)
+	if err != nil {
+		return err
+	}
+
+	// Calculate information leakage
+	totalLeakage := float64(pc.Leakage()) / float64(keyLen)
+
+	// Calculate maximum secure key length, bounded by the single-photon
+	// contribution when decoy states are in use
+	if pc.Decoy != nil {
+		pc.SecureLength = DecoySecureKeyLength(keyLen, pc.Decoy, pc.DisclosedBits, amplifier.SecurityParameter())
+	} else {
+		pc.SecureLength = amplifier.SecureKeyLength(keyLen, pc.QBER, pc.DisclosedBits)
+	}
+
+	if pc.SecureLength < pc.TargetLength {
+		return fmt.Errorf("Cannot generate requested key length: max secure length is %d bits", pc.SecureLength)
+	}
+
+	finalKey, err := amplifier.Amplify(pc.AliceKey, totalLeakage, pc.TargetLength)
+	if err != nil {
+		return err
+	}
+
+	pc.FinalKey = finalKey
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package qkd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// runTestPipeline runs the stages over a copy of the same noisy transmission.
+// QBER is fixed up front so Cascade behaves identically across runs.
+func runTestPipeline(t *testing.T, alice *AliceSession, bob *BobSession, stages ...Stage) *PipelineContext {
+	t.Helper()
+
+	pc := &PipelineContext{
+		Protocol:     NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 1024),
+		Alice:        alice,
+		Bob:          bob,
+		TargetLength: 128,
+		QBER:         0.05,
+	}
+
+	if err := NewPipeline(stages...).Run(pc); err != nil {
+		t.Fatalf("Pipeline %v failed: %v", NewPipeline(stages...).Stages(), err)
+	}
+
+	return pc
+}
+
+func TestPipelineOptionalStages(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(true, 0.05), 1024)
+	alice, err := bb84.AliceGenerateQubits(context.Background())
+	if err != nil {
+		t.Fatalf("Alice qubit generation failed: %v", err)
+	}
+	bob, err := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+	if err != nil {
+		t.Fatalf("Bob measurement failed: %v", err)
+	}
+
+	amplify := &AmplifyStage{Method: crypto.SHA3_256Method, SecurityParameter: 64}
+
+	// Cascade shuffles between passes; a fixed seed keeps the disclosures comparable
+	correct := &CorrectStage{NewCorrector: func(qber float64) crypto.Corrector {
+		c := crypto.NewCascadeCorrector(qber)
+		c.SeedShuffle(1)
+		return c
+	}}
+
+	base := runTestPipeline(t, alice, bob, &SiftStage{}, correct, amplify)
+	confirmed := runTestPipeline(t, alice, bob, &SiftStage{}, correct, &ConfirmStage{TagBits: 32}, amplify)
+	interleaved := runTestPipeline(t, alice, bob, &SiftStage{}, &InterleaveStage{}, correct, amplify)
+
+	// Confirmation discloses its tag bits but does not change the key
+	if confirmed.DisclosedBits != base.DisclosedBits+32 {
+		t.Errorf("Expected confirmation to add 32 disclosed bits: base %d, confirmed %d",
+			base.DisclosedBits, confirmed.DisclosedBits)
+	}
+	if !bytes.Equal(confirmed.FinalKey, base.FinalKey) {
+		t.Error("Expected confirmation to leave the final key unchanged")
+	}
+
+	// Interleaving reorders the key before amplification, so the final key differs
+	if bytes.Equal(interleaved.FinalKey, base.FinalKey) {
+		t.Error("Expected interleaving to change the final key")
+	}
+
+	for _, pc := range []*PipelineContext{base, confirmed, interleaved} {
+		if len(pc.FinalKey)*8 != 128 {
+			t.Errorf("Expected 128-bit final key, got %d bits", len(pc.FinalKey)*8)
+		}
+	}
+}
+
+func TestPipelineMultipleCorrectionRounds(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(true, 0.05), 1024)
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+
+	amplify := &AmplifyStage{Method: crypto.SHA3_256Method, SecurityParameter: 64}
+
+	single := runTestPipeline(t, alice, bob, &SiftStage{}, &CorrectStage{}, amplify)
+	double := runTestPipeline(t, alice, bob, &SiftStage{}, &CorrectStage{}, &CorrectStage{}, amplify)
+
+	// A second round over already-corrected keys still discloses its block parities
+	if double.DisclosedBits <= single.DisclosedBits {
+		t.Errorf("Expected a second correction round to disclose more bits: %d vs %d",
+			double.DisclosedBits, single.DisclosedBits)
+	}
+}
+
+func TestPipelineAbortsOnHighQBER(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(true, 0.30), 512)
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+
+	pc := &PipelineContext{Protocol: bb84, Alice: alice, Bob: bob, TargetLength: 128}
+	err := DefaultPipeline().Run(pc)
+
+	if _, ok := err.(*QBERExceededError); !ok {
+		t.Fatalf("Expected QBERExceededError, got %v", err)
+	}
+}
+
+func TestEstimateStageRemovesSample(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(true, 0.05), 1024)
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+
+	pc := &PipelineContext{Protocol: bb84, Alice: alice, Bob: bob, TargetLength: 128, Ledger: NewDisclosureLedger()}
+	for _, stage := range []Stage{&SiftStage{}, &EstimateStage{}} {
+		if err := stage.Process(pc); err != nil {
+			t.Fatalf("%s failed: %v", stage.Name(), err)
+		}
+	}
+
+	if pc.SampledBits == 0 {
+		t.Fatal("Expected EstimateStage to sample the sifted key")
+	}
+	if want := len(pc.Sifted.AliceKey) - pc.SampledBits; len(pc.AliceKey) != want || len(pc.BobKey) != want {
+		t.Errorf("Expected %d key bits after removing the sample, got %d and %d", want, len(pc.AliceKey), len(pc.BobKey))
+	}
+}
+
+func TestDisclosureLedgerMatchesLeakage(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(true, 0.05), 1024)
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+
+	pc := runTestPipeline(t, alice, bob,
+		&SiftStage{},
+		&EstimateStage{},
+		&CorrectStage{},
+		&ConfirmStage{TagBits: 32},
+		&AmplifyStage{Method: crypto.SHA3_256Method, SecurityParameter: 64},
+	)
+
+	// The QBER sample is disclosed but removed from the key, so it does not leak
+	if pc.Ledger.KeyLeakage() != pc.DisclosedBits {
+		t.Errorf("Expected ledger leakage %d to equal the %d disclosed bits",
+			pc.Ledger.KeyLeakage(), pc.DisclosedBits)
+	}
+
+	kinds := make(map[string]int)
+	for _, entry := range pc.Ledger.Entries() {
+		kinds[entry.Kind] += entry.Bits
+		if entry.Bytes != (entry.Bits+7)/8 {
+			t.Errorf("%s: expected %d bytes for %d bits, got %d", entry.Kind, (entry.Bits+7)/8, entry.Bits, entry.Bytes)
+		}
+	}
+
+	if kinds["sample_bits"] != pc.SampledBits || kinds["confirmation_tag"] != 32 {
+		t.Errorf("Unexpected ledger entries: %v", kinds)
+	}
+	if kinds["parities"]+kinds["confirmation_tag"] != pc.DisclosedBits {
+		t.Errorf("Expected parity and tag entries to sum to %d disclosed bits, got %v", pc.DisclosedBits, kinds)
+	}
+
+	// Basis announcements are public but carry no key information
+	if kinds["alice_bases"] != len(alice.Bases) || pc.Ledger.TotalBits() != pc.Ledger.KeyLeakage()+pc.SampledBits+2*len(alice.Bases) {
+		t.Errorf("Expected both basis announcements in the ledger, got %v", kinds)
+	}
+}
+
+func TestConfirmStageCatchesResidualError(t *testing.T) {
+	alice, err := quantum.SecureRandomBits(512)
+	if err != nil {
+		t.Fatalf("SecureRandomBits failed: %v", err)
+	}
+	bob := append([]quantum.Bit(nil), alice...)
+	bob[100] ^= 1 // Left behind by correction
+
+	pc := &PipelineContext{AliceKey: alice, BobKey: bob, Ledger: NewDisclosureLedger()}
+	stage := &ConfirmStage{}
+	if err := stage.Process(pc); err == nil || !strings.Contains(err.Error(), "key confirmation failed") {
+		t.Errorf("Expected the residual error to fail confirmation, got %v", err)
+	}
+
+	// The tag was disclosed whether or not the keys matched
+	if pc.DisclosedBits != DefaultConfirmTagBits || pc.Ledger.KeyLeakage() != DefaultConfirmTagBits {
+		t.Errorf("Expected %d tag bits counted as leakage, got %d disclosed and %d in the ledger",
+			DefaultConfirmTagBits, pc.DisclosedBits, pc.Ledger.KeyLeakage())
+	}
+}
+
+func TestAmplifyRejectsUnaccountedLeakage(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 1024)
+	alice, _ := bb84.AliceGenerateQubits(context.Background())
+	bob, _ := bb84.BobMeasureQubits(context.Background(), alice.Qubits)
+
+	pc := &PipelineContext{Protocol: bb84, Alice: alice, Bob: bob, TargetLength: 128, QBER: 0.05}
+	pipeline := NewPipeline(&SiftStage{}, &CorrectStage{}, &AmplifyStage{Method: crypto.SHA3_256Method, SecurityParameter: 64})
+
+	// Leakage that bypasses the ledger must be caught before amplification
+	pc.DisclosedBits = 10
+	if err := pipeline.Run(pc); err == nil || !strings.Contains(err.Error(), "leakage accounting mismatch") {
+		t.Errorf("Expected amplification to fail on a leakage accounting mismatch, got %v", err)
+	}
+}
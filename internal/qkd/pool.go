@@ -0,0 +1,377 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+)
+
+// DefaultKeyPoolRetryDelay is how long a key pool waits after a failed exchange
+// before trying again
+const DefaultKeyPoolRetryDelay = time.Second
+
+// PooledKey is key material generated ahead of any request, with the channel
+// statistics and public record of the exchange that produced it
+type PooledKey struct {
+	Material     []byte
+	QBER         float64
+	SiftedLength int
+
+	// Recorded for the session the key is served to; nil when not collected
+	Metrics    *qkd.SessionMetrics
+	Transcript *qkd.Transcript
+	Ledger     *DisclosureLedger
+}
+
+// KeyPool keeps up to a target number of pre-generated keys so a key request does
+// not wait for a full exchange. A background worker generates one key at a time
+// until the pool is full and refills it as keys are drawn.
+type KeyPool struct {
+	generate   func(ctx context.Context) (*PooledKey, error)
+	target     int
+	retryDelay time.Duration
+
+	mutex      sync.Mutex
+	keys       []*PooledKey
+	generating bool
+	consumed   int
+	ready      chan struct{} // Closed and replaced whenever a key is added
+	refill     chan struct{} // Wakes the worker after a key is drawn
+	cancel     context.CancelFunc
+	done       chan struct{}
+	stopped    bool
+}
+
+// NewKeyPool creates a pool of size keys made by generate. The pool is empty until
+// Start is called.
+func NewKeyPool(size int, generate func(ctx context.Context) (*PooledKey, error)) *KeyPool {
+	return &KeyPool{
+		generate:   generate,
+		target:     max(size, 1),
+		retryDelay: DefaultKeyPoolRetryDelay,
+		ready:      make(chan struct{}),
+		refill:     make(chan struct{}, 1),
+	}
+}
+
+// Start fills the pool in a background goroutine until Stop is called. Starting a
+// running pool has no effect.
+func (p *KeyPool) Start() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.cancel != nil || p.stopped {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go p.fill(ctx)
+}
+
+// fill generates keys whenever the pool is below its target
+func (p *KeyPool) fill(ctx context.Context) {
+	defer close(p.done)
+
+	for {
+		p.mutex.Lock()
+		full := len(p.keys) >= p.target
+		p.generating = !full
+		p.mutex.Unlock()
+
+		if full {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.refill:
+				continue
+			}
+		}
+
+		key, err := p.generate(ctx)
+
+		p.mutex.Lock()
+		p.generating = false
+		if err == nil {
+			p.keys = append(p.keys, key)
+			close(p.ready)
+			p.ready = make(chan struct{})
+		}
+		p.mutex.Unlock()
+
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.retryDelay):
+			}
+		}
+	}
+}
+
+// Stop halts generation, waits for the worker to exit and wipes the keys left in
+// the pool. A stopped pool hands out no more keys.
+func (p *KeyPool) Stop() {
+	p.mutex.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel = nil
+	p.stopped = true
+	p.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, key := range p.keys {
+		crypto.Zeroize(key.Material)
+	}
+	p.keys = nil
+	close(p.ready)
+	p.ready = make(chan struct{})
+}
+
+// TryTake removes and returns a pooled key without waiting, reporting false when
+// the pool is empty
+func (p *KeyPool) TryTake() (*PooledKey, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.take()
+}
+
+// Take removes and returns a pooled key, waiting for the worker to generate one
+// when the pool is empty. It fails once ctx is done or the pool is stopped.
+func (p *KeyPool) Take(ctx context.Context) (*PooledKey, error) {
+	for {
+		p.mutex.Lock()
+		if p.stopped {
+			p.mutex.Unlock()
+			return nil, qkd.ErrKeyPoolStopped
+		}
+		key, ok := p.take()
+		ready := p.ready
+		p.mutex.Unlock()
+
+		if ok {
+			return key, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ready:
+		}
+	}
+}
+
+// take pops the oldest key and wakes the worker. The caller must hold the mutex.
+func (p *KeyPool) take() (*PooledKey, bool) {
+	if len(p.keys) == 0 {
+		return nil, false
+	}
+
+	key := p.keys[0]
+	p.keys[0] = nil
+	p.keys = p.keys[1:]
+	p.consumed++
+
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+	return key, true
+}
+
+// Stats reports the pool's size and activity
+func (p *KeyPool) Stats() qkd.KeyPoolStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return qkd.KeyPoolStats{
+		Target:     p.target,
+		Available:  len(p.keys),
+		Generating: p.generating,
+		Consumed:   p.consumed,
+	}
+}
+
+// StartKeyPool keeps size post-processed keys of keyLength bits ready on the
+// default backend. A two-party session asking for that key length on the default
+// backend, without a simulated eavesdropper, is then served a pooled key instead
+// of running its own exchange, unless the pool is empty. Starting a pool replaces
+// the running one.
+func (sm *SessionManager) StartKeyPool(keyLength, size int) error {
+	if keyLength < 128 || keyLength > 4096 {
+		return qkd.ErrInvalidKeyLength
+	}
+
+	pool := NewKeyPool(size, func(ctx context.Context) (*PooledKey, error) {
+		return sm.generatePooledKey(ctx, keyLength)
+	})
+
+	sm.mutex.Lock()
+	previous := sm.keyPool
+	sm.keyPool = pool
+	sm.keyPoolLength = keyLength
+	sm.mutex.Unlock()
+
+	if previous != nil {
+		previous.Stop()
+	}
+	pool.Start()
+	return nil
+}
+
+// stopKeyPool stops the key pool, if one is running
+func (sm *SessionManager) stopKeyPool() {
+	sm.mutex.Lock()
+	pool := sm.keyPool
+	sm.keyPool = nil
+	sm.mutex.Unlock()
+
+	if pool != nil {
+		pool.Stop()
+	}
+}
+
+// KeyPoolStats reports the key pool's size and activity
+func (sm *SessionManager) KeyPoolStats() (*qkd.KeyPoolStats, error) {
+	sm.mutex.RLock()
+	pool, keyLength := sm.keyPool, sm.keyPoolLength
+	sm.mutex.RUnlock()
+
+	if pool == nil {
+		return nil, qkd.ErrKeyPoolNotConfigured
+	}
+
+	stats := pool.Stats()
+	stats.KeyLength = keyLength
+	return &stats, nil
+}
+
+// generatePooledKey runs a post-processed exchange that belongs to no session
+func (sm *SessionManager) generatePooledKey(ctx context.Context, keyLength int) (*PooledKey, error) {
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	sm.mutex.RLock()
+	bb84, err := sm.planPostProcessing(&qkd.QKDSession{KeyLength: keyLength})
+	pipeline, correction, logger := sm.pipeline, sm.correction, sm.logger
+	sm.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := sm.exchangeContext(ctx)
+	defer cancel()
+
+	pc := &PipelineContext{
+		Protocol:     bb84,
+		TargetLength: keyLength,
+		Correction:   correction,
+		Ledger:       NewDisclosureLedger(),
+	}
+	defer pc.ZeroizeIntermediate()
+
+	start := time.Now()
+	pc.Alice, err = bb84.AliceGenerateQubits(ctx)
+	if err == nil {
+		pc.Bob, err = bb84.BobMeasureQubits(ctx, pc.Alice.Qubits)
+	}
+	if err == nil {
+		err = pipeline.Run(pc)
+	}
+	if err != nil {
+		crypto.Zeroize(pc.FinalKey)
+		err = timeoutCause(ctx, err)
+		if ctx.Err() == nil {
+			logger.Warn("key pool exchange failed", "error", err)
+		}
+		return nil, err
+	}
+
+	return &PooledKey{
+		Material:     pc.FinalKey,
+		QBER:         pc.QBER,
+		SiftedLength: len(pc.AliceKey),
+		Metrics:      newSessionMetrics(uuid.Nil, pc, time.Since(start)),
+		Transcript:   newTranscript(uuid.Nil, pc, nil),
+		Ledger:       pc.Ledger,
+	}, nil
+}
+
+// takePooledKey draws a pooled key if the pool can serve the session
+func (sm *SessionManager) takePooledKey(session *qkd.QKDSession) (*PooledKey, bool) {
+	sm.mutex.RLock()
+	pool, keyLength := sm.keyPool, sm.keyPoolLength
+	backend, err := sm.sessionBackend(session)
+	sm.mutex.RUnlock()
+
+	if pool == nil || err != nil || backend != sm.backend ||
+		session.KeyLength != keyLength || session.InterceptProbability > 0 {
+		return nil, false
+	}
+	return pool.TryTake()
+}
+
+// servePooledKey stores a pooled key as the session's key and completes the session
+func (sm *SessionManager) servePooledKey(run *postProcessingRun, pooled *PooledKey) (*qkd.QuantumKey, error) {
+	session := run.session
+	sessionID := session.SessionID
+	now := time.Now()
+
+	quantumKey := &qkd.QuantumKey{
+		KeyID:       uuid.New(),
+		SessionID:   sessionID,
+		Label:       session.Label,
+		KeyMaterial: pooled.Material,
+		KeyLength:   len(pooled.Material) * 8,
+		GeneratedAt: now,
+		ExpiresAt:   sm.keyExpiry(session, now),
+		IsActive:    true,
+	}
+
+	if err := sm.store.SaveKey(quantumKey); err != nil {
+		crypto.Zeroize(pooled.Material)
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, pooled.QBER, pooled.SiftedLength, 0, false, err.Error())
+		return nil, fmt.Errorf("failed to store key: %w", err)
+	}
+	run.logger.Debug("key served from pool", "key_id", quantumKey.KeyID, "key_length", quantumKey.KeyLength)
+	sm.recordPooledExchange(sessionID, pooled)
+
+	msg := fmt.Sprintf("Secure key served from the key pool! QBER: %.2f%%", pooled.QBER*100)
+	sm.completeExchange(run, quantumKey, pooled.QBER, pooled.SiftedLength, msg)
+
+	return quantumKey, nil
+}
+
+// recordPooledExchange stores the metrics, transcript and disclosures of the
+// exchange that generated a pooled key as those of the session it was served to
+func (sm *SessionManager) recordPooledExchange(sessionID uuid.UUID, pooled *PooledKey) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if pooled.Metrics != nil {
+		pooled.Metrics.SessionID = sessionID
+		sm.sessionMetrics[sessionID] = pooled.Metrics
+	}
+	if pooled.Transcript != nil {
+		pooled.Transcript.SessionID = sessionID
+		sm.transcripts[sessionID] = pooled.Transcript
+	}
+	if pooled.Ledger != nil {
+		sm.disclosures[sessionID] = pooled.Ledger
+	}
+}
@@ -0,0 +1,203 @@
+package qkd
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// waitForPool polls the pool until it holds available keys
+func waitForPool(t *testing.T, pool *KeyPool, available int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Stats().Available != available {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the pool to reach %d keys, got %+v", available, pool.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestKeyPoolFillsToTarget(t *testing.T) {
+	var generated atomic.Int32
+	pool := NewKeyPool(3, func(ctx context.Context) (*PooledKey, error) {
+		n := generated.Add(1)
+		return &PooledKey{Material: []byte{byte(n)}}, nil
+	})
+	pool.Start()
+	defer pool.Stop()
+
+	waitForPool(t, pool, 3)
+	time.Sleep(20 * time.Millisecond)
+	if n := generated.Load(); n != 3 {
+		t.Errorf("Expected generation to stop at the target of 3 keys, got %d", n)
+	}
+
+	// Keys are drawn oldest first and removed from the pool
+	key, ok := pool.TryTake()
+	if !ok || key.Material[0] != 1 {
+		t.Fatalf("Expected the first generated key, got %v (%v)", key, ok)
+	}
+	if stats := pool.Stats(); stats.Consumed != 1 {
+		t.Errorf("Expected 1 consumed key, got %d", stats.Consumed)
+	}
+
+	// The worker replaces the drawn key
+	waitForPool(t, pool, 3)
+	for want := byte(2); want <= 4; want++ {
+		if key, ok := pool.TryTake(); !ok || key.Material[0] != want {
+			t.Errorf("Expected key %d, got %v (%v)", want, key, ok)
+		}
+	}
+}
+
+func TestKeyPoolTakeWaitsForGeneration(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewKeyPool(1, func(ctx context.Context) (*PooledKey, error) {
+		select {
+		case <-release:
+			return &PooledKey{Material: []byte{0xAA}}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	pool.Start()
+	defer pool.Stop()
+
+	if _, ok := pool.TryTake(); ok {
+		t.Fatal("Expected TryTake to report an empty pool")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !pool.Stats().Generating {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the empty pool to be generating a key, got %+v", pool.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Take(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected Take to give up with its context, got %v", err)
+	}
+
+	taken := make(chan *PooledKey)
+	go func() {
+		key, err := pool.Take(context.Background())
+		if err != nil {
+			t.Errorf("Take failed: %v", err)
+		}
+		taken <- key
+	}()
+
+	close(release)
+	select {
+	case key := <-taken:
+		if key == nil || key.Material[0] != 0xAA {
+			t.Errorf("Expected the newly generated key, got %v", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Take to return once a key was generated")
+	}
+}
+
+func TestKeyPoolStopWipesKeys(t *testing.T) {
+	material := []byte{1, 2, 3, 4}
+	var once atomic.Bool
+	pool := NewKeyPool(1, func(ctx context.Context) (*PooledKey, error) {
+		if once.Swap(true) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &PooledKey{Material: material}, nil
+	})
+	pool.Start()
+	waitForPool(t, pool, 1)
+
+	pool.Stop()
+	for _, b := range material {
+		if b != 0 {
+			t.Fatalf("Expected pooled keys to be wiped on stop, got %x", material)
+		}
+	}
+	if _, ok := pool.TryTake(); ok {
+		t.Error("Expected a stopped pool to hold no keys")
+	}
+	if _, err := pool.Take(context.Background()); err != qkd.ErrKeyPoolStopped {
+		t.Errorf("Expected ErrKeyPoolStopped, got %v", err)
+	}
+}
+
+func TestSessionServedFromKeyPool(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	defer sm.stopKeyPool()
+
+	if _, err := sm.KeyPoolStats(); err != qkd.ErrKeyPoolNotConfigured {
+		t.Errorf("Expected ErrKeyPoolNotConfigured without a pool, got %v", err)
+	}
+	if err := sm.StartKeyPool(64, 1); err != qkd.ErrInvalidKeyLength {
+		t.Errorf("Expected ErrInvalidKeyLength for a 64-bit pool, got %v", err)
+	}
+	if err := sm.StartKeyPool(128, 1); err != nil {
+		t.Fatalf("StartKeyPool failed: %v", err)
+	}
+	waitForPool(t, sm.keyPool, 1)
+
+	exchange := func(keyLength int) (*qkd.QKDSession, *qkd.QuantumKey) {
+		t.Helper()
+		session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: keyLength})
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+			t.Fatalf("JoinSession failed: %v", err)
+		}
+		key, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+		if err != nil {
+			t.Fatalf("exchange failed: %v", err)
+		}
+		session, _ = sm.GetSession(session.SessionID)
+		return session, key
+	}
+
+	session, key := exchange(128)
+	if key.KeyLength != 128 || len(key.KeyMaterial) != 16 {
+		t.Errorf("Expected a 128-bit pooled key, got %d bits", key.KeyLength)
+	}
+	if session.Status != qkd.SessionCompleted || !strings.Contains(session.Message, "key pool") {
+		t.Errorf("Expected the session to complete from the pool, got %s: %s", session.Status, session.Message)
+	}
+	if _, err := sm.GetKey(key.KeyID, "bob"); err != nil {
+		t.Errorf("Expected the pooled key to be retrievable, got %v", err)
+	}
+
+	// The exchange that filled the pool is recorded for the session it served
+	if metrics, err := sm.GetSessionMetrics(session.SessionID); err != nil || metrics.SessionID != session.SessionID || metrics.FinalKeyLength != 128 {
+		t.Errorf("Expected metrics of the pooled exchange, got %+v (%v)", metrics, err)
+	}
+	if transcript, err := sm.GetTranscript(session.SessionID); err != nil || transcript.SessionID != session.SessionID || transcript.RawQubits == 0 {
+		t.Errorf("Expected the transcript of the pooled exchange, got %+v (%v)", transcript, err)
+	}
+	if _, err := sm.GetDisclosures(session.SessionID); err != nil {
+		t.Errorf("Expected the disclosures of the pooled exchange, got %v", err)
+	}
+
+	stats, err := sm.KeyPoolStats()
+	if err != nil || stats.Consumed != 1 || stats.KeyLength != 128 || stats.Target != 1 {
+		t.Fatalf("Expected one key drawn from a 128-bit pool, got %+v (%v)", stats, err)
+	}
+
+	// Other key lengths run their own exchange and leave the pool alone
+	session, key = exchange(256)
+	if key.KeyLength != 256 || strings.Contains(session.Message, "key pool") {
+		t.Errorf("Expected a 256-bit session to run its own exchange, got %d bits: %s", key.KeyLength, session.Message)
+	}
+	if stats, _ := sm.KeyPoolStats(); stats.Consumed != 1 {
+		t.Errorf("Expected the pool to stay untouched, got %d consumed", stats.Consumed)
+	}
+}
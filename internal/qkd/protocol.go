@@ -0,0 +1,120 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// Protocol is a prepare-and-measure (or entanglement-based) QKD protocol
+type Protocol interface {
+	// Name returns the protocol's short identifier, e.g. "bb84"
+	Name() string
+
+	// QBERThreshold returns the maximum QBER at which a key is still accepted
+	QBERThreshold() float64
+
+	// PerformKeyExchange runs the complete protocol between Alice and Bob,
+	// returning ctx.Err() if the context is cancelled before it completes
+	PerformKeyExchange(ctx context.Context) (*KeyExchangeResult, error)
+}
+
+// ProtocolFactory creates a protocol instance on a backend for a target key length
+type ProtocolFactory func(backend quantum.QuantumBackend, keyLength int) Protocol
+
+var (
+	protocolsMutex sync.RWMutex
+	protocols      = map[string]ProtocolFactory{
+		"bb84": func(backend quantum.QuantumBackend, keyLength int) Protocol {
+			return NewBB84Protocol(backend, keyLength)
+		},
+		"b92": func(backend quantum.QuantumBackend, keyLength int) Protocol {
+			return NewB92Protocol(backend, keyLength)
+		},
+		"sarg04": func(backend quantum.QuantumBackend, keyLength int) Protocol {
+			return NewSARG04Protocol(backend, keyLength)
+		},
+		"six-state": func(backend quantum.QuantumBackend, keyLength int) Protocol {
+			return NewSixStateProtocol(backend, keyLength)
+		},
+	}
+)
+
+// RegisterProtocol makes a protocol available by name
+func RegisterProtocol(name string, factory ProtocolFactory) {
+	protocolsMutex.Lock()
+	defer protocolsMutex.Unlock()
+
+	protocols[name] = factory
+}
+
+// Protocols returns the names of all registered protocols in sorted order
+func Protocols() []string {
+	protocolsMutex.RLock()
+	defer protocolsMutex.RUnlock()
+
+	names := make([]string, 0, len(protocols))
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewProtocol creates a registered protocol by name
+func NewProtocol(name string, backend quantum.QuantumBackend, keyLength int) (Protocol, error) {
+	protocolsMutex.RLock()
+	factory, exists := protocols[name]
+	protocolsMutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown protocol %q", name)
+	}
+
+	return factory(backend, keyLength), nil
+}
+
+// CompareProtocols runs every registered protocol over an identical simulated
+// channel and reports their sifting efficiency, QBER and secure-key fraction
+func CompareProtocols(ctx context.Context, noiseLevel float64, keyLength int) ([]qkd.ProtocolComparison, error) {
+	names := Protocols()
+	results := make([]qkd.ProtocolComparison, 0, len(names))
+
+	for _, name := range names {
+		backend := quantum.NewSimulatorBackend(noiseLevel > 0, noiseLevel)
+		protocol, err := NewProtocol(name, backend, keyLength)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := protocol.PerformKeyExchange(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		results = append(results, qkd.ProtocolComparison{
+			Protocol:          name,
+			SiftingEfficiency: result.SiftingEfficiency,
+			QBER:              result.QBER,
+			QBERThreshold:     protocol.QBERThreshold(),
+			SecureKeyFraction: result.SiftingEfficiency * secretFraction(result.QBER),
+			Secure:            result.Secure,
+			Message:           result.Message,
+		})
+	}
+
+	return results, nil
+}
+
+// secretFraction is the asymptotic fraction of sifted bits that survive error
+// correction and privacy amplification, 1 - 2h(QBER) (Shor-Preskill)
+func secretFraction(qber float64) float64 {
+	return math.Max(0, 1-2*crypto.BinaryEntropy(qber))
+}
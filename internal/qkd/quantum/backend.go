@@ -1,8 +1,11 @@
 package quantum
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"math/rand"
+	"time"
 )
 
 // QuantumBackend defines the interface for quantum computing backends
@@ -10,25 +13,43 @@ type QuantumBackend interface {
 	// Name returns the name of the quantum backend
 	Name() string
 
-	// PrepareAndSend prepares qubits and sends them through the quantum channel
-	PrepareAndSend(bits []Bit, bases []Basis) ([]Qubit, error)
+	// PrepareAndSend prepares qubits and sends them through the quantum channel.
+	// It returns ctx.Err() if the context is cancelled before the qubits are sent.
+	PrepareAndSend(ctx context.Context, bits []Bit, bases []Basis) ([]Qubit, error)
 
-	// ReceiveAndMeasure receives qubits and measures them in specified bases
-	ReceiveAndMeasure(qubits []Qubit, bases []Basis) ([]MeasurementResult, error)
+	// ReceiveAndMeasure receives qubits and measures them in specified bases.
+	// It returns ctx.Err() if the context is cancelled before measurement completes.
+	ReceiveAndMeasure(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error)
 
 	// GetNoiseLevel returns the current noise level of the backend
 	GetNoiseLevel() float64
 
 	// IsSimulator returns true if this is a simulator, false for real hardware
 	IsSimulator() bool
+
+	// HealthStatus probes whether the backend can currently run exchanges
+	HealthStatus(ctx context.Context) BackendHealth
 }
 
+// cancelCheckInterval is the number of qubits a simulator processes between context checks
+const cancelCheckInterval = 1024
+
+// SimulatorSlotDuration is the spacing of the synthetic detection timestamps the
+// simulator assigns, one time slot per transmitted qubit
+const SimulatorSlotDuration = 10 * time.Nanosecond
+
 // SimulatorBackend implements a quantum simulator for development and testing
 type SimulatorBackend struct {
 	name           string
 	channel        *QuantumChannel
 	simulateNoise  bool
 	noiseLevel     float64
+	targetQBER     float64 // Deterministic error fraction injected into matching-basis measurements
+	transmittance  float64 // Probability a single photon reaches Bob's detector (pulse mode)
+	darkCountRate  float64 // Probability of a spurious click per pulse (pulse mode)
+	noiseModel     NoiseModel // Replaces the symmetric bit-flip channel when set
+	customChannel  Channel    // Replaces the built-in channel entirely when set
+	rng            *rand.Rand // Simulation randomness; nil uses the global math/rand source
 }
 
 // NewSimulatorBackend creates a new quantum simulator backend
@@ -38,6 +59,7 @@ func NewSimulatorBackend(simulateNoise bool, noiseLevel float64) *SimulatorBacke
 		channel:       NewQuantumChannel(noiseLevel, 0.0),
 		simulateNoise: simulateNoise,
 		noiseLevel:    noiseLevel,
+		transmittance: 1.0,
 	}
 }
 
@@ -47,197 +69,131 @@ func (s *SimulatorBackend) Name() string {
 }
 
 // PrepareAndSend prepares qubits according to BB84 protocol and simulates transmission
-func (s *SimulatorBackend) PrepareAndSend(bits []Bit, bases []Basis) ([]Qubit, error) {
+func (s *SimulatorBackend) PrepareAndSend(ctx context.Context, bits []Bit, bases []Basis) ([]Qubit, error) {
 	if len(bits) != len(bases) {
 		return nil, fmt.Errorf("bits and bases must have the same length")
 	}
 
 	qubits := make([]Qubit, len(bits))
 	for i := range bits {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
 		// Prepare qubit in the specified basis
 		qubits[i] = PrepareQubit(bits[i], bases[i])
 
 		// Simulate transmission through quantum channel
-		if s.simulateNoise {
-			qubits[i] = s.channel.Transmit(qubits[i])
-		}
+		qubits[i] = s.transmit(qubits[i])
 	}
 
 	return qubits, nil
 }
 
 // ReceiveAndMeasure simulates receiving qubits and measuring them
-func (s *SimulatorBackend) ReceiveAndMeasure(qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+func (s *SimulatorBackend) ReceiveAndMeasure(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
 	if len(qubits) != len(bases) {
 		return nil, fmt.Errorf("qubits and bases must have the same length")
 	}
 
+	start := time.Now()
 	results := make([]MeasurementResult, len(qubits))
 	for i := range qubits {
-		results[i] = MeasureQubit(qubits[i], bases[i])
-	}
-
-	return results, nil
-}
-
-// GetNoiseLevel returns the noise level of the simulator
-func (s *SimulatorBackend) GetNoiseLevel() float64 {
-	return s.noiseLevel
-}
-
-// IsSimulator returns true since this is a simulator
-func (s *SimulatorBackend) IsSimulator() bool {
-	return true
-}
-
-// QiskitBackend implements integration with IBM Qiskit (placeholder for real implementation)
-type QiskitBackend struct {
-	name       string
-	apiKey     string
-	deviceName string
-	noiseLevel float64
-}
-
-// NewQiskitBackend creates a new Qiskit backend
-// Note: This is a placeholder. Real implementation would use Qiskit REST API
-func NewQiskitBackend(apiKey, deviceName string) *QiskitBackend {
-	return &QiskitBackend{
-		name:       "IBM-Qiskit-" + deviceName,
-		apiKey:     apiKey,
-		deviceName: deviceName,
-		noiseLevel: 0.02, // Typical NISQ device error rate
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		results[i] = measureQubit(qubits[i], bases[i], s.rng)
 	}
-}
 
-// Name returns the name of the Qiskit backend
-func (q *QiskitBackend) Name() string {
-	return q.name
-}
-
-// PrepareAndSend prepares qubits using IBM Qiskit
-// TODO: Implement actual Qiskit REST API integration
-func (q *QiskitBackend) PrepareAndSend(bits []Bit, bases []Basis) ([]Qubit, error) {
-	if len(bits) != len(bases) {
-		return nil, fmt.Errorf("bits and bases must have the same length")
+	if s.targetQBER > 0 {
+		s.injectEavesdropperSignature(qubits, results)
 	}
 
-	// Placeholder: In production, this would:
-	// 1. Create quantum circuit using Qiskit REST API
-	// 2. Apply X gate for |1⟩ states
-	// 3. Apply H gate for diagonal basis states
-	// 4. Execute circuit on IBM Quantum device
-	// 5. Return results
-
-	qubits := make([]Qubit, len(bits))
-	for i := range bits {
-		qubits[i] = PrepareQubit(bits[i], bases[i])
-
-		// Simulate realistic NISQ device noise
-		if rand.Float64() < q.noiseLevel {
-			qubits[i].ClassicalValue = 1 - qubits[i].ClassicalValue
+	// Time-tag each click by its slot and attribute it to the detector of its outcome
+	for i := range results {
+		if results[i].NoDetection {
+			continue
 		}
+		results[i].DetectionTime = start.Add(time.Duration(i) * SimulatorSlotDuration)
+		results[i].DetectorID = DetectorFor(results[i].MeasurementBasis, results[i].MeasuredBit)
 	}
 
-	return qubits, nil
+	return results, nil
 }
 
-// ReceiveAndMeasure measures qubits using IBM Qiskit
-// TODO: Implement actual Qiskit REST API integration
-func (q *QiskitBackend) ReceiveAndMeasure(qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
-	if len(qubits) != len(bases) {
-		return nil, fmt.Errorf("qubits and bases must have the same length")
+// SetSeed makes the simulated channel deterministic: loss, noise, eavesdropping
+// and mismatched-basis measurement outcomes are drawn from a source seeded with
+// seed, so two simulators with the same seed given the same bits and bases
+// produce the same measurements. Key bits and bases stay crypto-random.
+func (s *SimulatorBackend) SetSeed(seed int64) {
+	s.rng = NewSeededRand(seed)
+	s.channel.Rand = s.rng
+}
+
+// SetTargetQBER enables a deterministic eavesdropper signature: exactly
+// round(target * n) of the n measurements made in the preparation basis
+// (the bits that survive sifting) are flipped, evenly spaced. Combined with
+// a noiseless channel this yields a sifted-key QBER of exactly the target,
+// which lets tests exercise the security threshold without probabilistic noise.
+// A target of 0 disables the mode.
+func (s *SimulatorBackend) SetTargetQBER(target float64) {
+	if target >= 0 && target <= 1 {
+		s.targetQBER = target
 	}
+}
 
-	// Placeholder: In production, this would:
-	// 1. Create measurement circuit
-	// 2. Apply H gate before measurement for diagonal basis
-	// 3. Measure qubits
-	// 4. Execute on IBM Quantum device
-	// 5. Return measurement results
-
-	results := make([]MeasurementResult, len(qubits))
+// injectEavesdropperSignature flips an exact fraction of the matching-basis measurements
+func (s *SimulatorBackend) injectEavesdropperSignature(qubits []Qubit, results []MeasurementResult) {
+	matched := make([]int, 0, len(qubits))
 	for i := range qubits {
-		results[i] = MeasureQubit(qubits[i], bases[i])
+		if results[i].Conclusive() && results[i].MeasurementBasis == qubits[i].PreparationBasis {
+			matched = append(matched, i)
+		}
 	}
 
-	return results, nil
-}
-
-// GetNoiseLevel returns the noise level of the Qiskit backend
-func (q *QiskitBackend) GetNoiseLevel() float64 {
-	return q.noiseLevel
-}
-
-// IsSimulator returns false for Qiskit (real quantum hardware or IBM simulator)
-func (q *QiskitBackend) IsSimulator() bool {
-	return false
-}
-
-// BraketBackend implements integration with AWS Braket (placeholder)
-type BraketBackend struct {
-	name       string
-	region     string
-	deviceArn  string
-	noiseLevel float64
-}
-
-// NewBraketBackend creates a new AWS Braket backend
-// Note: This is a placeholder. Real implementation would use AWS SDK
-func NewBraketBackend(region, deviceArn string) *BraketBackend {
-	return &BraketBackend{
-		name:       "AWS-Braket-" + deviceArn,
-		region:     region,
-		deviceArn:  deviceArn,
-		noiseLevel: 0.015, // AWS Braket typical error rate
+	flips := int(math.Round(s.targetQBER * float64(len(matched))))
+	for j := 0; j < flips; j++ {
+		idx := matched[j*len(matched)/flips]
+		results[idx].MeasuredBit = 1 - results[idx].MeasuredBit
 	}
 }
 
-// Name returns the name of the Braket backend
-func (b *BraketBackend) Name() string {
-	return b.name
-}
-
-// PrepareAndSend prepares qubits using AWS Braket
-// TODO: Implement actual AWS Braket SDK integration
-func (b *BraketBackend) PrepareAndSend(bits []Bit, bases []Basis) ([]Qubit, error) {
-	if len(bits) != len(bases) {
-		return nil, fmt.Errorf("bits and bases must have the same length")
-	}
-
-	// Placeholder implementation
-	qubits := make([]Qubit, len(bits))
-	for i := range bits {
-		qubits[i] = PrepareQubit(bits[i], bases[i])
-
-		if rand.Float64() < b.noiseLevel {
-			qubits[i].ClassicalValue = 1 - qubits[i].ClassicalValue
-		}
+// SetInterceptProbability makes an intercept-resend eavesdropper measure each
+// qubit with the given probability before it reaches Bob
+func (s *SimulatorBackend) SetInterceptProbability(p float64) {
+	if p >= 0 && p <= 1 {
+		s.channel.InterceptProbability = p
 	}
+}
 
-	return qubits, nil
+// WithInterceptProbability returns a copy of the simulator with its own channel
+// and the given eavesdropper intercept probability, leaving s unchanged
+func (s *SimulatorBackend) WithInterceptProbability(p float64) *SimulatorBackend {
+	clone := *s
+	channel := *s.channel
+	clone.channel = &channel
+	clone.SetInterceptProbability(p)
+	return &clone
 }
 
-// ReceiveAndMeasure measures qubits using AWS Braket
-// TODO: Implement actual AWS Braket SDK integration
-func (b *BraketBackend) ReceiveAndMeasure(qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
-	if len(qubits) != len(bases) {
-		return nil, fmt.Errorf("qubits and bases must have the same length")
+// SetLossRate sets the probability that a photon is lost in the channel,
+// modelling the transmittance of a fibre of a given length
+func (s *SimulatorBackend) SetLossRate(rate float64) {
+	if rate >= 0 && rate < 1 {
+		s.channel.LossRate = rate
 	}
-
-	results := make([]MeasurementResult, len(qubits))
-	for i := range qubits {
-		results[i] = MeasureQubit(qubits[i], bases[i])
-	}
-
-	return results, nil
 }
 
-// GetNoiseLevel returns the noise level of the Braket backend
-func (b *BraketBackend) GetNoiseLevel() float64 {
-	return b.noiseLevel
+// GetNoiseLevel returns the noise level of the simulator
+func (s *SimulatorBackend) GetNoiseLevel() float64 {
+	return s.noiseLevel
 }
 
-// IsSimulator returns false for Braket
-func (b *BraketBackend) IsSimulator() bool {
-	return false
+// IsSimulator returns true since this is a simulator
+func (s *SimulatorBackend) IsSimulator() bool {
+	return true
 }
@@ -0,0 +1,54 @@
+package quantum
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSimulatorReportsDetectionMetadata(t *testing.T) {
+	backend := NewSimulatorBackend(false, 0.0)
+	backend.SetLossRate(0.5)
+
+	const n = 512
+	bits := GenerateRandomBits(n)
+	prepBases := GenerateRandomBases(n)
+	measBases := GenerateRandomBases(n)
+
+	qubits, err := backend.PrepareAndSend(context.Background(), bits, prepBases)
+	if err != nil {
+		t.Fatalf("PrepareAndSend failed: %v", err)
+	}
+	results, err := backend.ReceiveAndMeasure(context.Background(), qubits, measBases)
+	if err != nil {
+		t.Fatalf("ReceiveAndMeasure failed: %v", err)
+	}
+
+	first := -1
+	for i, result := range results {
+		if result.NoDetection {
+			if !result.DetectionTime.IsZero() {
+				t.Errorf("Slot %d had no detection but a detection time", i)
+			}
+			continue
+		}
+		if result.Inconclusive {
+			t.Errorf("Slot %d: expected the simulator to report conclusive clicks", i)
+		}
+		if want := DetectorFor(result.MeasurementBasis, result.MeasuredBit); result.DetectorID != want {
+			t.Errorf("Slot %d: expected detector %d, got %d", i, want, result.DetectorID)
+		}
+
+		// Timestamps are one slot apart per transmitted qubit, including lost ones
+		if first < 0 {
+			first = i
+			continue
+		}
+		if gap := result.DetectionTime.Sub(results[first].DetectionTime); gap != SimulatorSlotDuration*time.Duration(i-first) {
+			t.Errorf("Slot %d: expected %v after slot %d, got %v", i, SimulatorSlotDuration*time.Duration(i-first), first, gap)
+		}
+	}
+	if first < 0 {
+		t.Fatal("Expected some photons to be detected")
+	}
+}
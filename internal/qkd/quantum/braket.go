@@ -0,0 +1,555 @@
+package quantum
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// Braket task defaults
+const (
+	DefaultBraketPollInterval = 2 * time.Second
+	DefaultBraketMaxShots     = 100000
+	DefaultBraketNoiseLevel   = 0.015 // AWS Braket typical error rate
+)
+
+// ErrBraketNotConfigured is returned when a task is submitted without the
+// settings Braket requires
+var ErrBraketNotConfigured = errors.New("braket backend requires a region, device ARN and output S3 bucket")
+
+// BraketConfig configures the AWS Braket backend
+type BraketConfig struct {
+	Region            string // AWS region hosting the device, e.g. "us-east-1"
+	DeviceArn         string // Device to run on, e.g. "arn:aws:braket:::device/quantum-simulator/amazon/sv1"
+	OutputS3Bucket    string // Bucket Braket writes task results to
+	OutputS3KeyPrefix string // Key prefix for task results
+
+	// Credentials signs API requests; nil loads the default AWS credential chain
+	// (environment, shared config files, instance or task role)
+	Credentials aws.CredentialsProvider
+
+	Endpoint   string // Overrides https://braket.{region}.amazonaws.com
+	S3Endpoint string // Overrides the regional S3 endpoint; results are fetched path-style
+
+	PollInterval        time.Duration // Delay between task status checks
+	MaxShots            int           // Largest shot count submitted in a single task
+	NoiseLevel          float64       // Expected device error rate reported by GetNoiseLevel
+	FallbackToSimulator bool          // Measure on a local simulator when the Braket API fails
+
+	HTTPClient *http.Client
+}
+
+// BraketBackend runs BB84 circuits on an AWS Braket device. Qubits are only
+// prepared on the device once Bob's bases are known: ReceiveAndMeasure builds a
+// single circuit with one device qubit per (bit, preparation basis, measurement
+// basis) combination and runs it for as many shots as the largest group, so a
+// whole exchange needs one task rather than one per qubit.
+type BraketBackend struct {
+	name     string
+	config   BraketConfig
+	signer   *v4.Signer
+	fallback *SimulatorBackend
+
+	credentialsOnce sync.Once
+	credentials     aws.CredentialsProvider
+	credentialsErr  error
+}
+
+// NewBraketBackend creates a new AWS Braket backend using the default credential
+// chain. Tasks fail until an output bucket is configured; use NewBraketBackendWithConfig
+// to set one.
+func NewBraketBackend(region, deviceArn string) *BraketBackend {
+	return newBraketBackend(BraketConfig{Region: region, DeviceArn: deviceArn})
+}
+
+// NewBraketBackendWithConfig creates a Braket backend, validating that the
+// region, device and output bucket are set
+func NewBraketBackendWithConfig(cfg BraketConfig) (*BraketBackend, error) {
+	if cfg.Region == "" || cfg.DeviceArn == "" || cfg.OutputS3Bucket == "" {
+		return nil, ErrBraketNotConfigured
+	}
+	if cfg.MaxShots < 0 || cfg.PollInterval < 0 || cfg.NoiseLevel < 0 || cfg.NoiseLevel > 1 {
+		return nil, fmt.Errorf("invalid braket configuration")
+	}
+
+	return newBraketBackend(cfg), nil
+}
+
+// newBraketBackend fills in defaults for unset options
+func newBraketBackend(cfg BraketConfig) *BraketBackend {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = DefaultBraketPollInterval
+	}
+	if cfg.MaxShots == 0 {
+		cfg.MaxShots = DefaultBraketMaxShots
+	}
+	if cfg.NoiseLevel == 0 {
+		cfg.NoiseLevel = DefaultBraketNoiseLevel
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &BraketBackend{
+		name:     "AWS-Braket-" + cfg.DeviceArn,
+		config:   cfg,
+		signer:   v4.NewSigner(),
+		fallback: NewSimulatorBackend(true, cfg.NoiseLevel),
+	}
+}
+
+// Name returns the name of the Braket backend
+func (b *BraketBackend) Name() string {
+	return b.name
+}
+
+// PrepareAndSend records the states Alice prepares. The circuit runs on the
+// device in ReceiveAndMeasure, once the measurement bases are known.
+func (b *BraketBackend) PrepareAndSend(ctx context.Context, bits []Bit, bases []Basis) ([]Qubit, error) {
+	if len(bits) != len(bases) {
+		return nil, fmt.Errorf("bits and bases must have the same length")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	qubits := make([]Qubit, len(bits))
+	for i := range bits {
+		qubits[i] = PrepareQubit(bits[i], bases[i])
+	}
+
+	return qubits, nil
+}
+
+// ReceiveAndMeasure runs the prepared qubits through the device and measures
+// them in the given bases. When FallbackToSimulator is set, API failures other
+// than cancellation are logged and the qubits are measured locally instead.
+func (b *BraketBackend) ReceiveAndMeasure(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+	if len(qubits) != len(bases) {
+		return nil, fmt.Errorf("qubits and bases must have the same length")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results, err := b.measureOnDevice(ctx, qubits, bases)
+	if err == nil {
+		return results, nil
+	}
+	if ctx.Err() != nil || !b.config.FallbackToSimulator {
+		return nil, err
+	}
+
+	slog.WarnContext(ctx, "braket task failed, falling back to simulator", "device_arn", b.config.DeviceArn, "error", err)
+	return b.measureOnSimulator(ctx, qubits, bases)
+}
+
+// GetNoiseLevel returns the noise level of the Braket backend
+func (b *BraketBackend) GetNoiseLevel() float64 {
+	return b.config.NoiseLevel
+}
+
+// IsSimulator returns false for Braket
+func (b *BraketBackend) IsSimulator() bool {
+	return false
+}
+
+// measureOnSimulator replays the prepared states through the local fallback simulator
+func (b *BraketBackend) measureOnSimulator(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+	bits := make([]Bit, len(qubits))
+	prepBases := make([]Basis, len(qubits))
+	for i, q := range qubits {
+		bits[i] = q.ClassicalValue
+		prepBases[i] = q.PreparationBasis
+	}
+
+	sent, err := b.fallback.PrepareAndSend(ctx, bits, prepBases)
+	if err != nil {
+		return nil, err
+	}
+	return b.fallback.ReceiveAndMeasure(ctx, sent, bases)
+}
+
+// measureOnDevice submits one task per MaxShots qubits and collects the results
+func (b *BraketBackend) measureOnDevice(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+	results := make([]MeasurementResult, len(qubits))
+
+	for start := 0; start < len(qubits); start += b.config.MaxShots {
+		end := min(start+b.config.MaxShots, len(qubits))
+
+		circuit := newBraketCircuit(qubits[start:end], bases[start:end])
+		if circuit.shots() == 0 {
+			// Every photon in this batch was lost; nothing to run
+			for i := start; i < end; i++ {
+				results[i] = MeasureQubit(qubits[i], bases[i])
+			}
+			continue
+		}
+
+		taskArn, err := b.createQuantumTask(ctx, circuit)
+		if err != nil {
+			return nil, err
+		}
+
+		task, err := b.waitForTask(ctx, taskArn)
+		if err != nil {
+			return nil, err
+		}
+
+		shots, err := b.fetchResults(ctx, task)
+		if err != nil {
+			return nil, fmt.Errorf("braket task %s: %w", taskArn, err)
+		}
+
+		measured, err := circuit.results(shots)
+		if err != nil {
+			return nil, fmt.Errorf("braket task %s: %w", taskArn, err)
+		}
+		copy(results[start:end], measured)
+	}
+
+	return results, nil
+}
+
+// braketBases is the number of bases a combination may use, including the six-state circular basis
+const braketBases = 3
+
+// braketCombinations is the number of (bit, preparation basis, measurement basis) combinations
+const braketCombinations = 2 * braketBases * braketBases
+
+// braketCircuit maps a batch of qubits onto device qubits, one per combination in use
+type braketCircuit struct {
+	qubits []Qubit
+	bases  []Basis
+	target [braketCombinations]int   // Device qubit for each combination, or -1
+	groups [braketCombinations][]int // Batch indices sharing each combination, in shot order
+	used   []int                     // Combinations in device qubit order
+}
+
+// newBraketCircuit groups a batch of qubits by combination, skipping lost photons
+func newBraketCircuit(qubits []Qubit, bases []Basis) *braketCircuit {
+	c := &braketCircuit{qubits: qubits, bases: bases}
+	for i := range c.target {
+		c.target[i] = -1
+	}
+
+	for i, q := range qubits {
+		if q.Lost {
+			continue
+		}
+
+		combination := (int(q.ClassicalValue)*braketBases+int(q.PreparationBasis))*braketBases + int(bases[i])
+		if c.target[combination] < 0 {
+			c.target[combination] = len(c.used)
+			c.used = append(c.used, combination)
+		}
+		c.groups[combination] = append(c.groups[combination], i)
+	}
+
+	return c
+}
+
+// shots returns the number of shots needed: the size of the largest group
+func (c *braketCircuit) shots() int {
+	shots := 0
+	for _, group := range c.groups {
+		shots = max(shots, len(group))
+	}
+	return shots
+}
+
+// braketInstruction is a single gate in a Braket JAQCD program
+type braketInstruction struct {
+	Type   string `json:"type"`
+	Target int    `json:"target"`
+}
+
+// braketProgram is a Braket JAQCD (JSON Amazon Quantum Circuit Description) program
+type braketProgram struct {
+	Header struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"braketSchemaHeader"`
+	Instructions []braketInstruction `json:"instructions"`
+}
+
+// program builds the JAQCD program. X prepares |1⟩, H rotates into or out of
+// the diagonal basis and H with S (or its inverse, Si) into or out of the circular
+// basis; qubits without gates get an identity so every device qubit in use is measured.
+func (c *braketCircuit) program() braketProgram {
+	var p braketProgram
+	p.Header.Name = "braket.ir.jaqcd.program"
+	p.Header.Version = "1"
+
+	for target, combination := range c.used {
+		bit := combination / (braketBases * braketBases)
+		prepBasis := Basis(combination / braketBases % braketBases)
+		measBasis := Basis(combination % braketBases)
+
+		before := len(p.Instructions)
+		if bit == 1 {
+			p.Instructions = append(p.Instructions, braketInstruction{Type: "x", Target: target})
+		}
+		switch prepBasis {
+		case DiagonalBasis:
+			p.Instructions = append(p.Instructions, braketInstruction{Type: "h", Target: target})
+		case CircularBasis:
+			p.Instructions = append(p.Instructions,
+				braketInstruction{Type: "h", Target: target},
+				braketInstruction{Type: "s", Target: target})
+		}
+		switch measBasis {
+		case DiagonalBasis:
+			p.Instructions = append(p.Instructions, braketInstruction{Type: "h", Target: target})
+		case CircularBasis:
+			p.Instructions = append(p.Instructions,
+				braketInstruction{Type: "si", Target: target},
+				braketInstruction{Type: "h", Target: target})
+		}
+		if len(p.Instructions) == before {
+			p.Instructions = append(p.Instructions, braketInstruction{Type: "i", Target: target})
+		}
+	}
+
+	return p
+}
+
+// braketResults is the subset of a Braket GateModelTaskResult used here
+type braketResults struct {
+	Measurements   [][]int `json:"measurements"`
+	MeasuredQubits []int   `json:"measuredQubits"`
+}
+
+// results assigns the n-th shot of each device qubit to the n-th qubit of its group
+func (c *braketCircuit) results(shots *braketResults) ([]MeasurementResult, error) {
+	column := make(map[int]int, len(shots.MeasuredQubits))
+	for i, q := range shots.MeasuredQubits {
+		column[q] = i
+	}
+	if len(shots.MeasuredQubits) == 0 {
+		// Without an explicit list, columns follow device qubit order
+		for target := range c.used {
+			column[target] = target
+		}
+	}
+
+	results := make([]MeasurementResult, len(c.qubits))
+	for i, q := range c.qubits {
+		if q.Lost {
+			results[i] = MeasureQubit(q, c.bases[i])
+		}
+	}
+
+	for target, combination := range c.used {
+		col, ok := column[target]
+		if !ok {
+			return nil, fmt.Errorf("qubit %d was not measured", target)
+		}
+
+		group := c.groups[combination]
+		if len(shots.Measurements) < len(group) {
+			return nil, fmt.Errorf("expected %d shots, got %d", len(group), len(shots.Measurements))
+		}
+
+		for shot, i := range group {
+			row := shots.Measurements[shot]
+			if col >= len(row) || (row[col] != 0 && row[col] != 1) {
+				return nil, fmt.Errorf("malformed measurement in shot %d", shot)
+			}
+			results[i] = MeasurementResult{
+				MeasuredBit:      Bit(row[col]),
+				MeasurementBasis: c.bases[i],
+				DetectorID:       DetectorFor(c.bases[i], Bit(row[col])),
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// braketTask is the subset of the GetQuantumTask response used here
+type braketTask struct {
+	QuantumTaskArn    string `json:"quantumTaskArn"`
+	Status            string `json:"status"`
+	FailureReason     string `json:"failureReason"`
+	OutputS3Bucket    string `json:"outputS3Bucket"`
+	OutputS3Directory string `json:"outputS3Directory"`
+}
+
+// createQuantumTask submits the circuit and returns the task ARN
+func (b *BraketBackend) createQuantumTask(ctx context.Context, circuit *braketCircuit) (string, error) {
+	if b.config.Region == "" || b.config.DeviceArn == "" || b.config.OutputS3Bucket == "" {
+		return "", ErrBraketNotConfigured
+	}
+
+	action, err := json.Marshal(circuit.program())
+	if err != nil {
+		return "", err
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"action":            string(action),
+		"clientToken":       hex.EncodeToString(token),
+		"deviceArn":         b.config.DeviceArn,
+		"outputS3Bucket":    b.config.OutputS3Bucket,
+		"outputS3KeyPrefix": b.config.OutputS3KeyPrefix,
+		"shots":             circuit.shots(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var created struct {
+		QuantumTaskArn string `json:"quantumTaskArn"`
+	}
+	if err := b.call(ctx, http.MethodPost, b.braketURL("/quantum-task"), "braket", body, &created); err != nil {
+		return "", fmt.Errorf("braket CreateQuantumTask: %w", err)
+	}
+	if created.QuantumTaskArn == "" {
+		return "", fmt.Errorf("braket CreateQuantumTask: response has no task ARN")
+	}
+
+	return created.QuantumTaskArn, nil
+}
+
+// waitForTask polls a task until it completes, fails or ctx is cancelled
+func (b *BraketBackend) waitForTask(ctx context.Context, taskArn string) (*braketTask, error) {
+	for {
+		var task braketTask
+		if err := b.call(ctx, http.MethodGet, b.braketURL("/quantum-task/"+url.PathEscape(taskArn)), "braket", nil, &task); err != nil {
+			return nil, fmt.Errorf("braket GetQuantumTask: %w", err)
+		}
+
+		switch task.Status {
+		case "COMPLETED":
+			return &task, nil
+		case "FAILED", "CANCELLING", "CANCELLED":
+			return nil, fmt.Errorf("braket task %s %s: %s", taskArn, strings.ToLower(task.Status), task.FailureReason)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(b.config.PollInterval):
+		}
+	}
+}
+
+// fetchResults downloads results.json from the task's output directory
+func (b *BraketBackend) fetchResults(ctx context.Context, task *braketTask) (*braketResults, error) {
+	key := strings.TrimSuffix(task.OutputS3Directory, "/") + "/results.json"
+
+	var results braketResults
+	if err := b.call(ctx, http.MethodGet, b.s3URL(task.OutputS3Bucket, key), "s3", nil, &results); err != nil {
+		return nil, fmt.Errorf("failed to fetch results: %w", err)
+	}
+	if results.Measurements == nil {
+		return nil, fmt.Errorf("results contain no shot measurements")
+	}
+
+	return &results, nil
+}
+
+// braketURL returns the Braket API URL for a path
+func (b *BraketBackend) braketURL(path string) string {
+	endpoint := b.config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://braket." + b.config.Region + ".amazonaws.com"
+	}
+	return strings.TrimSuffix(endpoint, "/") + path
+}
+
+// s3URL returns the URL of an object, path-style when an S3 endpoint is configured
+func (b *BraketBackend) s3URL(bucket, key string) string {
+	escaped := (&url.URL{Path: "/" + key}).EscapedPath()
+	if b.config.S3Endpoint != "" {
+		return strings.TrimSuffix(b.config.S3Endpoint, "/") + "/" + bucket + escaped
+	}
+	return "https://" + bucket + ".s3." + b.config.Region + ".amazonaws.com" + escaped
+}
+
+// call sends a SigV4-signed request and decodes a JSON response into out
+func (b *BraketBackend) call(ctx context.Context, method, target, service string, body []byte, out any) error {
+	creds, err := b.resolveCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	if err := b.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), service, b.config.Region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := b.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// resolveCredentials retrieves credentials, loading the default chain on first use
+func (b *BraketBackend) resolveCredentials(ctx context.Context) (aws.Credentials, error) {
+	b.credentialsOnce.Do(func() {
+		if b.config.Credentials != nil {
+			b.credentials = b.config.Credentials
+			return
+		}
+
+		cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(b.config.Region))
+		if err != nil {
+			b.credentialsErr = fmt.Errorf("failed to load AWS configuration: %w", err)
+			return
+		}
+		b.credentials = cfg.Credentials
+	})
+	if b.credentialsErr != nil {
+		return aws.Credentials{}, b.credentialsErr
+	}
+	if b.credentials == nil {
+		return aws.Credentials{}, fmt.Errorf("no AWS credentials configured")
+	}
+
+	return b.credentials.Retrieve(ctx)
+}
@@ -0,0 +1,260 @@
+package quantum
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// fakeBraket stands in for the Braket and S3 APIs. Submitted JAQCD programs are
+// executed on a noiseless single-qubit state-vector model.
+type fakeBraket struct {
+	mutex    sync.Mutex
+	rng      *rand.Rand
+	failWith int // HTTP status returned by CreateQuantumTask when non-zero
+	programs []braketProgram
+	shots    []int
+	polls    int
+	results  map[string][]byte // S3 object path -> body
+}
+
+func newFakeBraket() *fakeBraket {
+	return &fakeBraket{rng: rand.New(rand.NewSource(1)), results: make(map[string][]byte)}
+}
+
+func (f *fakeBraket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		http.Error(w, `{"message":"missing signature"}`, http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/quantum-task":
+		if f.failWith != 0 {
+			http.Error(w, `{"message":"service unavailable"}`, f.failWith)
+			return
+		}
+
+		var req struct {
+			Action         string `json:"action"`
+			DeviceArn      string `json:"deviceArn"`
+			OutputS3Bucket string `json:"outputS3Bucket"`
+			Shots          int    `json:"shots"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var program braketProgram
+		if err := json.Unmarshal([]byte(req.Action), &program); err != nil {
+			http.Error(w, `{"message":"invalid action"}`, http.StatusBadRequest)
+			return
+		}
+		f.programs = append(f.programs, program)
+		f.shots = append(f.shots, req.Shots)
+
+		id := len(f.programs)
+		f.results["/"+req.OutputS3Bucket+"/tasks/"+strconv.Itoa(id)+"/results.json"] = f.run(program, req.Shots)
+		json.NewEncoder(w).Encode(map[string]string{"quantumTaskArn": "arn:aws:braket:us-east-1:123:quantum-task/" + strconv.Itoa(id)})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/quantum-task/"):
+		arn, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/quantum-task/"))
+		id := arn[strings.LastIndex(arn, "/")+1:]
+
+		// Report the task as running once before it completes
+		f.polls++
+		status := "COMPLETED"
+		if f.polls%2 == 1 {
+			status = "RUNNING"
+		}
+		json.NewEncoder(w).Encode(braketTask{
+			QuantumTaskArn:    arn,
+			Status:            status,
+			OutputS3Bucket:    "results-bucket",
+			OutputS3Directory: "tasks/" + id,
+		})
+
+	case r.Method == http.MethodGet:
+		body, ok := f.results[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// run executes a program for the given number of shots and encodes the results
+func (f *fakeBraket) run(program braketProgram, shots int) []byte {
+	gates := make(map[int][]string)
+	qubits := 0
+	for _, in := range program.Instructions {
+		gates[in.Target] = append(gates[in.Target], in.Type)
+		qubits = max(qubits, in.Target+1)
+	}
+
+	results := braketResults{MeasuredQubits: make([]int, qubits)}
+	for q := range results.MeasuredQubits {
+		results.MeasuredQubits[q] = q
+	}
+
+	for s := 0; s < shots; s++ {
+		row := make([]int, qubits)
+		for q := 0; q < qubits; q++ {
+			amp := [2]float64{1, 0}
+			for _, gate := range gates[q] {
+				switch gate {
+				case "x":
+					amp[0], amp[1] = amp[1], amp[0]
+				case "h":
+					amp[0], amp[1] = (amp[0]+amp[1])/1.4142135623730951, (amp[0]-amp[1])/1.4142135623730951
+				}
+			}
+			if f.rng.Float64() < amp[1]*amp[1] {
+				row[q] = 1
+			}
+		}
+		results.Measurements = append(results.Measurements, row)
+	}
+
+	data, _ := json.Marshal(results)
+	return data
+}
+
+// newTestBraketBackend points a Braket backend at a fake API server
+func newTestBraketBackend(t *testing.T, server *httptest.Server, fallback bool) *BraketBackend {
+	t.Helper()
+
+	backend, err := NewBraketBackendWithConfig(BraketConfig{
+		Region:              "us-east-1",
+		DeviceArn:           "arn:aws:braket:::device/quantum-simulator/amazon/sv1",
+		OutputS3Bucket:      "results-bucket",
+		Credentials:         credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+		Endpoint:            server.URL,
+		S3Endpoint:          server.URL,
+		PollInterval:        time.Millisecond,
+		FallbackToSimulator: fallback,
+	})
+	if err != nil {
+		t.Fatalf("NewBraketBackendWithConfig failed: %v", err)
+	}
+
+	return backend
+}
+
+func TestBraketBackendSubmitsCircuitAndParsesShots(t *testing.T) {
+	fake := newFakeBraket()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	backend := newTestBraketBackend(t, server, false)
+
+	const n = 400
+	r := rand.New(rand.NewSource(2))
+	bits := make([]Bit, n)
+	prepBases := make([]Basis, n)
+	measBases := make([]Basis, n)
+	for i := range bits {
+		bits[i] = Bit(r.Intn(2))
+		prepBases[i] = Basis(r.Intn(2))
+		measBases[i] = Basis(r.Intn(2))
+	}
+
+	qubits, err := backend.PrepareAndSend(context.Background(), bits, prepBases)
+	if err != nil {
+		t.Fatalf("PrepareAndSend failed: %v", err)
+	}
+	results, err := backend.ReceiveAndMeasure(context.Background(), qubits, measBases)
+	if err != nil {
+		t.Fatalf("ReceiveAndMeasure failed: %v", err)
+	}
+
+	// All eight combinations fit in one task with one device qubit each
+	if len(fake.programs) != 1 {
+		t.Fatalf("Expected a single task, got %d", len(fake.programs))
+	}
+	program := fake.programs[0]
+	if program.Header.Name != "braket.ir.jaqcd.program" {
+		t.Errorf("Unexpected program schema %q", program.Header.Name)
+	}
+	targets := make(map[int]bool)
+	for _, in := range program.Instructions {
+		targets[in.Target] = true
+	}
+	const bb84Combinations = 8 // Two bits, two preparation and two measurement bases
+	if len(targets) != bb84Combinations {
+		t.Errorf("Expected %d device qubits, got %d", bb84Combinations, len(targets))
+	}
+	if fake.shots[0] >= n || fake.shots[0] < n/bb84Combinations {
+		t.Errorf("Expected shots to match the largest group, got %d", fake.shots[0])
+	}
+
+	mismatched := 0
+	for i, result := range results {
+		if result.MeasurementBasis != measBases[i] {
+			t.Fatalf("Result %d has basis %v, want %v", i, result.MeasurementBasis, measBases[i])
+		}
+		if prepBases[i] == measBases[i] && result.MeasuredBit != bits[i] {
+			t.Errorf("Qubit %d measured in its preparation basis: got %d, want %d", i, result.MeasuredBit, bits[i])
+		}
+		if prepBases[i] != measBases[i] && result.MeasuredBit != bits[i] {
+			mismatched++
+		}
+	}
+
+	// Mismatched bases give random outcomes
+	if mismatched == 0 {
+		t.Error("Expected random outcomes when bases differ")
+	}
+}
+
+func TestBraketBackendFallsBackToSimulator(t *testing.T) {
+	fake := newFakeBraket()
+	fake.failWith = http.StatusServiceUnavailable
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	bits := []Bit{0, 1, 1, 0}
+	bases := []Basis{RectilinearBasis, DiagonalBasis, RectilinearBasis, DiagonalBasis}
+
+	strict := newTestBraketBackend(t, server, false)
+	qubits, _ := strict.PrepareAndSend(context.Background(), bits, bases)
+	if _, err := strict.ReceiveAndMeasure(context.Background(), qubits, bases); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected the API error without fallback, got %v", err)
+	}
+
+	lenient := newTestBraketBackend(t, server, true)
+	results, err := lenient.ReceiveAndMeasure(context.Background(), qubits, bases)
+	if err != nil {
+		t.Fatalf("Expected fallback to the simulator, got %v", err)
+	}
+	if len(results) != len(bits) {
+		t.Errorf("Expected %d results, got %d", len(bits), len(results))
+	}
+}
+
+func TestBraketBackendRequiresConfiguration(t *testing.T) {
+	if _, err := NewBraketBackendWithConfig(BraketConfig{Region: "us-east-1", DeviceArn: "sv1"}); err != ErrBraketNotConfigured {
+		t.Errorf("Expected ErrBraketNotConfigured without an output bucket, got %v", err)
+	}
+
+	// The convenience constructor has no bucket, so tasks fail before any request
+	backend := NewBraketBackend("us-east-1", "sv1")
+	qubits, _ := backend.PrepareAndSend(context.Background(), []Bit{1}, []Basis{RectilinearBasis})
+	if _, err := backend.ReceiveAndMeasure(context.Background(), qubits, []Basis{RectilinearBasis}); err != ErrBraketNotConfigured {
+		t.Errorf("Expected ErrBraketNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package quantum
+
+// Channel carries prepared qubits from Alice to Bob. *QuantumChannel is the
+// simulator's built-in channel; other implementations can model a specific
+// attack, such as photon-number splitting or a basis-dependent eavesdropper.
+type Channel interface {
+	// Transmit returns the qubit as it reaches Bob, or false if it was lost
+	Transmit(qubit Qubit) (Qubit, bool)
+}
+
+// NewSimulatorBackendWithChannel creates a simulator that sends every qubit
+// through channel. A *QuantumChannel becomes the simulator's built-in channel,
+// so SetSeed, SetLossRate and SetInterceptProbability apply to it. Any other
+// channel replaces loss, interception and noise entirely and reports a noise
+// level of 0, so post-processed exchanges over it need a fixed oversampling factor.
+func NewSimulatorBackendWithChannel(channel Channel) *SimulatorBackend {
+	if qc, ok := channel.(*QuantumChannel); ok {
+		s := NewSimulatorBackend(true, qc.NoiseLevel)
+		s.channel = qc
+		return s
+	}
+
+	s := NewSimulatorBackend(false, 0.0)
+	s.customChannel = channel
+	return s
+}
@@ -0,0 +1,77 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+)
+
+// diagonalFlipChannel is a basis-dependent attack that flips every qubit
+// prepared in the diagonal basis and passes the rest untouched
+type diagonalFlipChannel struct{}
+
+func (diagonalFlipChannel) Transmit(qubit Qubit) (Qubit, bool) {
+	if qubit.PreparationBasis == DiagonalBasis {
+		qubit.ClassicalValue = 1 - qubit.ClassicalValue
+	}
+	return qubit, true
+}
+
+func TestCustomChannelBasisDependentQBER(t *testing.T) {
+	backend := NewSimulatorBackendWithChannel(diagonalFlipChannel{})
+
+	if got := basisQBER(t, backend, RectilinearBasis, 2000); got != 0 {
+		t.Errorf("Expected rectilinear QBER 0, got %.3f", got)
+	}
+	if got := basisQBER(t, backend, DiagonalBasis, 2000); got != 1 {
+		t.Errorf("Expected diagonal QBER 1, got %.3f", got)
+	}
+}
+
+func TestQuantumChannelBecomesBuiltInChannel(t *testing.T) {
+	channel := NewQuantumChannel(0.1, 0.0)
+	backend := NewSimulatorBackendWithChannel(channel)
+
+	if backend.GetNoiseLevel() != 0.1 {
+		t.Errorf("Expected noise level 0.1, got %f", backend.GetNoiseLevel())
+	}
+	if got := basisQBER(t, backend, RectilinearBasis, 40000); math.Abs(got-0.1) > 0.01 {
+		t.Errorf("Expected QBER about 0.1, got %.3f", got)
+	}
+
+	backend.SetLossRate(0.5)
+	if channel.LossRate != 0.5 {
+		t.Errorf("Expected SetLossRate to configure the supplied channel, got loss rate %f", channel.LossRate)
+	}
+}
+
+func TestQuantumChannelPhaseFlipIsBasisDependent(t *testing.T) {
+	channel := &QuantumChannel{PhaseFlipProbability: 0.2}
+	const n = 20000
+
+	errorRate := func(basis Basis) float64 {
+		errors := 0
+		for _, bit := range GenerateRandomBits(n) {
+			qubit, _ := channel.Transmit(PrepareQubit(bit, basis))
+			if MeasureQubit(qubit, basis).MeasuredBit != bit {
+				errors++
+			}
+		}
+		return float64(errors) / n
+	}
+
+	if got := errorRate(RectilinearBasis); got != 0 {
+		t.Errorf("Expected no rectilinear errors from phase flips, got %.3f", got)
+	}
+	if got := errorRate(DiagonalBasis); math.Abs(got-0.2) > 0.02 {
+		t.Errorf("Expected diagonal error rate about 0.2, got %.3f", got)
+	}
+
+	// The simulator's built-in channel applies the same errors
+	backend := NewSimulatorBackendWithChannel(&QuantumChannel{BitFlipProbability: 0.2})
+	if got := basisQBER(t, backend, DiagonalBasis, n); got != 0 {
+		t.Errorf("Expected no diagonal errors from bit flips, got %.3f", got)
+	}
+	if got := basisQBER(t, backend, RectilinearBasis, n); math.Abs(got-0.2) > 0.02 {
+		t.Errorf("Expected rectilinear QBER about 0.2, got %.3f", got)
+	}
+}
@@ -0,0 +1,53 @@
+package quantum
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// The compact codec packs one bit (or one basis choice) per binary digit and
+// encodes the result as unpadded URL-safe base64. A 4096-element sequence is
+// therefore carried in 683 characters instead of a JSON array of integers.
+
+// EncodeBits encodes a slice of bits using the compact codec
+func EncodeBits(bits []Bit) string {
+	return base64.RawURLEncoding.EncodeToString(BitsToBytes(bits))
+}
+
+// DecodeBits decodes a compact-encoded string into exactly length bits
+func DecodeBits(encoded string, length int) ([]Bit, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compact encoding: %w", err)
+	}
+
+	if length < 0 || len(data) != (length+7)/8 {
+		return nil, fmt.Errorf("compact encoding holds %d bytes, expected %d for %d bits", len(data), (length+7)/8, length)
+	}
+
+	return BytesToBits(data, length), nil
+}
+
+// EncodeBases encodes a slice of bases using the compact codec
+// (0 = rectilinear, 1 = diagonal)
+func EncodeBases(bases []Basis) string {
+	bits := make([]Bit, len(bases))
+	for i, basis := range bases {
+		bits[i] = Bit(basis)
+	}
+	return EncodeBits(bits)
+}
+
+// DecodeBases decodes a compact-encoded string into exactly length bases
+func DecodeBases(encoded string, length int) ([]Basis, error) {
+	bits, err := DecodeBits(encoded, length)
+	if err != nil {
+		return nil, err
+	}
+
+	bases := make([]Basis, length)
+	for i, bit := range bits {
+		bases[i] = Basis(bit)
+	}
+	return bases, nil
+}
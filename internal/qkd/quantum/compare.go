@@ -0,0 +1,58 @@
+package quantum
+
+import (
+	"context"
+	"fmt"
+)
+
+// CompareBackends sends the same bits and bases through both backends, measures
+// each qubit in its preparation basis, and returns the fraction of positions at
+// which the two backends report the same bit. Two faithful backends agree on
+// (nearly) every position; channel noise on either side lowers the rate.
+func CompareBackends(ctx context.Context, a, b QuantumBackend, bits []Bit, bases []Basis) (float64, error) {
+	if len(bits) != len(bases) {
+		return 0, fmt.Errorf("bits and bases must have the same length")
+	}
+
+	if len(bits) == 0 {
+		return 0, fmt.Errorf("at least one bit is required")
+	}
+
+	resultsA, err := sendAndMeasure(ctx, a, bits, bases)
+	if err != nil {
+		return 0, fmt.Errorf("backend %s: %w", a.Name(), err)
+	}
+
+	resultsB, err := sendAndMeasure(ctx, b, bits, bases)
+	if err != nil {
+		return 0, fmt.Errorf("backend %s: %w", b.Name(), err)
+	}
+
+	agree := 0
+	for i := range resultsA {
+		if resultsA[i].MeasuredBit == resultsB[i].MeasuredBit {
+			agree++
+		}
+	}
+
+	return float64(agree) / float64(len(bits)), nil
+}
+
+// sendAndMeasure runs a full prepare/measure round with matching bases on one backend
+func sendAndMeasure(ctx context.Context, backend QuantumBackend, bits []Bit, bases []Basis) ([]MeasurementResult, error) {
+	qubits, err := backend.PrepareAndSend(ctx, bits, bases)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := backend.ReceiveAndMeasure(ctx, qubits, bases)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) != len(bits) {
+		return nil, fmt.Errorf("expected %d measurements, got %d", len(bits), len(results))
+	}
+
+	return results, nil
+}
@@ -0,0 +1,41 @@
+package quantum
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCompareBackendsNoiseless(t *testing.T) {
+	bits := GenerateRandomBits(10000)
+	bases := GenerateRandomBases(10000)
+
+	agreement, err := CompareBackends(context.Background(), NewIdealBackend(), NewSimulatorBackend(false, 0.0), bits, bases)
+	if err != nil {
+		t.Fatalf("CompareBackends failed: %v", err)
+	}
+
+	if agreement != 1.0 {
+		t.Errorf("Expected full agreement with a noiseless simulator, got %.4f", agreement)
+	}
+}
+
+func TestCompareBackendsNoisy(t *testing.T) {
+	bits := GenerateRandomBits(10000)
+	bases := GenerateRandomBases(10000)
+
+	agreement, err := CompareBackends(context.Background(), NewIdealBackend(), NewSimulatorBackend(true, 0.1), bits, bases)
+	if err != nil {
+		t.Fatalf("CompareBackends failed: %v", err)
+	}
+
+	if math.Abs(agreement-0.9) > 0.02 {
+		t.Errorf("Expected ~90%% agreement with a 10%% noise simulator, got %.4f", agreement)
+	}
+}
+
+func TestCompareBackendsLengthMismatch(t *testing.T) {
+	if _, err := CompareBackends(context.Background(), NewIdealBackend(), NewIdealBackend(), GenerateRandomBits(4), GenerateRandomBases(3)); err == nil {
+		t.Error("Expected an error for mismatched bits and bases")
+	}
+}
@@ -0,0 +1,92 @@
+package quantum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// ErrEntanglementUnsupported is returned by backends that cannot yet run
+// entangled-pair or GHZ circuits on their device
+var ErrEntanglementUnsupported = errors.New("backend cannot run entanglement circuits yet")
+
+// EntanglementSource is implemented by backends that can distribute entangled
+// pairs for entanglement-based protocols such as E91
+type EntanglementSource interface {
+	// MeasureEntangledPairs prepares one |Φ+⟩ pair per index and measures Alice's
+	// and Bob's halves at the given polarizer angles (radians)
+	MeasureEntangledPairs(ctx context.Context, aliceAngles, bobAngles []float64) ([]Bit, []Bit, error)
+}
+
+// BuildBellPairCircuit returns an OpenQASM 2.0 circuit that prepares a |Φ+⟩ pair
+// and measures each half at the given polarizer angle (radians)
+func BuildBellPairCircuit(aliceAngle, bobAngle float64) string {
+	// A polarizer at angle θ is a measurement along 2θ on the Bloch sphere,
+	// so rotate by -2θ about Y and measure in the computational basis
+	return fmt.Sprintf(`OPENQASM 2.0;
+include "qelib1.inc";
+qreg q[2];
+creg c[2];
+h q[0];
+cx q[0],q[1];
+ry(%.12f) q[0];
+ry(%.12f) q[1];
+measure q[0] -> c[0];
+measure q[1] -> c[1];
+`, -2*aliceAngle, -2*bobAngle)
+}
+
+// simulateBellPairs samples measurement outcomes of |Φ+⟩ pairs. Outcomes agree
+// with probability cos²(a-b); with probability noise Bob's half is depolarized
+// and his outcome is uniformly random. Outcomes are drawn from r, or from the
+// global source when r is nil.
+func simulateBellPairs(ctx context.Context, aliceAngles, bobAngles []float64, noise float64, r *rand.Rand) ([]Bit, []Bit, error) {
+	if len(aliceAngles) != len(bobAngles) {
+		return nil, nil, fmt.Errorf("alice and bob angles must have the same length")
+	}
+
+	aliceResults := make([]Bit, len(aliceAngles))
+	bobResults := make([]Bit, len(bobAngles))
+
+	for i := range aliceAngles {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		aliceResults[i] = randBit(r)
+
+		if noise > 0 && randFloat64(r) < noise {
+			bobResults[i] = randBit(r)
+			continue
+		}
+
+		agree := math.Pow(math.Cos(aliceAngles[i]-bobAngles[i]), 2)
+		bobResults[i] = aliceResults[i]
+		if randFloat64(r) >= agree {
+			bobResults[i] = 1 - bobResults[i]
+		}
+	}
+
+	return aliceResults, bobResults, nil
+}
+
+// MeasureEntangledPairs simulates EPR pairs, depolarized by the channel noise when enabled
+func (s *SimulatorBackend) MeasureEntangledPairs(ctx context.Context, aliceAngles, bobAngles []float64) ([]Bit, []Bit, error) {
+	noise := 0.0
+	if s.simulateNoise {
+		noise = s.noiseLevel
+	}
+	return simulateBellPairs(ctx, aliceAngles, bobAngles, noise, s.rng)
+}
+
+// MeasureEntangledPairs measures entangled pairs using IBM Qiskit. Outcomes are
+// never simulated in place of the device, so until the circuits are submitted it
+// fails with ErrEntanglementUnsupported.
+// TODO: Submit BuildBellPairCircuit for each pair via the Qiskit REST API
+func (q *QiskitBackend) MeasureEntangledPairs(ctx context.Context, aliceAngles, bobAngles []float64) ([]Bit, []Bit, error) {
+	return nil, nil, ErrEntanglementUnsupported
+}
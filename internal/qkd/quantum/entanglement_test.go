@@ -0,0 +1,84 @@
+package quantum
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+// correlation returns E = P(agree) - P(disagree) for measurements at fixed angles
+func correlation(t *testing.T, source EntanglementSource, a, b float64, n int) float64 {
+	t.Helper()
+
+	aliceAngles := make([]float64, n)
+	bobAngles := make([]float64, n)
+	for i := range aliceAngles {
+		aliceAngles[i] = a
+		bobAngles[i] = b
+	}
+
+	aliceResults, bobResults, err := source.MeasureEntangledPairs(context.Background(), aliceAngles, bobAngles)
+	if err != nil {
+		t.Fatalf("MeasureEntangledPairs failed: %v", err)
+	}
+
+	sum := 0
+	for i := range aliceResults {
+		if aliceResults[i] == bobResults[i] {
+			sum++
+		} else {
+			sum--
+		}
+	}
+	return float64(sum) / float64(n)
+}
+
+func TestSimulatorEntangledPairCorrelations(t *testing.T) {
+	sim := NewSimulatorBackend(false, 0.0)
+
+	for _, tt := range []struct{ a, b float64 }{
+		{0, 0},
+		{math.Pi / 4, math.Pi / 4},
+		{0, math.Pi / 8},
+		{0, math.Pi / 4},
+		{0, 3 * math.Pi / 8},
+	} {
+		want := math.Cos(2 * (tt.a - tt.b))
+		if got := correlation(t, sim, tt.a, tt.b, 20000); math.Abs(got-want) > 0.03 {
+			t.Errorf("E(%.3f, %.3f): expected %.3f, got %.3f", tt.a, tt.b, want, got)
+		}
+	}
+}
+
+func TestSimulatorEntangledPairNoise(t *testing.T) {
+	sim := NewSimulatorBackend(true, 0.4)
+
+	// Depolarizing noise scales every correlation by (1 - noise)
+	if got := correlation(t, sim, 0, 0, 20000); math.Abs(got-0.6) > 0.03 {
+		t.Errorf("Expected correlation ~0.6 with 40%% noise, got %.3f", got)
+	}
+}
+
+func TestMeasureEntangledPairsLengthMismatch(t *testing.T) {
+	if _, _, err := NewSimulatorBackend(false, 0.0).MeasureEntangledPairs(context.Background(), make([]float64, 2), make([]float64, 3)); err == nil {
+		t.Error("Expected error for mismatched angle slices")
+	}
+}
+
+func TestBuildBellPairCircuit(t *testing.T) {
+	circuit := BuildBellPairCircuit(math.Pi/8, math.Pi/4)
+
+	for _, want := range []string{
+		"OPENQASM 2.0;",
+		"h q[0];",
+		"cx q[0],q[1];",
+		"ry(-0.785398163397) q[0];",
+		"ry(-1.570796326795) q[1];",
+		"measure q[1] -> c[1];",
+	} {
+		if !strings.Contains(circuit, want) {
+			t.Errorf("Expected circuit to contain %q:\n%s", want, circuit)
+		}
+	}
+}
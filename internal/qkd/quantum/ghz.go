@@ -0,0 +1,139 @@
+package quantum
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+)
+
+// GHZSource is implemented by backends that can distribute N-party GHZ states
+// (|0…0⟩ + |1…1⟩)/√2 for conference key agreement
+type GHZSource interface {
+	// MeasureGHZ prepares one GHZ state per round, shared by len(bases) parties,
+	// and measures party p's qubit of round i in bases[p][i]. Outcomes are indexed
+	// the same way. Only the rectilinear and diagonal bases are supported.
+	MeasureGHZ(ctx context.Context, bases [][]Basis) ([][]Bit, error)
+}
+
+// BuildGHZStateCircuit returns a circuit that prepares a GHZ state over one qubit
+// per basis and measures each qubit in its basis
+func BuildGHZStateCircuit(bases []Basis, version QASMVersion) (string, error) {
+	if len(bases) < 2 {
+		return "", fmt.Errorf("a GHZ state needs at least 2 qubits, got %d", len(bases))
+	}
+
+	builder, err := NewQASMBuilder(len(bases), version)
+	if err != nil {
+		return "", err
+	}
+
+	builder.H(0)
+	for q := 1; q < len(bases); q++ {
+		builder.CX(0, q)
+	}
+	builder.Barrier()
+	builder.measureInBases(bases)
+
+	return builder.Build()
+}
+
+// checkGHZBases validates the shape and bases of a GHZ measurement request
+func checkGHZBases(bases [][]Basis) (int, error) {
+	if len(bases) < 2 {
+		return 0, fmt.Errorf("a GHZ state needs at least 2 parties, got %d", len(bases))
+	}
+
+	rounds := len(bases[0])
+	for p, partyBases := range bases {
+		if len(partyBases) != rounds {
+			return 0, fmt.Errorf("party %d has %d bases, expected %d", p, len(partyBases), rounds)
+		}
+		for _, basis := range partyBases {
+			if basis != RectilinearBasis && basis != DiagonalBasis {
+				return 0, fmt.Errorf("GHZ measurements support only the rectilinear and diagonal bases, got %s", basis)
+			}
+		}
+	}
+
+	return rounds, nil
+}
+
+// simulateGHZ samples measurement outcomes of GHZ states. When any party measures
+// in the rectilinear basis the state collapses: every rectilinear outcome is the
+// same random bit and every diagonal outcome is uniform. When all parties measure
+// in the diagonal basis the outcomes are uniform with even parity. With probability
+// noise each qubit but the first, which stays at the source, is depolarized and its
+// outcome is uniformly random. Outcomes are drawn from r, or from the global source
+// when r is nil.
+func simulateGHZ(ctx context.Context, bases [][]Basis, noise float64, r *rand.Rand) ([][]Bit, error) {
+	rounds, err := checkGHZBases(bases)
+	if err != nil {
+		return nil, err
+	}
+
+	parties := len(bases)
+	results := make([][]Bit, parties)
+	for p := range results {
+		results[p] = make([]Bit, rounds)
+	}
+
+	for i := 0; i < rounds; i++ {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		collapsed := false
+		for p := 0; p < parties; p++ {
+			if bases[p][i] == RectilinearBasis {
+				collapsed = true
+				break
+			}
+		}
+
+		if collapsed {
+			shared := randBit(r)
+			for p := 0; p < parties; p++ {
+				if bases[p][i] == RectilinearBasis {
+					results[p][i] = shared
+				} else {
+					results[p][i] = randBit(r)
+				}
+			}
+		} else {
+			parity := Zero
+			for p := 0; p < parties-1; p++ {
+				results[p][i] = randBit(r)
+				parity ^= results[p][i]
+			}
+			results[parties-1][i] = parity
+		}
+
+		if noise > 0 {
+			for p := 1; p < parties; p++ {
+				if randFloat64(r) < noise {
+					results[p][i] = randBit(r)
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// MeasureGHZ simulates GHZ states, depolarized by the channel noise when enabled
+func (s *SimulatorBackend) MeasureGHZ(ctx context.Context, bases [][]Basis) ([][]Bit, error) {
+	noise := 0.0
+	if s.simulateNoise {
+		noise = s.noiseLevel
+	}
+	return simulateGHZ(ctx, bases, noise, s.rng)
+}
+
+// MeasureGHZ measures GHZ states using IBM Qiskit. Like MeasureEntangledPairs, it
+// fails with ErrEntanglementUnsupported until the circuits are submitted.
+// TODO: Submit BuildGHZStateCircuit for each round via the Qiskit REST API
+func (q *QiskitBackend) MeasureGHZ(ctx context.Context, bases [][]Basis) ([][]Bit, error) {
+	return nil, ErrEntanglementUnsupported
+}
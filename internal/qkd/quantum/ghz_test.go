@@ -0,0 +1,91 @@
+package quantum
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// ghzBases returns bases for parties measuring n rounds, all in basis
+func ghzBases(parties, n int, basis Basis) [][]Basis {
+	bases := make([][]Basis, parties)
+	for p := range bases {
+		bases[p] = make([]Basis, n)
+		for i := range bases[p] {
+			bases[p][i] = basis
+		}
+	}
+	return bases
+}
+
+func TestSimulatorGHZCorrelations(t *testing.T) {
+	sim := NewSimulatorBackend(false, 0.0)
+	const parties, n = 4, 2000
+
+	// Rectilinear outcomes all agree
+	results, err := sim.MeasureGHZ(context.Background(), ghzBases(parties, n, RectilinearBasis))
+	if err != nil {
+		t.Fatalf("MeasureGHZ failed: %v", err)
+	}
+	ones := 0
+	for i := 0; i < n; i++ {
+		for p := 1; p < parties; p++ {
+			if results[p][i] != results[0][i] {
+				t.Fatalf("round %d: party %d disagrees with party 0 in the rectilinear basis", i, p)
+			}
+		}
+		ones += int(results[0][i])
+	}
+	if ones < n/3 || ones > 2*n/3 {
+		t.Errorf("Expected shared rectilinear outcomes to be balanced, got %d ones in %d", ones, n)
+	}
+
+	// Diagonal outcomes have even parity
+	results, err = sim.MeasureGHZ(context.Background(), ghzBases(parties, n, DiagonalBasis))
+	if err != nil {
+		t.Fatalf("MeasureGHZ failed: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		parity := Zero
+		for p := 0; p < parties; p++ {
+			parity ^= results[p][i]
+		}
+		if parity != Zero {
+			t.Fatalf("round %d: diagonal outcomes have odd parity", i)
+		}
+	}
+}
+
+func TestSimulatorGHZRejectsInvalidBases(t *testing.T) {
+	sim := NewSimulatorBackend(false, 0.0)
+
+	if _, err := sim.MeasureGHZ(context.Background(), ghzBases(1, 10, RectilinearBasis)); err == nil {
+		t.Error("Expected an error for a single party")
+	}
+	if _, err := sim.MeasureGHZ(context.Background(), ghzBases(3, 10, CircularBasis)); err == nil {
+		t.Error("Expected an error for the circular basis")
+	}
+
+	bases := ghzBases(3, 10, RectilinearBasis)
+	bases[2] = bases[2][:5]
+	if _, err := sim.MeasureGHZ(context.Background(), bases); err == nil {
+		t.Error("Expected an error for mismatched round counts")
+	}
+}
+
+func TestBuildGHZStateCircuit(t *testing.T) {
+	circuit, err := BuildGHZStateCircuit([]Basis{RectilinearBasis, DiagonalBasis, RectilinearBasis}, QASM2)
+	if err != nil {
+		t.Fatalf("BuildGHZStateCircuit failed: %v", err)
+	}
+
+	for _, want := range []string{"qreg q[3];", "h q[0];\ncx q[0],q[1];\ncx q[0],q[2];\nbarrier q;\nh q[1];\n", "measure q[2] -> c[2];"} {
+		if !strings.Contains(circuit, want) {
+			t.Errorf("Expected circuit to contain %q, got:\n%s", want, circuit)
+		}
+	}
+
+	if _, err := BuildGHZStateCircuit([]Basis{RectilinearBasis}, QASM2); err == nil {
+		t.Error("Expected an error for a single qubit")
+	}
+}
@@ -0,0 +1,79 @@
+package quantum
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// HealthState summarizes whether a backend can run exchanges
+type HealthState string
+
+const (
+	// HealthHealthy means the backend is reachable and ready
+	HealthHealthy HealthState = "healthy"
+	// HealthDegraded means the backend failed its probe but exchanges still run
+	// on the local fallback simulator
+	HealthDegraded HealthState = "degraded"
+	// HealthUnhealthy means exchanges on the backend will fail
+	HealthUnhealthy HealthState = "unhealthy"
+)
+
+// BackendHealth is the result of probing a backend
+type BackendHealth struct {
+	State   HealthState
+	Message string // Why the backend is not healthy
+}
+
+// probeFailed reports a failed probe, degraded when a fallback simulator takes over
+func probeFailed(fallback bool, format string, args ...any) BackendHealth {
+	state := HealthUnhealthy
+	if fallback {
+		state = HealthDegraded
+	}
+	return BackendHealth{State: state, Message: fmt.Sprintf(format, args...)}
+}
+
+// HealthStatus always reports the simulator healthy
+func (s *SimulatorBackend) HealthStatus(ctx context.Context) BackendHealth {
+	return BackendHealth{State: HealthHealthy}
+}
+
+// HealthStatus always reports the ideal backend healthy
+func (i *IdealBackend) HealthStatus(ctx context.Context) BackendHealth {
+	return BackendHealth{State: HealthHealthy}
+}
+
+// HealthStatus checks that the client is authenticated and the device is operational
+func (q *QiskitBackend) HealthStatus(ctx context.Context) BackendHealth {
+	client, err := q.getClient()
+	if err != nil {
+		return probeFailed(q.config.FallbackToSimulator, "qiskit authentication failed: %v", err)
+	}
+
+	status, err := client.BackendStatus(ctx)
+	if err != nil {
+		return probeFailed(q.config.FallbackToSimulator, "qiskit status check failed: %v", err)
+	}
+	if !status.Operational {
+		return probeFailed(q.config.FallbackToSimulator, "qiskit device %s is %s", q.config.Backend, status.Status)
+	}
+
+	return BackendHealth{State: HealthHealthy}
+}
+
+// HealthStatus checks that the device is online
+func (b *BraketBackend) HealthStatus(ctx context.Context) BackendHealth {
+	var device struct {
+		DeviceStatus string `json:"deviceStatus"`
+	}
+	if err := b.call(ctx, http.MethodGet, b.braketURL("/device/"+url.PathEscape(b.config.DeviceArn)), "braket", nil, &device); err != nil {
+		return probeFailed(b.config.FallbackToSimulator, "braket GetDevice: %v", err)
+	}
+	if device.DeviceStatus != "ONLINE" {
+		return probeFailed(b.config.FallbackToSimulator, "braket device is %s", device.DeviceStatus)
+	}
+
+	return BackendHealth{State: HealthHealthy}
+}
@@ -0,0 +1,67 @@
+package quantum
+
+import (
+	"context"
+	"fmt"
+)
+
+// IdealBackend is a perfect, noiseless reference backend. Measurements in the
+// preparation basis always reproduce the encoded bit; it is intended as a
+// baseline when validating other backends.
+type IdealBackend struct{}
+
+// NewIdealBackend creates a new ideal backend
+func NewIdealBackend() *IdealBackend {
+	return &IdealBackend{}
+}
+
+// Name returns the name of the ideal backend
+func (i *IdealBackend) Name() string {
+	return "Ideal"
+}
+
+// PrepareAndSend prepares qubits without any channel effects
+func (i *IdealBackend) PrepareAndSend(ctx context.Context, bits []Bit, bases []Basis) ([]Qubit, error) {
+	if len(bits) != len(bases) {
+		return nil, fmt.Errorf("bits and bases must have the same length")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	qubits := make([]Qubit, len(bits))
+	for j := range bits {
+		qubits[j] = PrepareQubit(bits[j], bases[j])
+	}
+
+	return qubits, nil
+}
+
+// ReceiveAndMeasure measures qubits in the specified bases
+func (i *IdealBackend) ReceiveAndMeasure(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+	if len(qubits) != len(bases) {
+		return nil, fmt.Errorf("qubits and bases must have the same length")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]MeasurementResult, len(qubits))
+	for j := range qubits {
+		results[j] = MeasureQubit(qubits[j], bases[j])
+	}
+
+	return results, nil
+}
+
+// GetNoiseLevel returns zero
+func (i *IdealBackend) GetNoiseLevel() float64 {
+	return 0
+}
+
+// IsSimulator returns true since the ideal backend is simulated
+func (i *IdealBackend) IsSimulator() bool {
+	return true
+}
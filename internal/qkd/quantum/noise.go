@@ -0,0 +1,174 @@
+package quantum
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// NoiseModel is a single-qubit noise channel applied during transmission.
+// Errors are tracked in the qubit's preparation basis: an X (bit-flip) error is
+// invisible in the diagonal basis and a Z (phase-flip) error in the rectilinear
+// basis, while the circular basis sees both, so the resulting QBER depends on the
+// basis like real hardware.
+type NoiseModel interface {
+	// Apply returns the qubit after passing through the noisy channel, drawing
+	// randomness from r (nil uses the global source)
+	Apply(q Qubit, r *rand.Rand) Qubit
+}
+
+// flipIf flips the qubit's encoded value with probability p
+func flipIf(q Qubit, p float64, r *rand.Rand) Qubit {
+	if p > 0 && randFloat64(r) < p {
+		q.ClassicalValue = 1 - q.ClassicalValue
+	}
+	return q
+}
+
+// BitFlipNoise applies an X error with probability P
+type BitFlipNoise struct {
+	P float64
+}
+
+// Apply flips rectilinear and circular states; diagonal states are eigenstates of X and unaffected
+func (n BitFlipNoise) Apply(q Qubit, r *rand.Rand) Qubit {
+	if q.PreparationBasis == DiagonalBasis {
+		return q
+	}
+	return flipIf(q, n.P, r)
+}
+
+// PhaseFlipNoise applies a Z error with probability P
+type PhaseFlipNoise struct {
+	P float64
+}
+
+// Apply flips diagonal and circular states; rectilinear states are eigenstates of Z and unaffected
+func (n PhaseFlipNoise) Apply(q Qubit, r *rand.Rand) Qubit {
+	if q.PreparationBasis == RectilinearBasis {
+		return q
+	}
+	return flipIf(q, n.P, r)
+}
+
+// DepolarizingNoise replaces the state with the maximally mixed state with probability P
+type DepolarizingNoise struct {
+	P float64
+}
+
+// Apply randomizes the qubit with probability P, giving an error rate of P/2 in either basis
+func (n DepolarizingNoise) Apply(q Qubit, r *rand.Rand) Qubit {
+	if n.P > 0 && randFloat64(r) < n.P {
+		q.ClassicalValue = randBit(r)
+	}
+	return q
+}
+
+// AmplitudeDampingNoise models energy relaxation (T1): |1⟩ decays to |0⟩ with
+// probability Gamma. Dephasing adds pure phase damping (T2 < 2·T1), which is
+// typical of superconducting hardware and makes diagonal states the more fragile.
+type AmplitudeDampingNoise struct {
+	Gamma     float64
+	Dephasing float64
+}
+
+// Apply relaxes rectilinear |1⟩ states and damps the coherence of diagonal and circular states
+func (n AmplitudeDampingNoise) Apply(q Qubit, r *rand.Rand) Qubit {
+	if q.PreparationBasis == RectilinearBasis {
+		if q.ClassicalValue == One {
+			return flipIf(q, n.Gamma, r)
+		}
+		return q
+	}
+
+	// Off-diagonal terms shrink by √((1-γ)(1-λ)); measuring |±⟩ or |±i⟩ in
+	// its own basis then errs with probability (1 - √((1-γ)(1-λ)))/2
+	coherence := math.Sqrt((1 - n.Gamma) * (1 - n.Dephasing))
+	return flipIf(q, (1-coherence)/2, r)
+}
+
+// BurstNoise models a channel whose errors arrive in bursts, as on fibre where a
+// disturbance corrupts a run of consecutive pulses rather than independent ones.
+// A qubit outside a burst starts one with probability P, each qubit inside one
+// ends it with probability 1/MeanLength, so burst lengths are geometric with mean
+// MeanLength, and every qubit in a burst is flipped. Unlike the other models it
+// keeps state between qubits, so it must see a transmission's qubits in order.
+type BurstNoise struct {
+	P          float64
+	MeanLength float64
+
+	mutex   sync.Mutex
+	inBurst bool
+}
+
+// NewBurstNoise creates a burst error model. P is clamped to [0, 1] and
+// meanLength to at least 1; a mean of 1 gives independent errors.
+func NewBurstNoise(p, meanLength float64) *BurstNoise {
+	return &BurstNoise{P: min(max(p, 0), 1), MeanLength: max(meanLength, 1)}
+}
+
+// ErrorRate returns the long-run fraction of flipped qubits, P·L / (1 + P·L)
+func (n *BurstNoise) ErrorRate() float64 {
+	return n.P * n.MeanLength / (1 + n.P*n.MeanLength)
+}
+
+// Apply advances the burst state by one qubit and flips the qubit inside a burst
+func (n *BurstNoise) Apply(q Qubit, r *rand.Rand) Qubit {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.inBurst {
+		n.inBurst = randFloat64(r) >= 1/n.MeanLength
+	} else {
+		n.inBurst = n.P > 0 && randFloat64(r) < n.P
+	}
+
+	if n.inBurst {
+		q.ClassicalValue = 1 - q.ClassicalValue
+	}
+	return q
+}
+
+// NewSimulatorBackendWithNoiseModel creates a simulator whose channel applies the given noise model
+func NewSimulatorBackendWithNoiseModel(model NoiseModel) *SimulatorBackend {
+	s := NewSimulatorBackend(false, 0.0)
+	s.noiseModel = model
+	return s
+}
+
+// SetNoiseModel replaces the simulator's symmetric bit-flip channel with a noise model.
+// A nil model restores the channel configured at construction.
+func (s *SimulatorBackend) SetNoiseModel(model NoiseModel) {
+	s.noiseModel = model
+}
+
+// SetBurstErrors replaces the simulator's channel noise with correlated burst
+// errors: bursts start with the given probability per qubit and last meanLength
+// qubits on average. The QBER matches independent errors at BurstNoise.ErrorRate,
+// but the errors cluster, which error correction must break up to find them.
+func (s *SimulatorBackend) SetBurstErrors(probability, meanLength float64) {
+	s.noiseModel = NewBurstNoise(probability, meanLength)
+}
+
+// transmit sends a prepared qubit through the simulated channel
+func (s *SimulatorBackend) transmit(q Qubit) Qubit {
+	if s.customChannel != nil {
+		q, ok := s.customChannel.Transmit(q)
+		if !ok {
+			q.Lost = true
+		}
+		return q
+	}
+	if s.channel.lose() {
+		q.Lost = true
+		return q
+	}
+	q = s.channel.intercept(q)
+	if s.noiseModel != nil {
+		return s.noiseModel.Apply(q, s.rng)
+	}
+	if s.simulateNoise {
+		return s.channel.flip(q)
+	}
+	return q
+}
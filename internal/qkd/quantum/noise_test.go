@@ -0,0 +1,134 @@
+package quantum
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// basisQBER sends n random bits prepared in basis through the backend, measures
+// them in the same basis and returns the error rate
+func basisQBER(t *testing.T, backend QuantumBackend, basis Basis, n int) float64 {
+	t.Helper()
+
+	bits := GenerateRandomBits(n)
+	bases := make([]Basis, n)
+	for i := range bases {
+		bases[i] = basis
+	}
+
+	qubits, err := backend.PrepareAndSend(context.Background(), bits, bases)
+	if err != nil {
+		t.Fatalf("PrepareAndSend failed: %v", err)
+	}
+	results, err := backend.ReceiveAndMeasure(context.Background(), qubits, bases)
+	if err != nil {
+		t.Fatalf("ReceiveAndMeasure failed: %v", err)
+	}
+
+	errors := 0
+	for i := range bits {
+		if results[i].MeasuredBit != bits[i] {
+			errors++
+		}
+	}
+	return float64(errors) / float64(n)
+}
+
+func TestNoiseModelBasisDependence(t *testing.T) {
+	const n = 40000
+
+	tests := []struct {
+		name                  string
+		model                 NoiseModel
+		rectilinear, diagonal float64
+	}{
+		{"bit-flip", BitFlipNoise{P: 0.1}, 0.1, 0},
+		{"phase-flip", PhaseFlipNoise{P: 0.1}, 0, 0.1},
+		{"depolarizing", DepolarizingNoise{P: 0.2}, 0.1, 0.1},
+		// Pure relaxation: only |1⟩ decays, averaging γ/2 over random bits
+		{"amplitude-damping", AmplitudeDampingNoise{Gamma: 0.2}, 0.1, (1 - math.Sqrt(0.8)) / 2},
+	}
+
+	for _, tt := range tests {
+		backend := NewSimulatorBackendWithNoiseModel(tt.model)
+
+		if got := basisQBER(t, backend, RectilinearBasis, n); math.Abs(got-tt.rectilinear) > 0.01 {
+			t.Errorf("%s: expected rectilinear QBER %.3f, got %.3f", tt.name, tt.rectilinear, got)
+		}
+		if got := basisQBER(t, backend, DiagonalBasis, n); math.Abs(got-tt.diagonal) > 0.01 {
+			t.Errorf("%s: expected diagonal QBER %.3f, got %.3f", tt.name, tt.diagonal, got)
+		}
+	}
+}
+
+func TestAmplitudeDampingDiagonalExceedsRectilinear(t *testing.T) {
+	const n = 40000
+
+	// Relaxation with the additional dephasing of real devices (T2 < 2·T1)
+	backend := NewSimulatorBackendWithNoiseModel(AmplitudeDampingNoise{Gamma: 0.05, Dephasing: 0.2})
+
+	rectilinear := basisQBER(t, backend, RectilinearBasis, n)
+	diagonal := basisQBER(t, backend, DiagonalBasis, n)
+
+	if diagonal <= rectilinear {
+		t.Errorf("Expected diagonal QBER (%.3f) to exceed rectilinear QBER (%.3f)", diagonal, rectilinear)
+	}
+}
+
+func TestAmplitudeDampingOnlyRelaxesOne(t *testing.T) {
+	backend := NewSimulatorBackendWithNoiseModel(AmplitudeDampingNoise{Gamma: 1})
+
+	for _, bit := range []Bit{Zero, One} {
+		qubits, _ := backend.PrepareAndSend(context.Background(), []Bit{bit}, []Basis{RectilinearBasis})
+		results, _ := backend.ReceiveAndMeasure(context.Background(), qubits, []Basis{RectilinearBasis})
+		if results[0].MeasuredBit != Zero {
+			t.Errorf("Expected |%d⟩ to relax to |0⟩ with γ = 1, measured %d", bit, results[0].MeasuredBit)
+		}
+	}
+}
+
+func TestBurstErrorsClusterAtTheSameRate(t *testing.T) {
+	const n = 200000
+	burst := NewBurstNoise(0.005, 8)
+	rate := burst.ErrorRate()
+
+	sim := NewSimulatorBackend(false, 0)
+	sim.SetSeed(1)
+	sim.SetBurstErrors(burst.P, burst.MeanLength)
+
+	// Compare against independent errors at the same rate
+	iid := NewSimulatorBackendWithNoiseModel(DepolarizingNoise{P: 2 * rate})
+	iid.SetSeed(2)
+
+	for _, tt := range []struct {
+		name    string
+		backend *SimulatorBackend
+		minRun  float64
+		maxRun  float64
+	}{
+		{"burst", sim, 6, 10},
+		{"iid", iid, 1, 1.2},
+	} {
+		bits := GenerateRandomBits(n)
+		bases := make([]Basis, n)
+		qubits, _ := tt.backend.PrepareAndSend(context.Background(), bits, bases)
+
+		errors, runs := 0, 0
+		for i, q := range qubits {
+			if q.ClassicalValue != bits[i] {
+				errors++
+				if i == 0 || qubits[i-1].ClassicalValue == bits[i-1] {
+					runs++
+				}
+			}
+		}
+
+		if qber := float64(errors) / n; math.Abs(qber-rate) > 0.006 {
+			t.Errorf("%s: expected QBER near %.4f, got %.4f", tt.name, rate, qber)
+		}
+		if mean := float64(errors) / float64(runs); mean < tt.minRun || mean > tt.maxRun {
+			t.Errorf("%s: expected mean error run length in [%.1f, %.1f], got %.2f", tt.name, tt.minRun, tt.maxRun, mean)
+		}
+	}
+}
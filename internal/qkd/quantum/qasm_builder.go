@@ -0,0 +1,316 @@
+package quantum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QASMVersion selects the OpenQASM dialect emitted by a QASMBuilder
+type QASMVersion int
+
+const (
+	// QASM2 emits OpenQASM 2.0 with qelib1.inc, qreg/creg and "measure q -> c"
+	QASM2 QASMVersion = 2
+	// QASM3 emits OpenQASM 3.0 with stdgates.inc, qubit[]/bit[] and "c = measure q"
+	QASM3 QASMVersion = 3
+)
+
+func (v QASMVersion) String() string {
+	switch v {
+	case QASM2:
+		return "2.0"
+	case QASM3:
+		return "3.0"
+	default:
+		return "unknown"
+	}
+}
+
+// qasmOp is a single gate or measurement on one qubit
+type qasmOp struct {
+	gate    string // "x", "h", "cx", "barrier" or "measure"
+	qubit   int
+	control int // Control qubit of a "cx"
+	classic int // Target classical bit for measurements
+}
+
+// QASMBuilder assembles an OpenQASM circuit over a single quantum and classical register
+type QASMBuilder struct {
+	version   QASMVersion
+	numQubits int
+	ops       []qasmOp
+}
+
+// NewQASMBuilder creates a builder for numQubits qubits in the given dialect
+func NewQASMBuilder(numQubits int, version QASMVersion) (*QASMBuilder, error) {
+	if version != QASM2 && version != QASM3 {
+		return nil, fmt.Errorf("unsupported OpenQASM version %d", int(version))
+	}
+	if numQubits <= 0 {
+		return nil, fmt.Errorf("circuit needs at least one qubit, got %d", numQubits)
+	}
+
+	return &QASMBuilder{version: version, numQubits: numQubits}, nil
+}
+
+// Version returns the dialect the builder emits
+func (b *QASMBuilder) Version() QASMVersion {
+	return b.version
+}
+
+// X applies a Pauli-X gate to qubit
+func (b *QASMBuilder) X(qubit int) *QASMBuilder {
+	b.ops = append(b.ops, qasmOp{gate: "x", qubit: qubit})
+	return b
+}
+
+// H applies a Hadamard gate to qubit
+func (b *QASMBuilder) H(qubit int) *QASMBuilder {
+	b.ops = append(b.ops, qasmOp{gate: "h", qubit: qubit})
+	return b
+}
+
+// S applies a phase gate to qubit
+func (b *QASMBuilder) S(qubit int) *QASMBuilder {
+	b.ops = append(b.ops, qasmOp{gate: "s", qubit: qubit})
+	return b
+}
+
+// Sdg applies the inverse phase gate to qubit
+func (b *QASMBuilder) Sdg(qubit int) *QASMBuilder {
+	b.ops = append(b.ops, qasmOp{gate: "sdg", qubit: qubit})
+	return b
+}
+
+// CX applies a controlled-NOT gate from control to target
+func (b *QASMBuilder) CX(control, target int) *QASMBuilder {
+	b.ops = append(b.ops, qasmOp{gate: "cx", qubit: target, control: control})
+	return b
+}
+
+// Barrier separates preparation from measurement across the whole register
+func (b *QASMBuilder) Barrier() *QASMBuilder {
+	b.ops = append(b.ops, qasmOp{gate: "barrier"})
+	return b
+}
+
+// Measure measures qubit into the classical bit with the same index
+func (b *QASMBuilder) Measure(qubit int) *QASMBuilder {
+	b.ops = append(b.ops, qasmOp{gate: "measure", qubit: qubit, classic: qubit})
+	return b
+}
+
+// MeasureAll measures every qubit into its matching classical bit
+func (b *QASMBuilder) MeasureAll() *QASMBuilder {
+	for q := 0; q < b.numQubits; q++ {
+		b.Measure(q)
+	}
+	return b
+}
+
+// Build renders the circuit as OpenQASM source
+func (b *QASMBuilder) Build() (string, error) {
+	var sb strings.Builder
+
+	switch b.version {
+	case QASM2:
+		sb.WriteString("OPENQASM 2.0;\n")
+		sb.WriteString("include \"qelib1.inc\";\n")
+		fmt.Fprintf(&sb, "qreg q[%d];\n", b.numQubits)
+		fmt.Fprintf(&sb, "creg c[%d];\n", b.numQubits)
+	case QASM3:
+		sb.WriteString("OPENQASM 3.0;\n")
+		sb.WriteString("include \"stdgates.inc\";\n")
+		fmt.Fprintf(&sb, "qubit[%d] q;\n", b.numQubits)
+		fmt.Fprintf(&sb, "bit[%d] c;\n", b.numQubits)
+	}
+
+	for _, op := range b.ops {
+		if op.gate == "barrier" {
+			sb.WriteString("barrier q;\n")
+			continue
+		}
+		if op.qubit < 0 || op.qubit >= b.numQubits {
+			return "", fmt.Errorf("qubit %d out of range for a %d-qubit circuit", op.qubit, b.numQubits)
+		}
+
+		switch {
+		case op.gate == "cx":
+			if op.control < 0 || op.control >= b.numQubits {
+				return "", fmt.Errorf("qubit %d out of range for a %d-qubit circuit", op.control, b.numQubits)
+			}
+			fmt.Fprintf(&sb, "cx q[%d],q[%d];\n", op.control, op.qubit)
+		case op.gate != "measure":
+			fmt.Fprintf(&sb, "%s q[%d];\n", op.gate, op.qubit)
+		case b.version == QASM2:
+			fmt.Fprintf(&sb, "measure q[%d] -> c[%d];\n", op.qubit, op.classic)
+		default:
+			fmt.Fprintf(&sb, "c[%d] = measure q[%d];\n", op.classic, op.qubit)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// prepareBB84 encodes each bit in its basis: X for a 1, then H for the diagonal
+// basis or H and S for the circular basis
+func (b *QASMBuilder) prepareBB84(bits []Bit, bases []Basis) {
+	for i, bit := range bits {
+		if bit == One {
+			b.X(i)
+		}
+		switch bases[i] {
+		case DiagonalBasis:
+			b.H(i)
+		case CircularBasis:
+			b.H(i).S(i)
+		}
+	}
+}
+
+// measureInBases rotates each qubit so that a computational-basis measurement
+// reads it out in the given basis, then measures the whole register
+func (b *QASMBuilder) measureInBases(bases []Basis) {
+	for i, basis := range bases {
+		switch basis {
+		case DiagonalBasis:
+			b.H(i)
+		case CircularBasis:
+			b.Sdg(i).H(i)
+		}
+	}
+	b.MeasureAll()
+}
+
+// BuildBB84AliceCircuit builds Alice's preparation circuit, measured in her own bases
+func BuildBB84AliceCircuit(bits []Bit, bases []Basis, version QASMVersion) (string, error) {
+	if len(bits) != len(bases) {
+		return "", fmt.Errorf("bits and bases length mismatch: %d vs %d", len(bits), len(bases))
+	}
+
+	builder, err := NewQASMBuilder(len(bits), version)
+	if err != nil {
+		return "", err
+	}
+
+	builder.prepareBB84(bits, bases)
+	builder.Barrier()
+	builder.measureInBases(bases)
+
+	return builder.Build()
+}
+
+// BuildBB84CombinedCircuit builds Alice's preparation followed by Bob's measurement
+func BuildBB84CombinedCircuit(aliceBits []Bit, aliceBases, bobBases []Basis, version QASMVersion) (string, error) {
+	if len(aliceBits) != len(aliceBases) || len(aliceBits) != len(bobBases) {
+		return "", fmt.Errorf("circuit inputs have mismatched lengths: %d bits, %d Alice bases, %d Bob bases",
+			len(aliceBits), len(aliceBases), len(bobBases))
+	}
+
+	builder, err := NewQASMBuilder(len(aliceBits), version)
+	if err != nil {
+		return "", err
+	}
+
+	builder.prepareBB84(aliceBits, aliceBases)
+	builder.Barrier()
+	builder.measureInBases(bobBases)
+
+	return builder.Build()
+}
+
+// BitOrder is the convention a provider uses to write classical bitstrings
+type BitOrder int
+
+const (
+	// BitOrderLittleEndian puts qubit 0 in the rightmost character, as Qiskit does
+	BitOrderLittleEndian BitOrder = iota
+	// BitOrderBigEndian puts qubit 0 in the leftmost character
+	BitOrderBigEndian
+)
+
+// normalizeOutcome strips the spaces Qiskit puts between classical registers
+func normalizeOutcome(outcome string) string {
+	return strings.ReplaceAll(outcome, " ", "")
+}
+
+// outcomeBit returns the value of qubit in a normalized bitstring
+func outcomeBit(outcome string, qubit int, order BitOrder) (Bit, bool) {
+	pos := len(outcome) - 1 - qubit
+	if order == BitOrderBigEndian {
+		pos = qubit
+	}
+	if pos < 0 || pos >= len(outcome) {
+		return Zero, false
+	}
+
+	switch outcome[pos] {
+	case '0':
+		return Zero, true
+	case '1':
+		return One, true
+	default:
+		return Zero, false
+	}
+}
+
+// ParseQASMResult returns the bits of the most frequent outcome in counts,
+// read in Qiskit's little-endian order
+func ParseQASMResult(counts map[string]int, numBits int) []Bit {
+	return ParseQASMResultOrdered(counts, numBits, BitOrderLittleEndian)
+}
+
+// ParseQASMResultOrdered returns the bits of the most frequent outcome in counts
+func ParseQASMResultOrdered(counts map[string]int, numBits int, order BitOrder) []Bit {
+	maxOutcome, maxCount := "", -1
+	for outcome, count := range counts {
+		// Break ties on the outcome itself so the result is deterministic
+		if count > maxCount || (count == maxCount && outcome < maxOutcome) {
+			maxOutcome, maxCount = outcome, count
+		}
+	}
+
+	maxOutcome = normalizeOutcome(maxOutcome)
+	bits := make([]Bit, numBits)
+	for i := range bits {
+		bits[i], _ = outcomeBit(maxOutcome, i, order)
+	}
+
+	return bits
+}
+
+// ParseQASMResultPerQubit returns the marginal probability of measuring 1 for
+// each qubit across all shots in counts, read in Qiskit's little-endian order
+func ParseQASMResultPerQubit(counts map[string]int, numBits int) []float64 {
+	return ParseQASMResultPerQubitOrdered(counts, numBits, BitOrderLittleEndian)
+}
+
+// ParseQASMResultPerQubitOrdered returns the marginal probability of measuring 1
+// for each qubit across all shots in counts
+func ParseQASMResultPerQubitOrdered(counts map[string]int, numBits int, order BitOrder) []float64 {
+	ones := make([]int, numBits)
+	shots := make([]int, numBits)
+
+	for outcome, count := range counts {
+		outcome = normalizeOutcome(outcome)
+		for i := 0; i < numBits; i++ {
+			bit, ok := outcomeBit(outcome, i, order)
+			if !ok {
+				continue
+			}
+			shots[i] += count
+			if bit == One {
+				ones[i] += count
+			}
+		}
+	}
+
+	marginals := make([]float64, numBits)
+	for i := range marginals {
+		if shots[i] > 0 {
+			marginals[i] = float64(ones[i]) / float64(shots[i])
+		}
+	}
+
+	return marginals
+}
@@ -0,0 +1,218 @@
+package quantum
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// qasmGrammar is the subset of each dialect's statement grammar the builder may emit
+var qasmGrammar = map[QASMVersion]struct {
+	header     []string
+	statements []*regexp.Regexp
+}{
+	QASM2: {
+		header: []string{`OPENQASM 2.0;`, `include "qelib1.inc";`},
+		statements: []*regexp.Regexp{
+			regexp.MustCompile(`^qreg q\[(\d+)\];$`),
+			regexp.MustCompile(`^creg c\[(\d+)\];$`),
+			regexp.MustCompile(`^(x|h|s|sdg) q\[(\d+)\];$`),
+			regexp.MustCompile(`^barrier q;$`),
+			regexp.MustCompile(`^measure q\[(\d+)\] -> c\[(\d+)\];$`),
+		},
+	},
+	QASM3: {
+		header: []string{`OPENQASM 3.0;`, `include "stdgates.inc";`},
+		statements: []*regexp.Regexp{
+			regexp.MustCompile(`^qubit\[(\d+)\] q;$`),
+			regexp.MustCompile(`^bit\[(\d+)\] c;$`),
+			regexp.MustCompile(`^(x|h|s|sdg) q\[(\d+)\];$`),
+			regexp.MustCompile(`^barrier q;$`),
+			regexp.MustCompile(`^c\[(\d+)\] = measure q\[(\d+)\];$`),
+		},
+	},
+}
+
+// parseQASM checks source against a dialect and returns the number of measurements
+func parseQASM(t *testing.T, source string, version QASMVersion, numQubits int) int {
+	t.Helper()
+
+	grammar := qasmGrammar[version]
+	lines := strings.Split(strings.TrimSuffix(source, "\n"), "\n")
+	if len(lines) < len(grammar.header)+2 {
+		t.Fatalf("Circuit too short:\n%s", source)
+	}
+	for i, want := range grammar.header {
+		if lines[i] != want {
+			t.Fatalf("Header line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+
+	measured := 0
+	for _, line := range lines[len(grammar.header):] {
+		matched := false
+		for s, stmt := range grammar.statements {
+			m := stmt.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			matched = true
+
+			// Register sizes must match and indices must stay in range
+			for _, group := range m[1:] {
+				if n, err := strconv.Atoi(group); err == nil && s >= 2 && n >= numQubits {
+					t.Errorf("Index out of range in %q", line)
+				}
+				if n, err := strconv.Atoi(group); err == nil && s < 2 && n != numQubits {
+					t.Errorf("Register size %d in %q, want %d", n, line, numQubits)
+				}
+			}
+			if s == len(grammar.statements)-1 {
+				measured++
+			}
+			break
+		}
+		if !matched {
+			t.Errorf("Statement %q is not valid OpenQASM %s", line, version)
+		}
+	}
+
+	return measured
+}
+
+func TestQASMBuilderBB84AliceCircuit(t *testing.T) {
+	bits := []Bit{0, 1, 1, 0}
+	bases := []Basis{RectilinearBasis, RectilinearBasis, DiagonalBasis, DiagonalBasis}
+
+	for _, version := range []QASMVersion{QASM2, QASM3} {
+		t.Run(version.String(), func(t *testing.T) {
+			source, err := BuildBB84AliceCircuit(bits, bases, version)
+			if err != nil {
+				t.Fatalf("BuildBB84AliceCircuit failed: %v", err)
+			}
+
+			if measured := parseQASM(t, source, version, len(bits)); measured != len(bits) {
+				t.Errorf("Expected %d measurements, got %d", len(bits), measured)
+			}
+			if strings.Count(source, "x q[") != 2 {
+				t.Errorf("Expected an X gate for each 1 bit:\n%s", source)
+			}
+
+			// Diagonal qubits are rotated in for preparation and back out for measurement
+			if strings.Count(source, "h q[2];") != 2 || strings.Count(source, "h q[3];") != 2 {
+				t.Errorf("Expected two H gates on each diagonal qubit:\n%s", source)
+			}
+		})
+	}
+}
+
+func TestQASMBuilderCircularBasis(t *testing.T) {
+	bits := []Bit{0, 1}
+	bases := []Basis{CircularBasis, CircularBasis}
+
+	for _, version := range []QASMVersion{QASM2, QASM3} {
+		t.Run(version.String(), func(t *testing.T) {
+			source, err := BuildBB84CombinedCircuit(bits, bases, []Basis{CircularBasis, DiagonalBasis}, version)
+			if err != nil {
+				t.Fatalf("BuildBB84CombinedCircuit failed: %v", err)
+			}
+			parseQASM(t, source, version, len(bits))
+
+			// H then S prepares |±i⟩; Sdg then H rotates it back for measurement
+			if !strings.Contains(source, "h q[0];\ns q[0];") {
+				t.Errorf("Expected H and S to prepare the circular states:\n%s", source)
+			}
+			if !strings.Contains(source, "sdg q[0];\nh q[0];") {
+				t.Errorf("Expected Sdg and H before measuring in the circular basis:\n%s", source)
+			}
+			if strings.Contains(source, "sdg q[1];") {
+				t.Errorf("Expected no Sdg on a qubit measured in the diagonal basis:\n%s", source)
+			}
+		})
+	}
+}
+
+func TestQASMBuilderDialectsDiffer(t *testing.T) {
+	v2, _ := NewQASMBuilder(2, QASM2)
+	v3, _ := NewQASMBuilder(2, QASM3)
+	source2, _ := v2.X(0).Measure(0).Build()
+	source3, _ := v3.X(0).Measure(0).Build()
+
+	if strings.Contains(source2, "stdgates.inc") || strings.Contains(source2, "bit[") {
+		t.Errorf("OpenQASM 2.0 output uses 3.0 syntax:\n%s", source2)
+	}
+	if strings.Contains(source3, "qelib1.inc") || strings.Contains(source3, "creg") || strings.Contains(source3, "->") {
+		t.Errorf("OpenQASM 3.0 output uses 2.0 syntax:\n%s", source3)
+	}
+	if !strings.Contains(source3, "c[0] = measure q[0];") {
+		t.Errorf("Expected 3.0 measurement assignment:\n%s", source3)
+	}
+}
+
+func TestQASMBuilderErrors(t *testing.T) {
+	if _, err := NewQASMBuilder(4, QASMVersion(1)); err == nil {
+		t.Error("Expected error for unsupported version")
+	}
+	if _, err := NewQASMBuilder(0, QASM3); err == nil {
+		t.Error("Expected error for an empty circuit")
+	}
+
+	builder, _ := NewQASMBuilder(2, QASM3)
+	if _, err := builder.H(2).Build(); err == nil {
+		t.Error("Expected error for an out-of-range qubit")
+	}
+
+	if _, err := BuildBB84CombinedCircuit([]Bit{0, 1}, []Basis{0, 1}, []Basis{0}, QASM2); err == nil {
+		t.Error("Expected error for mismatched lengths")
+	}
+}
+
+func TestParseQASMResultPerQubit(t *testing.T) {
+	// Qubit 0 is the rightmost character: it reads 1 in 75 of 100 shots,
+	// qubit 1 in 50 and qubit 2 never
+	counts := map[string]int{
+		"001": 25,
+		"011": 50,
+		"000": 25,
+	}
+
+	marginals := ParseQASMResultPerQubit(counts, 3)
+	want := []float64{0.75, 0.5, 0}
+	for i := range want {
+		if marginals[i] != want[i] {
+			t.Errorf("Qubit %d marginal = %v, want %v", i, marginals[i], want[i])
+		}
+	}
+
+	// Spaces between registers do not shift qubit positions
+	spaced := ParseQASMResultPerQubit(map[string]int{"0 01": 10}, 3)
+	if spaced[0] != 1 || spaced[1] != 0 || spaced[2] != 0 {
+		t.Errorf("Expected [1 0 0] for a spaced outcome, got %v", spaced)
+	}
+}
+
+func TestParseQASMResultLittleEndian(t *testing.T) {
+	// Only qubit 0 is set, which Qiskit reports as the last character
+	bits := ParseQASMResult(map[string]int{"0001": 900, "1000": 100}, 4)
+	want := []Bit{1, 0, 0, 0}
+	for i := range want {
+		if bits[i] != want[i] {
+			t.Fatalf("ParseQASMResult = %v, want %v", bits, want)
+		}
+	}
+}
+
+func TestParseQASMResultBigEndian(t *testing.T) {
+	counts := map[string]int{"1000": 900, "0001": 100}
+
+	bits := ParseQASMResultOrdered(counts, 4, BitOrderBigEndian)
+	if bits[0] != 1 || bits[3] != 0 {
+		t.Errorf("Expected qubit 0 to be the leftmost character, got %v", bits)
+	}
+
+	marginals := ParseQASMResultPerQubitOrdered(counts, 4, BitOrderBigEndian)
+	if marginals[0] != 0.9 || marginals[3] != 0.1 {
+		t.Errorf("Unexpected big-endian marginals %v", marginals)
+	}
+}
@@ -0,0 +1,288 @@
+package quantum
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Qiskit backend defaults
+const (
+	DefaultQiskitShots       = 1024
+	DefaultQiskitMaxWaitTime = 5 * time.Minute
+	DefaultQiskitNoiseLevel  = 0.02 // Typical NISQ device error rate
+
+	// DefaultQiskitMaxCircuitQubits matches the 127-qubit IBM Eagle devices
+	DefaultQiskitMaxCircuitQubits = 127
+)
+
+// QiskitBackendConfig configures the IBM Qiskit backend
+type QiskitBackendConfig struct {
+	QiskitConfig
+
+	Shots               int               // Shots per circuit; each qubit's bit is its majority outcome
+	MaxWaitTime         time.Duration     // Longest to wait for a job to complete
+	Polling             QiskitPollOptions // Job poll interval and backoff; its MaxWaitTime is ignored
+	QASMVersion         QASMVersion       // Dialect of submitted circuits, defaults to QASM2
+	BitOrder            BitOrder          // Order of result bitstrings, defaults to Qiskit's little-endian
+	NoiseLevel          float64           // Expected device error rate reported by GetNoiseLevel
+	FallbackToSimulator bool              // Measure on a local simulator when the Qiskit API fails
+
+	MaxCircuitQubits  int // Largest circuit submitted in a single job; longer exchanges are split
+	MaxConcurrentJobs int // Jobs of one exchange that may run at once, defaults to 1
+}
+
+// QiskitBackend runs BB84 circuits on IBM Quantum. Like the Braket backend,
+// preparation is deferred to ReceiveAndMeasure, which submits circuits with
+// Alice's preparation followed by Bob's measurement on each qubit, at most
+// MaxCircuitQubits qubits per job.
+type QiskitBackend struct {
+	name     string
+	config   QiskitBackendConfig
+	fallback *SimulatorBackend
+
+	clientOnce sync.Once
+	client     *QiskitClient
+	clientErr  error
+}
+
+// NewQiskitBackend creates a new Qiskit backend. The client authenticates on first use.
+func NewQiskitBackend(apiKey, deviceName string) *QiskitBackend {
+	return newQiskitBackend(QiskitBackendConfig{
+		QiskitConfig: QiskitConfig{APIToken: apiKey, Backend: deviceName},
+	})
+}
+
+// NewQiskitBackendWithConfig creates a Qiskit backend and authenticates its client
+func NewQiskitBackendWithConfig(cfg QiskitBackendConfig) (*QiskitBackend, error) {
+	if cfg.Shots < 0 || cfg.MaxWaitTime < 0 || cfg.NoiseLevel < 0 || cfg.NoiseLevel > 1 ||
+		cfg.Polling.Interval < 0 || cfg.Polling.MaxInterval < 0 || cfg.Polling.Backoff < 0 ||
+		cfg.MaxCircuitQubits < 0 || cfg.MaxConcurrentJobs < 0 {
+		return nil, fmt.Errorf("invalid qiskit configuration")
+	}
+
+	backend := newQiskitBackend(cfg)
+	if _, err := backend.getClient(); err != nil {
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+// newQiskitBackend fills in defaults for unset options
+func newQiskitBackend(cfg QiskitBackendConfig) *QiskitBackend {
+	if cfg.Shots == 0 {
+		cfg.Shots = DefaultQiskitShots
+	}
+	if cfg.MaxWaitTime == 0 {
+		cfg.MaxWaitTime = DefaultQiskitMaxWaitTime
+	}
+	if cfg.QASMVersion == 0 {
+		cfg.QASMVersion = QASM2
+	}
+	if cfg.NoiseLevel == 0 {
+		cfg.NoiseLevel = DefaultQiskitNoiseLevel
+	}
+	if cfg.MaxCircuitQubits == 0 {
+		cfg.MaxCircuitQubits = DefaultQiskitMaxCircuitQubits
+	}
+	if cfg.MaxConcurrentJobs == 0 {
+		cfg.MaxConcurrentJobs = 1
+	}
+
+	return &QiskitBackend{
+		name:     "IBM-Qiskit-" + cfg.Backend,
+		config:   cfg,
+		fallback: NewSimulatorBackend(true, cfg.NoiseLevel),
+	}
+}
+
+// getClient creates and authenticates the API client once
+func (q *QiskitBackend) getClient() (*QiskitClient, error) {
+	q.clientOnce.Do(func() {
+		q.client, q.clientErr = NewQiskitClient(q.config.QiskitConfig)
+	})
+	return q.client, q.clientErr
+}
+
+// Name returns the name of the Qiskit backend
+func (q *QiskitBackend) Name() string {
+	return q.name
+}
+
+// PrepareAndSend records the states Alice prepares. The circuit runs on the
+// device in ReceiveAndMeasure, once the measurement bases are known.
+func (q *QiskitBackend) PrepareAndSend(ctx context.Context, bits []Bit, bases []Basis) ([]Qubit, error) {
+	if len(bits) != len(bases) {
+		return nil, fmt.Errorf("bits and bases must have the same length")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	qubits := make([]Qubit, len(bits))
+	for i := range bits {
+		qubits[i] = PrepareQubit(bits[i], bases[i])
+	}
+
+	return qubits, nil
+}
+
+// ReceiveAndMeasure runs the prepared qubits through the device and measures
+// them in the given bases. When FallbackToSimulator is set, API failures other
+// than cancellation are logged and the qubits are measured locally instead.
+func (q *QiskitBackend) ReceiveAndMeasure(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+	if len(qubits) != len(bases) {
+		return nil, fmt.Errorf("qubits and bases must have the same length")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results, err := q.measureOnDevice(ctx, qubits, bases)
+	if err == nil {
+		return results, nil
+	}
+	if ctx.Err() != nil || !q.config.FallbackToSimulator {
+		return nil, err
+	}
+
+	slog.WarnContext(ctx, "qiskit job failed, falling back to simulator", "backend", q.config.Backend, "error", err)
+	return q.measureOnSimulator(ctx, qubits, bases)
+}
+
+// GetNoiseLevel returns the noise level of the Qiskit backend
+func (q *QiskitBackend) GetNoiseLevel() float64 {
+	return q.config.NoiseLevel
+}
+
+// IsSimulator returns false for Qiskit (real quantum hardware or IBM simulator)
+func (q *QiskitBackend) IsSimulator() bool {
+	return false
+}
+
+// measureOnSimulator replays the prepared states through the local fallback simulator
+func (q *QiskitBackend) measureOnSimulator(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+	bits := make([]Bit, len(qubits))
+	prepBases := make([]Basis, len(qubits))
+	for i, qubit := range qubits {
+		bits[i] = qubit.ClassicalValue
+		prepBases[i] = qubit.PreparationBasis
+	}
+
+	sent, err := q.fallback.PrepareAndSend(ctx, bits, prepBases)
+	if err != nil {
+		return nil, err
+	}
+	return q.fallback.ReceiveAndMeasure(ctx, sent, bases)
+}
+
+// measureOnDevice splits the qubits into batches of at most MaxCircuitQubits,
+// runs each as its own job, up to MaxConcurrentJobs at a time, and reassembles
+// the results in order. The first failing job cancels the others.
+func (q *QiskitBackend) measureOnDevice(ctx context.Context, qubits []Qubit, bases []Basis) ([]MeasurementResult, error) {
+	client, err := q.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]MeasurementResult, len(qubits))
+	slots := make(chan struct{}, q.config.MaxConcurrentJobs)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for start := 0; start < len(qubits); start += q.config.MaxCircuitQubits {
+		end := min(start+q.config.MaxCircuitQubits, len(qubits))
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := q.measureBatch(ctx, client, qubits[start:end], bases[start:end], results[start:end]); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// measureBatch submits one combined circuit and thresholds each qubit's
+// marginal probability of measuring 1 across all shots into results. A qubit
+// whose shots split evenly has no majority outcome and is reported as inconclusive.
+func (q *QiskitBackend) measureBatch(ctx context.Context, client *QiskitClient, qubits []Qubit, bases []Basis, results []MeasurementResult) error {
+	bits := make([]Bit, len(qubits))
+	prepBases := make([]Basis, len(qubits))
+	for i, qubit := range qubits {
+		bits[i] = qubit.ClassicalValue
+		prepBases[i] = qubit.PreparationBasis
+	}
+
+	circuit, err := BuildBB84CombinedCircuit(bits, prepBases, bases, q.config.QASMVersion)
+	if err != nil {
+		return err
+	}
+
+	jobID, err := client.SubmitJob(ctx, circuit, q.config.Shots)
+	if err != nil {
+		return err
+	}
+
+	polling := q.config.Polling
+	polling.MaxWaitTime = q.config.MaxWaitTime
+	result, err := client.WaitForJobContext(ctx, jobID, polling)
+	if err != nil {
+		return err
+	}
+	if len(result.Counts) == 0 {
+		return fmt.Errorf("qiskit job %s returned no counts", jobID)
+	}
+
+	marginals := ParseQASMResultPerQubitOrdered(result.Counts, len(qubits), q.config.BitOrder)
+	for i, p := range marginals {
+		if qubits[i].Lost {
+			results[i] = MeasureQubit(qubits[i], bases[i])
+			continue
+		}
+
+		bit := Zero
+		if p > 0.5 {
+			bit = One
+		}
+		results[i] = MeasurementResult{
+			MeasuredBit:      bit,
+			MeasurementBasis: bases[i],
+			DetectorID:       DetectorFor(bases[i], bit),
+			Inconclusive:     p == 0.5,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,458 @@
+package quantum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Qiskit client defaults
+const (
+	DefaultQiskitBaseURL      = "https://api.quantum-computing.ibm.com"
+	DefaultQiskitPollInterval = 2 * time.Second
+
+	// DefaultQiskitRuntimeURL is the Qiskit Runtime API used by IBM Cloud accounts
+	DefaultQiskitRuntimeURL = "https://quantum.cloud.ibm.com/api/v1"
+	// DefaultIBMCloudIAMURL exchanges an IBM Cloud API key for a bearer token
+	DefaultIBMCloudIAMURL = "https://iam.cloud.ibm.com/identity/token"
+
+	// qiskitRuntimeProgram runs circuits through the Sampler primitive
+	qiskitRuntimeProgram = "sampler"
+
+	// qiskitTokenRefreshWindow re-authenticates before a token actually expires
+	qiskitTokenRefreshWindow = 5 * time.Minute
+)
+
+// ErrQiskitNotConfigured is returned when a client is created without an API token
+var ErrQiskitNotConfigured = errors.New("qiskit client requires an API token")
+
+// QiskitToken is an IBM Quantum access token and its expiry
+type QiskitToken struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// Valid reports whether the token can still be used at now without refreshing
+func (t QiskitToken) Valid(now time.Time) bool {
+	return t.AccessToken != "" && now.Add(qiskitTokenRefreshWindow).Before(t.ExpiresAt)
+}
+
+// TokenCache persists access tokens so they can be reused across client instances
+type TokenCache interface {
+	Get() (QiskitToken, bool)
+	Set(token QiskitToken)
+}
+
+// MemoryTokenCache is an in-process TokenCache shared by clients for one account
+type MemoryTokenCache struct {
+	mutex sync.Mutex
+	token QiskitToken
+	set   bool
+}
+
+// Get returns the cached token, if any
+func (c *MemoryTokenCache) Get() (QiskitToken, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.token, c.set
+}
+
+// Set replaces the cached token
+func (c *MemoryTokenCache) Set(token QiskitToken) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.token = token
+	c.set = true
+}
+
+// QiskitConfig configures the IBM Quantum API client
+type QiskitConfig struct {
+	APIToken string // IBM Quantum API token, or IBM Cloud API key when CRN is set
+	BaseURL  string // Defaults to DefaultQiskitBaseURL, or DefaultQiskitRuntimeURL when CRN is set
+	CRN      string // Service CRN of a Qiskit Runtime instance; selects IBM Cloud IAM auth
+	IAMURL   string // IBM Cloud IAM token endpoint, defaults to DefaultIBMCloudIAMURL
+	Backend  string // Device jobs run on, e.g. "ibmq_qasm_simulator"
+
+	// TokenCache shares access tokens between clients; nil keeps them per client
+	TokenCache TokenCache
+
+	HTTPClient *http.Client
+}
+
+// QiskitJob is the status of a submitted job
+type QiskitJob struct {
+	ID     string        `json:"id"`
+	Status string        `json:"status"`
+	Result *QiskitResult `json:"result,omitempty"`
+}
+
+// QiskitResult holds the measurement counts of a completed job. Runtime Sampler
+// primitive results are converted to counts when decoded; see UnmarshalJSON.
+type QiskitResult struct {
+	Counts map[string]int `json:"counts"`
+	Shots  int            `json:"shots"`
+}
+
+// QiskitBackendStatus reports whether a device is accepting jobs
+type QiskitBackendStatus struct {
+	Operational bool   `json:"state"`
+	Status      string `json:"status"` // e.g. "active" or "maintenance"
+	QueueLength int    `json:"lengthQueue"`
+}
+
+// Qiskit job statuses
+const (
+	QiskitJobCompleted = "COMPLETED"
+	QiskitJobFailed    = "FAILED"
+	QiskitJobCancelled = "CANCELLED"
+)
+
+// QiskitClient talks to the IBM Quantum REST API. With a CRN configured it uses
+// the IBM Cloud flow instead: the API key is exchanged for an IAM bearer token and
+// jobs go to the Qiskit Runtime endpoints with the CRN in the Service-CRN header.
+type QiskitClient struct {
+	config     QiskitConfig
+	httpClient *http.Client
+
+	mutex sync.Mutex
+	token QiskitToken
+}
+
+// NewQiskitClient creates a client and authenticates it, unless the token cache
+// already holds a valid access token
+func NewQiskitClient(cfg QiskitConfig) (*QiskitClient, error) {
+	if cfg.APIToken == "" {
+		return nil, ErrQiskitNotConfigured
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultQiskitBaseURL
+		if cfg.CRN != "" {
+			cfg.BaseURL = DefaultQiskitRuntimeURL
+		}
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.IAMURL == "" {
+		cfg.IAMURL = DefaultIBMCloudIAMURL
+	}
+
+	client := &QiskitClient{config: cfg, httpClient: cfg.HTTPClient}
+	if client.httpClient == nil {
+		client.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	if err := client.ensureAuthenticated(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// ensureAuthenticated makes sure the client holds a token that is not about to
+// expire, preferring a cached token over logging in again
+func (c *QiskitClient) ensureAuthenticated(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if c.token.Valid(now) {
+		return nil
+	}
+
+	if c.config.TokenCache != nil {
+		if token, ok := c.config.TokenCache.Get(); ok && token.Valid(now) {
+			c.token = token
+			return nil
+		}
+	}
+
+	return c.authenticate(ctx)
+}
+
+// runtime reports whether the client uses IBM Cloud IAM and the Qiskit Runtime API
+func (c *QiskitClient) runtime() bool {
+	return c.config.CRN != ""
+}
+
+// authenticate exchanges the API token for an access token. The caller must hold the mutex.
+func (c *QiskitClient) authenticate(ctx context.Context) error {
+	if c.runtime() {
+		return c.authenticateIAM(ctx)
+	}
+
+	body, _ := json.Marshal(map[string]string{"apiToken": c.config.APIToken})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+"/api/auth/login", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var login struct {
+		ID  string `json:"id"`
+		TTL int64  `json:"ttl"` // Seconds
+	}
+	if err := c.send(req, &login); err != nil {
+		return fmt.Errorf("qiskit authentication failed: %w", err)
+	}
+	if login.ID == "" {
+		return errors.New("qiskit authentication failed: no access token returned")
+	}
+
+	c.setToken(login.ID, login.TTL)
+	return nil
+}
+
+// authenticateIAM exchanges an IBM Cloud API key for an IAM bearer token. The
+// caller must hold the mutex.
+func (c *QiskitClient) authenticateIAM(ctx context.Context) error {
+	form := url.Values{
+		"grant_type": {"urn:ibm:params:oauth:grant-type:apikey"},
+		"apikey":     {c.config.APIToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.IAMURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"` // Seconds
+	}
+	if err := c.send(req, &token); err != nil {
+		return fmt.Errorf("IBM Cloud IAM authentication failed: %w", err)
+	}
+	if token.AccessToken == "" {
+		return errors.New("IBM Cloud IAM authentication failed: no access token returned")
+	}
+
+	c.setToken(token.AccessToken, token.ExpiresIn)
+	return nil
+}
+
+// setToken stores a new access token valid for ttl seconds. The caller must hold the mutex.
+func (c *QiskitClient) setToken(accessToken string, ttl int64) {
+	c.token = QiskitToken{
+		AccessToken: accessToken,
+		ExpiresAt:   time.Now().Add(time.Duration(ttl) * time.Second),
+	}
+	if c.config.TokenCache != nil {
+		c.config.TokenCache.Set(c.token)
+	}
+}
+
+// do sends an authenticated request and decodes the JSON response into out
+func (c *QiskitClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	if err := c.ensureAuthenticated(ctx); err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.mutex.Lock()
+	if c.runtime() {
+		req.Header.Set("Authorization", "Bearer "+c.token.AccessToken)
+		req.Header.Set("Service-CRN", c.config.CRN)
+	} else {
+		req.Header.Set("X-Access-Token", c.token.AccessToken)
+	}
+	c.mutex.Unlock()
+
+	return c.send(req, out)
+}
+
+// send performs a request and decodes a successful JSON response
+func (c *QiskitClient) send(req *http.Request, out interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("qiskit API %s %s returned %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// SubmitJob submits an OpenQASM circuit to the configured backend and returns the job ID
+func (c *QiskitClient) SubmitJob(ctx context.Context, qasm string, shots int) (string, error) {
+	path := "/api/jobs"
+	var request interface{} = map[string]interface{}{
+		"backend": map[string]string{"name": c.config.Backend},
+		"qasms":   []map[string]string{{"qasm": qasm}},
+		"shots":   shots,
+	}
+	if c.runtime() {
+		path = "/jobs"
+		request = map[string]interface{}{
+			"program_id": qiskitRuntimeProgram,
+			"backend":    c.config.Backend,
+			"params": map[string]interface{}{
+				"pubs":  [][]string{{qasm}},
+				"shots": shots,
+			},
+		}
+	}
+
+	var job QiskitJob
+	if err := c.do(ctx, http.MethodPost, path, request, &job); err != nil {
+		return "", err
+	}
+	if job.ID == "" {
+		return "", errors.New("qiskit API returned a job without an ID")
+	}
+
+	return job.ID, nil
+}
+
+// BackendStatus returns the status of the configured device
+func (c *QiskitClient) BackendStatus(ctx context.Context) (*QiskitBackendStatus, error) {
+	if c.runtime() {
+		var status struct {
+			State       bool   `json:"state"`
+			Status      string `json:"status"`
+			LengthQueue int    `json:"length_queue"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/backends/"+url.PathEscape(c.config.Backend)+"/status", nil, &status); err != nil {
+			return nil, err
+		}
+		return &QiskitBackendStatus{Operational: status.State, Status: status.Status, QueueLength: status.LengthQueue}, nil
+	}
+
+	var status QiskitBackendStatus
+	if err := c.do(ctx, http.MethodGet, "/api/backends/"+url.PathEscape(c.config.Backend)+"/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetJob returns the current status of a job, including its result once completed
+func (c *QiskitClient) GetJob(ctx context.Context, jobID string) (*QiskitJob, error) {
+	if c.runtime() {
+		return c.getRuntimeJob(ctx, jobID)
+	}
+
+	var job QiskitJob
+	if err := c.do(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(jobID), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// getRuntimeJob fetches a Qiskit Runtime job, whose statuses are title case
+// ("Completed"), and its results once it has completed
+func (c *QiskitClient) getRuntimeJob(ctx context.Context, jobID string) (*QiskitJob, error) {
+	path := "/jobs/" + url.PathEscape(jobID)
+
+	var job QiskitJob
+	if err := c.do(ctx, http.MethodGet, path, nil, &job); err != nil {
+		return nil, err
+	}
+	job.Status = strings.ToUpper(job.Status)
+
+	if job.Status == QiskitJobCompleted && job.Result == nil {
+		var result QiskitResult
+		if err := c.do(ctx, http.MethodGet, path+"/results", nil, &result); err != nil {
+			return nil, err
+		}
+		job.Result = &result
+	}
+
+	return &job, nil
+}
+
+// QiskitPollOptions controls how WaitForJobContext polls a job
+type QiskitPollOptions struct {
+	Interval    time.Duration // First wait between polls, defaults to DefaultQiskitPollInterval
+	MaxInterval time.Duration // Longest wait between polls, defaults to Interval
+	Backoff     float64       // Factor the wait grows by after each poll; below 1 keeps it fixed
+	MaxWaitTime time.Duration // Longest to wait in total; zero waits until ctx is done
+}
+
+// WaitForJob polls a job every DefaultQiskitPollInterval until it completes, fails
+// or maxWaitTime elapses
+func (c *QiskitClient) WaitForJob(ctx context.Context, jobID string, maxWaitTime time.Duration) (*QiskitResult, error) {
+	return c.WaitForJobContext(ctx, jobID, QiskitPollOptions{MaxWaitTime: maxWaitTime})
+}
+
+// WaitForJobContext polls a job until it completes, fails, opts.MaxWaitTime elapses
+// or ctx is done, backing off between polls as a long queue wait grows. A
+// cancelled ctx stops polling at once, including mid-wait.
+func (c *QiskitClient) WaitForJobContext(ctx context.Context, jobID string, opts QiskitPollOptions) (*QiskitResult, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultQiskitPollInterval
+	}
+	maxInterval := max(opts.MaxInterval, interval)
+
+	var deadline <-chan time.Time
+	if opts.MaxWaitTime > 0 {
+		timer := time.NewTimer(opts.MaxWaitTime)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		job, err := c.GetJob(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch job.Status {
+		case QiskitJobCompleted:
+			if job.Result == nil {
+				return nil, fmt.Errorf("qiskit job %s completed without a result", jobID)
+			}
+			return job.Result, nil
+		case QiskitJobFailed, QiskitJobCancelled:
+			return nil, fmt.Errorf("qiskit job %s ended with status %s", jobID, job.Status)
+		}
+
+		wait := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return nil, ctx.Err()
+		case <-deadline:
+			wait.Stop()
+			return nil, fmt.Errorf("qiskit job %s did not complete within %v", jobID, opts.MaxWaitTime)
+		case <-wait.C:
+		}
+
+		if opts.Backoff > 1 {
+			interval = min(time.Duration(float64(interval)*opts.Backoff), maxInterval)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package quantum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFakeQiskit serves the login and job endpoints and counts login calls
+func newFakeQiskit(t *testing.T, logins *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth/login":
+			atomic.AddInt32(logins, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "fresh-token", "ttl": 3600})
+		case "/api/jobs/job-1":
+			token := r.Header.Get("X-Access-Token")
+			if token == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(QiskitJob{ID: "job-1", Status: QiskitJobCompleted, Result: &QiskitResult{
+				Counts: map[string]int{token: 1},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestQiskitClientUsesCachedToken(t *testing.T) {
+	var logins int32
+	server := newFakeQiskit(t, &logins)
+	defer server.Close()
+
+	cache := &MemoryTokenCache{}
+	cache.Set(QiskitToken{AccessToken: "cached-token", ExpiresAt: time.Now().Add(time.Hour)})
+
+	client, err := NewQiskitClient(QiskitConfig{APIToken: "api", BaseURL: server.URL, TokenCache: cache})
+	if err != nil {
+		t.Fatalf("NewQiskitClient failed: %v", err)
+	}
+
+	job, err := client.GetJob(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if logins != 0 {
+		t.Errorf("Expected no login with a valid cached token, got %d", logins)
+	}
+	if job.Result.Counts["cached-token"] != 1 {
+		t.Errorf("Expected requests to carry the cached token, got %v", job.Result.Counts)
+	}
+}
+
+func TestQiskitClientRefreshesExpiringCachedToken(t *testing.T) {
+	var logins int32
+	server := newFakeQiskit(t, &logins)
+	defer server.Close()
+
+	// A token inside the refresh window is treated as expired
+	cache := &MemoryTokenCache{}
+	cache.Set(QiskitToken{AccessToken: "stale-token", ExpiresAt: time.Now().Add(time.Minute)})
+
+	if _, err := NewQiskitClient(QiskitConfig{APIToken: "api", BaseURL: server.URL, TokenCache: cache}); err != nil {
+		t.Fatalf("NewQiskitClient failed: %v", err)
+	}
+	if logins != 1 {
+		t.Errorf("Expected one login for an expiring token, got %d", logins)
+	}
+
+	token, _ := cache.Get()
+	if token.AccessToken != "fresh-token" {
+		t.Errorf("Expected the new token to be cached, got %q", token.AccessToken)
+	}
+}
+
+func TestQiskitClientsShareTokenCache(t *testing.T) {
+	var logins int32
+	server := newFakeQiskit(t, &logins)
+	defer server.Close()
+
+	cache := &MemoryTokenCache{}
+	for i := 0; i < 3; i++ {
+		if _, err := NewQiskitClient(QiskitConfig{APIToken: "api", BaseURL: server.URL, TokenCache: cache}); err != nil {
+			t.Fatalf("NewQiskitClient failed: %v", err)
+		}
+	}
+
+	if logins != 1 {
+		t.Errorf("Expected clients sharing a cache to log in once, got %d", logins)
+	}
+}
+
+func TestQiskitClientRequiresAPIToken(t *testing.T) {
+	if _, err := NewQiskitClient(QiskitConfig{}); err != ErrQiskitNotConfigured {
+		t.Errorf("Expected ErrQiskitNotConfigured, got %v", err)
+	}
+}
+
+// newFakeRuntime serves the IBM Cloud IAM token endpoint and the Qiskit Runtime job
+// endpoints, rejecting job requests without the bearer token and CRN
+func newFakeRuntime(t *testing.T, crn string, submitted *map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/identity/token" {
+			if r.PostFormValue("grant_type") != "urn:ibm:params:oauth:grant-type:apikey" || r.PostFormValue("apikey") != "cloud-key" {
+				http.Error(w, "bad credentials", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "iam-token", "expires_in": 3600})
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer iam-token" || r.Header.Get("Service-CRN") != crn {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/jobs":
+			json.NewDecoder(r.Body).Decode(submitted)
+			json.NewEncoder(w).Encode(map[string]string{"id": "job-1"})
+		case r.URL.Path == "/jobs/job-1":
+			json.NewEncoder(w).Encode(map[string]string{"id": "job-1", "status": "Completed"})
+		case r.URL.Path == "/jobs/job-1/results":
+			json.NewEncoder(w).Encode(QiskitResult{Counts: map[string]int{"01": 3}, Shots: 3})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestQiskitClientExchangesIAMToken(t *testing.T) {
+	const crn = "crn:v1:bluemix:public:quantum-computing:us-east:a/123::"
+	var submitted map[string]interface{}
+	server := newFakeRuntime(t, crn, &submitted)
+	defer server.Close()
+
+	cache := &MemoryTokenCache{}
+	client, err := NewQiskitClient(QiskitConfig{
+		APIToken:   "cloud-key",
+		BaseURL:    server.URL,
+		IAMURL:     server.URL + "/identity/token",
+		CRN:        crn,
+		Backend:    "ibm_brisbane",
+		TokenCache: cache,
+	})
+	if err != nil {
+		t.Fatalf("NewQiskitClient failed: %v", err)
+	}
+	if token, _ := cache.Get(); token.AccessToken != "iam-token" || !token.Valid(time.Now()) {
+		t.Errorf("Expected the IAM token to be cached, got %+v", token)
+	}
+
+	jobID, err := client.SubmitJob(context.Background(), "OPENQASM 2.0;", 3)
+	if err != nil {
+		t.Fatalf("SubmitJob failed: %v", err)
+	}
+	if submitted["program_id"] != "sampler" || submitted["backend"] != "ibm_brisbane" {
+		t.Errorf("Expected a Sampler job on ibm_brisbane, got %v", submitted)
+	}
+
+	result, err := client.WaitForJob(context.Background(), jobID, time.Second)
+	if err != nil {
+		t.Fatalf("WaitForJob failed: %v", err)
+	}
+	if result.Counts["01"] != 3 {
+		t.Errorf("Expected the results endpoint's counts, got %v", result.Counts)
+	}
+}
+
+func TestQiskitClientRejectsBadIAMKey(t *testing.T) {
+	server := newFakeRuntime(t, "crn", new(map[string]interface{}))
+	defer server.Close()
+
+	_, err := NewQiskitClient(QiskitConfig{APIToken: "wrong-key", BaseURL: server.URL, IAMURL: server.URL + "/identity/token", CRN: "crn"})
+	if err == nil {
+		t.Error("Expected an error when IAM rejects the API key")
+	}
+}
+
+// newPendingQiskit serves a job that never finishes and records when it is polled
+func newPendingQiskit(t *testing.T, polls chan<- time.Time) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth/login":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "token", "ttl": 3600})
+		case "/api/jobs/job-1":
+			select {
+			case polls <- time.Now():
+			default:
+			}
+			json.NewEncoder(w).Encode(QiskitJob{ID: "job-1", Status: "RUNNING"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestWaitForJobContextBacksOff(t *testing.T) {
+	polls := make(chan time.Time, 16)
+	server := newPendingQiskit(t, polls)
+	defer server.Close()
+
+	client, err := NewQiskitClient(QiskitConfig{APIToken: "api", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewQiskitClient failed: %v", err)
+	}
+
+	opts := QiskitPollOptions{Interval: 10 * time.Millisecond, MaxInterval: 40 * time.Millisecond, Backoff: 2, MaxWaitTime: 150 * time.Millisecond}
+	if _, err := client.WaitForJobContext(context.Background(), "job-1", opts); err == nil {
+		t.Fatal("Expected a timeout for a job that never completes")
+	}
+	close(polls)
+
+	var times []time.Time
+	for at := range polls {
+		times = append(times, at)
+	}
+	// Waits of 10, 20, 40, 40... ms fit at most 6 polls into 150ms; a fixed 10ms interval would fit 15
+	if len(times) < 3 || len(times) > 6 {
+		t.Fatalf("Expected 3-6 polls with backoff, got %d", len(times))
+	}
+	if first, second := times[1].Sub(times[0]), times[2].Sub(times[1]); second <= first {
+		t.Errorf("Expected the poll interval to grow, got %v then %v", first, second)
+	}
+}
+
+func TestWaitForJobContextStopsOnCancel(t *testing.T) {
+	polls := make(chan time.Time, 16)
+	server := newPendingQiskit(t, polls)
+	defer server.Close()
+
+	client, err := NewQiskitClient(QiskitConfig{APIToken: "api", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewQiskitClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.WaitForJobContext(ctx, "job-1", QiskitPollOptions{Interval: time.Hour})
+		done <- err
+	}()
+
+	<-polls
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitForJobContext kept waiting after cancellation")
+	}
+	if len(polls) != 0 {
+		t.Errorf("Expected no polls after cancellation, got %d", len(polls))
+	}
+}
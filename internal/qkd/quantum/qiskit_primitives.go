@@ -0,0 +1,172 @@
+package quantum
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// QiskitClassicalRegister is the classical register BB84 circuits measure into
+const QiskitClassicalRegister = "c"
+
+// samplerRegister is one classical register of a Sampler V2 pub result: a hex
+// integer per shot whose bit i is clbit i
+type samplerRegister struct {
+	Samples []string `json:"samples"`
+	NumBits int      `json:"num_bits"`
+}
+
+// UnmarshalJSON accepts the legacy counts map as well as the Qiskit Runtime
+// Sampler primitive formats, which it converts to counts:
+//
+//   - Sampler V2: {"results": [{"data": {"c": {"samples": ["0x1", ...], "num_bits": 2}}}]}
+//   - Sampler V1: {"quasi_dists": [{"1": 0.5, ...}], "metadata": [{"shots": 1024}]}
+//
+// Converted outcomes are written in Qiskit's little-endian bitstring order, and
+// only the first pub (circuit) of a primitive result is read.
+func (r *QiskitResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Counts     map[string]int       `json:"counts"`
+		Shots      int                  `json:"shots"`
+		Results    []json.RawMessage    `json:"results"`
+		QuasiDists []map[string]float64 `json:"quasi_dists"`
+		Metadata   json.RawMessage      `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch {
+	case raw.Counts != nil:
+		r.Counts, r.Shots = raw.Counts, raw.Shots
+		return nil
+	case len(raw.Results) > 0:
+		return r.parseSamplerV2(raw.Results[0], QiskitClassicalRegister)
+	case len(raw.QuasiDists) > 0:
+		var metadata []struct {
+			Shots int `json:"shots"`
+		}
+		// V2 results carry object metadata; only the V1 array holds shot counts
+		_ = json.Unmarshal(raw.Metadata, &metadata)
+		shots := 0
+		if len(metadata) > 0 {
+			shots = metadata[0].Shots
+		}
+		return r.parseQuasiDist(raw.QuasiDists[0], shots)
+	}
+
+	r.Counts, r.Shots = nil, raw.Shots
+	return nil
+}
+
+// parseSamplerV2 counts the samples of register in a Sampler V2 pub result. When
+// the pub has a single register it is used whatever its name.
+func (r *QiskitResult) parseSamplerV2(pub json.RawMessage, register string) error {
+	var result struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(pub, &result); err != nil {
+		return fmt.Errorf("invalid sampler pub result: %w", err)
+	}
+
+	field, ok := result.Data[register]
+	if !ok && len(result.Data) == 1 {
+		for _, only := range result.Data {
+			field = only
+		}
+		ok = true
+	}
+	if !ok {
+		names := make([]string, 0, len(result.Data))
+		for name := range result.Data {
+			names = append(names, name)
+		}
+		return fmt.Errorf("sampler result has no classical register %q (registers: %s)", register, strings.Join(names, ", "))
+	}
+
+	var reg samplerRegister
+	if err := json.Unmarshal(field, &reg); err != nil {
+		return fmt.Errorf("invalid sampler register %q: %w", register, err)
+	}
+
+	counts := make(map[string]int)
+	for _, sample := range reg.Samples {
+		outcome, err := samplerOutcome(sample, reg.NumBits)
+		if err != nil {
+			return err
+		}
+		counts[outcome]++
+	}
+
+	r.Counts, r.Shots = counts, len(reg.Samples)
+	return nil
+}
+
+// parseQuasiDist scales a Sampler V1 quasi-probability distribution to counts
+// over shots, or over DefaultQiskitShots when the result does not report them.
+// Negative quasi-probabilities, which error mitigation can produce, count as zero.
+func (r *QiskitResult) parseQuasiDist(dist map[string]float64, shots int) error {
+	if shots <= 0 {
+		shots = DefaultQiskitShots
+	}
+
+	values := make(map[string]*big.Int, len(dist))
+	width := 0
+	for key := range dist {
+		value, ok := parseOutcomeInt(key)
+		if !ok {
+			return fmt.Errorf("invalid quasi-distribution outcome %q", key)
+		}
+		values[key] = value
+		width = max(width, value.BitLen())
+	}
+
+	counts := make(map[string]int)
+	for key, p := range dist {
+		if count := int(math.Round(p * float64(shots))); count > 0 {
+			counts[formatOutcome(values[key], width)] += count
+		}
+	}
+
+	r.Counts, r.Shots = counts, shots
+	return nil
+}
+
+// samplerOutcome converts a Sampler V2 sample to a numBits-wide bitstring. Hex
+// samples ("0x5") are integers; anything else is taken to be a bitstring already.
+func samplerOutcome(sample string, numBits int) (string, error) {
+	if !strings.HasPrefix(sample, "0x") && !strings.HasPrefix(sample, "0X") {
+		return normalizeOutcome(sample), nil
+	}
+
+	value, ok := parseOutcomeInt(sample)
+	if !ok {
+		return "", fmt.Errorf("invalid sampler sample %q", sample)
+	}
+	if numBits > 0 && value.BitLen() > numBits {
+		return "", fmt.Errorf("sampler sample %q exceeds %d bits", sample, numBits)
+	}
+	return formatOutcome(value, numBits), nil
+}
+
+// parseOutcomeInt parses a decimal or 0x-prefixed hex outcome
+func parseOutcomeInt(s string) (*big.Int, bool) {
+	value := new(big.Int)
+	if hex, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		_, ok = value.SetString(hex, 16)
+		return value, ok && value.Sign() >= 0
+	}
+	_, ok := value.SetString(s, 10)
+	return value, ok && value.Sign() >= 0
+}
+
+// formatOutcome writes value as a bitstring with clbit 0 rightmost, padded to width
+func formatOutcome(value *big.Int, width int) string {
+	s := value.Text(2)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package quantum
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// samplerV2Payload is a Sampler V2 result for a 3-qubit circuit measured into "c"
+// alongside an unrelated "flags" register
+const samplerV2Payload = `{
+	"results": [{
+		"data": {
+			"c": {"samples": ["0x5", "0x5", "0x4", "0x1"], "num_bits": 3},
+			"flags": {"samples": ["0x0", "0x1", "0x0", "0x0"], "num_bits": 1}
+		},
+		"metadata": {"circuit_metadata": {}}
+	}],
+	"metadata": {"version": 2}
+}`
+
+func TestQiskitResultParsesSamplerV2(t *testing.T) {
+	var result QiskitResult
+	if err := json.Unmarshal([]byte(samplerV2Payload), &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := map[string]int{"101": 2, "100": 1, "001": 1}
+	if !reflect.DeepEqual(result.Counts, want) || result.Shots != 4 {
+		t.Fatalf("Expected counts %v over 4 shots, got %v over %d", want, result.Counts, result.Shots)
+	}
+
+	// 0x5 sets clbits 0 and 2, so the majority outcome reads 1, 0, 1
+	if bits := ParseQASMResult(result.Counts, 3); !reflect.DeepEqual(bits, []Bit{One, Zero, One}) {
+		t.Errorf("Expected bits [1 0 1], got %v", bits)
+	}
+	marginals := ParseQASMResultPerQubit(result.Counts, 3)
+	if want := []float64{0.75, 0, 0.75}; !reflect.DeepEqual(marginals, want) {
+		t.Errorf("Expected marginals %v, got %v", want, marginals)
+	}
+}
+
+func TestQiskitResultSelectsRegister(t *testing.T) {
+	// A lone register is used whatever its name
+	var result QiskitResult
+	payload := `{"results": [{"data": {"meas": {"samples": ["0x2", "0x3"], "num_bits": 2}}}]}`
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if want := map[string]int{"10": 1, "11": 1}; !reflect.DeepEqual(result.Counts, want) {
+		t.Errorf("Expected counts %v, got %v", want, result.Counts)
+	}
+
+	for name, payload := range map[string]string{
+		"ambiguous registers": `{"results": [{"data": {"a": {"samples": ["0x0"]}, "b": {"samples": ["0x1"]}}}]}`,
+		"sample too wide":     `{"results": [{"data": {"c": {"samples": ["0x8"], "num_bits": 3}}}]}`,
+		"invalid sample":      `{"results": [{"data": {"c": {"samples": ["0xzz"], "num_bits": 3}}}]}`,
+	} {
+		if err := json.Unmarshal([]byte(payload), &QiskitResult{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestQiskitResultParsesQuasiDistribution(t *testing.T) {
+	var result QiskitResult
+	payload := `{"quasi_dists": [{"5": 0.5, "0x1": 0.3, "4": 0.21, "2": -0.01}], "metadata": [{"shots": 1000}]}`
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := map[string]int{"101": 500, "001": 300, "100": 210}
+	if !reflect.DeepEqual(result.Counts, want) || result.Shots != 1000 {
+		t.Errorf("Expected counts %v over 1000 shots, got %v over %d", want, result.Counts, result.Shots)
+	}
+	if bits := ParseQASMResult(result.Counts, 3); !reflect.DeepEqual(bits, []Bit{One, Zero, One}) {
+		t.Errorf("Expected bits [1 0 1], got %v", bits)
+	}
+
+	// Without a shot count the distribution is scaled to DefaultQiskitShots
+	if err := json.Unmarshal([]byte(`{"quasi_dists": [{"1": 1.0}]}`), &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if result.Counts["1"] != DefaultQiskitShots {
+		t.Errorf("Expected %d counts, got %v", DefaultQiskitShots, result.Counts)
+	}
+}
+
+func TestQiskitResultKeepsLegacyCounts(t *testing.T) {
+	var job QiskitJob
+	payload := `{"id": "job-1", "status": "COMPLETED", "result": {"counts": {"01": 7, "10": 1}, "shots": 8}}`
+	if err := json.Unmarshal([]byte(payload), &job); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if want := map[string]int{"01": 7, "10": 1}; !reflect.DeepEqual(job.Result.Counts, want) || job.Result.Shots != 8 {
+		t.Errorf("Expected legacy counts %v over 8 shots, got %+v", want, job.Result)
+	}
+}
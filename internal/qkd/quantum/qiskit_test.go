@@ -0,0 +1,303 @@
+package quantum
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	qasmGate = regexp.MustCompile(`^(x|h) q\[(\d+)\];$`)
+	qasmQreg = regexp.MustCompile(`^qreg q\[(\d+)\];$`)
+)
+
+// fakeQiskitDevice executes submitted BB84 circuits on a noiseless
+// single-qubit state-vector model and reports little-endian counts
+type fakeQiskitDevice struct {
+	mutex     sync.Mutex
+	rng       *rand.Rand
+	failWith  int
+	bigEndian bool // Report qubit 0 as the leftmost character instead of Qiskit's rightmost
+	circuits  []string
+	results   map[string]map[string]int
+}
+
+func (f *fakeQiskitDevice) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch {
+	case r.URL.Path == "/api/auth/login":
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "token", "ttl": 3600})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/api/jobs":
+		if f.failWith != 0 {
+			http.Error(w, "unavailable", f.failWith)
+			return
+		}
+
+		var req struct {
+			QASMs []struct {
+				QASM string `json:"qasm"`
+			} `json:"qasms"`
+			Shots int `json:"shots"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		circuit := req.QASMs[0].QASM
+		f.circuits = append(f.circuits, circuit)
+		id := "job-" + strconv.Itoa(len(f.circuits))
+		f.results[id] = f.run(circuit, req.Shots)
+		json.NewEncoder(w).Encode(QiskitJob{ID: id, Status: "QUEUED"})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/jobs/"):
+		id := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
+		json.NewEncoder(w).Encode(QiskitJob{ID: id, Status: QiskitJobCompleted, Result: &QiskitResult{Counts: f.results[id]}})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// run simulates each qubit independently and tallies little-endian outcomes
+func (f *fakeQiskitDevice) run(circuit string, shots int) map[string]int {
+	gates := make(map[int][]string)
+	qubits := 0
+	for _, line := range strings.Split(circuit, "\n") {
+		if m := qasmQreg.FindStringSubmatch(line); m != nil {
+			qubits, _ = strconv.Atoi(m[1])
+		}
+		if m := qasmGate.FindStringSubmatch(line); m != nil {
+			q, _ := strconv.Atoi(m[2])
+			gates[q] = append(gates[q], m[1])
+		}
+	}
+
+	counts := make(map[string]int)
+	for s := 0; s < shots; s++ {
+		outcome := make([]byte, qubits)
+		for q := 0; q < qubits; q++ {
+			amp := [2]float64{1, 0}
+			for _, gate := range gates[q] {
+				switch gate {
+				case "x":
+					amp[0], amp[1] = amp[1], amp[0]
+				case "h":
+					amp[0], amp[1] = (amp[0]+amp[1])/1.4142135623730951, (amp[0]-amp[1])/1.4142135623730951
+				}
+			}
+			pos := qubits - 1 - q
+			if f.bigEndian {
+				pos = q
+			}
+			outcome[pos] = '0'
+			if f.rng.Float64() < amp[1]*amp[1] {
+				outcome[pos] = '1'
+			}
+		}
+		counts[string(outcome)]++
+	}
+
+	return counts
+}
+
+func newTestQiskitBackend(t *testing.T, server *httptest.Server, fallback bool) *QiskitBackend {
+	t.Helper()
+	return newTestQiskitBackendWithConfig(t, server, QiskitBackendConfig{FallbackToSimulator: fallback})
+}
+
+func newTestQiskitBackendWithConfig(t *testing.T, server *httptest.Server, cfg QiskitBackendConfig) *QiskitBackend {
+	t.Helper()
+
+	cfg.QiskitConfig = QiskitConfig{APIToken: "api", BaseURL: server.URL, Backend: "ibmq_qasm_simulator"}
+	cfg.Shots = 200
+	cfg.MaxWaitTime = time.Second
+	backend, err := NewQiskitBackendWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewQiskitBackendWithConfig failed: %v", err)
+	}
+
+	return backend
+}
+
+func TestQiskitBackendThresholdsPerQubitMarginals(t *testing.T) {
+	device := &fakeQiskitDevice{rng: rand.New(rand.NewSource(1)), results: make(map[string]map[string]int)}
+	server := httptest.NewServer(device)
+	defer server.Close()
+	backend := newTestQiskitBackend(t, server, false)
+
+	const n = 64
+	r := rand.New(rand.NewSource(2))
+	bits := make([]Bit, n)
+	prepBases := make([]Basis, n)
+	measBases := make([]Basis, n)
+	for i := range bits {
+		bits[i] = Bit(r.Intn(2))
+		prepBases[i] = Basis(r.Intn(2))
+		measBases[i] = Basis(r.Intn(2))
+	}
+
+	qubits, err := backend.PrepareAndSend(context.Background(), bits, prepBases)
+	if err != nil {
+		t.Fatalf("PrepareAndSend failed: %v", err)
+	}
+	results, err := backend.ReceiveAndMeasure(context.Background(), qubits, measBases)
+	if err != nil {
+		t.Fatalf("ReceiveAndMeasure failed: %v", err)
+	}
+
+	if len(device.circuits) != 1 {
+		t.Fatalf("Expected one combined circuit, got %d", len(device.circuits))
+	}
+
+	// Every shot disagrees on mismatched-basis qubits, so the mode outcome is
+	// meaningless, but each matched-basis qubit is deterministic on its own
+	for i, result := range results {
+		if prepBases[i] == measBases[i] && result.MeasuredBit != bits[i] {
+			t.Errorf("Qubit %d measured in its preparation basis: got %d, want %d", i, result.MeasuredBit, bits[i])
+		}
+		if result.DetectorID != DetectorFor(measBases[i], result.MeasuredBit) {
+			t.Errorf("Qubit %d: detector %d does not match its outcome", i, result.DetectorID)
+		}
+	}
+}
+
+func TestQiskitBackendSplitsLargeCircuits(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		device := &fakeQiskitDevice{rng: rand.New(rand.NewSource(1)), results: make(map[string]map[string]int)}
+		server := httptest.NewServer(device)
+		backend := newTestQiskitBackendWithConfig(t, server, QiskitBackendConfig{MaxCircuitQubits: 127, MaxConcurrentJobs: concurrency})
+
+		// Measure every qubit in its preparation basis so each outcome is deterministic
+		const n = 300
+		r := rand.New(rand.NewSource(2))
+		bits := make([]Bit, n)
+		bases := make([]Basis, n)
+		for i := range bits {
+			bits[i] = Bit(r.Intn(2))
+			bases[i] = Basis(r.Intn(2))
+		}
+
+		qubits, _ := backend.PrepareAndSend(context.Background(), bits, bases)
+		results, err := backend.ReceiveAndMeasure(context.Background(), qubits, bases)
+		server.Close()
+		if err != nil {
+			t.Fatalf("concurrency %d: ReceiveAndMeasure failed: %v", concurrency, err)
+		}
+
+		sizes := make(map[string]int)
+		for _, circuit := range device.circuits {
+			for _, line := range strings.Split(circuit, "\n") {
+				if m := qasmQreg.FindStringSubmatch(line); m != nil {
+					sizes[m[1]]++
+				}
+			}
+		}
+		if len(device.circuits) != 3 || sizes["127"] != 2 || sizes["46"] != 1 {
+			t.Fatalf("concurrency %d: expected jobs of 127, 127 and 46 qubits, got %v", concurrency, sizes)
+		}
+
+		for i, result := range results {
+			if result.MeasuredBit != bits[i] {
+				t.Fatalf("concurrency %d: qubit %d measured %d, want %d", concurrency, i, result.MeasuredBit, bits[i])
+			}
+		}
+	}
+}
+
+func TestQiskitBackendFallsBackToSimulator(t *testing.T) {
+	device := &fakeQiskitDevice{rng: rand.New(rand.NewSource(1)), results: make(map[string]map[string]int), failWith: http.StatusServiceUnavailable}
+	server := httptest.NewServer(device)
+	defer server.Close()
+
+	bits := []Bit{0, 1, 1, 0}
+	bases := []Basis{RectilinearBasis, DiagonalBasis, RectilinearBasis, DiagonalBasis}
+
+	strict := newTestQiskitBackend(t, server, false)
+	qubits, _ := strict.PrepareAndSend(context.Background(), bits, bases)
+	if _, err := strict.ReceiveAndMeasure(context.Background(), qubits, bases); err == nil {
+		t.Error("Expected the API error without fallback")
+	}
+
+	lenient := newTestQiskitBackend(t, server, true)
+	results, err := lenient.ReceiveAndMeasure(context.Background(), qubits, bases)
+	if err != nil {
+		t.Fatalf("Expected fallback to the simulator, got %v", err)
+	}
+	if len(results) != len(bits) {
+		t.Errorf("Expected %d results, got %d", len(bits), len(results))
+	}
+}
+
+func TestQiskitBackendEntanglementUnsupported(t *testing.T) {
+	device := &fakeQiskitDevice{rng: rand.New(rand.NewSource(1)), results: make(map[string]map[string]int)}
+	server := httptest.NewServer(device)
+	defer server.Close()
+	backend := newTestQiskitBackend(t, server, true)
+
+	// Even with fallback enabled, entanglement outcomes are never simulated in place of the device
+	if _, _, err := backend.MeasureEntangledPairs(context.Background(), []float64{0}, []float64{0}); err != ErrEntanglementUnsupported {
+		t.Errorf("Expected ErrEntanglementUnsupported from MeasureEntangledPairs, got %v", err)
+	}
+	if _, err := backend.MeasureGHZ(context.Background(), [][]Basis{{RectilinearBasis, RectilinearBasis, RectilinearBasis}}); err != ErrEntanglementUnsupported {
+		t.Errorf("Expected ErrEntanglementUnsupported from MeasureGHZ, got %v", err)
+	}
+}
+
+func TestQiskitBackendBitOrder(t *testing.T) {
+	// Set qubit 0 to 1 and leave the rest 0
+	bits := []Bit{1, 0, 0, 0}
+	bases := []Basis{RectilinearBasis, RectilinearBasis, RectilinearBasis, RectilinearBasis}
+
+	tests := []struct {
+		name      string
+		bigEndian bool
+		order     BitOrder
+	}{
+		{"qiskit little-endian", false, BitOrderLittleEndian},
+		{"big-endian provider", true, BitOrderBigEndian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := &fakeQiskitDevice{rng: rand.New(rand.NewSource(1)), results: make(map[string]map[string]int), bigEndian: tt.bigEndian}
+			server := httptest.NewServer(device)
+			defer server.Close()
+
+			backend := newTestQiskitBackendWithConfig(t, server, QiskitBackendConfig{BitOrder: tt.order})
+			qubits, _ := backend.PrepareAndSend(context.Background(), bits, bases)
+			results, err := backend.ReceiveAndMeasure(context.Background(), qubits, bases)
+			if err != nil {
+				t.Fatalf("ReceiveAndMeasure failed: %v", err)
+			}
+
+			for i, result := range results {
+				if result.MeasuredBit != bits[i] {
+					t.Errorf("Bit %d = %d, want %d", i, result.MeasuredBit, bits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQiskitBackendHealthDegradesWithFallback(t *testing.T) {
+	// The fake device has no status endpoint, so every probe fails
+	device := &fakeQiskitDevice{rng: rand.New(rand.NewSource(1)), results: make(map[string]map[string]int)}
+	server := httptest.NewServer(device)
+	defer server.Close()
+
+	if health := newTestQiskitBackend(t, server, false).HealthStatus(context.Background()); health.State != HealthUnhealthy {
+		t.Errorf("Expected unhealthy without fallback, got %+v", health)
+	}
+	if health := newTestQiskitBackend(t, server, true).HealthStatus(context.Background()); health.State != HealthDegraded {
+		t.Errorf("Expected degraded with fallback, got %+v", health)
+	}
+}
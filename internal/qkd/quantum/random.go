@@ -0,0 +1,81 @@
+package quantum
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// randomSource is the entropy source for secure bit and basis generation.
+// It is a variable so tests can substitute a faulty reader.
+var randomSource io.Reader = rand.Reader
+
+// SecureRandomBits generates length bits from the cryptographic RNG, reading
+// one batch of ceil(length/8) bytes. The batch is either filled completely or
+// an error is returned; a partially filled slice is never produced.
+func SecureRandomBits(length int) ([]Bit, error) {
+	packed, err := readRandomBatch(randomSource, length)
+	if err != nil {
+		return nil, err
+	}
+
+	return BytesToBits(packed, length), nil
+}
+
+// SecureRandomBases generates length bases from the cryptographic RNG (see SecureRandomBits)
+func SecureRandomBases(length int) ([]Basis, error) {
+	packed, err := readRandomBatch(randomSource, length)
+	if err != nil {
+		return nil, err
+	}
+
+	bases := make([]Basis, length)
+	for i, bit := range BytesToBits(packed, length) {
+		bases[i] = Basis(bit)
+	}
+
+	return bases, nil
+}
+
+// SecureRandomSixStateBases generates length bases drawn uniformly from the
+// rectilinear, diagonal and circular bases. Each basis takes two random bits;
+// the fourth value is rejected and redrawn so the choice is unbiased.
+func SecureRandomSixStateBases(length int) ([]Basis, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("length must not be negative")
+	}
+
+	bases := make([]Basis, 0, length)
+	for len(bases) < length {
+		remaining := length - len(bases)
+		packed, err := readRandomBatch(randomSource, 2*remaining)
+		if err != nil {
+			return nil, err
+		}
+
+		bits := BytesToBits(packed, 2*remaining)
+		for i := 0; i < len(bits) && len(bases) < length; i += 2 {
+			if value := Basis(bits[i]<<1 | bits[i+1]); value <= CircularBasis {
+				bases = append(bases, value)
+			}
+		}
+	}
+
+	return bases, nil
+}
+
+// readRandomBatch reads enough bytes for length bits, looping over short reads
+// until the buffer is full. On failure the buffer is zeroed and discarded.
+func readRandomBatch(r io.Reader, length int) ([]byte, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("length must not be negative")
+	}
+
+	buf := make([]byte, (length+7)/8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		clear(buf)
+		return nil, fmt.Errorf("failed to read random bits: %w", err)
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,111 @@
+package quantum
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// shortReader returns at most chunk bytes per Read, then fails after limit bytes
+type shortReader struct {
+	src   io.Reader
+	chunk int
+	limit int
+	read  int
+}
+
+func (r *shortReader) Read(p []byte) (int, error) {
+	if r.read >= r.limit {
+		return 0, errors.New("entropy source failed")
+	}
+
+	n := min(len(p), r.chunk, r.limit-r.read)
+	n, err := r.src.Read(p[:n])
+	r.read += n
+	return n, err
+}
+
+// withRandomSource swaps the package entropy source for the duration of a test
+func withRandomSource(t *testing.T, r io.Reader) {
+	t.Helper()
+
+	previous := randomSource
+	randomSource = r
+	t.Cleanup(func() { randomSource = previous })
+}
+
+func TestSecureRandomBitsShortReads(t *testing.T) {
+	// Every byte is 0xFF, so any unfilled position would show up as a zero bit
+	withRandomSource(t, &shortReader{src: bytes.NewReader(bytes.Repeat([]byte{0xFF}, 128)), chunk: 3, limit: 128})
+
+	bits, err := SecureRandomBits(1000)
+	if err != nil {
+		t.Fatalf("Expected short reads to be retried, got %v", err)
+	}
+
+	for i, bit := range bits {
+		if bit != One {
+			t.Fatalf("Bit %d was not filled from the entropy source", i)
+		}
+	}
+}
+
+func TestSecureRandomBitsFailureMidBatch(t *testing.T) {
+	withRandomSource(t, &shortReader{src: bytes.NewReader(bytes.Repeat([]byte{0xFF}, 128)), chunk: 3, limit: 50})
+
+	bits, err := SecureRandomBits(1000)
+	if err == nil {
+		t.Fatal("Expected an error when the entropy source fails mid-batch")
+	}
+	if bits != nil {
+		t.Errorf("Expected no bits on failure, got %d", len(bits))
+	}
+
+	bases, err := SecureRandomBases(1000)
+	if err == nil || bases != nil {
+		t.Errorf("Expected bases generation to fail without output, got %d bases, err %v", len(bases), err)
+	}
+}
+
+func TestSecureRandomBasesDistribution(t *testing.T) {
+	bases, err := SecureRandomBases(10000)
+	if err != nil {
+		t.Fatalf("SecureRandomBases failed: %v", err)
+	}
+
+	diagonal := 0
+	for _, basis := range bases {
+		if basis == DiagonalBasis {
+			diagonal++
+		}
+	}
+
+	if ratio := float64(diagonal) / float64(len(bases)); ratio < 0.45 || ratio > 0.55 {
+		t.Errorf("Expected ~50%% diagonal bases, got %.2f%%", ratio*100)
+	}
+}
+
+func TestSecureRandomSixStateBasesDistribution(t *testing.T) {
+	bases, err := SecureRandomSixStateBases(9000)
+	if err != nil {
+		t.Fatalf("SecureRandomSixStateBases failed: %v", err)
+	}
+	if len(bases) != 9000 {
+		t.Fatalf("Expected 9000 bases, got %d", len(bases))
+	}
+
+	counts := make(map[Basis]int)
+	for _, basis := range bases {
+		counts[basis]++
+	}
+
+	for _, basis := range []Basis{RectilinearBasis, DiagonalBasis, CircularBasis} {
+		if ratio := float64(counts[basis]) / float64(len(bases)); ratio < 0.30 || ratio > 0.37 {
+			t.Errorf("Expected ~33%% %v bases, got %.2f%%", basis, ratio*100)
+		}
+	}
+	if len(counts) != 3 {
+		t.Errorf("Expected only the three six-state bases, got %v", counts)
+	}
+}
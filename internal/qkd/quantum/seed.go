@@ -0,0 +1,57 @@
+package quantum
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// The simulators draw channel loss, noise, eavesdropping and measurement outcomes
+// from math/rand. By default that is the global source; a seeded source makes a
+// simulation reproducible. Key bits and bases are never drawn from these sources:
+// they always come from crypto/rand (see SecureRandomBits).
+
+// lockedSource serializes access to a rand.Source so a seeded simulator can be
+// shared by concurrent exchanges
+type lockedSource struct {
+	mutex  sync.Mutex
+	source rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.source.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.source.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.source.Seed(seed)
+}
+
+// NewSeededRand returns a deterministic, concurrency-safe random source for simulations
+func NewSeededRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{source: rand.NewSource(seed).(rand.Source64)})
+}
+
+// randFloat64 draws from r, or from the global source when r is nil
+func randFloat64(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
+
+// randBit draws a uniformly random bit from r, or from the global source when r is nil
+func randBit(r *rand.Rand) Bit {
+	if r == nil {
+		return Bit(rand.Intn(2))
+	}
+	return Bit(r.Intn(2))
+}
@@ -0,0 +1,70 @@
+package quantum
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// seededRun sends the same bits and bases through a noisy, lossy simulator seeded with seed
+func seededRun(t *testing.T, seed int64, bits []Bit, prepBases, measBases []Basis) []MeasurementResult {
+	t.Helper()
+
+	backend := NewSimulatorBackend(true, 0.05)
+	backend.SetLossRate(0.3)
+	backend.SetInterceptProbability(0.2)
+	backend.SetSeed(seed)
+
+	qubits, err := backend.PrepareAndSend(context.Background(), bits, prepBases)
+	if err != nil {
+		t.Fatalf("PrepareAndSend failed: %v", err)
+	}
+	results, err := backend.ReceiveAndMeasure(context.Background(), qubits, measBases)
+	if err != nil {
+		t.Fatalf("ReceiveAndMeasure failed: %v", err)
+	}
+
+	// Detection times start at the wall clock, so only the outcomes are compared
+	for i := range results {
+		results[i].DetectionTime = time.Time{}
+	}
+	return results
+}
+
+func TestSeededSimulatorsAreReproducible(t *testing.T) {
+	const n = 4096
+	bits := GenerateRandomBits(n)
+	prepBases := GenerateRandomBases(n)
+	measBases := GenerateRandomBases(n)
+
+	first := seededRun(t, 42, bits, prepBases, measBases)
+	second := seededRun(t, 42, bits, prepBases, measBases)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("Expected two simulators with the same seed to produce identical measurements")
+	}
+
+	if other := seededRun(t, 43, bits, prepBases, measBases); reflect.DeepEqual(first, other) {
+		t.Error("Expected a different seed to produce different measurements")
+	}
+}
+
+func TestSeededNoiseModelIsReproducible(t *testing.T) {
+	const n = 2048
+	bits := GenerateRandomBits(n)
+	bases := GenerateRandomBases(n)
+
+	run := func() []Qubit {
+		backend := NewSimulatorBackendWithNoiseModel(DepolarizingNoise{P: 0.2})
+		backend.SetSeed(7)
+		qubits, err := backend.PrepareAndSend(context.Background(), bits, bases)
+		if err != nil {
+			t.Fatalf("PrepareAndSend failed: %v", err)
+		}
+		return qubits
+	}
+
+	if !reflect.DeepEqual(run(), run()) {
+		t.Error("Expected a seeded noise model to apply identical errors")
+	}
+}
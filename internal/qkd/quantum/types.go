@@ -3,6 +3,7 @@ package quantum
 import (
 	"fmt"
 	"math/rand"
+	"time"
 )
 
 // Basis represents the measurement basis in BB84 protocol
@@ -13,6 +14,8 @@ const (
 	RectilinearBasis Basis = 0
 	// DiagonalBasis represents the Hadamard basis (X-basis): |+⟩, |−⟩
 	DiagonalBasis Basis = 1
+	// CircularBasis represents the Y-basis used by the six-state protocol: |+i⟩, |−i⟩
+	CircularBasis Basis = 2
 )
 
 func (b Basis) String() string {
@@ -21,6 +24,8 @@ func (b Basis) String() string {
 		return "Rectilinear(+)"
 	case DiagonalBasis:
 		return "Diagonal(×)"
+	case CircularBasis:
+		return "Circular(○)"
 	default:
 		return "Unknown"
 	}
@@ -40,6 +45,8 @@ type Qubit struct {
 	ClassicalValue Bit
 	// PreparationBasis is the basis used to prepare this qubit
 	PreparationBasis Basis
+	// Lost is set when the photon did not survive the channel
+	Lost bool
 }
 
 // MeasurementResult represents the outcome of measuring a qubit
@@ -48,14 +55,45 @@ type MeasurementResult struct {
 	MeasuredBit Bit
 	// MeasurementBasis is the basis used for measurement
 	MeasurementBasis Basis
+	// NoDetection marks a slot where no photon arrived; MeasuredBit is meaningless
+	NoDetection bool
+	// Inconclusive marks a click that cannot be assigned a bit, such as both
+	// detectors of a basis firing at once; it is dropped during sifting
+	Inconclusive bool
+	// DetectionTime is when the detector clicked; zero if the backend does not report it
+	DetectionTime time.Time
+	// DetectorID identifies the detector that fired (see DetectorFor)
+	DetectorID int
+}
+
+// Conclusive reports whether the measurement produced a usable bit
+func (m MeasurementResult) Conclusive() bool {
+	return !m.NoDetection && !m.Inconclusive
+}
+
+// DetectorFor returns the ID of the detector that registers bit in basis, for a
+// receiver with one detector per outcome and basis: 2·basis + bit
+func DetectorFor(basis Basis, bit Bit) int {
+	return 2*int(basis) + int(bit)
 }
 
 // QuantumChannel represents a simulated quantum communication channel
 type QuantumChannel struct {
-	// NoiseLevel represents the probability of bit flip error (0.0 to 1.0)
+	// NoiseLevel is the probability of an error in whichever basis the qubit was
+	// prepared in (0.0 to 1.0)
 	NoiseLevel float64
+	// BitFlipProbability is the probability of an X error, which is seen only in
+	// the rectilinear and circular bases
+	BitFlipProbability float64
+	// PhaseFlipProbability is the probability of a Z error (phase damping), which
+	// is seen only in the diagonal and circular bases
+	PhaseFlipProbability float64
 	// InterceptProbability simulates eavesdropper presence (0.0 to 1.0)
 	InterceptProbability float64
+	// LossRate is the probability that a photon is lost in transit (0.0 to 1.0)
+	LossRate float64
+	// Rand is the randomness source for loss, interception and noise; nil uses the global source
+	Rand *rand.Rand
 }
 
 // NewQuantumChannel creates a new quantum channel with specified noise characteristics
@@ -66,28 +104,57 @@ func NewQuantumChannel(noiseLevel, interceptProbability float64) *QuantumChannel
 	}
 }
 
-// Transmit simulates transmission of a qubit through the quantum channel
-func (qc *QuantumChannel) Transmit(qubit Qubit) Qubit {
+// Transmit simulates transmission of a qubit through the quantum channel.
+// It returns false if the photon was lost.
+func (qc *QuantumChannel) Transmit(qubit Qubit) (Qubit, bool) {
+	if qc.lose() {
+		qubit.Lost = true
+		return qubit, false
+	}
+	return qc.applyNoise(qubit), true
+}
+
+// lose reports whether a photon is lost according to LossRate
+func (qc *QuantumChannel) lose() bool {
+	return qc.LossRate > 0 && randFloat64(qc.Rand) < qc.LossRate
+}
+
+// applyNoise applies eavesdropping and channel noise to a surviving qubit
+func (qc *QuantumChannel) applyNoise(qubit Qubit) Qubit {
+	return qc.flip(qc.intercept(qubit))
+}
+
+// intercept simulates an intercept-resend eavesdropper
+func (qc *QuantumChannel) intercept(qubit Qubit) Qubit {
 	// Simulate eavesdropper interception
-	if rand.Float64() < qc.InterceptProbability {
+	if randFloat64(qc.Rand) < qc.InterceptProbability {
 		// Eve intercepts and measures in random basis
-		eveBasis := Basis(rand.Intn(2))
+		eveBasis := Basis(randBit(qc.Rand))
 		// Eve's measurement collapses the state
 		// If bases match, state is preserved; if not, it's disturbed
 		if eveBasis != qubit.PreparationBasis {
 			// 50% chance of bit flip when wrong basis is used
-			if rand.Float64() < 0.5 {
+			if randFloat64(qc.Rand) < 0.5 {
 				qubit.ClassicalValue = 1 - qubit.ClassicalValue
 			}
 		}
 	}
 
+	return qubit
+}
+
+// flip simulates channel noise: a basis-independent error with probability
+// NoiseLevel, then X and Z errors. Like a NoiseModel, errors are tracked in the
+// preparation basis, so measuring in that basis reveals only the errors that
+// disturb it, and a mismatched basis gives a random outcome regardless.
+func (qc *QuantumChannel) flip(qubit Qubit) Qubit {
 	// Simulate channel noise (decoherence)
-	if rand.Float64() < qc.NoiseLevel {
+	if randFloat64(qc.Rand) < qc.NoiseLevel {
 		qubit.ClassicalValue = 1 - qubit.ClassicalValue
 	}
 
-	return qubit
+	qubit = BitFlipNoise{P: qc.BitFlipProbability}.Apply(qubit, qc.Rand)
+	return PhaseFlipNoise{P: qc.PhaseFlipProbability}.Apply(qubit, qc.Rand)
 }
 
 // PrepareQubit prepares a qubit in a specific state using the given basis
@@ -100,12 +167,24 @@ func PrepareQubit(bit Bit, basis Basis) Qubit {
 
 // MeasureQubit simulates measuring a qubit in a specified basis
 func MeasureQubit(qubit Qubit, measurementBasis Basis) MeasurementResult {
+	return measureQubit(qubit, measurementBasis, nil)
+}
+
+// measureQubit is MeasureQubit drawing mismatched-basis outcomes from r
+func measureQubit(qubit Qubit, measurementBasis Basis, r *rand.Rand) MeasurementResult {
+	if qubit.Lost {
+		return MeasurementResult{
+			MeasurementBasis: measurementBasis,
+			NoDetection:      true,
+		}
+	}
+
 	measuredBit := qubit.ClassicalValue
 
-	// If measurement basis doesn't match preparation basis,
-	// outcome is random (50/50) due to quantum superposition
+	// If measurement basis doesn't match preparation basis, outcome is random
+	// (50/50): the three bases are mutually unbiased
 	if measurementBasis != qubit.PreparationBasis {
-		if rand.Float64() < 0.5 {
+		if randFloat64(r) < 0.5 {
 			measuredBit = 1 - measuredBit
 		}
 	}
@@ -113,6 +192,7 @@ func MeasureQubit(qubit Qubit, measurementBasis Basis) MeasurementResult {
 	return MeasurementResult{
 		MeasuredBit:      measuredBit,
 		MeasurementBasis: measurementBasis,
+		DetectorID:       DetectorFor(measurementBasis, measuredBit),
 	}
 }
 
@@ -0,0 +1,47 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// minRandomKeyBits is the shortest exchange RandomBytes runs, so its QBER sample
+// stays above DefaultMinQBERSample however few bytes are asked for
+const minRandomKeyBits = 256
+
+// RandomBytes returns n fresh random bytes drawn from the sifted key of a BB84
+// exchange on a noiseless simulator, serving as a quantum random number generator.
+// The exchange is not tied to a session and nothing is stored, but it counts
+// against the concurrent exchange limit and the exchange timeout.
+func (sm *SessionManager) RandomBytes(ctx context.Context, n int) ([]byte, error) {
+	if n < 1 || n > qkd.MaxRandomBytes {
+		return nil, qkd.ErrInvalidRandomBytes
+	}
+
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, cancel := sm.exchangeContext(ctx)
+	defer cancel()
+
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0), max(8*n, minRandomKeyBits))
+	result, err := bb84.PerformKeyExchange(ctx)
+	if err != nil {
+		return nil, timeoutCause(ctx, err)
+	}
+	defer crypto.Zeroize(result.Key)
+	if !result.Secure {
+		return nil, fmt.Errorf("random exchange failed: %s", result.Message)
+	}
+
+	random := make([]byte, n)
+	copy(random, result.Key)
+	return random, nil
+}
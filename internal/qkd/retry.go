@@ -0,0 +1,77 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// DefaultMaxRetries is the number of times a failed session's exchange may be re-run
+const DefaultMaxRetries = 3
+
+// SetMaxRetries sets how many times RetryKeyExchange may re-run a session's exchange
+func (sm *SessionManager) SetMaxRetries(n int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.maxRetries = n
+}
+
+// RetryKeyExchange re-runs the post-processed key exchange of a session that
+// failed or was aborted (for example because the QBER exceeded the threshold),
+// so Alice and Bob do not need to create and join a new session. Each session
+// may be retried up to the configured maximum while it has not expired.
+func (sm *SessionManager) RetryKeyExchange(ctx context.Context, sessionID uuid.UUID) (*qkd.QuantumKey, error) {
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	sm.mutex.Lock()
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		sm.mutex.Unlock()
+		return nil, err
+	}
+
+	if session.Status != qkd.SessionFailed && session.Status != qkd.SessionAborted {
+		sm.mutex.Unlock()
+		return nil, qkd.ErrSessionNotRetryable
+	}
+	if time.Now().After(session.ExpiresAt) {
+		sm.mutex.Unlock()
+		return nil, qkd.ErrSessionExpired
+	}
+	if session.RetryCount >= sm.maxRetries {
+		sm.mutex.Unlock()
+		return nil, qkd.ErrRetryLimitReached
+	}
+
+	session.RetryCount++
+	session.Status = qkd.SessionActive
+	session.Message = fmt.Sprintf("Retrying key exchange (attempt %d of %d)", session.RetryCount, sm.maxRetries)
+	session.CompletedAt = nil
+	if err := sm.store.SaveSession(session); err != nil {
+		sm.mutex.Unlock()
+		return nil, err
+	}
+	sm.publishStatus(sessionID, session.Status, session.Message)
+
+	// Claim the session before releasing the lock, so no other exchange or
+	// rotation can start on it once it is active again
+	run, err := sm.initiatePostProcessing(session)
+	if err != nil {
+		sm.mutex.Unlock()
+		return nil, err
+	}
+	logger := sm.logger
+	sm.mutex.Unlock()
+
+	logger.DebugContext(ctx, "retrying key exchange", "session_id", sessionID, "attempt", session.RetryCount)
+
+	return sm.runPostProcessing(ctx, run)
+}
@@ -0,0 +1,102 @@
+package qkd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// abortedSession runs a post-processed exchange over a channel above the QBER
+// threshold and returns the aborted session
+func abortedSession(t *testing.T, sm *SessionManager) *qkd.QKDSession {
+	t.Helper()
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); err == nil {
+		t.Fatal("Expected the high-noise exchange to abort")
+	}
+
+	session, _ = sm.GetSession(session.SessionID)
+	if session.Status != qkd.SessionAborted {
+		t.Fatalf("Expected aborted session, got %s", session.Status)
+	}
+	return session
+}
+
+func TestRetryKeyExchangeAfterHighNoise(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	backend.SetTargetQBER(0.25)
+	sm := NewSessionManager(backend)
+	// The injected QBER is not the backend's noise level, so fix the transmission length
+	sm.SetOversamplingFactor(16)
+	session := abortedSession(t, sm)
+
+	// The channel is still noisy, so the first retry aborts again
+	if _, err := sm.RetryKeyExchange(context.Background(), session.SessionID); err == nil {
+		t.Fatal("Expected the retry over the same channel to abort")
+	}
+	session, _ = sm.GetSession(session.SessionID)
+	if session.Status != qkd.SessionAborted || session.RetryCount != 1 {
+		t.Fatalf("Expected aborted session with 1 retry, got %s with %d", session.Status, session.RetryCount)
+	}
+
+	// Once the noise drops, retrying the same session produces a key
+	backend.SetTargetQBER(0.03)
+	key, err := sm.RetryKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if key.SessionID != session.SessionID {
+		t.Errorf("Expected the key to belong to the retried session")
+	}
+
+	session, _ = sm.GetSession(session.SessionID)
+	if session.Status != qkd.SessionCompleted || session.RetryCount != 2 {
+		t.Errorf("Expected completed session with 2 retries, got %s with %d", session.Status, session.RetryCount)
+	}
+
+	// A completed session cannot be retried
+	if _, err := sm.RetryKeyExchange(context.Background(), session.SessionID); err != qkd.ErrSessionNotRetryable {
+		t.Errorf("Expected ErrSessionNotRetryable for a completed session, got %v", err)
+	}
+}
+
+func TestRetryKeyExchangeLimit(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+	backend.SetTargetQBER(0.25)
+	sm := NewSessionManager(backend)
+	sm.SetMaxRetries(1)
+	session := abortedSession(t, sm)
+
+	if _, err := sm.RetryKeyExchange(context.Background(), session.SessionID); err == nil {
+		t.Fatal("Expected the retry to abort")
+	}
+	if _, err := sm.RetryKeyExchange(context.Background(), session.SessionID); err != qkd.ErrRetryLimitReached {
+		t.Errorf("Expected ErrRetryLimitReached, got %v", err)
+	}
+
+	session, _ = sm.GetSession(session.SessionID)
+	if session.RetryCount != 1 {
+		t.Errorf("Expected the rejected retry not to count, got %d", session.RetryCount)
+	}
+}
+
+func TestRetryKeyExchangeRejectsActiveSessions(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	if _, err := sm.RetryKeyExchange(context.Background(), session.SessionID); err != qkd.ErrSessionNotRetryable {
+		t.Errorf("Expected ErrSessionNotRetryable for an active session, got %v", err)
+	}
+}
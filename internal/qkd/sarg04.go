@@ -0,0 +1,151 @@
+package qkd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// SARG04Protocol implements the SARG04 variant of BB84. The quantum step is
+// identical, but the key bit is Alice's basis: instead of announcing bases she
+// announces a pair of non-orthogonal states, one of which she sent.
+type SARG04Protocol struct {
+	*BB84Protocol
+}
+
+// StatePair is the pair of states Alice announces for one qubit: one value in
+// each basis, indexed by quantum.Basis
+type StatePair [2]quantum.Bit
+
+// NewSARG04Protocol creates a new SARG04 protocol instance
+func NewSARG04Protocol(backend quantum.QuantumBackend, keyLength int) *SARG04Protocol {
+	return &SARG04Protocol{
+		BB84Protocol: NewBB84Protocol(backend, keyLength),
+	}
+}
+
+// Name returns the protocol identifier
+func (s *SARG04Protocol) Name() string {
+	return "sarg04"
+}
+
+// TransmissionLength returns the number of qubits sent for the target key length
+func (s *SARG04Protocol) TransmissionLength() int {
+	return s.keyLength * 8 // Only ~25% of measurements are conclusive
+}
+
+// AliceGenerateQubits - Step 1: Alice prepares one of BB84's four states per qubit
+func (s *SARG04Protocol) AliceGenerateQubits(ctx context.Context) (*AliceSession, error) {
+	transmissionLength := s.TransmissionLength()
+
+	bits, err := quantum.SecureRandomBits(transmissionLength)
+	if err != nil {
+		return nil, err
+	}
+
+	bases, err := quantum.SecureRandomBases(transmissionLength)
+	if err != nil {
+		return nil, err
+	}
+
+	alice := &AliceSession{
+		Bits:  bits,
+		Bases: bases,
+	}
+
+	qubits, err := s.backend.PrepareAndSend(ctx, alice.Bits, alice.Bases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare qubits: %w", err)
+	}
+
+	alice.Qubits = qubits
+
+	return alice, nil
+}
+
+// AnnounceStatePairs pairs each of Alice's states with a random state from the other basis
+func (s *SARG04Protocol) AnnounceStatePairs(alice *AliceSession) ([]StatePair, error) {
+	decoys, err := quantum.SecureRandomBits(len(alice.Bits))
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]StatePair, len(alice.Bits))
+	for i := range alice.Bits {
+		pairs[i][alice.Bases[i]] = alice.Bits[i]
+		pairs[i][1-alice.Bases[i]] = decoys[i]
+	}
+
+	return pairs, nil
+}
+
+// BasisReconciliation - Step 3: Alice announces a state pair per qubit and Bob keeps
+// the bit only when his outcome is orthogonal to the pair's state in his measurement
+// basis. That state is excluded, so Alice's basis - the key bit - must be the other one.
+func (s *SARG04Protocol) BasisReconciliation(alice *AliceSession, bob *BobSession) (*SiftedKey, error) {
+	if err := checkTranscriptLengths(alice, bob); err != nil {
+		return nil, err
+	}
+
+	pairs, err := s.AnnounceStatePairs(alice)
+	if err != nil {
+		return nil, err
+	}
+
+	sifted := &SiftedKey{
+		AliceKey:  make([]quantum.Bit, 0),
+		BobKey:    make([]quantum.Bit, 0),
+		Indices:   make([]int, 0),
+		RawLength: len(alice.Bases),
+	}
+
+	for i, measurement := range bob.Measurements {
+		if !measurement.Conclusive() {
+			continue // Photon lost or inconclusive click
+		}
+
+		basis := measurement.MeasurementBasis
+		if measurement.MeasuredBit == pairs[i][basis] {
+			continue // Consistent with both states
+		}
+
+		sifted.AliceKey = append(sifted.AliceKey, quantum.Bit(alice.Bases[i]))
+		sifted.BobKey = append(sifted.BobKey, quantum.Bit(1-basis))
+		sifted.Indices = append(sifted.Indices, i)
+	}
+
+	return sifted, nil
+}
+
+// PerformKeyExchange executes the complete SARG04 protocol between Alice and Bob
+func (s *SARG04Protocol) PerformKeyExchange(ctx context.Context) (*KeyExchangeResult, error) {
+	result := &KeyExchangeResult{}
+
+	// Step 1: Alice generates qubits
+	alice, err := s.AliceGenerateQubits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("alice qubit generation failed: %w", err)
+	}
+
+	// Step 2: Bob measures qubits in random bases
+	bob, err := s.BobMeasureQubits(ctx, alice.Qubits)
+	if err != nil {
+		return nil, fmt.Errorf("bob measurement failed: %w", err)
+	}
+
+	// Step 3: State-pair announcement and sifting
+	sifted, err := s.BasisReconciliation(alice, bob)
+	if err != nil {
+		return nil, fmt.Errorf("basis reconciliation failed: %w", err)
+	}
+
+	result.RawKeyLength = len(sifted.AliceKey)
+	result.SiftingEfficiency = sifted.Efficiency(sifted.RawLength)
+
+	if result.RawKeyLength == 0 {
+		return nil, fmt.Errorf("no conclusive measurements - sifted key is empty")
+	}
+
+	return s.finalizeKey(sifted, result)
+}
@@ -0,0 +1,79 @@
+package qkd
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestSARG04SiftEfficiencyHalfOfBB84(t *testing.T) {
+	const keyLength = 1024
+
+	bb84, err := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), keyLength).PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("BB84 PerformKeyExchange failed: %v", err)
+	}
+
+	sarg04, err := NewSARG04Protocol(quantum.NewSimulatorBackend(false, 0.0), keyLength).PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("SARG04 PerformKeyExchange failed: %v", err)
+	}
+
+	if ratio := sarg04.SiftingEfficiency / bb84.SiftingEfficiency; math.Abs(ratio-0.5) > 0.05 {
+		t.Errorf("Expected SARG04 to sift ~half as efficiently as BB84, got %.3f vs %.3f (ratio %.3f)",
+			sarg04.SiftingEfficiency, bb84.SiftingEfficiency, ratio)
+	}
+}
+
+func TestSARG04NoiselessKeyMatches(t *testing.T) {
+	sarg04 := NewSARG04Protocol(quantum.NewIdealBackend(), 512)
+
+	alice, err := sarg04.AliceGenerateQubits(context.Background())
+	if err != nil {
+		t.Fatalf("AliceGenerateQubits failed: %v", err)
+	}
+	bob, err := sarg04.BobMeasureQubits(context.Background(), alice.Qubits)
+	if err != nil {
+		t.Fatalf("BobMeasureQubits failed: %v", err)
+	}
+
+	sifted, err := sarg04.BasisReconciliation(alice, bob)
+	if err != nil {
+		t.Fatalf("BasisReconciliation failed: %v", err)
+	}
+
+	if len(sifted.AliceKey) == 0 {
+		t.Fatal("Expected conclusive measurements")
+	}
+	if match, rate := crypto.VerifyKeyCorrectness(sifted.AliceKey, sifted.BobKey); !match {
+		t.Fatalf("Expected sifted keys to match on a noiseless channel, error rate %.3f", rate)
+	}
+
+	result, err := sarg04.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("PerformKeyExchange failed: %v", err)
+	}
+	if !result.Secure || result.QBER != 0 || result.FinalKeyLength != 512 {
+		t.Errorf("Expected a secure 512-bit key, got %+v", result)
+	}
+}
+
+func TestSARG04StatePairs(t *testing.T) {
+	sarg04 := NewSARG04Protocol(quantum.NewIdealBackend(), 64)
+
+	alice, _ := sarg04.AliceGenerateQubits(context.Background())
+	pairs, err := sarg04.AnnounceStatePairs(alice)
+	if err != nil {
+		t.Fatalf("AnnounceStatePairs failed: %v", err)
+	}
+
+	// Each pair contains the state Alice actually sent
+	for i, pair := range pairs {
+		if pair[alice.Bases[i]] != alice.Bits[i] {
+			t.Fatalf("Pair %d does not contain Alice's state", i)
+		}
+	}
+}
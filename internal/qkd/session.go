@@ -1,7 +1,12 @@
 package qkd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,23 +18,281 @@ import (
 
 // SessionManager manages QKD sessions and orchestrates key generation
 type SessionManager struct {
-	sessions  map[uuid.UUID]*qkd.QKDSession
-	keys      map[uuid.UUID]*qkd.QuantumKey
+	store     Store
+	externalBases map[uuid.UUID]*ExternalBases
+	joinTokens map[uuid.UUID]*joinToken
+	disclosures map[uuid.UUID]*DisclosureLedger
+	sessionMetrics map[uuid.UUID]*qkd.SessionMetrics
+	transcripts map[uuid.UUID]*qkd.Transcript
+	jobs      map[uuid.UUID]*ExchangeJob // session ID -> background exchange
+	joinTokenTTL time.Duration
+	keyTTL    time.Duration // Lifetime of generated keys unless the session sets its own
+	labels    map[string]uuid.UUID // participant+label -> session ID
 	mutex     sync.RWMutex
-	backend   quantum.QuantumBackend
+	backend   quantum.QuantumBackend // Serves sessions that do not name a backend
+	backends  map[qkd.QuantumBackendType]quantum.QuantumBackend // Backends sessions may request
+	idNorm    IDNormalization
+	pipeline  *Pipeline
+	webhooks  *WebhookNotifier
+	authorizer Authorizer // Decides who may access a session's keys
+	events    *EventBroker
+	metrics   *Metrics
+	logger    *slog.Logger
+	requirePostProcessing bool // Refuse the basic path so only corrected and amplified keys are stored
+	maxRawQubits int // Upper bound on qubits transmitted in a single exchange
+	oversampling int // Qubits per target key bit in post-processed exchanges; 0 budgets from the backend noise
+	decoy        *DecoyStateConfig // Decoy-state configuration for post-processed exchanges
+	correction   crypto.CorrectionMethod // Error correction algorithm for post-processed exchanges
+	maxRetries   int // Key exchange retries allowed per session
+	cleanupStop  chan struct{} // Closed to stop the background cleanup loop
+	cleanupDone  chan struct{} // Closed once the cleanup loop has exited
+	background   sync.WaitGroup // Background exchanges and callback deliveries in flight
+	jobsCtx      context.Context // Cancelled to abort background exchanges at shutdown
+	abortJobs    context.CancelFunc
+	shuttingDown bool // Set once Shutdown starts; no new background work is accepted
+	exchangeSlots chan struct{} // Holds one token per running exchange; nil when unlimited
+	exchangeTimeout time.Duration // Longest a single exchange may run; 0 is unlimited
+	keyPool      *KeyPool // Pre-generated keys for sessions of keyPoolLength bits; nil when disabled
+	keyPoolLength int
 }
 
+// DefaultMaxRawQubits caps the qubits a single exchange may transmit
+const DefaultMaxRawQubits = 1 << 20
+
+// Key lifetime defaults and bounds
+const (
+	DefaultKeyTTL = 24 * time.Hour
+	MinKeyTTL     = time.Minute
+	MaxKeyTTL     = 7 * 24 * time.Hour
+)
+
 // NewSessionManager creates a new session manager
 func NewSessionManager(backend quantum.QuantumBackend) *SessionManager {
+	jobsCtx, abortJobs := context.WithCancel(context.Background())
 	return &SessionManager{
-		sessions: make(map[uuid.UUID]*qkd.QKDSession),
-		keys:     make(map[uuid.UUID]*qkd.QuantumKey),
+		store:    NewMemoryStore(),
+		externalBases: make(map[uuid.UUID]*ExternalBases),
+		joinTokens: make(map[uuid.UUID]*joinToken),
+		disclosures: make(map[uuid.UUID]*DisclosureLedger),
+		sessionMetrics: make(map[uuid.UUID]*qkd.SessionMetrics),
+		transcripts: make(map[uuid.UUID]*qkd.Transcript),
+		jobs:     make(map[uuid.UUID]*ExchangeJob),
+		joinTokenTTL: DefaultJoinTokenTTL,
+		keyTTL:   DefaultKeyTTL,
+		labels:   make(map[string]uuid.UUID),
 		backend:  backend,
+		backends: map[qkd.QuantumBackendType]quantum.QuantumBackend{backendKind(backend): backend},
+		authorizer: ParticipantAuthorizer{},
+		pipeline: DefaultPipeline(),
+		events:   NewEventBroker(DefaultMaxSubscribersPerSession, DefaultMaxSubscribers),
+		metrics:  NewMetrics(),
+		logger:   slog.Default(),
+		maxRawQubits: DefaultMaxRawQubits,
+		maxRetries:   DefaultMaxRetries,
+		jobsCtx:      jobsCtx,
+		abortJobs:    abortJobs,
+	}
+}
+
+// SetWebhookNotifier enables key-ready callbacks for sessions that specify a callback URL
+func (sm *SessionManager) SetWebhookNotifier(n *WebhookNotifier) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.webhooks = n
+}
+
+// SetLogger sets the logger for protocol steps and background failures.
+// Exchange steps are logged at debug level with the caller's context, so the
+// request ID of the request that started them is attached.
+func (sm *SessionManager) SetLogger(logger *slog.Logger) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.logger = logger
+}
+
+// Logger returns the manager's logger
+func (sm *SessionManager) Logger() *slog.Logger {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return sm.logger
+}
+
+// BackendHealth probes the quantum backend exchanges run on
+func (sm *SessionManager) BackendHealth(ctx context.Context) *qkd.BackendHealth {
+	health := sm.backend.HealthStatus(ctx)
+	return &qkd.BackendHealth{
+		Name:       sm.backend.Name(),
+		Status:     string(health.State),
+		NoiseLevel: sm.backend.GetNoiseLevel(),
+		Message:    health.Message,
+	}
+}
+
+// SetPipeline replaces the post-processing pipeline used by ExecuteKeyExchangeWithPostProcessing
+func (sm *SessionManager) SetPipeline(p *Pipeline) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.pipeline = p
+}
+
+// SetRequirePostProcessing makes ExecuteKeyExchange return ErrPostProcessingRequired,
+// so that only keys produced by ExecuteKeyExchangeWithPostProcessing can be retrieved
+func (sm *SessionManager) SetRequirePostProcessing(required bool) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.requirePostProcessing = required
+}
+
+// SetMaxRawQubits caps the number of qubits a single exchange may transmit.
+// Exchanges that would exceed it fail with a RawQubitLimitError, matching
+// ErrOversamplingTooLarge, before any generation.
+func (sm *SessionManager) SetMaxRawQubits(max int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if max > 0 {
+		sm.maxRawQubits = max
+	}
+}
+
+// SetKeyTTL sets the lifetime of keys generated in sessions that do not choose
+// their own. It must be between MinKeyTTL and MaxKeyTTL.
+func (sm *SessionManager) SetKeyTTL(ttl time.Duration) error {
+	if ttl < MinKeyTTL || ttl > MaxKeyTTL {
+		return qkd.ErrInvalidKeyTTL
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.keyTTL = ttl
+	return nil
+}
+
+// keyExpiry returns when a key generated now in session expires
+func (sm *SessionManager) keyExpiry(session *qkd.QKDSession, now time.Time) time.Time {
+	if session.KeyTTLMinutes > 0 {
+		return now.Add(time.Duration(session.KeyTTLMinutes) * time.Minute)
+	}
+
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return now.Add(sm.keyTTL)
+}
+
+// SetOversamplingFactor sets how many qubits ExecuteKeyExchangeWithPostProcessing
+// transmits per bit of requested key length, overriding the budget computed from
+// the backend's noise level. Raise it for lossy channels, which the budget does not
+// model; the raw qubit cap still applies. A factor of 0 restores the budget.
+func (sm *SessionManager) SetOversamplingFactor(factor int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if factor >= 0 {
+		sm.oversampling = factor
+	}
+}
+
+// SetErrorCorrection selects the error correction algorithm used by
+// ExecuteKeyExchangeWithPostProcessing. Cascade is the default.
+func (sm *SessionManager) SetErrorCorrection(method crypto.CorrectionMethod) error {
+	if _, err := crypto.NewCorrector(method, 0); err != nil {
+		return err
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.correction = method
+	return nil
+}
+
+// SetDecoyStates enables decoy-state BB84 for ExecuteKeyExchangeWithPostProcessing,
+// bounding the secure key length by the single-photon contribution. The backend must
+// implement quantum.PulseSource. A nil config disables decoy states.
+func (sm *SessionManager) SetDecoyStates(config *DecoyStateConfig) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.decoy = config
+	return nil
+}
+
+// RawQubitLimitError reports an exchange that would transmit more qubits than
+// the configured cap allows. Required is 0 when no transmission within the cap is
+// expected to survive post-processing. It matches qkd.ErrOversamplingTooLarge.
+type RawQubitLimitError struct {
+	KeyLength int
+	Required  int
+	Max       int
+}
+
+func (e *RawQubitLimitError) Error() string {
+	if e.Required == 0 {
+		return fmt.Sprintf("%s: a %d-bit key is not expected to survive post-processing within %d raw qubits; request a shorter key",
+			qkd.ErrOversamplingTooLarge, e.KeyLength, e.Max)
 	}
+	return fmt.Sprintf("%s: a %d-bit key needs %d raw qubits but at most %d are allowed; request a shorter key or a lower oversampling factor",
+		qkd.ErrOversamplingTooLarge, e.KeyLength, e.Required, e.Max)
+}
+
+// Is reports whether target is qkd.ErrOversamplingTooLarge
+func (e *RawQubitLimitError) Is(target error) bool {
+	return target == qkd.ErrOversamplingTooLarge
+}
+
+// checkRawQubits rejects protocols that would transmit more than the configured cap.
+// Must be called with sm.mutex held.
+func (sm *SessionManager) checkRawQubits(bb84 *BB84Protocol) error {
+	if required := bb84.TransmissionLength(); required > sm.maxRawQubits {
+		return &RawQubitLimitError{KeyLength: bb84.keyLength, Required: required, Max: sm.maxRawQubits}
+	}
+	return nil
+}
+
+// SetIDNormalization configures how Alice and Bob IDs are canonicalized.
+// The same rules are applied at session creation, join and key authorization.
+func (sm *SessionManager) SetIDNormalization(n IDNormalization) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.idNorm = n
+}
+
+// normalizeID applies the configured ID normalization
+func (sm *SessionManager) normalizeID(id string) string {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return sm.idNorm.Normalize(id)
 }
 
-// CreateSession creates a new QKD session initiated by Alice
+// SameUser reports whether two IDs name the same user under the configured normalization
+func (sm *SessionManager) SameUser(a, b string) bool {
+	a = sm.normalizeID(a)
+	return a != "" && a == sm.normalizeID(b)
+}
+
+// CreateSession creates a new QKD session initiated by Alice.
+// The returned session carries the single-use join token Bob must present;
+// it is not retrievable afterwards.
 func (sm *SessionManager) CreateSession(req *qkd.SessionCreateRequest) (*qkd.QKDSession, error) {
+	req.AliceID = sm.normalizeID(req.AliceID)
+	if req.Backend == "" {
+		req.Backend = backendKind(sm.backend)
+	}
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
@@ -37,6 +300,36 @@ func (sm *SessionManager) CreateSession(req *qkd.SessionCreateRequest) (*qkd.QKD
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
+	backend, ok := sm.backends[req.Backend]
+	if !ok {
+		return nil, qkd.ErrBackendNotConfigured
+	}
+
+	if req.InterceptProbability > 0 {
+		if _, ok := backend.(*quantum.SimulatorBackend); !ok {
+			return nil, qkd.ErrEavesdropperUnsupported
+		}
+	}
+
+	if req.Participants > 2 {
+		// Qiskit does not run GHZ circuits on the device yet
+		_, qiskit := backend.(*quantum.QiskitBackend)
+		if _, ok := backend.(quantum.GHZSource); !ok || qiskit {
+			return nil, qkd.ErrConferenceUnsupported
+		}
+	}
+
+	if req.Label != "" {
+		if _, taken := sm.labels[labelIndexKey(req.AliceID, req.Label)]; taken {
+			return nil, qkd.ErrDuplicateLabel
+		}
+	}
+
+	token, stored, err := newJoinToken(sm.joinTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
 	sessionID := uuid.New()
 	now := time.Now()
 
@@ -46,27 +339,60 @@ func (sm *SessionManager) CreateSession(req *qkd.SessionCreateRequest) (*qkd.QKD
 		Status:     qkd.SessionWaitingForBob,
 		Backend:    req.Backend,
 		KeyLength:  req.KeyLength,
+		Label:      req.Label,
+		CallbackURL: req.CallbackURL,
+		CallbackIncludeKey: req.CallbackIncludeKey,
+		InterceptProbability: req.InterceptProbability,
+		KeyTTLMinutes: req.KeyTTLMinutes,
 		CreatedAt:  now,
 		ExpiresAt:  now.Add(time.Duration(req.TTLMinutes) * time.Minute),
 	}
 
-	sm.sessions[sessionID] = session
+	if req.Participants > 2 {
+		session.Participants = req.Participants
+	}
 
-	return session, nil
+	if err := sm.store.SaveSession(session); err != nil {
+		return nil, err
+	}
+	sm.metrics.SessionsCreated.Inc()
+	sm.joinTokens[sessionID] = stored
+	if session.Label != "" {
+		sm.labels[labelIndexKey(session.AliceID, session.Label)] = sessionID
+	}
+
+	created := *session
+	created.JoinToken = token
+	return &created, nil
 }
 
-// JoinSession allows Bob to join an existing session
-func (sm *SessionManager) JoinSession(sessionID uuid.UUID, bobID string) (*qkd.QKDSession, error) {
+// JoinSession allows Bob to join an existing session using the join token
+// issued at creation. The token is invalidated once Bob has joined. In a
+// conference session every other participant joins with the same token, which
+// stays valid until the session is full.
+func (sm *SessionManager) JoinSession(sessionID uuid.UUID, bobID, joinToken string) (*qkd.QKDSession, error) {
+	bobID = sm.normalizeID(bobID)
+	if bobID == "" {
+		return nil, qkd.ErrInvalidBobID
+	}
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	session, exists := sm.sessions[sessionID]
-	if !exists {
-		return nil, qkd.ErrSessionNotFound
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sm.checkJoinToken(sessionID, joinToken); err != nil {
+		return nil, err
 	}
 
 	if time.Now().After(session.ExpiresAt) {
 		session.Status = qkd.SessionAborted
+		if err := sm.store.SaveSession(session); err != nil {
+			return nil, err
+		}
 		return nil, qkd.ErrSessionExpired
 	}
 
@@ -74,52 +400,158 @@ func (sm *SessionManager) JoinSession(sessionID uuid.UUID, bobID string) (*qkd.Q
 		return nil, qkd.ErrSessionInProgress
 	}
 
-	session.BobID = bobID
+	conference := session.Participants > 2
+	if conference && slices.Contains(sessionParticipants(session), bobID) {
+		return nil, qkd.ErrAlreadyJoined
+	}
+
+	if session.Label != "" {
+		indexKey := labelIndexKey(bobID, session.Label)
+		if existing, taken := sm.labels[indexKey]; taken && existing != sessionID {
+			return nil, qkd.ErrDuplicateLabel
+		}
+		sm.labels[indexKey] = sessionID
+	}
+
+	if conference {
+		if session.BobID == "" {
+			session.BobID = bobID
+		}
+		session.ParticipantIDs = append(slices.Clip(session.ParticipantIDs), bobID)
+
+		if joined := len(session.ParticipantIDs) + 1; joined < session.Participants {
+			if err := sm.store.SaveSession(session); err != nil {
+				return nil, err
+			}
+			sm.publishStatus(sessionID, session.Status, fmt.Sprintf("%d of %d participants joined", joined, session.Participants))
+			return session, nil
+		}
+	} else {
+		session.BobID = bobID
+	}
+
 	session.Status = qkd.SessionActive
+	if err := sm.store.SaveSession(session); err != nil {
+		return nil, err
+	}
+	delete(sm.joinTokens, sessionID)
+	if conference {
+		sm.publishStatus(sessionID, session.Status, "All participants joined the session")
+	} else {
+		sm.publishStatus(sessionID, session.Status, "Bob joined the session")
+	}
 
 	return session, nil
 }
 
-// ExecuteKeyExchange performs the complete BB84 key exchange for a session
-func (sm *SessionManager) ExecuteKeyExchange(sessionID uuid.UUID) (*qkd.QuantumKey, error) {
+// exchangeBackend returns the backend for a session's key exchange. Sessions that
+// simulate an eavesdropper get their own copy of the simulator so other sessions
+// sharing the backend are unaffected. Must be called with sm.mutex held.
+func (sm *SessionManager) exchangeBackend(session *qkd.QKDSession) (quantum.QuantumBackend, error) {
+	backend, err := sm.sessionBackend(session)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.InterceptProbability > 0 {
+		if sim, ok := backend.(*quantum.SimulatorBackend); ok {
+			return sim.WithInterceptProbability(session.InterceptProbability), nil
+		}
+	}
+	return backend, nil
+}
+
+// checkExecutable reports why a session cannot run a key exchange, or nil if it can
+func checkExecutable(session *qkd.QKDSession) error {
+	switch session.Status {
+	case qkd.SessionActive:
+		return nil
+	case qkd.SessionCompleted:
+		return qkd.ErrSessionAlreadyCompleted
+	case qkd.SessionAborted, qkd.SessionFailed:
+		return qkd.ErrSessionTerminated
+	default:
+		return qkd.ErrSessionNotActive
+	}
+}
+
+// ExecuteKeyExchange performs the complete BB84 key exchange for a session.
+// Cancelling ctx aborts the exchange and marks the session failed.
+func (sm *SessionManager) ExecuteKeyExchange(ctx context.Context, sessionID uuid.UUID) (*qkd.QuantumKey, error) {
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	sm.mutex.Lock()
-	session, exists := sm.sessions[sessionID]
-	if !exists {
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		sm.mutex.Unlock()
+		return nil, err
+	}
+
+	if err := checkExecutable(session); err != nil {
+		sm.mutex.Unlock()
+		return nil, err
+	}
+
+	// Conference keys are always reconciled and amplified
+	if session.Participants > 2 {
+		sm.mutex.Unlock()
+		return sm.executePostProcessing(ctx, sessionID)
+	}
+
+	if sm.requirePostProcessing {
 		sm.mutex.Unlock()
-		return nil, qkd.ErrSessionNotFound
+		return nil, qkd.ErrPostProcessingRequired
 	}
 
-	if session.Status != qkd.SessionActive {
+	// Create BB84 protocol instance
+	backend, err := sm.exchangeBackend(session)
+	if err != nil {
+		sm.mutex.Unlock()
+		return nil, err
+	}
+	bb84 := NewBB84Protocol(backend, session.KeyLength)
+	if err := sm.checkRawQubits(bb84); err != nil {
 		sm.mutex.Unlock()
-		return nil, fmt.Errorf("session is not active")
+		return nil, err
 	}
 
 	session.Status = qkd.SessionInitiating
+	if err := sm.store.SaveSession(session); err != nil {
+		sm.mutex.Unlock()
+		return nil, err
+	}
+	sm.publishStatus(sessionID, session.Status, "Key exchange started")
+	logger := sm.logger.With("session_id", sessionID)
 	sm.mutex.Unlock()
 
-	// Create BB84 protocol instance
-	bb84 := NewBB84Protocol(sm.backend, session.KeyLength)
+	ctx, cancel := sm.exchangeContext(ctx)
+	defer cancel()
+
+	defer sm.observeExchange(backend, time.Now())
+	outcome := ExchangeFailed
+	defer func() { sm.countExchange(outcome) }()
+	logger.DebugContext(ctx, "key exchange started", "qubits", bb84.TransmissionLength())
 
 	// Execute key exchange
-	result, err := bb84.PerformKeyExchange()
+	result, err := bb84.PerformKeyExchange(ctx)
 	if err != nil {
+		err = timeoutCause(ctx, err)
+		logger.DebugContext(ctx, "key exchange failed", "error", err)
 		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
 		return nil, fmt.Errorf("key exchange failed: %w", err)
 	}
-
-	// Update session with results
-	sm.updateSessionStatus(
-		sessionID,
-		qkd.SessionCompleted,
-		result.QBER,
-		result.RawKeyLength,
-		result.FinalKeyLength,
-		result.Secure,
-		result.Message,
-	)
+	logger.DebugContext(ctx, "key exchange finished", "qber", result.QBER, "secure", result.Secure)
+	sm.observeChannel(result.QBER, result.SiftingEfficiency)
 
 	// If key generation was not secure, don't store the key
 	if !result.Secure {
+		outcome = ExchangeInsecure
+		sm.updateSessionStatus(sessionID, qkd.SessionCompleted, result.QBER, result.RawKeyLength, result.FinalKeyLength, false, result.Message)
+		crypto.Zeroize(result.Key)
 		return nil, fmt.Errorf("key generation was not secure: %s", result.Message)
 	}
 
@@ -130,121 +562,249 @@ func (sm *SessionManager) ExecuteKeyExchange(sessionID uuid.UUID) (*qkd.QuantumK
 	quantumKey := &qkd.QuantumKey{
 		KeyID:       keyID,
 		SessionID:   sessionID,
+		Label:       session.Label,
 		KeyMaterial: result.Key,
 		KeyLength:   result.FinalKeyLength,
 		GeneratedAt: now,
-		ExpiresAt:   now.Add(24 * time.Hour), // Keys expire after 24 hours
+		ExpiresAt:   sm.keyExpiry(session, now),
 		IsActive:    true,
 	}
 
-	sm.mutex.Lock()
-	sm.keys[keyID] = quantumKey
-	sm.mutex.Unlock()
+	if err := sm.store.SaveKey(quantumKey); err != nil {
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, result.QBER, result.RawKeyLength, 0, false, err.Error())
+		crypto.Zeroize(result.Key)
+		return nil, fmt.Errorf("failed to store key: %w", err)
+	}
+
+	// The session only reports completion once its key can be retrieved
+	sm.publishEvent(sessionID, EventKeyReady, qkd.SessionCompleted, "Key stored")
+	sm.updateSessionStatus(
+		sessionID,
+		qkd.SessionCompleted,
+		result.QBER,
+		result.RawKeyLength,
+		result.FinalKeyLength,
+		result.Secure,
+		result.Message,
+	)
+
+	logger.DebugContext(ctx, "key stored", "key_id", keyID, "key_length", quantumKey.KeyLength)
+	outcome = ExchangeSucceeded
+
+	sm.notifyKeyReady(quantumKey)
 
 	return quantumKey, nil
 }
 
-// ExecuteKeyExchangeWithPostProcessing performs BB84 with error correction and privacy amplification
-func (sm *SessionManager) ExecuteKeyExchangeWithPostProcessing(sessionID uuid.UUID) (*qkd.QuantumKey, error) {
-	sm.mutex.Lock()
-	session, exists := sm.sessions[sessionID]
-	if !exists {
-		sm.mutex.Unlock()
-		return nil, qkd.ErrSessionNotFound
+// ExecuteKeyExchangeWithPostProcessing performs BB84 with error correction and privacy amplification.
+// Post-processing runs through the manager's configured pipeline (see SetPipeline).
+// Cancelling ctx aborts the exchange and marks the session failed.
+func (sm *SessionManager) ExecuteKeyExchangeWithPostProcessing(ctx context.Context, sessionID uuid.UUID) (*qkd.QuantumKey, error) {
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	if session.Status != qkd.SessionActive {
-		sm.mutex.Unlock()
-		return nil, fmt.Errorf("session is not active")
+	return sm.executePostProcessing(ctx, sessionID)
+}
+
+// executePostProcessing runs a post-processed exchange; the caller holds an exchange slot
+func (sm *SessionManager) executePostProcessing(ctx context.Context, sessionID uuid.UUID) (*qkd.QuantumKey, error) {
+	run, err := sm.startPostProcessing(sessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	session.Status = qkd.SessionInitiating
-	sm.mutex.Unlock()
+	return sm.runPostProcessing(ctx, run)
+}
 
-	// Step 1: BB84 Protocol
-	bb84 := NewBB84Protocol(sm.backend, session.KeyLength*4) // Generate 4x for post-processing overhead
+// postProcessingRun is an exchange that has been validated and marked initiating
+type postProcessingRun struct {
+	session      *qkd.QKDSession
+	backend      quantum.QuantumBackend // The session's backend, for metric labels
+	bb84         *BB84Protocol
+	conference   *ConferenceProtocol // Set instead of bb84 for conference sessions
+	pipeline     *Pipeline
+	correction   crypto.CorrectionMethod
+	logger       *slog.Logger
+	batchPending bool // More keys of a batch follow this exchange
+}
 
-	// Generate qubits (Alice)
-	alice, err := bb84.AliceGenerateQubits()
+// startPostProcessing checks that a session can run a post-processed exchange
+// and marks it initiating, so no second exchange can start on it
+func (sm *SessionManager) startPostProcessing(sessionID uuid.UUID) (*postProcessingRun, error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	session, err := sm.store.GetSession(sessionID)
 	if err != nil {
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
 		return nil, err
 	}
 
-	// Measure qubits (Bob)
-	bob, err := bb84.BobMeasureQubits(alice.Qubits)
-	if err != nil {
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
+	if err := checkExecutable(session); err != nil {
 		return nil, err
 	}
 
-	// Basis reconciliation
-	sifted, err := bb84.BasisReconciliation(alice, bob)
-	if err != nil {
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
-		return nil, err
+	return sm.initiatePostProcessing(session)
+}
+
+// initiatePostProcessing plans the transmission for a session's next exchange and
+// marks the session initiating. The caller must hold the write lock.
+func (sm *SessionManager) initiatePostProcessing(session *qkd.QKDSession) (*postProcessingRun, error) {
+	sessionID := session.SessionID
+
+	if session.Participants > 2 {
+		return sm.initiateConference(session)
 	}
 
-	// Estimate QBER
-	qber, err := bb84.EstimateQBER(sifted)
+	// Step 1: BB84 Protocol, sending enough qubits to survive post-processing
+	bb84, err := sm.planPostProcessing(session)
 	if err != nil {
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
 		return nil, err
 	}
 
-	if qber > bb84.qberThreshold {
-		msg := fmt.Sprintf("QBER too high: %.2f%% (threshold: %.2f%%)", qber*100, bb84.qberThreshold*100)
-		sm.updateSessionStatus(sessionID, qkd.SessionAborted, qber, len(sifted.AliceKey), 0, false, msg)
-		return nil, fmt.Errorf("%s", msg)
+	session.Status = qkd.SessionInitiating
+	if err := sm.store.SaveSession(session); err != nil {
+		return nil, err
 	}
+	sm.publishStatus(sessionID, session.Status, "Key exchange started")
+
+	return &postProcessingRun{
+		session:    session,
+		backend:    bb84.backend,
+		bb84:       bb84,
+		pipeline:   sm.pipeline,
+		correction: sm.correction,
+		logger:     sm.logger.With("session_id", sessionID),
+	}, nil
+}
 
-	// Step 2: Error Correction
-	corrector := crypto.NewCascadeCorrector(qber)
-	bobCorrected, disclosedBits, err := corrector.Correct(sifted.AliceKey, sifted.BobKey)
+// planPostProcessing sets up the BB84 transmission for a session's post-processed
+// exchange. The caller must hold the lock.
+func (sm *SessionManager) planPostProcessing(session *qkd.QKDSession) (*BB84Protocol, error) {
+	backend, err := sm.exchangeBackend(session)
 	if err != nil {
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, qber, len(sifted.AliceKey), 0, false, err.Error())
 		return nil, err
 	}
+	bb84 := NewBB84Protocol(backend, session.KeyLength)
+	if sm.decoy != nil {
+		bb84.EnableDecoyStates(*sm.decoy)
+	}
+	if sm.oversampling > 0 {
+		bb84.SetOversamplingFactor(sm.oversampling)
+	} else {
+		budget := PostProcessingBudget{
+			ExpectedQBER:      backend.GetNoiseLevel(),
+			Correction:        sm.correction,
+			SecurityParameter: DefaultSecurityParameter,
+			MaxQubits:         sm.maxRawQubits,
+		}
+		if bb84.PostProcessingTransmissionLength(budget) == 0 {
+			return nil, &RawQubitLimitError{KeyLength: session.KeyLength, Max: sm.maxRawQubits}
+		}
+		bb84.BudgetPostProcessing(budget)
+	}
+	if err := sm.checkRawQubits(bb84); err != nil {
+		return nil, err
+	}
+	return bb84, nil
+}
 
-	// Verify keys match after error correction
-	keysMatch, errorRate := crypto.VerifyKeyCorrectness(sifted.AliceKey, bobCorrected)
-	if !keysMatch {
-		msg := fmt.Sprintf("Error correction failed: remaining error rate %.2f%%", errorRate*100)
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, qber, len(sifted.AliceKey), 0, false, msg)
-		return nil, fmt.Errorf("%s", msg)
+// runPostProcessing transmits, measures and post-processes a started exchange
+func (sm *SessionManager) runPostProcessing(ctx context.Context, run *postProcessingRun) (*qkd.QuantumKey, error) {
+	ctx, cancel := sm.exchangeContext(ctx)
+	defer cancel()
+
+	if run.conference != nil {
+		return sm.runConference(ctx, run)
 	}
 
-	// Step 3: Privacy Amplification
-	amplifier := crypto.NewPrivacyAmplifier(crypto.SHA3_256Method)
+	if pooled, ok := sm.takePooledKey(run.session); ok {
+		return sm.servePooledKey(run, pooled)
+	}
 
-	// Calculate information leakage
-	sampleBits := int(float64(len(sifted.AliceKey)) * bb84.sampleSize)
-	totalLeakage := float64(sampleBits+disclosedBits) / float64(len(sifted.AliceKey))
+	session, bb84, pipeline, logger := run.session, run.bb84, run.pipeline, run.logger
+	sessionID := session.SessionID
 
-	// Calculate maximum secure key length
-	secureLength := crypto.CalculateSecureKeyLength(
-		len(sifted.AliceKey),
-		qber,
-		disclosedBits,
-		64, // security parameter
-	)
+	defer sm.observeExchange(run.backend, time.Now())
+	outcome := ExchangeFailed
+	defer func() { sm.countExchange(outcome) }()
+	start := time.Now()
+	logger.DebugContext(ctx, "key exchange started", "qubits", bb84.TransmissionLength())
 
-	if secureLength < session.KeyLength {
-		msg := fmt.Sprintf("Cannot generate requested key length: max secure length is %d bits", secureLength)
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, qber, len(sifted.AliceKey), secureLength, false, msg)
-		return nil, fmt.Errorf("%s", msg)
+	pc := &PipelineContext{
+		Protocol:     bb84,
+		TargetLength: session.KeyLength,
+		Correction:   run.correction,
+		Ledger:       NewDisclosureLedger(),
 	}
 
-	// Perform privacy amplification
-	finalKey, err := amplifier.Amplify(sifted.AliceKey, totalLeakage, session.KeyLength)
+	// Wipe raw and intermediate keys on every exit path; the final key survives
+	// only once it has been handed to the store
+	stored := false
+	defer func() {
+		if stored {
+			pc.ZeroizeIntermediate()
+		} else {
+			pc.Zeroize()
+		}
+	}()
+
+	// Generate qubits (Alice)
+	alice, err := bb84.AliceGenerateQubits(ctx)
 	if err != nil {
-		sm.updateSessionStatus(sessionID, qkd.SessionFailed, qber, len(sifted.AliceKey), 0, false, err.Error())
+		err = timeoutCause(ctx, err)
+		logger.DebugContext(ctx, "qubit generation failed", "error", err)
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
 		return nil, err
 	}
+	pc.Alice = alice
+	logger.DebugContext(ctx, "qubits generated", "qubits", len(alice.Qubits))
 
-	// Update session
-	msg := fmt.Sprintf("Secure key generated! QBER: %.2f%%, Disclosed bits: %d", qber*100, disclosedBits)
-	sm.updateSessionStatus(sessionID, qkd.SessionCompleted, qber, len(sifted.AliceKey), len(finalKey)*8, true, msg)
+	// Measure qubits (Bob)
+	bob, err := bb84.BobMeasureQubits(ctx, alice.Qubits)
+	if err != nil {
+		err = timeoutCause(ctx, err)
+		logger.DebugContext(ctx, "qubit measurement failed", "error", err)
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
+		return nil, err
+	}
+	pc.Bob = bob
+	logger.DebugContext(ctx, "qubits measured", "measurements", len(bob.Measurements))
+
+	if err := ctx.Err(); err != nil {
+		err = timeoutCause(ctx, err)
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
+		return nil, err
+	}
+
+	// Step 2: Post-processing pipeline (sifting, QBER estimation, correction, amplification)
+	sm.mutex.Lock()
+	sm.disclosures[sessionID] = pc.Ledger
+	sm.mutex.Unlock()
+
+	pc.OnStage = func(stage string) {
+		logger.DebugContext(ctx, "post-processing stage", "stage", stage)
+		sm.publishStage(sessionID, stage, pc)
+	}
+
+	err = pipeline.Run(pc)
+	sm.recordSessionMetrics(sessionID, pc, time.Since(start))
+	sm.recordTranscript(sessionID, pc, err)
+	if err != nil {
+		status := qkd.SessionFailed
+		var qberErr *QBERExceededError
+		if errors.As(err, &qberErr) {
+			status = qkd.SessionAborted
+			outcome = ExchangeAborted
+		}
+		logger.DebugContext(ctx, "post-processing failed", "status", status, "qber", pc.QBER, "error", err)
+		sm.updateSessionStatus(sessionID, status, pc.QBER, len(pc.AliceKey), pc.SecureLength, false, err.Error())
+		return nil, err
+	}
+
+	finalKey := pc.FinalKey
 
 	// Store key
 	keyID := uuid.New()
@@ -253,26 +813,167 @@ func (sm *SessionManager) ExecuteKeyExchangeWithPostProcessing(sessionID uuid.UU
 	quantumKey := &qkd.QuantumKey{
 		KeyID:       keyID,
 		SessionID:   sessionID,
+		Label:       session.Label,
 		KeyMaterial: finalKey,
 		KeyLength:   len(finalKey) * 8,
 		GeneratedAt: now,
-		ExpiresAt:   now.Add(24 * time.Hour),
+		ExpiresAt:   sm.keyExpiry(session, now),
 		IsActive:    true,
 	}
 
-	sm.mutex.Lock()
-	sm.keys[keyID] = quantumKey
-	sm.mutex.Unlock()
+	if err := sm.store.SaveKey(quantumKey); err != nil {
+		sm.updateSessionStatus(sessionID, qkd.SessionFailed, pc.QBER, len(pc.AliceKey), 0, false, err.Error())
+		return nil, fmt.Errorf("failed to store key: %w", err)
+	}
+	stored = true
+	logger.DebugContext(ctx, "key stored", "key_id", keyID, "key_length", quantumKey.KeyLength, "qber", pc.QBER)
+	outcome = ExchangeSucceeded
+
+	// Update session once the key can be retrieved
+	msg := fmt.Sprintf("Secure key generated! QBER: %.2f%%, Disclosed bits: %d", pc.QBER*100, pc.DisclosedBits)
+	sm.completeExchange(run, quantumKey, pc.QBER, len(pc.AliceKey), msg)
 
 	return quantumKey, nil
 }
 
+// completeExchange publishes a stored key, completes the session and sends the
+// key callback. While more keys of a batch follow, only the key-ready event is
+// published: the session stays initiating, so progress streams stay open and no
+// other exchange or rotation can claim the session between keys.
+func (sm *SessionManager) completeExchange(run *postProcessingRun, key *qkd.QuantumKey, qber float64, rawKeyLen int, message string) {
+	sessionID := run.session.SessionID
+	if run.batchPending {
+		sm.publishEvent(sessionID, EventKeyReady, qkd.SessionInitiating, "Key stored")
+		return
+	}
+
+	sm.publishEvent(sessionID, EventKeyReady, qkd.SessionCompleted, "Key stored")
+	sm.updateSessionStatus(sessionID, qkd.SessionCompleted, qber, rawKeyLen, key.KeyLength, true, message)
+	sm.notifyKeyReady(key)
+}
+
+// ExecuteBatchKeyExchange runs count post-processed key exchanges on an active
+// session, storing one key per exchange, and returns the key IDs in order. The
+// session stays initiating for the whole batch and completes, sending a single
+// key callback for the last key, once every key is stored. If an exchange fails
+// the batch stops, and the IDs of the keys already stored are returned with the error.
+func (sm *SessionManager) ExecuteBatchKeyExchange(ctx context.Context, sessionID uuid.UUID, count int) ([]uuid.UUID, error) {
+	if count < 1 || count > qkd.MaxBatchKeys {
+		return nil, qkd.ErrInvalidBatchCount
+	}
+
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	run, err := sm.startPostProcessing(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	keyIDs := make([]uuid.UUID, 0, count)
+	for {
+		run.batchPending = len(keyIDs) < count-1
+		key, err := sm.runPostProcessing(ctx, run)
+		if err != nil {
+			return keyIDs, err
+		}
+		keyIDs = append(keyIDs, key.KeyID)
+		if len(keyIDs) == count {
+			return keyIDs, nil
+		}
+
+		if run, err = sm.continueBatch(sessionID); err != nil {
+			// Do not leave the session initiating with no exchange running
+			if err != qkd.ErrSessionNotActive {
+				sm.updateSessionStatus(sessionID, qkd.SessionFailed, 0, 0, 0, false, err.Error())
+			}
+			return keyIDs, err
+		}
+	}
+}
+
+// continueBatch starts the next exchange of a batch on a session whose previous
+// exchange has just stored its key and left the session initiating
+func (sm *SessionManager) continueBatch(sessionID uuid.UUID) (*postProcessingRun, error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != qkd.SessionInitiating {
+		return nil, qkd.ErrSessionNotActive
+	}
+
+	return sm.initiatePostProcessing(session)
+}
+
+// recordSessionMetrics stores the metrics of a post-processed exchange
+func (sm *SessionManager) recordSessionMetrics(sessionID uuid.UUID, pc *PipelineContext, elapsed time.Duration) {
+	metrics := newSessionMetrics(sessionID, pc, elapsed)
+
+	// Only report what the pipeline got far enough to measure
+	collectors := sm.Metrics()
+	if pc.Sifted != nil {
+		collectors.SiftEfficiency.Observe(metrics.SiftingEfficiency)
+	}
+	if pc.SampledBits > 0 {
+		collectors.QBER.Observe(pc.QBER)
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.sessionMetrics[sessionID] = metrics
+}
+
+// newSessionMetrics summarizes a pipeline run that took elapsed
+func newSessionMetrics(sessionID uuid.UUID, pc *PipelineContext, elapsed time.Duration) *qkd.SessionMetrics {
+	metrics := &qkd.SessionMetrics{
+		SessionID:        sessionID,
+		TotalQubits:      len(pc.Alice.Qubits),
+		QBER:             pc.QBER,
+		ErrorsCorrected:  pc.ErrorsCorrected,
+		DisclosedBits:    pc.SampledBits + pc.DisclosedBits,
+		FinalKeyLength:   len(pc.FinalKey) * 8,
+		ProcessingTimeMs: elapsed.Milliseconds(),
+	}
+	if pc.Sifted != nil {
+		metrics.SiftedKeyLength = len(pc.Sifted.AliceKey)
+	}
+	if metrics.TotalQubits > 0 {
+		metrics.SiftingEfficiency = float64(metrics.SiftedKeyLength) / float64(metrics.TotalQubits)
+	}
+	return metrics
+}
+
+// GetSessionMetrics returns the metrics recorded for a session's post-processed exchange
+func (sm *SessionManager) GetSessionMetrics(sessionID uuid.UUID) (*qkd.SessionMetrics, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	if _, err := sm.store.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	metrics, exists := sm.sessionMetrics[sessionID]
+	if !exists {
+		return nil, qkd.ErrMetricsNotRecorded
+	}
+
+	return metrics, nil
+}
+
 // updateSessionStatus updates a session's status and metrics
 func (sm *SessionManager) updateSessionStatus(sessionID uuid.UUID, status qkd.SessionStatus, qber float64, rawKeyLen, finalKeyLen int, secure bool, message string) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	if session, exists := sm.sessions[sessionID]; exists {
+	if session, err := sm.store.GetSession(sessionID); err == nil {
 		session.Status = status
 		session.QBER = qber
 		session.RawKeyLength = rawKeyLen
@@ -284,66 +985,458 @@ func (sm *SessionManager) updateSessionStatus(sessionID uuid.UUID, status qkd.Se
 			now := time.Now()
 			session.CompletedAt = &now
 		}
+
+		if err := sm.store.SaveSession(session); err != nil {
+			sm.logger.Error("failed to save session", "session_id", sessionID, "error", err)
+		}
+
+		sm.publishStatus(sessionID, status, message)
 	}
 }
 
-// GetSession retrieves a session by ID
+// GetSession returns a copy of a session taken under the manager's lock, safe to
+// read while an exchange on the session is still running. Changes to the copy
+// are not stored.
 func (sm *SessionManager) GetSession(sessionID uuid.UUID) (*qkd.QKDSession, error) {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	session, exists := sm.sessions[sessionID]
-	if !exists {
-		return nil, qkd.ErrSessionNotFound
+	session, err := sm.store.GetSession(sessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	return session, nil
+	return snapshotSession(session), nil
+}
+
+// snapshotSession copies a session so it can be read after sm.mutex is released
+// while the stored session is updated
+func snapshotSession(session *qkd.QKDSession) *qkd.QKDSession {
+	snapshot := *session
+	snapshot.ParticipantIDs = slices.Clone(session.ParticipantIDs)
+	if session.CompletedAt != nil {
+		completedAt := *session.CompletedAt
+		snapshot.CompletedAt = &completedAt
+	}
+	return &snapshot
+}
+
+// SessionFilter selects sessions in ListSessions. Zero-valued fields match every session.
+type SessionFilter struct {
+	Status qkd.SessionStatus
+	UserID string // Alice or Bob
 }
 
-// GetKey retrieves a generated key by ID
+// ListSessions returns copies of the sessions matching filter, newest first
+func (sm *SessionManager) ListSessions(filter SessionFilter) ([]*qkd.QKDSession, error) {
+	userID := sm.normalizeID(filter.UserID)
+
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	all, err := sm.store.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*qkd.QKDSession, 0, len(all))
+	for _, session := range all {
+		if filter.Status != "" && session.Status != filter.Status {
+			continue
+		}
+		if userID != "" && !slices.Contains(sessionParticipants(session), userID) {
+			continue
+		}
+		sessions = append(sessions, snapshotSession(session))
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+		}
+		return sessions[i].SessionID.String() < sessions[j].SessionID.String()
+	})
+
+	return sessions, nil
+}
+
+// GetKey retrieves a copy of a generated key by ID. The copy does not change
+// when the stored key is consumed or revoked; its material is the caller's to zeroize.
+// A key retired by rotation, or with bytes consumed for one-time-pad use, is
+// refused, so its material cannot be reused; GetKeyInfo still describes it.
 func (sm *SessionManager) GetKey(keyID uuid.UUID, userID string) (*qkd.QuantumKey, error) {
+	userID = sm.normalizeID(userID)
+	if userID == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	key, exists := sm.keys[keyID]
-	if !exists {
-		return nil, qkd.ErrKeyNotFound
+	key, err := sm.store.GetKey(keyID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Verify authorization (user must be Alice or Bob)
-	session, exists := sm.sessions[key.SessionID]
-	if !exists {
-		return nil, qkd.ErrSessionNotFound
+	if err := sm.checkKeyAccess(key, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := checkKeyUsable(key); err != nil {
+		return nil, err
 	}
 
-	if session.AliceID != userID && session.BobID != userID {
+	return snapshotKey(key), nil
+}
+
+// GetKeyInfo returns a copy of a key's metadata, without its material, whether
+// or not the key is still usable
+func (sm *SessionManager) GetKeyInfo(keyID uuid.UUID, userID string) (*qkd.QuantumKey, error) {
+	userID = sm.normalizeID(userID)
+	if userID == "" {
 		return nil, qkd.ErrUnauthorized
 	}
 
-	// Check if key has expired
-	if time.Now().After(key.ExpiresAt) {
-		key.IsActive = false
-		return nil, qkd.ErrKeyExpired
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	key, err := sm.store.GetKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := sm.checkKeyAccess(key, userID, time.Now()); err != nil {
+		return nil, err
 	}
 
-	return key, nil
+	info := *key
+	info.KeyMaterial = nil
+	info.RetrievedBy = slices.Clone(key.RetrievedBy)
+	return &info, nil
+}
+
+// snapshotKey copies a key, including its material, so it can be read after
+// sm.mutex is released while the stored key is mutated
+func snapshotKey(key *qkd.QuantumKey) *qkd.QuantumKey {
+	snapshot := *key
+	snapshot.KeyMaterial = slices.Clone(key.KeyMaterial)
+	snapshot.RetrievedBy = slices.Clone(key.RetrievedBy)
+	return &snapshot
 }
 
-// RevokeKey marks a key as inactive
-func (sm *SessionManager) RevokeKey(keyID uuid.UUID) error {
+// checkKeyAccess verifies that the authorizer grants userID access to the key's
+// session and that the key has not expired. Must be called with sm.mutex held; it
+// does not modify the key, so a read lock is enough.
+func (sm *SessionManager) checkKeyAccess(key *qkd.QuantumKey, userID string, now time.Time) error {
+	session, err := sm.store.GetSession(key.SessionID)
+	if err != nil {
+		return err
+	}
+
+	if !sm.authorizer.CanAccessKey(session, userID) {
+		return qkd.ErrUnauthorized
+	}
+
+	if now.After(key.ExpiresAt) {
+		return qkd.ErrKeyExpired
+	}
+
+	return nil
+}
+
+// checkKeyUsable refuses a key whose whole material must no longer be handed out:
+// one with bytes already consumed for one-time-pad use, one delivered to SAEs
+// through the ETSI API, or one retired by rotation
+func checkKeyUsable(key *qkd.QuantumKey) error {
+	if key.ConsumedBytes > 0 {
+		return qkd.ErrKeyConsumed
+	}
+	if key.ETSIMasterSAE != "" {
+		return qkd.ErrKeyDeliveredETSI
+	}
+	if !key.IsActive {
+		return qkd.ErrKeyInactive
+	}
+	return nil
+}
+
+// RetrieveKey returns a key for delivery of its material. Each participant may
+// retrieve the material once, so the secret is not re-sent on every request;
+// later calls fail with ErrKeyAlreadyRetrieved. GetKey reads the key without
+// counting as a retrieval. Like GetKey, it returns a copy.
+func (sm *SessionManager) RetrieveKey(keyID uuid.UUID, userID string) (*qkd.QuantumKey, error) {
+	return sm.retrieveKey(keyID, userID, false)
+}
+
+// RetrieveKeyForDerivation returns a key to derive symmetric keys from. It counts
+// as the participant's retrieval, so the raw material can no longer be fetched
+// with RetrieveKey and the key is not offered over ETSI, but repeated derivations
+// are allowed: the caller only ever receives one-way functions of material it is
+// entitled to.
+func (sm *SessionManager) RetrieveKeyForDerivation(keyID uuid.UUID, userID string) (*qkd.QuantumKey, error) {
+	return sm.retrieveKey(keyID, userID, true)
+}
+
+// retrieveKey records userID in the key's RetrievedBy list and returns a copy.
+// A repeat retrieval fails with ErrKeyAlreadyRetrieved unless allowRepeat is set.
+func (sm *SessionManager) retrieveKey(keyID uuid.UUID, userID string, allowRepeat bool) (*qkd.QuantumKey, error) {
+	userID = sm.normalizeID(userID)
+	if userID == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	key, exists := sm.keys[keyID]
-	if !exists {
-		return qkd.ErrKeyNotFound
+	key, err := sm.store.GetKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := sm.checkKeyAccess(key, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := checkKeyUsable(key); err != nil {
+		return nil, err
+	}
+	if slices.Contains(key.RetrievedBy, userID) {
+		if !allowRepeat {
+			return nil, qkd.ErrKeyAlreadyRetrieved
+		}
+		return snapshotKey(key), nil
+	}
+
+	key.RetrievedBy = append(key.RetrievedBy, userID)
+	if err := sm.store.SaveKey(key); err != nil {
+		return nil, err
 	}
 
-	key.IsActive = false
+	return snapshotKey(key), nil
+}
+
+// GetKeyByLabel retrieves the most recent active key carrying an application-supplied
+// label. Labels are scoped per participant, so the same label may name different
+// keys for different users.
+func (sm *SessionManager) GetKeyByLabel(label string, userID string) (*qkd.QuantumKey, error) {
+	keyID, err := sm.labeledKeyID(label, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetKey re-checks authorization and expiry
+	return sm.GetKey(keyID, userID)
+}
+
+// HasRetrievedKey reports whether userID has retrieved the key's material
+func (sm *SessionManager) HasRetrievedKey(key *qkd.QuantumKey, userID string) bool {
+	return slices.Contains(key.RetrievedBy, sm.normalizeID(userID))
+}
+
+// RetrieveKeyByLabel is RetrieveKey for the key GetKeyByLabel resolves
+func (sm *SessionManager) RetrieveKeyByLabel(label string, userID string) (*qkd.QuantumKey, error) {
+	keyID, err := sm.labeledKeyID(label, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sm.RetrieveKey(keyID, userID)
+}
+
+// labeledKeyID returns the ID of the most recent active key carrying a participant's label
+func (sm *SessionManager) labeledKeyID(label string, userID string) (uuid.UUID, error) {
+	userID = sm.normalizeID(userID)
+
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	sessionID, exists := sm.labels[labelIndexKey(userID, label)]
+	now := time.Now()
+	var latest *qkd.QuantumKey
+	if exists {
+		keys, err := sm.store.KeysForSession(sessionID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		for _, key := range keys {
+			if key.IsActive && key.ETSIMasterSAE == "" && now.Before(key.ExpiresAt) && (latest == nil || key.GeneratedAt.After(latest.GeneratedAt)) {
+				latest = key
+			}
+		}
+	}
+
+	if latest == nil {
+		return uuid.Nil, qkd.ErrKeyNotFound
+	}
+
+	return latest.KeyID, nil
+}
+
+// labelIndexKey builds the index key for a participant's label
+func labelIndexKey(userID, label string) string {
+	return userID + "\x00" + label
+}
+
+// ConsumeKey returns the next numBytes unused bytes of a key for one-time-pad use
+// and advances the key's consumed offset past them, so no byte is handed out
+// twice. The key is marked inactive once every byte has been consumed. The
+// returned key is a copy taken after the offset moved.
+func (sm *SessionManager) ConsumeKey(keyID uuid.UUID, userID string, numBytes int) ([]byte, *qkd.QuantumKey, error) {
+	if numBytes < 1 {
+		return nil, nil, qkd.ErrInvalidConsumeLength
+	}
+	return sm.consumeKey(keyID, userID, numBytes)
+}
+
+// DownloadKey consumes every unused byte of a key at once, for a participant
+// saving the key as a one-time-pad file. The key is left fully consumed, so a
+// second download fails with ErrKeyExhausted.
+func (sm *SessionManager) DownloadKey(keyID uuid.UUID, userID string) ([]byte, *qkd.QuantumKey, error) {
+	return sm.consumeKey(keyID, userID, 0)
+}
+
+// consumeKey hands out the next numBytes unused bytes of a key, or all of them
+// when numBytes is 0
+func (sm *SessionManager) consumeKey(keyID uuid.UUID, userID string, numBytes int) ([]byte, *qkd.QuantumKey, error) {
+	userID = sm.normalizeID(userID)
+	if userID == "" {
+		return nil, nil, qkd.ErrUnauthorized
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	key, err := sm.store.GetKey(keyID)
+	if err != nil {
+		return nil, nil, err
+	}
 	now := time.Now()
+	if err := sm.checkKeyAccess(key, userID, now); err != nil {
+		return nil, nil, err
+	}
+	remaining := len(key.KeyMaterial) - key.ConsumedBytes
+	// Keys retired by rotation keep their material for nobody; used-up keys are exhausted
+	if !key.IsActive && remaining > 0 {
+		return nil, nil, qkd.ErrKeyInactive
+	}
+	if numBytes == 0 {
+		numBytes = remaining
+	}
+	// A key delivered through the ETSI API has already been handed out whole
+	if key.ETSIMasterSAE != "" || remaining == 0 || numBytes > remaining {
+		return nil, nil, qkd.ErrKeyExhausted
+	}
+
+	material := make([]byte, numBytes)
+	copy(material, key.KeyMaterial[key.ConsumedBytes:])
+	key.ConsumedBytes += numBytes
 	key.UsedAt = &now
+	if key.ConsumedBytes == len(key.KeyMaterial) {
+		key.IsActive = false
+	}
+	if err := sm.store.SaveKey(key); err != nil {
+		return nil, nil, err
+	}
 
-	return nil
+	return material, snapshotKey(key), nil
+}
+
+// RevokeKey revokes a key, securely deleting its material from the store. Only
+// callers the authorizer lets access the key may revoke it; expired keys can
+// still be revoked.
+func (sm *SessionManager) RevokeKey(keyID uuid.UUID, userID string) error {
+	userID = sm.normalizeID(userID)
+	if userID == "" {
+		return qkd.ErrUnauthorized
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	key, err := sm.store.GetKey(keyID)
+	if err != nil {
+		return err
+	}
+
+	session, err := sm.store.GetSession(key.SessionID)
+	if err != nil {
+		return err
+	}
+	if !sm.authorizer.CanAccessKey(session, userID) {
+		return qkd.ErrUnauthorized
+	}
+
+	return sm.store.SecureDelete(keyID)
+}
+
+// RotateKey replaces a key with a fresh one from a new post-processed exchange on
+// the key's session, so participants can re-key without negotiating a new session.
+// The session must have completed and not yet expired. Once the new key is stored
+// the old key is retired: GetKey, RetrieveKey and derivation refuse it with
+// ErrKeyInactive, and only its metadata remains available.
+func (sm *SessionManager) RotateKey(ctx context.Context, oldKeyID uuid.UUID, userID string) (*qkd.QuantumKey, error) {
+	userID = sm.normalizeID(userID)
+	if userID == "" {
+		return nil, qkd.ErrUnauthorized
+	}
+
+	release, err := sm.acquireExchangeSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	run, err := sm.startRotation(oldKeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := sm.runPostProcessing(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	// The old key may have been revoked while the exchange ran
+	if old, err := sm.store.GetKey(oldKeyID); err == nil && old.IsActive {
+		old.IsActive = false
+		if err := sm.store.SaveKey(old); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// startRotation checks that userID may rotate a key and marks its session
+// initiating for the replacement exchange
+func (sm *SessionManager) startRotation(keyID uuid.UUID, userID string) (*postProcessingRun, error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	key, err := sm.store.GetKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := sm.checkKeyAccess(key, userID, now); err != nil {
+		return nil, err
+	}
+	// A key already rotated away has a successor; rotating it again would fork it
+	if !key.IsActive {
+		return nil, qkd.ErrKeyInactive
+	}
+
+	session, err := sm.store.GetSession(key.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if now.After(session.ExpiresAt) {
+		return nil, qkd.ErrSessionExpired
+	}
+	if session.Status != qkd.SessionCompleted {
+		return nil, qkd.ErrSessionNotActive
+	}
+
+	return sm.initiatePostProcessing(session)
 }
 
 // CleanupExpiredSessions removes expired sessions and keys
@@ -355,19 +1448,37 @@ func (sm *SessionManager) CleanupExpiredSessions() int {
 	removed := 0
 
 	// Cleanup expired sessions
-	for id, session := range sm.sessions {
-		if now.After(session.ExpiresAt) {
-			delete(sm.sessions, id)
-			removed++
+	sessions, err := sm.store.DeleteExpiredSessions(now)
+	if err != nil {
+		sm.logger.Error("failed to delete expired sessions", "error", err)
+	}
+	for _, session := range sessions {
+		id := session.SessionID
+		delete(sm.externalBases, id)
+		delete(sm.joinTokens, id)
+		delete(sm.disclosures, id)
+		delete(sm.sessionMetrics, id)
+		delete(sm.transcripts, id)
+		delete(sm.jobs, id)
+		if session.Label != "" {
+			for _, participant := range sessionParticipants(session) {
+				delete(sm.labels, labelIndexKey(participant, session.Label))
+			}
 		}
+		removed++
 	}
 
-	// Cleanup expired keys
-	for id, key := range sm.keys {
-		if now.After(key.ExpiresAt) {
-			delete(sm.keys, id)
-			removed++
+	// Securely delete expired keys
+	expired, err := sm.store.ExpiredKeys(now)
+	if err != nil {
+		sm.logger.Error("failed to list expired keys", "error", err)
+	}
+	for _, id := range expired {
+		if err := sm.store.SecureDelete(id); err != nil {
+			sm.logger.Error("failed to securely delete key", "key_id", id, "error", err)
+			continue
 		}
+		removed++
 	}
 
 	return removed
@@ -0,0 +1,1050 @@
+package qkd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// generateTestKey runs a noiseless exchange between aliceID and bobID and returns the stored key
+func generateTestKey(t *testing.T, sm *SessionManager, aliceID, bobID string) *qkd.QuantumKey {
+	t.Helper()
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{
+		AliceID:   aliceID,
+		KeyLength: 128,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, bobID, session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	key, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchange failed: %v", err)
+	}
+
+	return key
+}
+
+func TestIDNormalizationEnabled(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetIDNormalization(DefaultIDNormalization())
+
+	key := generateTestKey(t, sm, "  Alice@Example.com ", "BOB@example.com")
+
+	for _, userID := range []string{"alice@example.com", "ALICE@EXAMPLE.COM", "\tAlice@Example.com\n", " bob@Example.COM"} {
+		if _, err := sm.GetKey(key.KeyID, userID); err != nil {
+			t.Errorf("GetKey(%q) failed: %v", userID, err)
+		}
+	}
+
+	session, _ := sm.GetSession(key.SessionID)
+	if session.AliceID != "alice@example.com" {
+		t.Errorf("Expected stored Alice ID to be normalized, got %q", session.AliceID)
+	}
+}
+
+func TestIDNormalizationUnicodeNFC(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetIDNormalization(IDNormalization{UnicodeNFC: true})
+
+	// "é" precomposed (U+00E9) versus "e" + combining acute accent (U+0301)
+	key := generateTestKey(t, sm, "rené", "bob")
+
+	if _, err := sm.GetKey(key.KeyID, "rené"); err != nil {
+		t.Errorf("Expected decomposed ID to match precomposed ID: %v", err)
+	}
+}
+
+func TestIDNormalizationDisabled(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+
+	key := generateTestKey(t, sm, "Alice@Example.com", "bob")
+
+	if _, err := sm.GetKey(key.KeyID, "Alice@Example.com"); err != nil {
+		t.Fatalf("GetKey with exact ID failed: %v", err)
+	}
+
+	for _, userID := range []string{"alice@example.com", " Alice@Example.com"} {
+		if _, err := sm.GetKey(key.KeyID, userID); err != qkd.ErrUnauthorized {
+			t.Errorf("GetKey(%q): expected ErrUnauthorized, got %v", userID, err)
+		}
+	}
+}
+
+func TestIDNormalizationRejectsBlankIDs(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetIDNormalization(DefaultIDNormalization())
+
+	if _, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "   ", KeyLength: 128}); err != qkd.ErrInvalidAliceID {
+		t.Errorf("Expected ErrInvalidAliceID for blank Alice ID, got %v", err)
+	}
+
+	if _, err := sm.JoinSession(uuid.New(), " \t ", ""); err != qkd.ErrInvalidBobID {
+		t.Errorf("Expected ErrInvalidBobID for blank Bob ID, got %v", err)
+	}
+}
+
+func TestGetSessionReturnsCopy(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	created, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+
+	session, _ := sm.GetSession(created.SessionID)
+	session.Status = qkd.SessionCompleted
+	listed, _ := sm.ListSessions(SessionFilter{})
+	listed[0].AliceID = "mallory"
+
+	stored, _ := sm.GetSession(created.SessionID)
+	if stored.Status != qkd.SessionWaitingForBob || stored.AliceID != "alice" {
+		t.Errorf("Expected changes to returned sessions not to reach the store, got %s for %s", stored.Status, stored.AliceID)
+	}
+}
+
+func TestExecuteKeyExchangeRejectsFinishedSessions(t *testing.T) {
+	tests := []struct {
+		status   qkd.SessionStatus
+		expected error
+	}{
+		{qkd.SessionCompleted, qkd.ErrSessionAlreadyCompleted},
+		{qkd.SessionAborted, qkd.ErrSessionTerminated},
+		{qkd.SessionFailed, qkd.ErrSessionTerminated},
+		{qkd.SessionWaitingForBob, qkd.ErrSessionNotActive},
+	}
+
+	for _, tt := range tests {
+		sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+		created, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+		session, _ := sm.GetSession(created.SessionID)
+		session.Status = tt.status
+		if err := sm.store.SaveSession(session); err != nil {
+			t.Fatalf("SaveSession failed: %v", err)
+		}
+
+		if _, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID); err != tt.expected {
+			t.Errorf("ExecuteKeyExchange on %s session: expected %v, got %v", tt.status, tt.expected, err)
+		}
+
+		if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); err != tt.expected {
+			t.Errorf("ExecuteKeyExchangeWithPostProcessing on %s session: expected %v, got %v", tt.status, tt.expected, err)
+		}
+	}
+}
+
+func TestExecuteKeyExchangeTwice(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	key := generateTestKey(t, sm, "alice", "bob")
+
+	if _, err := sm.ExecuteKeyExchange(context.Background(), key.SessionID); err != qkd.ErrSessionAlreadyCompleted {
+		t.Errorf("Expected ErrSessionAlreadyCompleted on re-execution, got %v", err)
+	}
+}
+
+func TestJoinTokenSingleUse(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+
+	if session.JoinToken == "" {
+		t.Fatal("Expected CreateSession to return a join token")
+	}
+
+	stored, _ := sm.GetSession(session.SessionID)
+	if stored.JoinToken != "" {
+		t.Error("Join token must not be retrievable after creation")
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, "bob", "wrong-token"); err != qkd.ErrInvalidJoinToken {
+		t.Errorf("Expected ErrInvalidJoinToken for a wrong token, got %v", err)
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, "bob", ""); err != qkd.ErrInvalidJoinToken {
+		t.Errorf("Expected ErrInvalidJoinToken without a token, got %v", err)
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession with a valid token failed: %v", err)
+	}
+
+	if _, err := sm.JoinSession(session.SessionID, "mallory", session.JoinToken); err != qkd.ErrInvalidJoinToken {
+		t.Errorf("Expected a reused token to be rejected, got %v", err)
+	}
+}
+
+func TestJoinTokenExpired(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetJoinTokenTTL(time.Millisecond)
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != qkd.ErrJoinTokenExpired {
+		t.Errorf("Expected ErrJoinTokenExpired, got %v", err)
+	}
+}
+
+func TestJoinTokenBoundToSession(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	first, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	second, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+
+	if _, err := sm.JoinSession(second.SessionID, "bob", first.JoinToken); err != qkd.ErrInvalidJoinToken {
+		t.Errorf("Expected another session's token to be rejected, got %v", err)
+	}
+}
+
+func TestRequirePostProcessingPolicy(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetRequirePostProcessing(true)
+
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	if _, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID); err != qkd.ErrPostProcessingRequired {
+		t.Fatalf("Expected ErrPostProcessingRequired, got %v", err)
+	}
+
+	// The session is left untouched for the post-processing path
+	stored, _ := sm.GetSession(session.SessionID)
+	if stored.Status != qkd.SessionActive {
+		t.Errorf("Expected session to remain active, got %s", stored.Status)
+	}
+
+	if keys, _ := sm.store.KeysForSession(session.SessionID); len(keys) != 0 {
+		t.Errorf("Expected no keys to be stored, got %d", len(keys))
+	}
+}
+
+func TestRequirePostProcessingDisabled(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetRequirePostProcessing(false)
+
+	key := generateTestKey(t, sm, "alice", "bob")
+	if _, err := sm.GetKey(key.KeyID, "alice"); err != nil {
+		t.Errorf("Expected basic key to be retrievable with the policy off, got %v", err)
+	}
+}
+
+func TestExecuteKeyExchangeWithPostProcessing(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	key, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchangeWithPostProcessing failed: %v", err)
+	}
+	if key.KeyLength != 256 || len(key.KeyMaterial) != 32 {
+		t.Errorf("Expected a 256-bit key, got %d bits and %d bytes", key.KeyLength, len(key.KeyMaterial))
+	}
+
+	metrics, err := sm.GetSessionMetrics(session.SessionID)
+	if err != nil {
+		t.Fatalf("GetSessionMetrics failed: %v", err)
+	}
+	if metrics.TotalQubits > 16*256 {
+		t.Errorf("Expected the budget to send fewer qubits than the old fixed factor, sent %d", metrics.TotalQubits)
+	}
+}
+
+func TestExecuteBatchKeyExchange(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	keyIDs, err := sm.ExecuteBatchKeyExchange(context.Background(), session.SessionID, 4)
+	if err != nil {
+		t.Fatalf("ExecuteBatchKeyExchange failed: %v", err)
+	}
+	if len(keyIDs) != 4 {
+		t.Fatalf("Expected 4 key IDs, got %d", len(keyIDs))
+	}
+
+	seenIDs := make(map[uuid.UUID]bool)
+	seenMaterial := make(map[string]bool)
+	for _, keyID := range keyIDs {
+		key, err := sm.GetKey(keyID, "bob")
+		if err != nil {
+			t.Fatalf("GetKey(%s) failed: %v", keyID, err)
+		}
+		if key.SessionID != session.SessionID || key.KeyLength != 128 {
+			t.Errorf("Key %s: expected a 128-bit key of the session, got %d bits of %s", keyID, key.KeyLength, key.SessionID)
+		}
+		if seenIDs[keyID] || seenMaterial[string(key.KeyMaterial)] {
+			t.Errorf("Key %s repeats an earlier key", keyID)
+		}
+		seenIDs[keyID] = true
+		seenMaterial[string(key.KeyMaterial)] = true
+	}
+
+	session, _ = sm.GetSession(session.SessionID)
+	if session.Status != qkd.SessionCompleted {
+		t.Errorf("Expected completed session after the batch, got %s", session.Status)
+	}
+	if _, err := sm.ExecuteBatchKeyExchange(context.Background(), session.SessionID, 2); err != qkd.ErrSessionAlreadyCompleted {
+		t.Errorf("Expected ErrSessionAlreadyCompleted for a second batch, got %v", err)
+	}
+}
+
+func TestBatchCompletesSessionOnce(t *testing.T) {
+	var callbacks atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callbacks.Add(1)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]byte("secret"))
+	notifier.SetAllowPrivateNetworks(true)
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetWebhookNotifier(notifier)
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, CallbackURL: server.URL})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	sub, err := sm.SubscribeEvents(session.SessionID)
+	if err != nil {
+		t.Fatalf("SubscribeEvents failed: %v", err)
+	}
+	defer sub.Close()
+
+	// Slow subscribers may miss events, but none may follow a terminal status:
+	// progress streams close on the first one
+	events := make(chan []SessionEvent, 1)
+	go func() {
+		var received []SessionEvent
+		for event := range sub.Events {
+			received = append(received, event)
+		}
+		events <- received
+	}()
+
+	if _, err := sm.ExecuteBatchKeyExchange(context.Background(), session.SessionID, 3); err != nil {
+		t.Fatalf("ExecuteBatchKeyExchange failed: %v", err)
+	}
+	sub.Close()
+
+	received := <-events
+	for i, event := range received[:max(len(received)-1, 0)] {
+		if event.Type == EventStatus && event.Status.IsTerminal() {
+			t.Fatalf("Expected no terminal status before the batch finished, got %s at event %d of %d", event.Status, i+1, len(received))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sm.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if n := callbacks.Load(); n != 1 {
+		t.Errorf("Expected a single callback for the batch, got %d", n)
+	}
+}
+
+func TestExecuteBatchKeyExchangeRejectsInvalidCount(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	for _, count := range []int{0, -1, qkd.MaxBatchKeys + 1} {
+		if _, err := sm.ExecuteBatchKeyExchange(context.Background(), session.SessionID, count); err != qkd.ErrInvalidBatchCount {
+			t.Errorf("count %d: expected ErrInvalidBatchCount, got %v", count, err)
+		}
+	}
+
+	session, _ = sm.GetSession(session.SessionID)
+	if session.Status != qkd.SessionActive {
+		t.Errorf("Expected a rejected batch to leave the session active, got %s", session.Status)
+	}
+}
+
+func TestOversamplingFactorOnLossyChannel(t *testing.T) {
+	run := func(factor int) error {
+		// 80% photon loss leaves too few detections for the default factor
+		backend := quantum.NewSimulatorBackend(false, 0.0)
+		backend.SetLossRate(0.8)
+		backend.SetTargetQBER(0.03)
+		sm := NewSessionManager(backend)
+		sm.SetOversamplingFactor(factor)
+
+		session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+		sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+		_, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+		return err
+	}
+
+	if err := run(0); err == nil {
+		t.Fatal("Expected the noise-based budget to fall short on a lossy channel")
+	}
+	if err := run(64); err != nil {
+		t.Fatalf("Expected a 256-bit key with 64x oversampling, got %v", err)
+	}
+}
+
+func TestOversamplingFactorSetsTransmissionLength(t *testing.T) {
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 256)
+	if got := bb84.TransmissionLength(); got != 256*DefaultOversamplingFactor {
+		t.Errorf("Expected %d qubits by default, got %d", 256*DefaultOversamplingFactor, got)
+	}
+
+	bb84.SetOversamplingFactor(10)
+	bb84.SetOversamplingFactor(0) // Ignored
+	if got := bb84.TransmissionLength(); got != 2560 {
+		t.Errorf("Expected 2560 qubits with 10x oversampling, got %d", got)
+	}
+}
+
+func TestMaxRawQubitsCap(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetMaxRawQubits(8192)
+
+	// A 4096-bit key needs well over 8192 qubits on either path
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 4096})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); !errors.Is(err, qkd.ErrOversamplingTooLarge) {
+		t.Fatalf("Expected ErrOversamplingTooLarge, got %v", err)
+	}
+	if _, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID); !errors.Is(err, qkd.ErrOversamplingTooLarge) {
+		t.Fatalf("Expected ErrOversamplingTooLarge on the basic path, got %v", err)
+	}
+
+	// Rejected before any generation, so the session can still run once the cap allows it
+	stored, _ := sm.GetSession(session.SessionID)
+	if stored.Status != qkd.SessionActive {
+		t.Errorf("Expected session to remain active, got %s", stored.Status)
+	}
+
+	// A reasonable request proceeds
+	generateTestKey(t, sm, "alice", "bob")
+}
+
+func TestMaxRawQubitsBoundary(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(false, 0.0)
+
+	// The transmission the budget plans for a 1024-bit key on this channel
+	planned := NewBB84Protocol(backend, 1024)
+	planned.BudgetPostProcessing(PostProcessingBudget{SecurityParameter: DefaultSecurityParameter, MaxQubits: DefaultMaxRawQubits})
+	required := planned.TransmissionLength()
+
+	run := func(maxQubits int) error {
+		sm := NewSessionManager(backend)
+		sm.SetMaxRawQubits(maxQubits)
+		session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 1024})
+		sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+		_, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+		return err
+	}
+
+	if err := run(required); err != nil {
+		t.Fatalf("Expected an exchange of exactly %d qubits to be allowed, got %v", required, err)
+	}
+
+	err := run(required - 1)
+	var limitErr *RawQubitLimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, qkd.ErrOversamplingTooLarge) {
+		t.Fatalf("Expected a RawQubitLimitError one qubit below the requirement, got %v", err)
+	}
+	if limitErr.Max != required-1 || limitErr.KeyLength != 1024 {
+		t.Errorf("Expected the error to report the 1024-bit key and the cap of %d, got %+v", required-1, limitErr)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, strconv.Itoa(required-1)) || !strings.Contains(msg, "request a shorter key") {
+		t.Errorf("Expected the message to state the cap and how to fix the request, got %q", msg)
+	}
+}
+
+func TestSessionEavesdropperSimulation(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 1024, InterceptProbability: 1.0})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	if _, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID); err == nil {
+		t.Fatal("Expected the eavesdropped exchange to be rejected")
+	}
+
+	stored, _ := sm.GetSession(session.SessionID)
+	if stored.IsSecure || stored.QBER <= 0.11 {
+		t.Errorf("Expected an insecure session with QBER above threshold, got secure=%v QBER=%.2f%%", stored.IsSecure, stored.QBER*100)
+	}
+
+	// The shared backend is untouched, so other sessions still succeed
+	generateTestKey(t, sm, "alice", "bob")
+}
+
+func TestSessionEavesdropperRequiresSimulator(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+
+	if _, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256, InterceptProbability: 0.5}); err != qkd.ErrEavesdropperUnsupported {
+		t.Errorf("Expected ErrEavesdropperUnsupported, got %v", err)
+	}
+
+	if _, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256, InterceptProbability: 1.5}); err != qkd.ErrInvalidInterceptProbability {
+		t.Errorf("Expected ErrInvalidInterceptProbability, got %v", err)
+	}
+}
+
+// blockingBackend stands in for a hardware backend waiting on a queued job:
+// PrepareAndSend blocks until its context is cancelled
+type blockingBackend struct {
+	*quantum.SimulatorBackend
+	started chan struct{}
+}
+
+func (b *blockingBackend) PrepareAndSend(ctx context.Context, bits []quantum.Bit, bases []quantum.Basis) ([]quantum.Qubit, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestKeyExchangeCancellation(t *testing.T) {
+	backend := &blockingBackend{
+		SimulatorBackend: quantum.NewSimulatorBackend(false, 0.0),
+		started:          make(chan struct{}),
+	}
+	sm := NewSessionManager(backend)
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := sm.ExecuteKeyExchangeWithPostProcessing(ctx, session.SessionID)
+		done <- err
+	}()
+
+	<-backend.started
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the exchange to abort promptly after cancellation")
+	}
+
+	stored, err := sm.GetSession(session.SessionID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if stored.Status != qkd.SessionFailed {
+		t.Errorf("Expected a cancelled exchange to fail the session, got %s", stored.Status)
+	}
+}
+
+func TestSimulatorHonorsCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(true, 0.05), 4096)
+	if _, err := bb84.PerformKeyExchange(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from a cancelled exchange, got %v", err)
+	}
+
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	if _, err := sm.ExecuteKeyExchange(ctx, session.SessionID); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from ExecuteKeyExchange, got %v", err)
+	}
+}
+
+// captureStage records the pipeline context it sees and optionally fails the run
+type captureStage struct {
+	pc       *PipelineContext
+	finalKey []byte
+	err      error
+}
+
+func (s *captureStage) Name() string { return "capture" }
+
+func (s *captureStage) Process(pc *PipelineContext) error {
+	s.pc = pc
+	pc.AliceKey = pc.Sifted.AliceKey
+	pc.BobKey = pc.Sifted.BobKey
+	pc.FinalKey = s.finalKey
+	return s.err
+}
+
+// assertZeroBits fails if any bit in b is set
+func assertZeroBits(t *testing.T, name string, b []quantum.Bit) {
+	t.Helper()
+	if len(b) == 0 {
+		t.Fatalf("%s: expected a non-empty buffer", name)
+	}
+	for i, bit := range b {
+		if bit != quantum.Zero {
+			t.Fatalf("%s: bit %d was not zeroed", name, i)
+		}
+	}
+}
+
+// runCapturedExchange runs a post-processed exchange through stage and returns the result
+func runCapturedExchange(t *testing.T, stage *captureStage) (*qkd.QuantumKey, error) {
+	t.Helper()
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	sm.SetPipeline(NewPipeline(&SiftStage{}, stage))
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	return sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+}
+
+func TestFailedExchangeZeroizesKeyMaterial(t *testing.T) {
+	stage := &captureStage{finalKey: []byte{0xde, 0xad, 0xbe, 0xef}, err: errors.New("aborted")}
+	if _, err := runCapturedExchange(t, stage); err == nil {
+		t.Fatal("Expected the exchange to fail")
+	}
+
+	pc := stage.pc
+	assertZeroBits(t, "alice bits", pc.Alice.Bits)
+	assertZeroBits(t, "sifted alice key", pc.Sifted.AliceKey)
+	assertZeroBits(t, "sifted bob key", pc.Sifted.BobKey)
+	for i, b := range stage.finalKey {
+		if b != 0 {
+			t.Fatalf("final key byte %d was not zeroed", i)
+		}
+	}
+}
+
+func TestSuccessfulExchangeKeepsOnlyFinalKey(t *testing.T) {
+	stage := &captureStage{finalKey: []byte{0xde, 0xad, 0xbe, 0xef}}
+	key, err := runCapturedExchange(t, stage)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+
+	pc := stage.pc
+	assertZeroBits(t, "alice bits", pc.Alice.Bits)
+	assertZeroBits(t, "sifted alice key", pc.Sifted.AliceKey)
+	assertZeroBits(t, "sifted bob key", pc.Sifted.BobKey)
+	if key.KeyMaterial[0] != 0xde {
+		t.Error("Stored key material should survive a successful exchange")
+	}
+}
+
+func TestRevokeKeyZeroizesMaterial(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	key := generateTestKey(t, sm, "alice", "bob")
+	material := key.KeyMaterial
+
+	if err := sm.RevokeKey(key.KeyID, "alice"); err != nil {
+		t.Fatalf("RevokeKey failed: %v", err)
+	}
+	for i, b := range material {
+		if b != 0 {
+			t.Fatalf("key byte %d was not zeroed after revocation", i)
+		}
+	}
+}
+
+func TestRevokeKeyRequiresParticipant(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	key := generateTestKey(t, sm, "alice", "bob")
+
+	if err := sm.RevokeKey(key.KeyID, ""); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized without a caller, got %v", err)
+	}
+	if err := sm.RevokeKey(key.KeyID, "mallory"); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for a non-participant, got %v", err)
+	}
+	if _, err := sm.GetKey(key.KeyID, "alice"); err != nil {
+		t.Fatalf("Expected the key to survive refused revocations, got %v", err)
+	}
+	if err := sm.RevokeKey(key.KeyID, "bob"); err != nil {
+		t.Errorf("Expected a participant to revoke the key, got %v", err)
+	}
+}
+
+func TestConsumeKeyWalksThroughKey(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	key := generateTestKey(t, sm, "alice", "bob")
+	original := append([]byte(nil), key.KeyMaterial...)
+
+	var consumed []byte
+	for _, userID := range []string{"alice", "bob", "alice"} {
+		chunk, _, err := sm.ConsumeKey(key.KeyID, userID, 4)
+		if err != nil {
+			t.Fatalf("ConsumeKey as %s failed: %v", userID, err)
+		}
+		consumed = append(consumed, chunk...)
+	}
+	if !bytes.Equal(consumed, original[:12]) {
+		t.Errorf("Expected consecutive chunks of the key, got %x want %x", consumed, original[:12])
+	}
+
+	rest, stored, err := sm.ConsumeKey(key.KeyID, "bob", len(original)-12)
+	if err != nil {
+		t.Fatalf("ConsumeKey of the remainder failed: %v", err)
+	}
+	if !bytes.Equal(rest, original[12:]) {
+		t.Error("Expected the remainder to follow the consumed bytes")
+	}
+	if stored.ConsumedBytes != len(original) || stored.IsActive || stored.UsedAt == nil {
+		t.Errorf("Expected the key to be fully used, got consumed=%d active=%v", stored.ConsumedBytes, stored.IsActive)
+	}
+
+	if _, _, err := sm.ConsumeKey(key.KeyID, "alice", 1); err != qkd.ErrKeyExhausted {
+		t.Errorf("Expected ErrKeyExhausted once the key is used up, got %v", err)
+	}
+}
+
+func TestConsumeKeyRejectsOverConsumption(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	key := generateTestKey(t, sm, "alice", "bob")
+	size := len(key.KeyMaterial)
+
+	if _, _, err := sm.ConsumeKey(key.KeyID, "alice", size+1); err != qkd.ErrKeyExhausted {
+		t.Errorf("Expected ErrKeyExhausted, got %v", err)
+	}
+	if _, _, err := sm.ConsumeKey(key.KeyID, "alice", 0); err != qkd.ErrInvalidConsumeLength {
+		t.Errorf("Expected ErrInvalidConsumeLength, got %v", err)
+	}
+	if _, _, err := sm.ConsumeKey(key.KeyID, "mallory", 1); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for a third party, got %v", err)
+	}
+
+	// Failed requests must not advance the offset
+	chunk, _, err := sm.ConsumeKey(key.KeyID, "alice", size)
+	if err != nil {
+		t.Fatalf("ConsumeKey of the whole key failed: %v", err)
+	}
+	if len(chunk) != size {
+		t.Errorf("Expected %d bytes, got %d", size, len(chunk))
+	}
+}
+
+func TestConsumedKeyMaterialIsNotServedAgain(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	partial := generateTestKey(t, sm, "alice", "bob")
+	downloaded := generateTestKey(t, sm, "alice", "bob")
+
+	if _, _, err := sm.ConsumeKey(partial.KeyID, "alice", 1); err != nil {
+		t.Fatalf("ConsumeKey failed: %v", err)
+	}
+	if _, _, err := sm.DownloadKey(downloaded.KeyID, "bob"); err != nil {
+		t.Fatalf("DownloadKey failed: %v", err)
+	}
+
+	for name, keyID := range map[string]uuid.UUID{"partly consumed": partial.KeyID, "downloaded": downloaded.KeyID} {
+		if _, err := sm.GetKey(keyID, "alice"); err != qkd.ErrKeyConsumed {
+			t.Errorf("%s: expected ErrKeyConsumed from GetKey, got %v", name, err)
+		}
+		if _, err := sm.RetrieveKey(keyID, "bob"); err != qkd.ErrKeyConsumed {
+			t.Errorf("%s: expected ErrKeyConsumed from RetrieveKey, got %v", name, err)
+		}
+		if info, err := sm.GetKeyInfo(keyID, "alice"); err != nil || info.ConsumedBytes == 0 {
+			t.Errorf("%s: expected metadata showing consumed bytes, got %+v (%v)", name, info, err)
+		}
+	}
+}
+
+func TestDownloadKeyTakesUnusedBytes(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	key := generateTestKey(t, sm, "alice", "bob")
+	original := append([]byte(nil), key.KeyMaterial...)
+
+	if _, _, err := sm.ConsumeKey(key.KeyID, "alice", 4); err != nil {
+		t.Fatalf("ConsumeKey failed: %v", err)
+	}
+
+	// Bytes already handed out are never part of the download
+	material, stored, err := sm.DownloadKey(key.KeyID, "bob")
+	if err != nil {
+		t.Fatalf("DownloadKey failed: %v", err)
+	}
+	if !bytes.Equal(material, original[4:]) {
+		t.Errorf("Expected the unused bytes %x, got %x", original[4:], material)
+	}
+	if stored.ConsumedBytes != len(original) || stored.IsActive {
+		t.Errorf("Expected the key to be fully used, got consumed=%d active=%v", stored.ConsumedBytes, stored.IsActive)
+	}
+
+	if _, _, err := sm.DownloadKey(key.KeyID, "alice"); err != qkd.ErrKeyExhausted {
+		t.Errorf("Expected ErrKeyExhausted for a second download, got %v", err)
+	}
+	if _, _, err := sm.DownloadKey(key.KeyID, "mallory"); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for a third party, got %v", err)
+	}
+}
+
+func TestRotateKeyReplacesKey(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	old := generateTestKey(t, sm, "alice", "bob")
+
+	key, err := sm.RotateKey(context.Background(), old.KeyID, "bob")
+	if err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	if key.KeyID == old.KeyID || key.SessionID != old.SessionID || !key.IsActive {
+		t.Errorf("Expected a new active key on session %s, got %+v", old.SessionID, key)
+	}
+
+	stored, err := sm.GetKeyInfo(old.KeyID, "alice")
+	if err != nil {
+		t.Fatalf("GetKeyInfo of the old key failed: %v", err)
+	}
+	if stored.IsActive || stored.KeyMaterial != nil {
+		t.Error("Expected the old key to be inactive after rotation, without material")
+	}
+
+	// The retired key's material is no longer served
+	if _, err := sm.GetKey(old.KeyID, "alice"); err != qkd.ErrKeyInactive {
+		t.Errorf("Expected ErrKeyInactive from GetKey of the old key, got %v", err)
+	}
+	if _, err := sm.RetrieveKey(old.KeyID, "bob"); err != qkd.ErrKeyInactive {
+		t.Errorf("Expected ErrKeyInactive from RetrieveKey of the old key, got %v", err)
+	}
+	if _, err := sm.RetrieveKey(key.KeyID, "alice"); err != nil {
+		t.Errorf("RetrieveKey of the new key failed: %v", err)
+	}
+
+	// Nor consumed or downloaded as a one-time pad, or rotated a second time
+	if _, _, err := sm.ConsumeKey(old.KeyID, "alice", 1); err != qkd.ErrKeyInactive {
+		t.Errorf("Expected ErrKeyInactive from ConsumeKey of the old key, got %v", err)
+	}
+	if material, _, err := sm.DownloadKey(old.KeyID, "bob"); err != qkd.ErrKeyInactive || material != nil {
+		t.Errorf("Expected ErrKeyInactive from DownloadKey of the old key, got %d bytes and %v", len(material), err)
+	}
+	if _, err := sm.RotateKey(context.Background(), old.KeyID, "alice"); err != qkd.ErrKeyInactive {
+		t.Errorf("Expected ErrKeyInactive rotating the old key again, got %v", err)
+	}
+	if _, err := sm.RotateKey(context.Background(), key.KeyID, "alice"); err != nil {
+		t.Errorf("Expected the new key to be rotatable, got %v", err)
+	}
+}
+
+func TestRotateKeyRequiresParticipant(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	old := generateTestKey(t, sm, "alice", "bob")
+
+	if _, err := sm.RotateKey(context.Background(), old.KeyID, "mallory"); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for a third party, got %v", err)
+	}
+	if stored, _ := sm.GetKey(old.KeyID, "alice"); !stored.IsActive {
+		t.Error("Expected a refused rotation to leave the key active")
+	}
+}
+
+func TestRotateKeyOnExpiredSession(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	old := generateTestKey(t, sm, "alice", "bob")
+
+	sm.mutex.Lock()
+	session, _ := sm.store.GetSession(old.SessionID)
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+	sm.store.SaveSession(session)
+	sm.mutex.Unlock()
+
+	if _, err := sm.RotateKey(context.Background(), old.KeyID, "alice"); err != qkd.ErrSessionExpired {
+		t.Errorf("Expected ErrSessionExpired, got %v", err)
+	}
+}
+
+func TestRetrieveKeyOncePerParticipant(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	key := generateTestKey(t, sm, "alice", "bob")
+
+	if _, err := sm.RetrieveKey(key.KeyID, "mallory"); err != qkd.ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized for a third party, got %v", err)
+	}
+	for _, userID := range []string{"alice", "bob"} {
+		if _, err := sm.RetrieveKey(key.KeyID, userID); err != nil {
+			t.Fatalf("First RetrieveKey as %s failed: %v", userID, err)
+		}
+		if _, err := sm.RetrieveKey(key.KeyID, userID); err != qkd.ErrKeyAlreadyRetrieved {
+			t.Errorf("Expected ErrKeyAlreadyRetrieved for %s, got %v", userID, err)
+		}
+	}
+
+	// Metadata reads are not retrievals
+	if _, err := sm.GetKey(key.KeyID, "alice"); err != nil {
+		t.Errorf("GetKey after retrieval failed: %v", err)
+	}
+}
+
+// TestConcurrentKeyAccess is meant for the race detector (go test -race): reads,
+// consumption, revocation and cleanup of the same keys must not race
+func TestConcurrentKeyAccess(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+
+	var ids []uuid.UUID
+	for i := 0; i < 4; i++ {
+		key := generateTestKey(t, sm, "alice", "bob")
+		if i%2 == 1 {
+			key.ExpiresAt = time.Now().Add(-time.Minute) // Left for cleanup
+		}
+		ids = append(ids, key.KeyID)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			for _, id := range ids {
+				if key, err := sm.GetKey(id, "alice"); err == nil {
+					_ = key.IsActive
+					crypto.Zeroize(key.KeyMaterial)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for _, id := range ids {
+				sm.ConsumeKey(id, "bob", 1)
+				sm.RetrieveKey(id, "bob")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			sm.RevokeKey(ids[i%len(ids)], "alice")
+		}(i)
+		go func() {
+			defer wg.Done()
+			sm.CleanupExpiredSessions()
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		if _, err := sm.GetKey(id, "alice"); err != qkd.ErrKeyNotFound {
+			t.Errorf("Expected key %s to be revoked or cleaned up, got %v", id, err)
+		}
+	}
+}
+
+func TestKeyTTLDefaultsTo24Hours(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	key := generateTestKey(t, sm, "alice", "bob")
+
+	if ttl := key.ExpiresAt.Sub(key.GeneratedAt); ttl != 24*time.Hour {
+		t.Errorf("Expected the default key TTL to be 24h, got %v", ttl)
+	}
+}
+
+func TestShortKeyTTLExpiresKey(t *testing.T) {
+	sm := NewSessionManager(quantum.NewIdealBackend())
+	if err := sm.SetKeyTTL(time.Second); err != qkd.ErrInvalidKeyTTL {
+		t.Errorf("Expected a TTL below MinKeyTTL to be rejected, got %v", err)
+	}
+	if err := sm.SetKeyTTL(MaxKeyTTL + time.Minute); err != qkd.ErrInvalidKeyTTL {
+		t.Errorf("Expected a TTL above MaxKeyTTL to be rejected, got %v", err)
+	}
+	if err := sm.SetKeyTTL(2 * time.Hour); err != nil {
+		t.Fatalf("SetKeyTTL failed: %v", err)
+	}
+
+	// A per-session TTL overrides the manager's
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, KeyTTLMinutes: 1})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+	key, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchange failed: %v", err)
+	}
+	if ttl := key.ExpiresAt.Sub(key.GeneratedAt); ttl != time.Minute {
+		t.Fatalf("Expected a 1 minute key TTL, got %v", ttl)
+	}
+	if _, err := sm.GetKey(key.KeyID, "alice"); err != nil {
+		t.Fatalf("GetKey within the TTL failed: %v", err)
+	}
+
+	// Let the window pass
+	key.GeneratedAt = key.GeneratedAt.Add(-time.Minute)
+	key.ExpiresAt = key.ExpiresAt.Add(-time.Minute)
+	if _, err := sm.GetKey(key.KeyID, "alice"); err != qkd.ErrKeyExpired {
+		t.Errorf("Expected ErrKeyExpired after the TTL, got %v", err)
+	}
+
+	other := generateTestKey(t, sm, "carol", "dave")
+	if ttl := other.ExpiresAt.Sub(other.GeneratedAt); ttl != 2*time.Hour {
+		t.Errorf("Expected the manager's 2h key TTL, got %v", ttl)
+	}
+
+	if _, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 128, KeyTTLMinutes: 10081}); err != qkd.ErrInvalidKeyTTL {
+		t.Errorf("Expected ErrInvalidKeyTTL for a key TTL over 7 days, got %v", err)
+	}
+}
+
+func TestSetErrorCorrection(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(true, 0.03))
+	if err := sm.SetErrorCorrection("hamming"); err == nil {
+		t.Fatal("Expected an unknown correction method to be rejected")
+	}
+	if err := sm.SetErrorCorrection(crypto.WinnowMethod); err != nil {
+		t.Fatalf("SetErrorCorrection failed: %v", err)
+	}
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 512})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+		t.Fatalf("JoinSession failed: %v", err)
+	}
+
+	key, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("Winnow exchange failed: %v", err)
+	}
+	if key.KeyLength != 512 {
+		t.Errorf("Expected a 512-bit key, got %d", key.KeyLength)
+	}
+}
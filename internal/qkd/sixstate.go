@@ -0,0 +1,33 @@
+package qkd
+
+import (
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// SixStateQBERThreshold is the QBER up to which the six-state protocol still
+// yields a secure key. Eve's disturbance is visible in all three bases, so she
+// learns less per error than in BB84 and the threshold rises from 11% to ~12.6%.
+const SixStateQBERThreshold = 0.126
+
+// SixStateProtocol implements the six-state variant of BB84. Alice and Bob each
+// pick one of three mutually unbiased bases (rectilinear, diagonal, circular), so
+// only about a third of the measurements survive sifting.
+type SixStateProtocol struct {
+	*BB84Protocol
+}
+
+// NewSixStateProtocol creates a new six-state protocol instance. Its default
+// oversampling factor is raised by half over BB84's to make up for the lower sift rate.
+func NewSixStateProtocol(backend quantum.QuantumBackend, keyLength int) *SixStateProtocol {
+	bb := NewBB84Protocol(backend, keyLength)
+	bb.sixState = true
+	bb.qberThreshold = SixStateQBERThreshold
+	bb.oversampling = DefaultOversamplingFactor * 3 / 2
+
+	return &SixStateProtocol{BB84Protocol: bb}
+}
+
+// Name returns the protocol identifier
+func (s *SixStateProtocol) Name() string {
+	return "six-state"
+}
@@ -0,0 +1,97 @@
+package qkd
+
+import (
+	"context"
+	"math"
+	mrand "math/rand"
+	"strings"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestSixStateSiftEfficiency(t *testing.T) {
+	sixState := NewSixStateProtocol(quantum.NewSimulatorBackend(false, 0.0), 1024)
+
+	result, err := sixState.PerformKeyExchange(context.Background())
+	if err != nil {
+		t.Fatalf("PerformKeyExchange failed: %v", err)
+	}
+
+	if math.Abs(result.SiftingEfficiency-1.0/3.0) > 0.02 {
+		t.Errorf("Expected ~33%% sift rate, got %.3f", result.SiftingEfficiency)
+	}
+	if !result.Secure || result.QBER != 0 || result.FinalKeyLength != 1024 {
+		t.Fatalf("Expected a secure, error-free 1024-bit key, got %+v", result)
+	}
+}
+
+func TestSixStateUsesAllThreeBases(t *testing.T) {
+	sixState := NewSixStateProtocol(quantum.NewIdealBackend(), 512)
+
+	alice, err := sixState.AliceGenerateQubits(context.Background())
+	if err != nil {
+		t.Fatalf("AliceGenerateQubits failed: %v", err)
+	}
+	bob, err := sixState.BobMeasureQubits(context.Background(), alice.Qubits)
+	if err != nil {
+		t.Fatalf("BobMeasureQubits failed: %v", err)
+	}
+
+	counts := make(map[quantum.Basis]int)
+	for _, basis := range alice.Bases {
+		counts[basis]++
+	}
+	for _, basis := range []quantum.Basis{quantum.RectilinearBasis, quantum.DiagonalBasis, quantum.CircularBasis} {
+		if fraction := float64(counts[basis]) / float64(len(alice.Bases)); math.Abs(fraction-1.0/3.0) > 0.03 {
+			t.Errorf("Expected basis %v about a third of the time, got %.3f", basis, fraction)
+		}
+	}
+
+	// Measuring in the preparation basis is deterministic on an ideal channel
+	for i, measurement := range bob.Measurements {
+		if bob.Bases[i] == alice.Bases[i] && measurement.MeasuredBit != alice.Bits[i] {
+			t.Fatalf("Qubit %d in basis %v measured %d, want %d", i, alice.Bases[i], measurement.MeasuredBit, alice.Bits[i])
+		}
+	}
+}
+
+func TestSixStateHigherQBERThreshold(t *testing.T) {
+	// A sifted key with exactly 11.8% errors, between BB84's and six-state's thresholds.
+	// It is long enough that the 10% sample stays within 5σ of the true rate.
+	const length, qber = 400000, 0.118
+
+	sifted := &SiftedKey{
+		AliceKey:  make([]quantum.Bit, length),
+		BobKey:    make([]quantum.Bit, length),
+		Indices:   make([]int, length),
+		RawLength: 3 * length,
+	}
+	for i := range sifted.Indices {
+		sifted.Indices[i] = i
+	}
+	for _, i := range mrand.New(mrand.NewSource(1)).Perm(length)[:int(length*qber)] {
+		sifted.BobKey[i] = quantum.One
+	}
+
+	bb84 := NewBB84Protocol(quantum.NewSimulatorBackend(false, 0.0), 1024)
+	result, err := bb84.finalizeKey(sifted, &KeyExchangeResult{})
+	if err != nil {
+		t.Fatalf("BB84 finalizeKey failed: %v", err)
+	}
+	if result.Secure || !strings.Contains(result.Message, "INSECURE") {
+		t.Errorf("Expected BB84 to reject a QBER of %.3f, got %+v", result.QBER, result)
+	}
+
+	sixState := NewSixStateProtocol(quantum.NewSimulatorBackend(false, 0.0), 1024)
+	if sixState.QBERThreshold() != SixStateQBERThreshold {
+		t.Fatalf("Expected threshold %.3f, got %.3f", SixStateQBERThreshold, sixState.QBERThreshold())
+	}
+	result, err = sixState.finalizeKey(sifted, &KeyExchangeResult{})
+	if err != nil {
+		t.Fatalf("six-state finalizeKey failed: %v", err)
+	}
+	if strings.Contains(result.Message, "INSECURE") {
+		t.Errorf("Expected six-state to accept a QBER of %.3f, got %s", result.QBER, result.Message)
+	}
+}
@@ -0,0 +1,201 @@
+package qkd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+)
+
+// Store persists sessions and generated keys. Implementations must make key material
+// unrecoverable on SecureDelete rather than merely marking the record inactive.
+// Sessions returned by GetSession may be copies, so callers must SaveSession after
+// mutating them.
+type Store interface {
+	// SaveSession creates or replaces a session record
+	SaveSession(session *qkd.QKDSession) error
+
+	// GetSession returns a session by ID, or qkd.ErrSessionNotFound
+	GetSession(sessionID uuid.UUID) (*qkd.QKDSession, error)
+
+	// ListSessions returns every stored session
+	ListSessions() ([]*qkd.QKDSession, error)
+
+	// DeleteExpiredSessions removes sessions that expired before now and returns them
+	DeleteExpiredSessions(now time.Time) ([]*qkd.QKDSession, error)
+
+	// SaveKey stores a new key
+	SaveKey(key *qkd.QuantumKey) error
+
+	// GetKey returns a key by ID, or qkd.ErrKeyNotFound
+	GetKey(keyID uuid.UUID) (*qkd.QuantumKey, error)
+
+	// KeysForSession returns every stored key generated in a session
+	KeysForSession(sessionID uuid.UUID) ([]*qkd.QuantumKey, error)
+
+	// ExpiredKeys returns the IDs of keys that expired before now
+	ExpiredKeys(now time.Time) ([]uuid.UUID, error)
+
+	// SecureDelete overwrites a key's material and removes its record
+	SecureDelete(keyID uuid.UUID) error
+}
+
+// MemoryStore is an in-process Store. It hands out the stored pointers, so
+// mutations are visible before SaveSession is called.
+type MemoryStore struct {
+	mutex    sync.RWMutex
+	sessions map[uuid.UUID]*qkd.QKDSession
+	keys     map[uuid.UUID]*qkd.QuantumKey
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[uuid.UUID]*qkd.QKDSession),
+		keys:     make(map[uuid.UUID]*qkd.QuantumKey),
+	}
+}
+
+// SaveSession creates or replaces a session record
+func (ms *MemoryStore) SaveSession(session *qkd.QKDSession) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.sessions[session.SessionID] = session
+	return nil
+}
+
+// GetSession returns a session by ID
+func (ms *MemoryStore) GetSession(sessionID uuid.UUID) (*qkd.QKDSession, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	session, exists := ms.sessions[sessionID]
+	if !exists {
+		return nil, qkd.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// ListSessions returns every stored session
+func (ms *MemoryStore) ListSessions() ([]*qkd.QKDSession, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	sessions := make([]*qkd.QKDSession, 0, len(ms.sessions))
+	for _, session := range ms.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// DeleteExpiredSessions removes sessions that expired before now and returns them
+func (ms *MemoryStore) DeleteExpiredSessions(now time.Time) ([]*qkd.QKDSession, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	var expired []*qkd.QKDSession
+	for id, session := range ms.sessions {
+		if now.After(session.ExpiresAt) {
+			expired = append(expired, session)
+			delete(ms.sessions, id)
+		}
+	}
+	return expired, nil
+}
+
+// SaveKey stores a new key
+func (ms *MemoryStore) SaveKey(key *qkd.QuantumKey) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.keys[key.KeyID] = key
+	return nil
+}
+
+// GetKey returns a key by ID
+func (ms *MemoryStore) GetKey(keyID uuid.UUID) (*qkd.QuantumKey, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	key, exists := ms.keys[keyID]
+	if !exists {
+		return nil, qkd.ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// KeysForSession returns every stored key generated in a session
+func (ms *MemoryStore) KeysForSession(sessionID uuid.UUID) ([]*qkd.QuantumKey, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	var keys []*qkd.QuantumKey
+	for _, key := range ms.keys {
+		if key.SessionID == sessionID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// ExpiredKeys returns the IDs of keys that expired before now
+func (ms *MemoryStore) ExpiredKeys(now time.Time) ([]uuid.UUID, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	var expired []uuid.UUID
+	for id, key := range ms.keys {
+		if now.After(key.ExpiresAt) {
+			expired = append(expired, id)
+		}
+	}
+	return expired, nil
+}
+
+// SecureDelete zeroes the key material in place and removes the record.
+// Any outstanding references to the key observe the zeroed material.
+func (ms *MemoryStore) SecureDelete(keyID uuid.UUID) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	key, exists := ms.keys[keyID]
+	if !exists {
+		return qkd.ErrKeyNotFound
+	}
+
+	crypto.Zeroize(key.KeyMaterial)
+	key.KeyMaterial = nil
+	key.IsActive = false
+	delete(ms.keys, keyID)
+
+	return nil
+}
+
+// SetStore replaces the store used for sessions and generated keys. Records in
+// the previous store are not migrated, so call it before creating sessions. The
+// label index is rebuilt from the sessions already in the store, so labels stay
+// unique and resolvable across restarts.
+func (sm *SessionManager) SetStore(store Store) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.store = store
+	sm.labels = make(map[string]uuid.UUID)
+
+	sessions, err := store.ListSessions()
+	if err != nil {
+		sm.logger.Error("failed to rebuild the label index", "error", err)
+		return
+	}
+	for _, session := range sessions {
+		if session.Label == "" {
+			continue
+		}
+		for _, participant := range sessionParticipants(session) {
+			sm.labels[labelIndexKey(participant, session.Label)] = session.SessionID
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package qkd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// fakeStore wraps a MemoryStore and records the raw bytes it "persisted",
+// standing in for an on-disk store
+type fakeStore struct {
+	*MemoryStore
+	persisted map[uuid.UUID][]byte
+	deleted   []uuid.UUID
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		MemoryStore: NewMemoryStore(),
+		persisted:   make(map[uuid.UUID][]byte),
+	}
+}
+
+func (fs *fakeStore) SaveKey(key *qkd.QuantumKey) error {
+	fs.persisted[key.KeyID] = append([]byte(nil), key.KeyMaterial...)
+	return fs.MemoryStore.SaveKey(key)
+}
+
+func (fs *fakeStore) SecureDelete(keyID uuid.UUID) error {
+	if record, exists := fs.persisted[keyID]; exists {
+		clear(record) // Overwrite before unlink
+		delete(fs.persisted, keyID)
+	}
+	fs.deleted = append(fs.deleted, keyID)
+	return fs.MemoryStore.SecureDelete(keyID)
+}
+
+// allZero reports whether every byte of b is zero
+func allZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMemoryStoreSecureDelete(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	key := generateTestKey(t, sm, "alice", "bob")
+
+	material := key.KeyMaterial
+	if allZero(material) {
+		t.Fatal("Expected non-zero key material before deletion")
+	}
+
+	if err := sm.RevokeKey(key.KeyID, "alice"); err != nil {
+		t.Fatalf("RevokeKey failed: %v", err)
+	}
+
+	if !allZero(material) {
+		t.Error("Expected key material to be overwritten after revocation")
+	}
+
+	if _, err := sm.GetKey(key.KeyID, "alice"); err != qkd.ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound after revocation, got %v", err)
+	}
+
+	if err := sm.RevokeKey(key.KeyID, "alice"); err != qkd.ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound when revoking twice, got %v", err)
+	}
+}
+
+func TestCleanupSecurelyDeletesExpiredKeys(t *testing.T) {
+	store := newFakeStore()
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetStore(store)
+
+	expired := generateTestKey(t, sm, "alice", "bob")
+	live := generateTestKey(t, sm, "alice", "bob")
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	record := store.persisted[expired.KeyID]
+	material := expired.KeyMaterial
+
+	sm.CleanupExpiredSessions()
+
+	if len(store.deleted) != 1 || store.deleted[0] != expired.KeyID {
+		t.Fatalf("Expected only the expired key to be securely deleted, got %v", store.deleted)
+	}
+
+	if !allZero(record) || !allZero(material) {
+		t.Error("Expected the expired key's material to be unrecoverable")
+	}
+
+	if _, exists := store.persisted[expired.KeyID]; exists {
+		t.Error("Expected the expired key's record to be removed")
+	}
+
+	if _, err := sm.GetKey(live.KeyID, "alice"); err != nil {
+		t.Errorf("Expected the live key to survive cleanup, got %v", err)
+	}
+}
+
+func TestRevokeUsesStoreSecureDelete(t *testing.T) {
+	store := newFakeStore()
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetStore(store)
+
+	key := generateTestKey(t, sm, "alice", "bob")
+	record := store.persisted[key.KeyID]
+
+	if err := sm.RevokeKey(key.KeyID, "alice"); err != nil {
+		t.Fatalf("RevokeKey failed: %v", err)
+	}
+
+	if len(store.deleted) != 1 || !allZero(record) {
+		t.Error("Expected revocation to overwrite and remove the stored record")
+	}
+}
+
+// testStore exercises the behavior every Store implementation must share
+func testStore(t *testing.T, newStore func(t *testing.T) Store) {
+	now := time.Now()
+
+	newSession := func(expiresAt time.Time) *qkd.QKDSession {
+		return &qkd.QKDSession{
+			SessionID: uuid.New(),
+			AliceID:   "alice",
+			Status:    qkd.SessionWaitingForBob,
+			KeyLength: 256,
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	newKey := func(sessionID uuid.UUID, expiresAt time.Time) *qkd.QuantumKey {
+		return &qkd.QuantumKey{
+			KeyID:       uuid.New(),
+			SessionID:   sessionID,
+			KeyMaterial: []byte{0xde, 0xad, 0xbe, 0xef},
+			KeyLength:   32,
+			GeneratedAt: now,
+			ExpiresAt:   expiresAt,
+			IsActive:    true,
+		}
+	}
+
+	t.Run("SessionRoundTrip", func(t *testing.T) {
+		store := newStore(t)
+		session := newSession(now.Add(time.Hour))
+
+		if err := store.SaveSession(session); err != nil {
+			t.Fatalf("SaveSession failed: %v", err)
+		}
+
+		session.BobID = "bob"
+		session.Status = qkd.SessionActive
+		if err := store.SaveSession(session); err != nil {
+			t.Fatalf("SaveSession update failed: %v", err)
+		}
+
+		got, err := store.GetSession(session.SessionID)
+		if err != nil {
+			t.Fatalf("GetSession failed: %v", err)
+		}
+		if got.BobID != "bob" || got.Status != qkd.SessionActive || got.KeyLength != 256 {
+			t.Errorf("Expected the updated session, got %+v", got)
+		}
+
+		if _, err := store.GetSession(uuid.New()); err != qkd.ErrSessionNotFound {
+			t.Errorf("Expected ErrSessionNotFound, got %v", err)
+		}
+
+		sessions, err := store.ListSessions()
+		if err != nil {
+			t.Fatalf("ListSessions failed: %v", err)
+		}
+		if len(sessions) != 1 || sessions[0].SessionID != session.SessionID {
+			t.Errorf("Expected ListSessions to return the saved session once, got %v", sessions)
+		}
+	})
+
+	t.Run("DeleteExpiredSessions", func(t *testing.T) {
+		store := newStore(t)
+		expired := newSession(now.Add(-time.Minute))
+		live := newSession(now.Add(time.Hour))
+		for _, session := range []*qkd.QKDSession{expired, live} {
+			if err := store.SaveSession(session); err != nil {
+				t.Fatalf("SaveSession failed: %v", err)
+			}
+		}
+
+		removed, err := store.DeleteExpiredSessions(now)
+		if err != nil {
+			t.Fatalf("DeleteExpiredSessions failed: %v", err)
+		}
+		if len(removed) != 1 || removed[0].SessionID != expired.SessionID {
+			t.Fatalf("Expected only the expired session to be removed, got %v", removed)
+		}
+
+		if _, err := store.GetSession(expired.SessionID); err != qkd.ErrSessionNotFound {
+			t.Errorf("Expected the expired session to be gone, got %v", err)
+		}
+		if _, err := store.GetSession(live.SessionID); err != nil {
+			t.Errorf("Expected the live session to survive, got %v", err)
+		}
+	})
+
+	t.Run("KeyRoundTrip", func(t *testing.T) {
+		store := newStore(t)
+		sessionID := uuid.New()
+		key := newKey(sessionID, now.Add(time.Hour))
+		other := newKey(uuid.New(), now.Add(time.Hour))
+		for _, k := range []*qkd.QuantumKey{key, other} {
+			if err := store.SaveKey(k); err != nil {
+				t.Fatalf("SaveKey failed: %v", err)
+			}
+		}
+
+		got, err := store.GetKey(key.KeyID)
+		if err != nil {
+			t.Fatalf("GetKey failed: %v", err)
+		}
+		if !bytes.Equal(got.KeyMaterial, []byte{0xde, 0xad, 0xbe, 0xef}) || got.SessionID != sessionID {
+			t.Errorf("Expected the stored key, got %+v", got)
+		}
+
+		keys, err := store.KeysForSession(sessionID)
+		if err != nil {
+			t.Fatalf("KeysForSession failed: %v", err)
+		}
+		if len(keys) != 1 || keys[0].KeyID != key.KeyID {
+			t.Errorf("Expected only the session's key, got %v", keys)
+		}
+
+		if _, err := store.GetKey(uuid.New()); err != qkd.ErrKeyNotFound {
+			t.Errorf("Expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ExpiredKeysAndSecureDelete", func(t *testing.T) {
+		store := newStore(t)
+		expired := newKey(uuid.New(), now.Add(-time.Minute))
+		live := newKey(uuid.New(), now.Add(time.Hour))
+		for _, k := range []*qkd.QuantumKey{expired, live} {
+			if err := store.SaveKey(k); err != nil {
+				t.Fatalf("SaveKey failed: %v", err)
+			}
+		}
+
+		ids, err := store.ExpiredKeys(now)
+		if err != nil {
+			t.Fatalf("ExpiredKeys failed: %v", err)
+		}
+		if len(ids) != 1 || ids[0] != expired.KeyID {
+			t.Fatalf("Expected only the expired key, got %v", ids)
+		}
+
+		if err := store.SecureDelete(expired.KeyID); err != nil {
+			t.Fatalf("SecureDelete failed: %v", err)
+		}
+		if _, err := store.GetKey(expired.KeyID); err != qkd.ErrKeyNotFound {
+			t.Errorf("Expected ErrKeyNotFound after deletion, got %v", err)
+		}
+		if err := store.SecureDelete(expired.KeyID); err != qkd.ErrKeyNotFound {
+			t.Errorf("Expected ErrKeyNotFound when deleting twice, got %v", err)
+		}
+		if _, err := store.GetKey(live.KeyID); err != nil {
+			t.Errorf("Expected the live key to survive, got %v", err)
+		}
+	})
+
+	t.Run("SessionManager", func(t *testing.T) {
+		sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+		sm.SetStore(newStore(t))
+
+		key := generateTestKey(t, sm, "alice", "bob")
+
+		session, err := sm.GetSession(key.SessionID)
+		if err != nil {
+			t.Fatalf("GetSession failed: %v", err)
+		}
+		if session.Status != qkd.SessionCompleted || session.BobID != "bob" {
+			t.Errorf("Expected a completed session joined by bob, got %+v", session)
+		}
+
+		got, err := sm.GetKey(key.KeyID, "bob")
+		if err != nil {
+			t.Fatalf("GetKey failed: %v", err)
+		}
+		if !bytes.Equal(got.KeyMaterial, key.KeyMaterial) {
+			t.Error("Expected the retrieved key material to match the generated key")
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		return NewMemoryStore()
+	})
+}
+
+func TestBoltStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		return newTestBoltStore(t, filepath.Join(t.TempDir(), "qkd.db"), testStoreKey())
+	})
+}
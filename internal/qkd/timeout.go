@@ -0,0 +1,56 @@
+package qkd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ExchangeTimeoutError reports an exchange aborted by the manager's exchange
+// timeout. It matches context.DeadlineExceeded.
+type ExchangeTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *ExchangeTimeoutError) Error() string {
+	return fmt.Sprintf("key exchange timed out after %v", e.Timeout)
+}
+
+// Is reports whether target is context.DeadlineExceeded
+func (e *ExchangeTimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// SetExchangeTimeout bounds how long a single key exchange may run, independent
+// of the HTTP server's timeouts and of any deadline on the caller's context. An
+// exchange still running at the timeout is aborted and its session marked failed.
+// d <= 0 removes the limit, which is the default.
+func (sm *SessionManager) SetExchangeTimeout(d time.Duration) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.exchangeTimeout = max(d, 0)
+}
+
+// exchangeContext derives the context one exchange runs under
+func (sm *SessionManager) exchangeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	sm.mutex.RLock()
+	timeout := sm.exchangeTimeout
+	sm.mutex.RUnlock()
+
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, &ExchangeTimeoutError{Timeout: timeout})
+}
+
+// timeoutCause replaces a deadline error caused by the exchange timeout with the
+// timeout error, so the session records why the exchange stopped
+func timeoutCause(ctx context.Context, err error) error {
+	var timeout *ExchangeTimeoutError
+	if errors.Is(err, context.DeadlineExceeded) && errors.As(context.Cause(ctx), &timeout) {
+		return timeout
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package qkd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestExchangeTimeoutAbortsSlowExchange(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	for _, tt := range []struct {
+		name    string
+		execute func(*SessionManager, context.Context, *qkd.QKDSession) error
+	}{
+		{"basic", func(sm *SessionManager, ctx context.Context, s *qkd.QKDSession) error {
+			_, err := sm.ExecuteKeyExchange(ctx, s.SessionID)
+			return err
+		}},
+		{"post-processed", func(sm *SessionManager, ctx context.Context, s *qkd.QKDSession) error {
+			_, err := sm.ExecuteKeyExchangeWithPostProcessing(ctx, s.SessionID)
+			return err
+		}},
+	} {
+		backend := &blockingBackend{
+			SimulatorBackend: quantum.NewSimulatorBackend(false, 0.0),
+			started:          make(chan struct{}),
+		}
+		sm := NewSessionManager(backend)
+		sm.SetMaxConcurrentExchanges(1)
+		sm.SetExchangeTimeout(timeout)
+
+		session, err := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if _, err := sm.JoinSession(session.SessionID, "bob", session.JoinToken); err != nil {
+			t.Fatalf("JoinSession failed: %v", err)
+		}
+
+		// The caller's context never expires; only the exchange timeout can stop the backend
+		start := time.Now()
+		err = tt.execute(sm, context.Background(), session)
+		if elapsed := time.Since(start); elapsed < timeout || elapsed > 10*timeout {
+			t.Errorf("%s: expected the exchange to stop at the %v timeout, took %v", tt.name, timeout, elapsed)
+		}
+
+		var timeoutErr *ExchangeTimeoutError
+		if !errors.As(err, &timeoutErr) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: expected an ExchangeTimeoutError, got %v", tt.name, err)
+		}
+
+		got, _ := sm.GetSession(session.SessionID)
+		if got.Status != qkd.SessionFailed || got.Message != timeoutErr.Error() {
+			t.Errorf("%s: expected a failed session with the timeout message, got %s %q", tt.name, got.Status, got.Message)
+		}
+
+		// The exchange released its slot
+		release, err := sm.acquireExchangeSlot()
+		if err != nil {
+			t.Errorf("%s: expected the exchange slot to be released, got %v", tt.name, err)
+		} else {
+			release()
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package qkd
+
+import (
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// newTranscript collects the public record of a pipeline run. Only bases,
+// indices, lengths and disclosure sizes are copied, never key bits. runErr is
+// the error the pipeline stopped with, if any.
+func newTranscript(sessionID uuid.UUID, pc *PipelineContext, runErr error) *qkd.Transcript {
+	transcript := &qkd.Transcript{
+		SessionID:        sessionID,
+		QBER:             pc.QBER,
+		SamplePositions:  append([]int(nil), pc.SamplePositions...),
+		Disclosures:      pc.Ledger.Entries(),
+		ReconciledLength: len(pc.AliceKey),
+		DisclosedBits:    pc.Leakage(),
+		SecureLength:     pc.SecureLength,
+		FinalKeyLength:   len(pc.FinalKey) * 8,
+	}
+	if pc.Alice != nil {
+		transcript.RawQubits = len(pc.Alice.Qubits)
+		transcript.AliceBases = quantum.EncodeBases(pc.Alice.Bases)
+	}
+	if pc.Bob != nil {
+		transcript.BobBases = quantum.EncodeBases(pc.Bob.Bases)
+	}
+	if pc.Sifted != nil {
+		transcript.SiftedIndices = append([]int(nil), pc.Sifted.Indices...)
+	}
+	if runErr != nil {
+		transcript.Error = runErr.Error()
+		transcript.FinalKeyLength = 0
+	}
+
+	return transcript
+}
+
+// recordTranscript stores the transcript of a post-processed exchange
+func (sm *SessionManager) recordTranscript(sessionID uuid.UUID, pc *PipelineContext, runErr error) {
+	transcript := newTranscript(sessionID, pc, runErr)
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.transcripts[sessionID] = transcript
+}
+
+// GetTranscript returns the public transcript of a session's latest post-processed exchange
+func (sm *SessionManager) GetTranscript(sessionID uuid.UUID) (*qkd.Transcript, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	if _, err := sm.store.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	transcript, exists := sm.transcripts[sessionID]
+	if !exists {
+		return nil, qkd.ErrTranscriptNotRecorded
+	}
+
+	return transcript, nil
+}
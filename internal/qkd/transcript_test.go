@@ -0,0 +1,86 @@
+package qkd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/crypto"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+func TestTranscriptMatchesAmplifierInput(t *testing.T) {
+	sm := NewSessionManager(quantum.NewSimulatorBackend(true, 0.03))
+
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+	if _, err := sm.GetTranscript(session.SessionID); !errors.Is(err, qkd.ErrTranscriptNotRecorded) {
+		t.Fatalf("Expected no transcript before an exchange, got %v", err)
+	}
+
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	key, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("Key exchange failed: %v", err)
+	}
+
+	transcript, err := sm.GetTranscript(session.SessionID)
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+
+	// Privacy amplification charged exactly the leakage the transcript reports
+	want := crypto.CalculateSecureKeyLength(transcript.ReconciledLength, transcript.QBER, transcript.DisclosedBits, DefaultSecurityParameter)
+	if transcript.SecureLength != want {
+		t.Errorf("Expected secure length %d from the transcript's %d disclosed bits, got %d", want, transcript.DisclosedBits, transcript.SecureLength)
+	}
+	leaked := 0
+	for _, entry := range transcript.Disclosures {
+		if entry.LeaksKey {
+			leaked += entry.Bits
+		}
+	}
+	if leaked != transcript.DisclosedBits || transcript.DisclosedBits == 0 {
+		t.Errorf("Expected the disclosed bits %d to equal the leaking disclosures %d", transcript.DisclosedBits, leaked)
+	}
+
+	if transcript.FinalKeyLength != key.KeyLength || transcript.Error != "" {
+		t.Errorf("Unexpected outcome in transcript: %+v", transcript)
+	}
+	if want := len(transcript.SiftedIndices) - len(transcript.SamplePositions); transcript.ReconciledLength != want {
+		t.Errorf("Expected %d reconciled bits after removing the sample, got %d", want, transcript.ReconciledLength)
+	}
+	for _, basesString := range []string{transcript.AliceBases, transcript.BobBases} {
+		if _, err := quantum.DecodeBases(basesString, transcript.RawQubits); err != nil {
+			t.Errorf("Expected %d encoded bases: %v", transcript.RawQubits, err)
+		}
+	}
+
+	encoded, _ := json.Marshal(transcript)
+	if strings.Contains(string(encoded), hex.EncodeToString(key.KeyMaterial)) {
+		t.Error("Transcript contains the final key")
+	}
+}
+
+func TestTranscriptRecordsAbortedExchange(t *testing.T) {
+	backend := quantum.NewSimulatorBackend(true, 0.03)
+	backend.SetTargetQBER(0.25)
+	sm := NewSessionManager(backend)
+
+	session, _ := sm.CreateSession(&qkd.SessionCreateRequest{AliceID: "alice", KeyLength: 256})
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+	if _, err := sm.ExecuteKeyExchangeWithPostProcessing(context.Background(), session.SessionID); err == nil {
+		t.Fatal("Expected the noisy exchange to abort")
+	}
+
+	transcript, err := sm.GetTranscript(session.SessionID)
+	if err != nil {
+		t.Fatalf("GetTranscript failed: %v", err)
+	}
+	if transcript.Error == "" || transcript.FinalKeyLength != 0 || len(transcript.SamplePositions) == 0 {
+		t.Errorf("Expected an aborted transcript with the QBER sample, got %+v", transcript)
+	}
+}
@@ -0,0 +1,234 @@
+package qkd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+)
+
+// Webhook request headers. The signature is an HMAC-SHA256 over the timestamp,
+// delivery ID and body, so receivers can reject stale or replayed callbacks.
+const (
+	WebhookSignatureHeader = "X-QKD-Signature"
+	WebhookTimestampHeader = "X-QKD-Timestamp"
+	WebhookDeliveryHeader  = "X-QKD-Delivery"
+)
+
+// ErrCallbackAddressBlocked is returned when a callback URL resolves to a
+// loopback, private, link-local or otherwise non-public address
+var ErrCallbackAddressBlocked = errors.New("callback address is not publicly routable")
+
+// WebhookNotifier delivers signed key-ready notifications to session callback URLs
+type WebhookNotifier struct {
+	client         *http.Client
+	secret         []byte
+	maxAttempts    int
+	initialBackoff time.Duration
+	allowPrivate   bool
+}
+
+// NewWebhookNotifier creates a notifier that signs every callback with secret.
+// Callbacks are not sent to non-public addresses and redirects are not followed.
+func NewWebhookNotifier(secret []byte) *WebhookNotifier {
+	wn := &WebhookNotifier{
+		secret:         secret,
+		maxAttempts:    5,
+		initialBackoff: 500 * time.Millisecond,
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		// Checked on the resolved address, so DNS cannot point a public name inward
+		Control: func(network, address string, c syscall.RawConn) error {
+			if wn.allowPrivate {
+				return nil
+			}
+			return checkCallbackAddress(address)
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	wn.client = &http.Client{
+		Transport: transport,
+		Timeout:   10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return wn
+}
+
+// SetHTTPClient replaces the HTTP client used for delivery. The client's own
+// dialer and redirect policy are used instead of the notifier's.
+func (wn *WebhookNotifier) SetHTTPClient(client *http.Client) {
+	wn.client = client
+}
+
+// SetAllowPrivateNetworks allows callbacks to loopback and private addresses,
+// for deployments whose receivers run on an internal network
+func (wn *WebhookNotifier) SetAllowPrivateNetworks(allow bool) {
+	wn.allowPrivate = allow
+}
+
+// SetRetryPolicy sets the number of delivery attempts and the initial backoff,
+// which doubles after each failed attempt
+func (wn *WebhookNotifier) SetRetryPolicy(maxAttempts int, initialBackoff time.Duration) {
+	if maxAttempts > 0 {
+		wn.maxAttempts = maxAttempts
+	}
+	if initialBackoff > 0 {
+		wn.initialBackoff = initialBackoff
+	}
+}
+
+// checkCallbackAddress refuses host:port addresses that are not publicly routable
+func checkCallbackAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrCallbackAddressBlocked, host)
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		cgnatPrefix.Contains(ip) {
+		return fmt.Errorf("%w: %s", ErrCallbackAddressBlocked, ip)
+	}
+	return nil
+}
+
+// cgnatPrefix is the shared address space of RFC 6598, which netip does not treat as private
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// SignPayload returns the hex-encoded HMAC-SHA256 of a callback under secret.
+// The MAC covers "<timestamp>.<deliveryID>.<body>".
+func SignPayload(secret []byte, timestamp int64, deliveryID string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d.%s.", timestamp, deliveryID)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver POSTs the payload to callbackURL, retrying with exponential backoff
+// until a 2xx response is received, the attempts are exhausted or ctx ends. Every
+// attempt carries the same delivery ID and a fresh timestamp.
+func (wn *WebhookNotifier) Deliver(ctx context.Context, callbackURL string, payload *qkd.KeyCallbackPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode callback payload: %w", err)
+	}
+
+	deliveryID := uuid.NewString()
+	backoff := wn.initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err = wn.post(ctx, callbackURL, body, deliveryID)
+		if err == nil {
+			return nil
+		}
+
+		// A blocked address will not become routable by retrying
+		if errors.Is(err, ErrCallbackAddressBlocked) {
+			return fmt.Errorf("callback delivery refused: %w", err)
+		}
+
+		if attempt >= wn.maxAttempts {
+			return fmt.Errorf("callback delivery failed after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("callback delivery abandoned after %d attempts: %w", attempt, ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// post performs a single delivery attempt
+func (wn *WebhookNotifier) post(ctx context.Context, callbackURL string, body []byte, deliveryID string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, SignPayload(wn.secret, timestamp, deliveryID, body))
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// notifyKeyReady delivers the key-ready callback for a session in the background
+func (sm *SessionManager) notifyKeyReady(key *qkd.QuantumKey) {
+	sm.mutex.RLock()
+	notifier := sm.webhooks
+	session, err := sm.store.GetSession(key.SessionID)
+	if err != nil {
+		sm.mutex.RUnlock()
+		return
+	}
+	callbackURL := session.CallbackURL
+	qber := session.QBER
+	logger := sm.logger
+	var keyHex string
+	if session.CallbackIncludeKey {
+		// The key may be revoked as soon as the lock is released
+		keyHex = hex.EncodeToString(key.KeyMaterial)
+	}
+	sm.mutex.RUnlock()
+
+	if notifier == nil || callbackURL == "" {
+		return
+	}
+
+	payload := &qkd.KeyCallbackPayload{
+		Event:       "key.ready",
+		SessionID:   key.SessionID.String(),
+		KeyID:       key.KeyID.String(),
+		KeyLength:   key.KeyLength,
+		QBER:        qber,
+		GeneratedAt: key.GeneratedAt,
+		ExpiresAt:   key.ExpiresAt,
+		KeyHex:      keyHex,
+	}
+
+	// Deliveries still retrying when Shutdown's grace period ends are abandoned
+	ctx := sm.jobsCtx
+	sm.background.Add(1)
+	go func() {
+		defer sm.background.Done()
+		if err := notifier.Deliver(ctx, callbackURL, payload); err != nil {
+			logger.Error("key callback failed", "session_id", key.SessionID, "error", err)
+		}
+	}()
+}
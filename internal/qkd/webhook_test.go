@@ -0,0 +1,264 @@
+package qkd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaskrrish/Go-OKD/internal/models/qkd"
+	"github.com/jaskrrish/Go-OKD/internal/qkd/quantum"
+)
+
+// callbackReceiver records callbacks, failing the first failures attempts with 503
+type callbackReceiver struct {
+	failures  int32
+	attempts  int32
+	delivered chan *http.Request
+	bodies    chan []byte
+}
+
+func newCallbackReceiver(failures int32) *callbackReceiver {
+	return &callbackReceiver{
+		failures:  failures,
+		delivered: make(chan *http.Request, 1),
+		bodies:    make(chan []byte, 1),
+	}
+}
+
+func (cr *callbackReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.AddInt32(&cr.attempts, 1) <= cr.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	w.WriteHeader(http.StatusOK)
+	cr.delivered <- r
+	cr.bodies <- body
+}
+
+// awaitCallback waits for a successful delivery and returns the request and body
+func (cr *callbackReceiver) awaitCallback(t *testing.T) (*http.Request, []byte) {
+	t.Helper()
+
+	select {
+	case r := <-cr.delivered:
+		return r, <-cr.bodies
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for callback")
+		return nil, nil
+	}
+}
+
+// runCallbackSession runs a noiseless exchange for a session using the given callback settings
+func runCallbackSession(t *testing.T, sm *SessionManager, callbackURL string, includeKey bool) *qkd.QuantumKey {
+	t.Helper()
+
+	session, err := sm.CreateSession(&qkd.SessionCreateRequest{
+		AliceID:            "alice",
+		KeyLength:          128,
+		CallbackURL:        callbackURL,
+		CallbackIncludeKey: includeKey,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	sm.JoinSession(session.SessionID, "bob", session.JoinToken)
+
+	key, err := sm.ExecuteKeyExchange(context.Background(), session.SessionID)
+	if err != nil {
+		t.Fatalf("ExecuteKeyExchange failed: %v", err)
+	}
+
+	return key
+}
+
+func TestWebhookCallbackDelivered(t *testing.T) {
+	receiver := newCallbackReceiver(0)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	secret := []byte("webhook-secret")
+	notifier := NewWebhookNotifier(secret)
+	notifier.SetAllowPrivateNetworks(true)
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetWebhookNotifier(notifier)
+
+	key := runCallbackSession(t, sm, server.URL, false)
+	r, body := receiver.awaitCallback(t)
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)) > time.Minute {
+		t.Errorf("Expected a current timestamp, got %q", r.Header.Get(WebhookTimestampHeader))
+	}
+	deliveryID := r.Header.Get(WebhookDeliveryHeader)
+	if _, err := uuid.Parse(deliveryID); err != nil {
+		t.Errorf("Expected a UUID delivery ID, got %q", deliveryID)
+	}
+	if got := r.Header.Get(WebhookSignatureHeader); got != SignPayload(secret, timestamp, deliveryID, body) {
+		t.Errorf("Signature mismatch: got %q", got)
+	}
+
+	// A replay under another timestamp or delivery ID no longer verifies
+	if SignPayload(secret, timestamp+300, deliveryID, body) == SignPayload(secret, timestamp, deliveryID, body) ||
+		SignPayload(secret, timestamp, uuid.NewString(), body) == SignPayload(secret, timestamp, deliveryID, body) {
+		t.Error("Expected the signature to cover the timestamp and delivery ID")
+	}
+
+	var payload qkd.KeyCallbackPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Invalid callback body: %v", err)
+	}
+
+	if payload.Event != "key.ready" || payload.KeyID != key.KeyID.String() || payload.SessionID != key.SessionID.String() {
+		t.Errorf("Unexpected callback payload: %+v", payload)
+	}
+
+	if payload.KeyHex != "" {
+		t.Error("Key material must not be sent unless requested")
+	}
+}
+
+func TestWebhookCallbackRetried(t *testing.T) {
+	receiver := newCallbackReceiver(2)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]byte("secret"))
+	notifier.SetAllowPrivateNetworks(true)
+	notifier.SetRetryPolicy(5, time.Millisecond)
+
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetWebhookNotifier(notifier)
+
+	runCallbackSession(t, sm, server.URL, false)
+	receiver.awaitCallback(t)
+
+	if attempts := atomic.LoadInt32(&receiver.attempts); attempts != 3 {
+		t.Errorf("Expected 3 delivery attempts, got %d", attempts)
+	}
+}
+
+func TestWebhookDeliverGivesUp(t *testing.T) {
+	receiver := newCallbackReceiver(100)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]byte("secret"))
+	notifier.SetAllowPrivateNetworks(true)
+	notifier.SetRetryPolicy(3, time.Millisecond)
+
+	if err := notifier.Deliver(context.Background(), server.URL, &qkd.KeyCallbackPayload{Event: "key.ready"}); err == nil {
+		t.Fatal("Expected delivery to fail")
+	}
+
+	if attempts := atomic.LoadInt32(&receiver.attempts); attempts != 3 {
+		t.Errorf("Expected 3 delivery attempts, got %d", attempts)
+	}
+}
+
+func TestWebhookIncludesKeyOverHTTPS(t *testing.T) {
+	receiver := newCallbackReceiver(0)
+	server := httptest.NewTLSServer(receiver)
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]byte("secret"))
+	notifier.SetHTTPClient(server.Client())
+
+	sm := NewSessionManager(quantum.NewSimulatorBackend(false, 0.0))
+	sm.SetWebhookNotifier(notifier)
+
+	key := runCallbackSession(t, sm, server.URL, true)
+	_, body := receiver.awaitCallback(t)
+
+	var payload qkd.KeyCallbackPayload
+	json.Unmarshal(body, &payload)
+
+	if payload.KeyHex != hex.EncodeToString(key.KeyMaterial) {
+		t.Error("Expected key material in https callback")
+	}
+}
+
+func TestWebhookRefusesPrivateAddresses(t *testing.T) {
+	receiver := newCallbackReceiver(0)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]byte("secret"))
+	notifier.SetRetryPolicy(3, time.Millisecond)
+
+	err := notifier.Deliver(context.Background(), server.URL, &qkd.KeyCallbackPayload{Event: "key.ready"})
+	if !errors.Is(err, ErrCallbackAddressBlocked) {
+		t.Fatalf("Expected ErrCallbackAddressBlocked for a loopback callback, got %v", err)
+	}
+	if attempts := atomic.LoadInt32(&receiver.attempts); attempts != 0 {
+		t.Errorf("Expected no request to reach the loopback receiver, got %d", attempts)
+	}
+
+	for _, address := range []string{"10.0.0.1:80", "192.168.1.10:443", "169.254.169.254:80", "[::1]:80", "[fd00::1]:80", "100.64.0.1:80", "0.0.0.0:80"} {
+		if err := checkCallbackAddress(address); !errors.Is(err, ErrCallbackAddressBlocked) {
+			t.Errorf("Expected %s to be blocked, got %v", address, err)
+		}
+	}
+	if err := checkCallbackAddress("93.184.216.34:443"); err != nil {
+		t.Errorf("Expected a public address to be allowed, got %v", err)
+	}
+}
+
+func TestWebhookDoesNotFollowRedirects(t *testing.T) {
+	receiver := newCallbackReceiver(0)
+	target := httptest.NewServer(receiver)
+	defer target.Close()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer redirect.Close()
+
+	notifier := NewWebhookNotifier([]byte("secret"))
+	notifier.SetAllowPrivateNetworks(true)
+	notifier.SetRetryPolicy(2, time.Millisecond)
+
+	if err := notifier.Deliver(context.Background(), redirect.URL, &qkd.KeyCallbackPayload{Event: "key.ready"}); err == nil {
+		t.Fatal("Expected a redirect to fail delivery")
+	}
+	if attempts := atomic.LoadInt32(&receiver.attempts); attempts != 0 {
+		t.Errorf("Expected the redirect target not to be called, got %d requests", attempts)
+	}
+}
+
+func TestCallbackURLValidation(t *testing.T) {
+	tests := []struct {
+		url        string
+		includeKey bool
+		expected   error
+	}{
+		{"ftp://example.com/hook", false, qkd.ErrInvalidCallbackURL},
+		{"/relative/hook", false, qkd.ErrInvalidCallbackURL},
+		{"http://example.com/hook", true, qkd.ErrInsecureCallbackURL},
+		{"", true, qkd.ErrInvalidCallbackURL},
+		{"https://example.com/hook", true, nil},
+	}
+
+	for _, tt := range tests {
+		req := &qkd.SessionCreateRequest{
+			AliceID:            "alice",
+			KeyLength:          128,
+			CallbackURL:        tt.url,
+			CallbackIncludeKey: tt.includeKey,
+		}
+		if err := req.Validate(); err != tt.expected {
+			t.Errorf("Validate(%q, includeKey=%v): expected %v, got %v", tt.url, tt.includeKey, tt.expected, err)
+		}
+	}
+}